@@ -2,17 +2,13 @@ package main
 
 import (
 	_ "mpc/docs"
-	"mpc/internal/delivery/http"
+	"mpc/internal/app"
 	_ "mpc/internal/domain"
-	"mpc/internal/infrastructure/auth"
 	"mpc/internal/infrastructure/config"
 	"mpc/internal/infrastructure/db"
-	"mpc/internal/infrastructure/ethereum"
 	"mpc/internal/infrastructure/kafka"
 	"mpc/internal/infrastructure/logger"
 	"mpc/internal/infrastructure/redis"
-	"mpc/internal/repository/postgres"
-	"mpc/internal/usecase"
 )
 
 // @title MPC API
@@ -48,36 +44,17 @@ func main() {
 	}
 	defer redisClient.Close()
 
-	// kafka
-	kafkaProducer, err := kafka.NewKafkaProducer(&cfg.Kafka)
+	// kafka; transaction messages go through the outbox and are published by the worker
+	userOpPublisher, err := kafka.NewKafkaBroker(&cfg.Kafka).Publisher(cfg.Kafka.UserOpTopic)
 	if err != nil {
-		log.Fatalf("Failed to initialize Kafka producer: %v", err)
+		log.Fatalf("Failed to initialize Kafka user operation producer: %v", err)
 	}
-	defer kafkaProducer.Close()
+	defer userOpPublisher.Close()
 
-	// jwt
-	jwtConfig := auth.NewJWTConfig(&cfg.JWT)
-	jwtService := auth.NewJWTService(jwtConfig, *redisClient)
-
-	// ethereum
-	ethClient, err := ethereum.NewEthereumClient(&cfg.Ethereum)
+	router, err := app.NewAPIRouter(cfg, dbPool, redisClient, userOpPublisher, log)
 	if err != nil {
-		log.Fatalf("Failed to initialize Ethereum client: %v", err)
+		log.Fatalf("Failed to initialize API: %v", err)
 	}
 
-	// repository
-	userRepo := postgres.NewUserRepo(dbPool)
-	walletRepo := postgres.NewWalletRepo(dbPool)
-	transactionRepo := postgres.NewTransactionRepo(dbPool)
-
-	// usecase
-	walletUC := usecase.NewWalletUC(walletRepo, ethClient)
-	authUC := usecase.NewAuthUC(userRepo, walletUC, *jwtService)
-	userUC := usecase.NewUserUC(userRepo)
-	txnUC := usecase.NewTxnUC(transactionRepo, ethClient, walletUC, *redisClient, kafkaProducer)
-
-	// router
-	router := http.NewRouter(&userUC, &walletUC, &txnUC, &authUC, jwtService, log)
-
 	log.Fatal(router.Run(":8080"))
 }
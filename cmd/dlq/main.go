@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/infrastructure/kafka"
+	"mpc/internal/infrastructure/logger"
+	"os"
+	"time"
+)
+
+// This command inspects the dead-letter topic of a Kafka topic and replays its messages.
+//
+//	dlq list -topic <topic> [-partition 0] [-offset 0] [-limit 20]
+//	dlq replay -topic <topic> -offset <offset> [-partition 0] [-limit 1]
+//
+// Replaying publishes the messages to the topic they failed on again; they stay in the dead-letter
+// topic, so note the offset to continue from.
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatalf("Usage: dlq list|replay [flags]")
+	}
+	command := os.Args[1]
+
+	cfg, err := config.Load(logger.NewLogger())
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
+	topic := flags.String("topic", cfg.Kafka.Topic, "Topic whose dead-letter topic to read")
+	partition := flags.Int("partition", 0, "Partition of the dead-letter topic")
+	offset := flags.Int64("offset", 0, "Offset of the first message")
+	limit := flags.Int("limit", 20, "Maximum number of messages")
+	flags.Parse(os.Args[2:])
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	switch command {
+	case "list":
+		letters, err := kafka.ReadDeadLetters(ctx, &cfg.Kafka, *topic, *partition, *offset, *limit)
+		if err != nil {
+			log.Fatalf("Failed to read dead letters: %v", err)
+		}
+		for _, letter := range letters {
+			printDeadLetter(letter)
+		}
+		log.Printf("%d dead letters in %s/%d from offset %d", len(letters), kafka.DLQTopic(*topic), *partition, *offset)
+	case "replay":
+		if !isFlagSet(flags, "offset") {
+			log.Fatalf("The offset of the message to replay is required")
+		}
+		if !isFlagSet(flags, "limit") {
+			*limit = 1
+		}
+
+		letters, err := kafka.ReadDeadLetters(ctx, &cfg.Kafka, *topic, *partition, *offset, *limit)
+		if err != nil {
+			log.Fatalf("Failed to read dead letters: %v", err)
+		}
+		for _, letter := range letters {
+			if err := kafka.ReplayDeadLetter(ctx, &cfg.Kafka, letter); err != nil {
+				log.Fatalf("Failed to replay dead letter %d: %v", letter.Offset, err)
+			}
+			log.Printf("Replayed dead letter %d to %s", letter.Offset, letter.OriginalTopic)
+		}
+		if len(letters) > 0 {
+			log.Printf("Continue from offset %d", letters[len(letters)-1].Offset+1)
+		}
+	default:
+		log.Fatalf("Unknown command %q, expected list or replay", command)
+	}
+}
+
+func printDeadLetter(letter kafka.DeadLetter) {
+	fmt.Printf("offset %d: key=%s original=%s/%d@%d attempts=%d failed_at=%s\n",
+		letter.Offset, letter.Key, letter.OriginalTopic, letter.OriginalPartition, letter.OriginalOffset,
+		letter.Attempts, letter.FailedAt.Format(time.RFC3339))
+	fmt.Printf("  error: %s\n", letter.Error)
+	fmt.Printf("  value: %s\n", letter.Value)
+}
+
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	set := false
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
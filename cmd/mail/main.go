@@ -2,11 +2,13 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
+	"mpc/internal/domain"
 	"mpc/internal/infrastructure/config"
+	"mpc/internal/infrastructure/events"
 	"mpc/internal/infrastructure/kafka"
+	"mpc/internal/infrastructure/logger"
 	"mpc/internal/infrastructure/mail"
 	"mpc/internal/infrastructure/otp"
 	"mpc/internal/infrastructure/redis"
@@ -14,42 +16,27 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
-	"time"
 )
 
 const (
 	OTPTopic = "otp_emails"
 )
 
-type OTPMessage struct {
-	Email string `json:"email"`
-}
-
-type MailWorkerConfig struct {
-	NumWorkers int `mapstructure:"NUM_WORKERS"`
-}
-
 func main() {
-	cfg, err := config.Load()
+	cfg, err := config.Load(logger.NewLogger())
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
-	consumer, err := kafka.NewKafkaConsumer(cfg, kafka.WithTopic(OTPTopic))
-	if err != nil {
-		log.Fatalf("Failed to create Kafka consumer: %v", err)
-	}
-	defer consumer.Close()
-
-	redisClient, err := redis.NewRedisClient(cfg.Redis)
+	redisClient, err := redis.NewRedisClient(&cfg.Redis)
 	if err != nil {
 		log.Fatalf("Failed to create Redis client: %v", err)
 	}
 	defer redisClient.Close()
 
-	otpService := otp.NewOTPService(redisClient, time.Duration(cfg.MailConfig.OTPExpiration)*time.Second)
+	otpService := otp.NewOTPService(redisClient, cfg.Mail.OTPExpiration)
 
-	mailClient, err := mail.NewClient(&cfg.MailConfig)
+	mailClient, err := mail.NewClient(&cfg.Mail)
 	if err != nil {
 		log.Fatalf("Failed to create mail client: %v", err)
 	}
@@ -57,14 +44,32 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	var mailWorkerConfig MailWorkerConfig
+	// Mails that fail to send are retried with backoff through retry topics, and end up in a
+	// dead-letter topic once their retries are used up
+	broker := kafka.NewKafkaBroker(&cfg.Kafka)
+	retries, err := kafka.NewRetryPipeline(broker, &cfg.Kafka, OTPTopic)
+	if err != nil {
+		log.Fatalf("Failed to initialize Kafka retry topics of %s: %v", OTPTopic, err)
+	}
+	defer retries.Close()
+
+	// Every worker has its own consumers in the mail group, so they split the partitions of the
+	// topic and of its retry topics
 	var wg sync.WaitGroup
-	for i := 0; i < mailWorkerConfig.NumWorkers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			handleOTPMessages(ctx, consumer, otpService, mailClient)
-		}()
+	for i := 0; i < cfg.Mail.NumWorkers; i++ {
+		for _, topic := range append([]string{OTPTopic}, retries.RetryTopics()...) {
+			consumer, err := broker.Subscriber(topic, cfg.Mail.GroupID)
+			if err != nil {
+				log.Fatalf("Failed to create Kafka consumer of %s: %v", topic, err)
+			}
+			defer consumer.Close()
+
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				handleOTPMessages(ctx, consumer, retries, otpService, mailClient)
+			}()
+		}
 	}
 
 	// Graceful shutdown
@@ -76,35 +81,21 @@ func main() {
 	wg.Wait()
 }
 
-func handleOTPMessages(ctx context.Context, consumer *kafka.Reader, otpService otp.OTPService, mailClient *mail.Client) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			m, err := consumer.ReadMessage(ctx)
-			if err != nil {
-				if err == context.Canceled {
-					return
-				}
-				log.Printf("Failed to read message: %v", err)
-				continue
-			}
-
-			var otpMsg OTPMessage
-			if err := json.Unmarshal(m.Value, &otpMsg); err != nil {
-				log.Printf("Failed to unmarshal message: %v", err)
-				continue
-			}
-
-			if err := processOTPMessage(ctx, otpService, mailClient, otpMsg); err != nil {
-				log.Printf("Failed to process OTP message: %v", err)
-			}
+func handleOTPMessages(ctx context.Context, consumer kafka.Subscriber, retries *kafka.RetryPipeline, otpService otp.OTPService, mailClient *mail.Client) {
+	err := kafka.ConsumeMessagesWithRetries(ctx, consumer, retries, func(ctx context.Context, m kafka.Message) error {
+		var otpMsg domain.OTPRequestedEvent
+		if _, err := events.DecodeAs(m.Value, domain.EventUserOTPRequested, &otpMsg); err != nil {
+			// Retrying does not fix a malformed message
+			return kafka.Permanent(fmt.Errorf("failed to decode message: %w", err))
 		}
+		return processOTPMessage(ctx, otpService, mailClient, otpMsg)
+	})
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Stopped consuming OTP messages of %s: %v", consumer.Topic(), err)
 	}
 }
 
-func processOTPMessage(ctx context.Context, otpService otp.OTPService, mailClient *mail.Client, otpMsg OTPMessage) error {
+func processOTPMessage(ctx context.Context, otpService otp.OTPService, mailClient *mail.Client, otpMsg domain.OTPRequestedEvent) error {
 	otp, err := otpService.GenerateOTP(ctx, otpMsg.Email)
 	if err != nil {
 		return fmt.Errorf("failed to generate OTP: %w", err)
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/infrastructure/db"
+	"mpc/internal/infrastructure/ethereum"
+	"mpc/internal/infrastructure/logger"
+	"mpc/internal/repository/postgres"
+	"mpc/internal/usecase"
+
+	"github.com/google/uuid"
+)
+
+// This command adds a new hot wallet to the relayer pool of a chain.
+// The printed address must be funded before it can sponsor gas.
+func main() {
+	chain := flag.String("chain", "", "ID of the chain (chains.id) the relayer wallet belongs to")
+	flag.Parse()
+
+	chainID, err := uuid.Parse(*chain)
+	if err != nil {
+		log.Fatalf("Invalid chain ID %q: %v", *chain, err)
+	}
+
+	cfg, err := config.Load(logger.NewLogger())
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	dbPool, err := db.InitDB(&cfg.DB)
+	if err != nil {
+		log.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer db.CloseDB()
+
+	ethClient, err := ethereum.NewEthereumClient(&cfg.Ethereum)
+	if err != nil {
+		log.Fatalf("Failed to initialize Ethereum client: %v", err)
+	}
+
+	relayerUC, err := usecase.NewRelayerUC(postgres.NewRelayerRepo(dbPool), postgres.NewChainRepo(dbPool), ethClient, ethereum.NewChainClient(&cfg.Ethereum), &cfg.Relayer)
+	if err != nil {
+		log.Fatalf("Failed to initialize relayer: %v", err)
+	}
+
+	wallet, err := relayerUC.CreateRelayerWallet(context.Background(), chainID)
+	if err != nil {
+		log.Fatalf("Failed to create relayer wallet: %v", err)
+	}
+
+	log.Printf("Relayer wallet created: (%v, %v)", wallet.ID, wallet.Address)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"mpc/internal/domain"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+)
+
+// settle refreshes the balances a transaction touched and only then releases its reservation, so the
+// available balance, the stored balance minus the reservations, never counts funds that already left
+// the wallet. When the refresh fails the reservation is kept; a mined transaction settles again once
+// it is final, and the reservation of a dropped or replaced one runs out when it expires.
+func (s *blockScanner) settle(ctx context.Context, txn domain.Transaction) error {
+	if err := s.refreshBalances(ctx, txn); err != nil {
+		return fmt.Errorf("failed to refresh balances of wallet %s: %w", txn.WalletID, err)
+	}
+	releaseReservation(ctx, s.balanceRepo, txn)
+	return nil
+}
+
+// refreshBalances reads the wallet's balances touched by a transaction from the chain and
+// stores them. The native balance always changes, if only by the fee; the token balance is only
+// read when the transaction moved an ERC-20 token of this chain.
+func (s *blockScanner) refreshBalances(ctx context.Context, txn domain.Transaction) error {
+	wallet, err := s.walletRepo.GetWallet(ctx, txn.WalletID)
+	if err != nil {
+		return fmt.Errorf("failed to get wallet: %w", err)
+	}
+	owner := common.HexToAddress(wallet.Address)
+
+	native, err := s.ethRepo.GetBalance(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if err := s.storeBalance(ctx, txn.WalletID, uuid.Nil, native); err != nil {
+		return err
+	}
+
+	if txn.TokenID == uuid.Nil {
+		return nil
+	}
+
+	tokens, err := s.loadTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	for address, token := range tokens {
+		if token.ID != txn.TokenID {
+			continue
+		}
+
+		balance, err := s.ethRepo.GetTokenBalance(ctx, address, owner)
+		if err != nil {
+			return err
+		}
+		return s.storeBalance(ctx, txn.WalletID, token.ID, balance)
+	}
+	return nil
+}
+
+func (s *blockScanner) storeBalance(ctx context.Context, walletID uuid.UUID, tokenID uuid.UUID, balance *big.Int) error {
+	if err := s.balanceRepo.UpsertBalance(ctx, domain.UpsertBalanceParams{
+		WalletID: walletID,
+		ChainID:  s.chain.ID,
+		TokenID:  tokenID,
+		Balance:  balance,
+	}); err != nil {
+		return fmt.Errorf("failed to store balance: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/repository"
+	"mpc/internal/usecase"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+// stubScannerEth is a chain whose head, wallet balance and receipts the test moves by hand. Every
+// block it is asked about is canonical, and every unmined transaction waits in the mempool.
+type stubScannerEth struct {
+	repository.EthereumRepository
+	head       uint64
+	balance    *big.Int
+	balanceErr error
+	receipts   map[common.Hash]*types.Receipt
+	submitted  []common.Hash
+	// pending counts the lookups of unmined transactions in the mempool
+	pending int
+}
+
+func (s *stubScannerEth) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	return s.head, nil
+}
+
+func (s *stubScannerEth) GetBlockHash(ctx context.Context, number uint64) (common.Hash, error) {
+	return common.BigToHash(new(big.Int).SetUint64(number)), nil
+}
+
+func (s *stubScannerEth) FindTransactionReceipts(ctx context.Context, hashes []common.Hash) (map[common.Hash]*types.Receipt, error) {
+	return s.receipts, nil
+}
+
+func (s *stubScannerEth) SubmitTransaction(ctx context.Context, signedTx *types.Transaction) (common.Hash, error) {
+	s.submitted = append(s.submitted, signedTx.Hash())
+	return signedTx.Hash(), nil
+}
+
+func (s *stubScannerEth) GetTransactionState(ctx context.Context, hash common.Hash, from common.Address, nonce uint64) (domain.TxState, error) {
+	s.pending++
+	return domain.TxStatePending, nil
+}
+
+func (s *stubScannerEth) GetBalance(ctx context.Context, address common.Address) (*big.Int, error) {
+	if s.balanceErr != nil {
+		return nil, s.balanceErr
+	}
+	return new(big.Int).Set(s.balance), nil
+}
+
+// stubScannerTxnRepo holds the transactions the scanner follows.
+type stubScannerTxnRepo struct {
+	repository.TransactionRepository
+	transactions map[uuid.UUID]domain.Transaction
+}
+
+func (r *stubScannerTxnRepo) GetSubmittedTransactionsByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.Transaction, error) {
+	var submitted []domain.Transaction
+	for _, txn := range r.transactions {
+		if txn.ChainID == chainID && txn.Status == domain.StatusSubmitted {
+			submitted = append(submitted, txn)
+		}
+	}
+	return submitted, nil
+}
+
+func (r *stubScannerTxnRepo) GetTransaction(ctx context.Context, id uuid.UUID) (domain.Transaction, error) {
+	return r.transactions[id], nil
+}
+
+func (r *stubScannerTxnRepo) UpdateTransaction(ctx context.Context, txn domain.Transaction, messages ...domain.OutboxMessage) error {
+	r.transactions[txn.ID] = txn
+	return nil
+}
+
+type stubScannerWalletRepo struct {
+	repository.WalletRepository
+	wallet domain.Wallet
+}
+
+func (r *stubScannerWalletRepo) GetWallet(ctx context.Context, id uuid.UUID) (domain.Wallet, error) {
+	return r.wallet, nil
+}
+
+type stubScannerChainRepo struct {
+	repository.ChainRepository
+}
+
+func (r *stubScannerChainRepo) GetTokensByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.Token, error) {
+	return nil, nil
+}
+
+// memBalanceRepo keeps the stored native balances and the open reservations of one chain.
+type memBalanceRepo struct {
+	repository.BalanceRepository
+	chainID      uuid.UUID
+	balances     map[uuid.UUID]*big.Int
+	reservations map[uuid.UUID]*big.Int
+}
+
+func (r *memBalanceRepo) UpsertBalance(ctx context.Context, params domain.UpsertBalanceParams) error {
+	r.balances[params.WalletID] = params.Balance
+	return nil
+}
+
+func (r *memBalanceRepo) GetBalancesByWalletID(ctx context.Context, walletID uuid.UUID) ([]domain.Balance, error) {
+	return []domain.Balance{{WalletID: walletID, ChainID: r.chainID, Balance: r.balances[walletID]}}, nil
+}
+
+func (r *memBalanceRepo) ReleaseReservation(ctx context.Context, transactionID uuid.UUID) error {
+	delete(r.reservations, transactionID)
+	return nil
+}
+
+func (r *memBalanceRepo) GetReservedAmount(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) (*big.Int, error) {
+	reserved := new(big.Int)
+	for _, amount := range r.reservations {
+		reserved.Add(reserved, amount)
+	}
+	return reserved, nil
+}
+
+// TestSettleConfirmationWindow follows the available balance of a wallet sending 0.25 ether, from
+// the reservation through the blocks between its receipt and its finality.
+func TestSettleConfirmationWindow(t *testing.T) {
+	const ether = 1_000_000_000_000_000_000
+	funded := big.NewInt(ether)
+	value := big.NewInt(ether / 4)
+	fee := big.NewInt(21_000 * 10_000_000_000)
+	spent := new(big.Int).Sub(funded, new(big.Int).Add(value, fee))
+
+	tests := []struct {
+		name       string
+		balanceErr error
+		// wantReleased tells whether the reservation is gone once the receipt shows up.
+		wantReleased bool
+	}{
+		{
+			name:         "released with the refreshed balance",
+			wantReleased: true,
+		},
+		{
+			// Without a fresh balance the reservation keeps holding the funds back
+			name:       "kept while the balance cannot be read",
+			balanceErr: errors.New("node unavailable"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			userID := uuid.New()
+			wallet := domain.Wallet{ID: uuid.New(), UserID: userID, Address: "0x5FbDB2315678afecb367f032d93F642f64180aa3"}
+			chain := domain.Chain{ID: uuid.New(), Name: "Test", RequiredConfirmations: 2}
+			txn := domain.Transaction{
+				ID:          uuid.New(),
+				WalletID:    wallet.ID,
+				ChainID:     chain.ID,
+				Direction:   domain.DirectionOutbound,
+				FromAddress: wallet.Address,
+				ToAddress:   "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045",
+				Amount:      "0.25",
+				Status:      domain.StatusSubmitted,
+				TxHash:      common.HexToHash("0x01").Hex(),
+			}
+
+			ethRepo := &stubScannerEth{head: 10, balance: funded}
+			txnRepo := &stubScannerTxnRepo{transactions: map[uuid.UUID]domain.Transaction{txn.ID: txn}}
+			walletRepo := &stubScannerWalletRepo{wallet: wallet}
+			balanceRepo := &memBalanceRepo{
+				chainID:      chain.ID,
+				balances:     map[uuid.UUID]*big.Int{wallet.ID: funded},
+				reservations: map[uuid.UUID]*big.Int{txn.ID: new(big.Int).Add(value, fee)},
+			}
+			scanner := &blockScanner{
+				chain:       chain,
+				ethRepo:     ethRepo,
+				chainRepo:   &stubScannerChainRepo{},
+				walletRepo:  walletRepo,
+				txnRepo:     txnRepo,
+				balanceRepo: balanceRepo,
+				relayerRepo: &memRelayerRepo{},
+				cfg:         &config.ScannerConfig{},
+			}
+			walletUC := usecase.NewWalletUC(walletRepo, ethRepo, balanceRepo, "")
+
+			available := func() string {
+				t.Helper()
+				response, err := walletUC.GetBalances(ctx, userID, wallet.ID)
+				if err != nil {
+					t.Fatalf("GetBalances() error = %v", err)
+				}
+				return response.Balances[0].Available
+			}
+
+			if got, want := available(), spent.String(); got != want {
+				t.Fatalf("Available before mining = %s, want %s", got, want)
+			}
+
+			// The transaction is mined in block 11 and has one of its two confirmations
+			ethRepo.head = 11
+			ethRepo.balance = spent
+			ethRepo.balanceErr = tt.balanceErr
+			ethRepo.receipts = map[common.Hash]*types.Receipt{common.HexToHash(txn.TxHash): {
+				Status:            types.ReceiptStatusSuccessful,
+				TxHash:            common.HexToHash(txn.TxHash),
+				BlockNumber:       big.NewInt(11),
+				BlockHash:         common.BigToHash(big.NewInt(11)),
+				GasUsed:           21_000,
+				EffectiveGasPrice: big.NewInt(10_000_000_000),
+			}}
+			if err := scanner.poll(ctx); err != nil {
+				t.Fatalf("poll() error = %v", err)
+			}
+			if mined := txnRepo.transactions[txn.ID]; mined.BlockNumber != 11 || mined.Status != domain.StatusSubmitted {
+				t.Fatalf("Mined transaction is %s at block %d, want submitted at block 11", mined.Status, mined.BlockNumber)
+			}
+			if released := len(balanceRepo.reservations) == 0; released != tt.wantReleased {
+				t.Errorf("Reservation released = %v between receipt and finality, want %v", released, tt.wantReleased)
+			}
+			if got, want := available(), spent.String(); got != want {
+				t.Errorf("Available between receipt and finality = %s, want %s", got, want)
+			}
+
+			// The node is back by the time the transaction is final
+			ethRepo.head = 12
+			ethRepo.balanceErr = nil
+			if err := scanner.poll(ctx); err != nil {
+				t.Fatalf("poll() error = %v", err)
+			}
+			if final := txnRepo.transactions[txn.ID]; final.Status != domain.StatusSuccess {
+				t.Fatalf("Final transaction is %s, want %s", final.Status, domain.StatusSuccess)
+			}
+			if len(balanceRepo.reservations) != 0 {
+				t.Errorf("Reservation still open once final")
+			}
+			if got, want := available(), spent.String(); got != want {
+				t.Errorf("Available once final = %s, want %s", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/bitcoin"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// satoshiDecimals is the number of decimals of bitcoin.
+const satoshiDecimals = 8
+
+// bitcoinScanner follows one Bitcoin chain. It keeps the UTXO set of the wallet addresses it knows
+// about from the blocks it walks, records payments from outside the wallet as inbound transactions
+// and confirms submitted transactions the same way blockScanner does on EVM chains. Unlike EVM
+// deposits, the UTXO set cannot be skipped, so blocks are scanned even when deposit scanning is off.
+type bitcoinScanner struct {
+	chain     domain.Chain
+	backend   repository.BitcoinBackend
+	chainRepo repository.ChainRepository
+	utxoRepo  repository.UTXORepository
+	txnRepo   repository.TransactionRepository
+	cfg       *config.ScannerConfig
+	// eventsTopic is the topic state changes of transactions and deposits are announced on.
+	eventsTopic string
+}
+
+func startBitcoinScanner(ctx context.Context, cfg *config.Config, chain domain.Chain, chainRepo repository.ChainRepository, utxoRepo repository.UTXORepository, txnRepo repository.TransactionRepository) {
+	backend, err := bitcoin.NewBackend(chain)
+	if err != nil {
+		log.Printf("Skipping block scanner for %s: %v", chain.Name, err)
+		return
+	}
+
+	scanner := &bitcoinScanner{
+		chain:       chain,
+		backend:     backend,
+		chainRepo:   chainRepo,
+		utxoRepo:    utxoRepo,
+		txnRepo:     txnRepo,
+		cfg:         &cfg.Scanner,
+		eventsTopic: cfg.Kafka.EventsTopic,
+	}
+	go scanner.run(ctx)
+}
+
+func (s *bitcoinScanner) run(ctx context.Context) {
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.poll(ctx); err != nil {
+			log.Printf("Bitcoin scanner for %s: %v", s.chain.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *bitcoinScanner) poll(ctx context.Context) error {
+	head, err := s.backend.GetBlockCount(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.scan(ctx, head); err != nil {
+		return err
+	}
+
+	return s.confirmTransactions(ctx, head)
+}
+
+// scan processes up to BatchSize blocks after the cursor. When a block does not build on the last
+// scanned one, the cursor and the UTXO set are rewound by the confirmation depth.
+func (s *bitcoinScanner) scan(ctx context.Context, head uint64) error {
+	next, parentHash, err := s.nextBlock(ctx, head)
+	if err != nil {
+		return err
+	}
+	if next > head {
+		return nil
+	}
+
+	last := head
+	if s.cfg.BatchSize > 0 && next+uint64(s.cfg.BatchSize)-1 < head {
+		last = next + uint64(s.cfg.BatchSize) - 1
+	}
+
+	for number := next; number <= last; number++ {
+		hash, err := s.backend.GetBlockHash(ctx, number)
+		if err != nil {
+			return err
+		}
+		block, err := s.backend.GetBlock(ctx, hash)
+		if err != nil {
+			return err
+		}
+
+		if parentHash != "" && block.ParentHash != parentHash {
+			return s.rewind(ctx, number-1)
+		}
+
+		if err := s.processBlock(ctx, block); err != nil {
+			return err
+		}
+
+		if err := s.chainRepo.UpdateChainCursor(ctx, domain.ChainCursor{
+			ChainID:     s.chain.ID,
+			BlockNumber: int64(block.Number),
+			BlockHash:   block.Hash,
+		}); err != nil {
+			return fmt.Errorf("failed to update cursor: %w", err)
+		}
+		parentHash = block.Hash
+	}
+
+	return nil
+}
+
+// processBlock spends the wallet outputs the block's transactions consume, records the outputs they
+// create for wallet addresses and attaches the block to the wallet's own transactions in it.
+// Addresses and outputs are reloaded per block, since a block may pay to change addresses handed out
+// while the scanner was running.
+func (s *bitcoinScanner) processBlock(ctx context.Context, block domain.BitcoinBlock) error {
+	addresses, err := s.utxoRepo.ListBitcoinAddressesByChainID(ctx, s.chain.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list addresses: %w", err)
+	}
+	watched := make(map[string]domain.BitcoinAddress, len(addresses))
+	for _, address := range addresses {
+		watched[address.Address] = address
+	}
+
+	utxos, err := s.utxoRepo.ListUnspentUTXOsByChainID(ctx, s.chain.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list UTXOs: %w", err)
+	}
+	unspent := make(map[domain.BitcoinOutPoint]bool, len(utxos))
+	for _, utxo := range utxos {
+		unspent[domain.BitcoinOutPoint{TxID: utxo.TxID, Vout: utxo.Vout}] = true
+	}
+
+	submitted, err := s.txnRepo.GetSubmittedTransactionsByChainID(ctx, s.chain.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list submitted transactions: %w", err)
+	}
+	outbound := make(map[string]domain.Transaction, len(submitted))
+	for _, txn := range submitted {
+		if txn.Direction != domain.DirectionInbound && txn.BlockNumber == 0 {
+			outbound[txn.TxHash] = txn
+		}
+	}
+
+	deposits := 0
+	for _, tx := range block.Transactions {
+		// A transaction spending wallet outputs was sent by the wallet; its outputs to wallet
+		// addresses are change, not deposits
+		fromWallet := false
+		for _, in := range tx.Inputs {
+			if !unspent[in] {
+				continue
+			}
+			fromWallet = true
+			if err := s.utxoRepo.SpendUTXO(ctx, s.chain.ID, in, tx.TxID, int64(block.Number)); err != nil {
+				return fmt.Errorf("failed to spend %s:%d: %w", in.TxID, in.Vout, err)
+			}
+			delete(unspent, in)
+		}
+
+		for _, out := range tx.Outputs {
+			address, ok := watched[out.Address]
+			if !ok {
+				continue
+			}
+			if err := s.utxoRepo.UpsertUTXO(ctx, domain.UTXO{
+				WalletID:    address.WalletID,
+				ChainID:     s.chain.ID,
+				TxID:        tx.TxID,
+				Vout:        out.Vout,
+				Amount:      out.Amount,
+				Address:     out.Address,
+				PkScript:    out.PkScript,
+				BlockNumber: int64(block.Number),
+				BlockHash:   block.Hash,
+			}); err != nil {
+				return fmt.Errorf("failed to record %s:%d: %w", tx.TxID, out.Vout, err)
+			}
+			unspent[domain.BitcoinOutPoint{TxID: tx.TxID, Vout: out.Vout}] = true
+
+			if fromWallet || address.Change {
+				continue
+			}
+			if err := s.recordDeposit(ctx, block, tx.TxID, out, address.WalletID); err != nil {
+				return err
+			}
+			deposits++
+		}
+
+		if txn, ok := outbound[tx.TxID]; ok {
+			txn.BlockNumber = int64(block.Number)
+			txn.BlockHash = block.Hash
+			if err := s.txnRepo.UpdateTransaction(ctx, txn); err != nil {
+				return fmt.Errorf("failed to update transaction %s: %w", txn.ID, err)
+			}
+		}
+	}
+
+	if deposits > 0 {
+		log.Printf("Recorded %d deposits on %s at block %d", deposits, s.chain.Name, block.Number)
+	}
+	return nil
+}
+
+// rewind moves the cursor back from an orphaned block by the chain's confirmation depth and undoes
+// what the rewound blocks did to the UTXO set; rescanning them applies the canonical blocks instead.
+func (s *bitcoinScanner) rewind(ctx context.Context, orphaned uint64) error {
+	target := uint64(0)
+	if depth := uint64(s.chain.RequiredConfirmations); orphaned > depth {
+		target = orphaned - depth
+	}
+
+	hash, err := s.backend.GetBlockHash(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Reorg detected on %s at block %d, rescanning from block %d", s.chain.Name, orphaned, target+1)
+	if err := s.utxoRepo.RewindUTXOs(ctx, s.chain.ID, int64(target)); err != nil {
+		return fmt.Errorf("failed to rewind UTXOs: %w", err)
+	}
+	if err := s.chainRepo.UpdateChainCursor(ctx, domain.ChainCursor{
+		ChainID:     s.chain.ID,
+		BlockNumber: int64(target),
+		BlockHash:   hash,
+	}); err != nil {
+		return fmt.Errorf("failed to rewind cursor: %w", err)
+	}
+	return nil
+}
+
+// confirmTransactions finalizes submitted transactions whose block has the required number of
+// confirmations. Transactions whose block is no longer canonical lose their block and stay submitted
+// until they are found in a block again, and unmined outbound transactions are rebroadcast.
+func (s *bitcoinScanner) confirmTransactions(ctx context.Context, head uint64) error {
+	transactions, err := s.txnRepo.GetSubmittedTransactionsByChainID(ctx, s.chain.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list submitted transactions: %w", err)
+	}
+
+	for _, txn := range transactions {
+		if err := s.confirmTransaction(ctx, txn, head); err != nil {
+			log.Printf("Failed to confirm transaction %s on %s: %v", txn.ID, s.chain.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *bitcoinScanner) confirmTransaction(ctx context.Context, txn domain.Transaction, head uint64) error {
+	if txn.BlockNumber == 0 {
+		return s.trackPending(ctx, txn)
+	}
+
+	canonical, err := s.backend.GetBlockHash(ctx, uint64(txn.BlockNumber))
+	if err != nil {
+		return err
+	}
+
+	if canonical != txn.BlockHash {
+		log.Printf("Block %d of transaction %s was orphaned, moving it back to submitted", txn.BlockNumber, txn.ID)
+		txn.Status = domain.StatusSubmitted
+		clearReceipt(&txn)
+		return s.txnRepo.UpdateTransaction(ctx, txn)
+	}
+
+	confirmations := int64(head) - txn.BlockNumber + 1
+	if confirmations < int64(s.chain.RequiredConfirmations) {
+		return nil
+	}
+
+	txn.Status = domain.StatusSuccess
+	if err := saveStatus(ctx, s.txnRepo, s.eventsTopic, txn); err != nil {
+		return err
+	}
+	log.Printf("Transaction %s %s after %d confirmations", txn.ID, txn.Status, confirmations)
+	return nil
+}
+
+// trackPending rebroadcasts an unmined outbound transaction, which is a no-op while the node still
+// has it. A transaction the node rejects, typically because its inputs were spent by another one, is
+// marked dropped and its outputs are released for new transactions.
+func (s *bitcoinScanner) trackPending(ctx context.Context, txn domain.Transaction) error {
+	if txn.Direction == domain.DirectionInbound {
+		return nil
+	}
+
+	if err := s.rebroadcast(ctx, txn); err != nil {
+		log.Printf("Transaction %s could not be rebroadcast: %v", txn.ID, err)
+		txn.Status = domain.StatusDropped
+		if err := s.utxoRepo.ReleaseUTXOs(ctx, txn.ID); err != nil {
+			log.Printf("Failed to release UTXOs of transaction %s: %v", txn.ID, err)
+		}
+		return saveStatus(ctx, s.txnRepo, s.eventsTopic, txn)
+	}
+	return nil
+}
+
+func (s *bitcoinScanner) rebroadcast(ctx context.Context, txn domain.Transaction) error {
+	if txn.RawTx == "" {
+		return errors.New("no signed transaction stored")
+	}
+
+	raw, err := hex.DecodeString(txn.RawTx)
+	if err != nil {
+		return fmt.Errorf("invalid signed transaction: %w", err)
+	}
+
+	_, err = s.backend.Broadcast(ctx, raw)
+	return err
+}
+
+// nextBlock returns the first block to scan and the hash it must build on. A chain without a cursor
+// starts at the current head.
+func (s *bitcoinScanner) nextBlock(ctx context.Context, head uint64) (uint64, string, error) {
+	cursor, err := s.chainRepo.GetChainCursor(ctx, s.chain.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return head, "", nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get cursor: %w", err)
+	}
+	return uint64(cursor.BlockNumber) + 1, cursor.BlockHash, nil
+}
+
+// recordDeposit stores a payment to a wallet address as submitted; it becomes successful once its
+// block is final. The output index stands in for the log index, so each output is its own deposit.
+func (s *bitcoinScanner) recordDeposit(ctx context.Context, block domain.BitcoinBlock, txID string, out domain.BitcoinOutput, walletID uuid.UUID) error {
+	params := domain.CreateInboundTransactionParams{
+		ID:          uuid.New(),
+		WalletID:    walletID,
+		ChainID:     s.chain.ID,
+		ToAddress:   out.Address,
+		Amount:      formatUnits(big.NewInt(out.Amount), satoshiDecimals),
+		Status:      domain.StatusSubmitted,
+		TxHash:      txID,
+		LogIndex:    int(out.Vout),
+		BlockNumber: int64(block.Number),
+		BlockHash:   block.Hash,
+	}
+
+	message, err := depositMessage(s.eventsTopic, params)
+	if err != nil {
+		return err
+	}
+	if err := s.txnRepo.CreateInboundTransaction(ctx, params, message); err != nil {
+		return fmt.Errorf("failed to record deposit %s:%d: %w", txID, out.Vout, err)
+	}
+	return nil
+}
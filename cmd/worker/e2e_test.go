@@ -0,0 +1,427 @@
+//go:build e2e
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/auth"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/infrastructure/db"
+	sqlc "mpc/internal/infrastructure/db/sqlc"
+	"mpc/internal/infrastructure/ethereum/simchain"
+	"mpc/internal/infrastructure/kafka"
+	"mpc/internal/infrastructure/logger"
+	"mpc/internal/infrastructure/redis"
+	"mpc/internal/repository"
+	"mpc/internal/repository/postgres"
+	"mpc/internal/usecase"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// The end-to-end suite drives the API usecases and the worker's block scanner against a simulated
+// chain. It needs the Postgres and Redis of docker-compose with all migrations applied, and
+// reads their settings from the environment like the services do. Run it with `make test-e2e`.
+
+// e2eChainID is the chain ID of the chain row the suite recreates for every test.
+const e2eChainID = "1337"
+
+type e2eEnv struct {
+	chain         *simchain.Chain
+	chainID       uuid.UUID
+	nativeTokenID uuid.UUID
+	dbPool        *pgxpool.Pool
+	authUC        usecase.AuthUseCase
+	txnUC         usecase.TxnUseCase
+	outboxRelay   usecase.OutboxRelayUseCase
+	txnRepo       repository.TransactionRepository
+	balanceRepo   repository.BalanceRepository
+	scanner       *blockScanner
+}
+
+func newE2EEnv(t *testing.T, ctx context.Context) *e2eEnv {
+	t.Helper()
+
+	cfg, err := config.Load(logger.NewLogger())
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Ethereum.SecretKey == "" {
+		cfg.Ethereum.SecretKey = "0123456789abcdef0123456789abcdef"
+	}
+
+	chain, err := simchain.New(cfg.Ethereum)
+	if err != nil {
+		t.Fatalf("Failed to start simulated chain: %v", err)
+	}
+	t.Cleanup(func() { chain.Close() })
+
+	dbPool, err := db.InitDB(&cfg.DB)
+	if err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	redisClient, err := redis.NewRedisClient(&cfg.Redis)
+	if err != nil {
+		t.Fatalf("Failed to initialize Redis client: %v", err)
+	}
+	t.Cleanup(func() { redisClient.Close() })
+
+	// Messages stay in memory, so the test does not need Kafka
+	broker := kafka.NewMemoryBroker()
+	kafkaProducer, err := broker.Publisher(cfg.Kafka.Topic)
+	if err != nil {
+		t.Fatalf("Failed to initialize Kafka producer: %v", err)
+	}
+
+	eventsProducer, err := broker.Publisher(cfg.Kafka.EventsTopic)
+	if err != nil {
+		t.Fatalf("Failed to initialize Kafka events producer: %v", err)
+	}
+
+	env := &e2eEnv{chain: chain, dbPool: dbPool}
+	env.seedChain(t, ctx)
+
+	userRepo := postgres.NewUserRepo(dbPool)
+	walletRepo := postgres.NewWalletRepo(dbPool)
+	txnRepo := postgres.NewTransactionRepo(dbPool)
+	chainRepo := postgres.NewChainRepo(dbPool)
+	balanceRepo := postgres.NewBalanceRepo(dbPool)
+	relayerRepo := postgres.NewRelayerRepo(dbPool)
+
+	jwtService := auth.NewJWTService(auth.NewJWTConfig(&cfg.JWT), *redisClient)
+	walletUC := usecase.NewWalletUC(walletRepo, chain, balanceRepo, cfg.Kafka.EventsTopic)
+	relayerUC, err := usecase.NewRelayerUC(relayerRepo, chainRepo, chain, func(domain.Chain) (repository.EthereumRepository, error) { return chain, nil }, &cfg.Relayer)
+	if err != nil {
+		t.Fatalf("Failed to initialize relayer: %v", err)
+	}
+
+	env.authUC = usecase.NewAuthUC(userRepo, walletUC, *jwtService, cfg.Kafka.EventsTopic)
+	evmFamily := usecase.NewEVMFamily(txnRepo, chain, walletUC, relayerUC, nil, nil, balanceRepo, &cfg.Batch, &cfg.Balance, cfg.Kafka.Topic, cfg.Kafka.EventsTopic)
+	env.txnUC = usecase.NewTxnUC(txnRepo, chainRepo, walletUC, usecase.NewChainFamilies(evmFamily), *redisClient, cfg.Kafka.Topic, cfg.Kafka.EventsTopic)
+	env.outboxRelay = usecase.NewOutboxRelayUC(postgres.NewOutboxRepo(dbPool), &cfg.Outbox, kafkaProducer, eventsProducer)
+	env.txnRepo = txnRepo
+	env.balanceRepo = balanceRepo
+	env.scanner = &blockScanner{
+		chain:        domain.Chain{ID: env.chainID, Name: "Simulated", RPCURL: chain.URL(), RequiredConfirmations: 2},
+		scanDeposits: true,
+		ethRepo:      chain,
+		chainRepo:    chainRepo,
+		walletRepo:   walletRepo,
+		txnRepo:      txnRepo,
+		balanceRepo:  balanceRepo,
+		relayerRepo:  relayerRepo,
+		cfg:          &cfg.Scanner,
+		eventsTopic:  cfg.Kafka.EventsTopic,
+	}
+	return env
+}
+
+// seedChain replaces the simulated chain's row, and with it the rows of earlier runs, by a fresh one
+// with its native currency token.
+func (env *e2eEnv) seedChain(t *testing.T, ctx context.Context) {
+	t.Helper()
+
+	if _, err := env.dbPool.Exec(ctx, `DELETE FROM chains WHERE chain_id = $1`, e2eChainID); err != nil {
+		t.Fatalf("Failed to delete chain: %v", err)
+	}
+
+	err := env.dbPool.QueryRow(ctx, `
+		INSERT INTO chains (name, chain_id, rpc_url, native_currency, required_confirmations)
+		VALUES ('Simulated', $1, $2, 'ETH', 2) RETURNING id`, e2eChainID, env.chain.URL()).Scan(&env.chainID)
+	if err != nil {
+		t.Fatalf("Failed to create chain: %v", err)
+	}
+
+	env.nativeTokenID = env.addToken(t, ctx, "0x1", "Ethereum", "ETH")
+}
+
+func (env *e2eEnv) addToken(t *testing.T, ctx context.Context, contractAddress string, name string, symbol string) uuid.UUID {
+	t.Helper()
+
+	var id uuid.UUID
+	err := env.dbPool.QueryRow(ctx, `
+		INSERT INTO tokens (chain_id, contract_address, name, symbol, decimals)
+		VALUES ($1, $2, $3, $4, 18) RETURNING id`, env.chainID, contractAddress, name, symbol).Scan(&id)
+	if err != nil {
+		t.Fatalf("Failed to create token %s: %v", symbol, err)
+	}
+	return id
+}
+
+func (env *e2eEnv) signup(t *testing.T, ctx context.Context) (uuid.UUID, domain.CreateWalletResponse) {
+	t.Helper()
+
+	user, wallet, _, _, err := env.authUC.Signup(ctx, domain.CreateUserParams{
+		Email:    fmt.Sprintf("e2e-%s@example.com", uuid.NewString()),
+		Password: "e2e-password",
+	})
+	if err != nil {
+		t.Fatalf("Signup failed: %v", err)
+	}
+	return user.ID, wallet
+}
+
+func (env *e2eEnv) poll(t *testing.T, ctx context.Context) {
+	t.Helper()
+
+	if err := env.scanner.poll(ctx); err != nil {
+		t.Fatalf("Block scanner poll failed: %v", err)
+	}
+}
+
+func (env *e2eEnv) transaction(t *testing.T, ctx context.Context, id uuid.UUID) domain.Transaction {
+	t.Helper()
+
+	txn, err := env.txnRepo.GetTransaction(ctx, id)
+	if err != nil {
+		t.Fatalf("Failed to get transaction %s: %v", id, err)
+	}
+	return txn
+}
+
+func (env *e2eEnv) inbound(t *testing.T, ctx context.Context, walletID uuid.UUID) domain.Transaction {
+	t.Helper()
+
+	transactions, err := env.txnRepo.GetTransactionsByWalletID(ctx, walletID)
+	if err != nil {
+		t.Fatalf("Failed to list transactions: %v", err)
+	}
+	for _, txn := range transactions {
+		if txn.Direction == domain.DirectionInbound {
+			return txn
+		}
+	}
+	t.Fatalf("No deposit recorded for wallet %s", walletID)
+	return domain.Transaction{}
+}
+
+func (env *e2eEnv) storedBalance(t *testing.T, ctx context.Context, walletID uuid.UUID, tokenID uuid.UUID) *big.Int {
+	t.Helper()
+
+	balances, err := env.balanceRepo.GetBalancesByWalletID(ctx, walletID)
+	if err != nil {
+		t.Fatalf("Failed to get balances: %v", err)
+	}
+	for _, balance := range balances {
+		if balance.ChainID == env.chainID && balance.TokenID == tokenID {
+			return balance.Balance
+		}
+	}
+	t.Fatalf("No balance stored for wallet %s and token %s", walletID, tokenID)
+	return nil
+}
+
+func TestE2ETransactionLifecycle(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	env := newE2EEnv(t, ctx)
+
+	userID, wallet := env.signup(t, ctx)
+	walletAddress := common.HexToAddress(wallet.Address)
+
+	if err := env.chain.Fund(ctx, walletAddress, big.NewInt(params.Ether)); err != nil {
+		t.Fatal(err)
+	}
+	env.poll(t, ctx)
+
+	deposit := env.inbound(t, ctx, wallet.ID)
+	if deposit.Status != domain.StatusSubmitted || deposit.Amount != "1" {
+		t.Fatalf("Deposit recorded as %s of %s, want submitted of 1", deposit.Status, deposit.Amount)
+	}
+
+	recipient := common.HexToAddress("0x00000000000000000000000000000000000e2e01")
+	txnID, err := env.txnUC.CreateTransaction(ctx, userID, domain.CreateTxnRequest{
+		WalletID:  wallet.ID,
+		ChainID:   env.chainID,
+		ToAddress: recipient.Hex(),
+		Amount:    "0.25",
+		TokenID:   env.nativeTokenID,
+	})
+	if err != nil {
+		t.Fatalf("CreateTransaction failed: %v", err)
+	}
+
+	submitted, err := env.txnUC.SubmitTransaction(ctx, userID, txnID)
+	if err != nil {
+		t.Fatalf("SubmitTransaction failed: %v", err)
+	}
+	if submitted.Status != domain.StatusSubmitted || submitted.TxHash == "" {
+		t.Fatalf("Submitted transaction is %s with hash %q", submitted.Status, submitted.TxHash)
+	}
+
+	// The submission was announced through the outbox
+	if sent, err := env.outboxRelay.RelayPending(ctx); err != nil || sent == 0 {
+		t.Fatalf("Relaying the outbox sent %d messages: %v", sent, err)
+	}
+
+	// The first block holds the receipt, the second one makes it final
+	env.chain.Mine(1)
+	env.poll(t, ctx)
+
+	mined := env.transaction(t, ctx, txnID)
+	if mined.Status != domain.StatusSubmitted || mined.BlockNumber == 0 || mined.GasUsed == 0 {
+		t.Fatalf("Mined transaction is %s at block %d using %d gas, want submitted with a receipt", mined.Status, mined.BlockNumber, mined.GasUsed)
+	}
+
+	env.chain.Mine(1)
+	env.poll(t, ctx)
+
+	final := env.transaction(t, ctx, txnID)
+	if final.Status != domain.StatusSuccess {
+		t.Fatalf("Final transaction is %s, want %s", final.Status, domain.StatusSuccess)
+	}
+	if deposit := env.inbound(t, ctx, wallet.ID); deposit.Status != domain.StatusSuccess {
+		t.Fatalf("Final deposit is %s, want %s", deposit.Status, domain.StatusSuccess)
+	}
+
+	received, err := env.chain.GetBalance(ctx, recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := new(big.Int).Div(big.NewInt(params.Ether), big.NewInt(4)); received.Cmp(want) != 0 {
+		t.Fatalf("Recipient received %s wei, want %s", received, want)
+	}
+
+	onChain, err := env.chain.GetBalance(ctx, walletAddress)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored := env.storedBalance(t, ctx, wallet.ID, uuid.Nil); stored.Cmp(onChain) != 0 {
+		t.Fatalf("Stored balance is %s, on-chain balance is %s", stored, onChain)
+	}
+}
+
+func TestE2ETokenDeposit(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	env := newE2EEnv(t, ctx)
+
+	token, err := env.chain.DeployERC20(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tokenID := env.addToken(t, ctx, token.Hex(), "Test Token", "TEST")
+
+	_, wallet := env.signup(t, ctx)
+	walletAddress := common.HexToAddress(wallet.Address)
+
+	amount := new(big.Int).Mul(big.NewInt(42), big.NewInt(params.Ether))
+	if err := env.chain.MintERC20(ctx, token, walletAddress, amount); err != nil {
+		t.Fatal(err)
+	}
+	env.poll(t, ctx)
+
+	deposit := env.inbound(t, ctx, wallet.ID)
+	if deposit.TokenID != tokenID || deposit.Amount != "42" {
+		t.Fatalf("Deposit recorded as %s of token %s, want 42 of token %s", deposit.Amount, deposit.TokenID, tokenID)
+	}
+
+	env.chain.Mine(1)
+	env.poll(t, ctx)
+
+	if deposit := env.inbound(t, ctx, wallet.ID); deposit.Status != domain.StatusSuccess {
+		t.Fatalf("Final deposit is %s, want %s", deposit.Status, domain.StatusSuccess)
+	}
+	if stored := env.storedBalance(t, ctx, wallet.ID, tokenID); stored.Cmp(amount) != 0 {
+		t.Fatalf("Stored token balance is %s, want %s", stored, amount)
+	}
+}
+
+func TestE2EOutboxKeyOrder(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	env := newE2EEnv(t, ctx)
+
+	// A topic of its own keeps the messages of other tests out of the way
+	topic := fmt.Sprintf("e2e_outbox_%s", uuid.NewString())
+	first, second, other := uuid.New(), uuid.New(), uuid.New()
+	q := sqlc.New(env.dbPool)
+	for _, m := range []struct {
+		id  uuid.UUID
+		key string
+	}{{first, "wallet-a"}, {second, "wallet-a"}, {other, "wallet-b"}} {
+		if err := q.CreateOutboxMessage(ctx, sqlc.CreateOutboxMessageParams{
+			ID:      pgtype.UUID{Bytes: m.id, Valid: true},
+			Topic:   topic,
+			Key:     []byte(m.key),
+			Payload: []byte("{}"),
+		}); err != nil {
+			t.Fatalf("Failed to write outbox message: %v", err)
+		}
+	}
+
+	outboxRepo := postgres.NewOutboxRepo(env.dbPool)
+	failing := true
+	var published []uuid.UUID
+	relay := func() {
+		t.Helper()
+		messages, err := outboxRepo.ClaimPendingMessages(ctx, 1000, time.Minute)
+		if err != nil {
+			t.Fatalf("Claiming outbox messages failed: %v", err)
+		}
+
+		var sent []uuid.UUID
+		for _, message := range messages {
+			if message.Topic == topic && failing && message.ID == first {
+				if err := outboxRepo.MarkMessageFailed(ctx, message.ID, "broker unavailable", time.Now()); err != nil {
+					t.Fatalf("Marking outbox message failed: %v", err)
+				}
+				continue
+			}
+			if message.Topic == topic {
+				published = append(published, message.ID)
+			}
+			sent = append(sent, message.ID)
+		}
+		if err := outboxRepo.MarkMessagesSent(ctx, sent); err != nil {
+			t.Fatalf("Marking outbox messages sent failed: %v", err)
+		}
+	}
+
+	// Claimed messages are leased, so a second relay does not claim them while they are published
+	claimed, err := outboxRepo.ClaimPendingMessages(ctx, 1000, time.Minute)
+	if err != nil {
+		t.Fatalf("Claiming outbox messages failed: %v", err)
+	}
+	if again, err := outboxRepo.ClaimPendingMessages(ctx, 1000, time.Minute); err != nil || len(again) != 0 {
+		t.Fatalf("Claimed %d leased messages again: %v", len(again), err)
+	}
+	var ids []uuid.UUID
+	for _, message := range claimed {
+		if message.Topic != topic {
+			ids = append(ids, message.ID)
+		}
+	}
+	if err := outboxRepo.MarkMessagesSent(ctx, ids); err != nil {
+		t.Fatalf("Marking outbox messages sent failed: %v", err)
+	}
+	if _, err := env.dbPool.Exec(ctx, `UPDATE outbox_messages SET locked_until = NULL WHERE topic = $1`, topic); err != nil {
+		t.Fatalf("Failed to end the lease: %v", err)
+	}
+
+	// The failed message holds back the later one of its key, even once it is due again, but not
+	// the messages of other keys
+	relay()
+	relay()
+	if len(published) != 1 || published[0] != other {
+		t.Fatalf("Published %v while the first message fails, want only %s", published, other)
+	}
+
+	failing = false
+	relay()
+	relay()
+	if want := []uuid.UUID{other, first, second}; fmt.Sprint(published) != fmt.Sprint(want) {
+		t.Fatalf("Published %v, want %v", published, want)
+	}
+}
@@ -2,38 +2,49 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"flag"
+	"fmt"
 	"log"
+	"mpc/internal/app"
 	"mpc/internal/domain"
 	"mpc/internal/infrastructure/config"
 	"mpc/internal/infrastructure/db"
+	"mpc/internal/infrastructure/erc4337"
 	"mpc/internal/infrastructure/ethereum"
+	"mpc/internal/infrastructure/events"
 	"mpc/internal/infrastructure/kafka"
+	"mpc/internal/infrastructure/logger"
+	"mpc/internal/infrastructure/redis"
 	"mpc/internal/repository"
 	"mpc/internal/repository/postgres"
-	"strconv"
+	"mpc/internal/usecase"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 const (
 	TopicTxReceipt = "tx_receipt_topic"
-	TopicBalance   = "balance_topic"
 )
 
 type TxReceiptTask struct {
 	TxHash string `json:"tx_hash"`
 }
 
-type BalanceTask struct {
-	Address string `json:"address"`
-}
-
 // This worker is responsible for processing transaction receipts and updating the transaction status.
-// It also listens to the balance topic to update the balance in the database.
+// It also scans new blocks for deposits into user wallets, and refreshes the stored wallet balances
+// whenever a transaction or deposit becomes final. Messages the API commits to the outbox are
+// published to Kafka from here, including the events of every transaction state change, which
+// other services consume from the events topic.
+//
+// With -dev, messages go through an in-memory broker instead of Kafka, and the API runs in the same
+// process, so that the whole backend runs locally with only Postgres and Redis.
 func main() {
-	cfg, err := config.Load()
+	dev := flag.Bool("dev", false, "use an in-memory broker instead of Kafka and serve the API as well")
+	flag.Parse()
+
+	cfg, err := config.Load(logger.NewLogger())
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
@@ -49,71 +60,217 @@ func main() {
 		log.Fatalf("Failed to initialize Ethereum client: %v", err)
 	}
 
+	smartAccountClient, err := erc4337.NewClient(&cfg.Ethereum, &cfg.ERC4337)
+	if err != nil {
+		log.Fatalf("Failed to initialize ERC-4337 client: %v", err)
+	}
+
 	// kafka
-	producer, err := kafka.NewKafkaProducer(&cfg.Kafka)
+	var broker kafka.Broker = kafka.NewKafkaBroker(&cfg.Kafka)
+	if *dev {
+		broker = kafka.NewMemoryBroker()
+	}
+
+	producer, err := broker.Publisher(cfg.Kafka.Topic)
 	if err != nil {
 		log.Fatalf("Failed to initialize Kafka producer: %v", err)
 	}
 	defer producer.Close()
 
-	consumer, err := kafka.NewKafkaConsumer(&cfg.Kafka)
+	eventsProducer, err := broker.Publisher(cfg.Kafka.EventsTopic)
+	if err != nil {
+		log.Fatalf("Failed to initialize Kafka events producer: %v", err)
+	}
+	defer eventsProducer.Close()
+
+	consumer, err := broker.Subscriber(cfg.Kafka.Topic, cfg.Kafka.GroupID)
 	if err != nil {
 		log.Fatalf("Failed to initialize Kafka consumer: %v", err)
 	}
 	defer consumer.Close()
 
+	userOpConsumer, err := broker.Subscriber(cfg.Kafka.UserOpTopic, cfg.Kafka.GroupID)
+	if err != nil {
+		log.Fatalf("Failed to initialize Kafka user operation consumer: %v", err)
+	}
+	defer userOpConsumer.Close()
+
+	// Messages that fail to process are retried with backoff through retry topics, and end up in a
+	// dead-letter topic once their retries are used up
+	txRetries, txConsumers := newRetryConsumers(broker, &cfg.Kafka, cfg.Kafka.Topic, consumer)
+	defer txRetries.Close()
+
+	userOpRetries, userOpConsumers := newRetryConsumers(broker, &cfg.Kafka, cfg.Kafka.UserOpTopic, userOpConsumer)
+	defer userOpRetries.Close()
+
 	// repositories
 	txnRepo := postgres.NewTransactionRepo(dbPool)
+	userOpRepo := postgres.NewUserOperationRepo(dbPool)
+	chainRepo := postgres.NewChainRepo(dbPool)
+	walletRepo := postgres.NewWalletRepo(dbPool)
+	balanceRepo := postgres.NewBalanceRepo(dbPool)
+	relayerRepo := postgres.NewRelayerRepo(dbPool)
+	utxoRepo := postgres.NewUTXORepo(dbPool)
+	outboxRepo := postgres.NewOutboxRepo(dbPool)
 
 	ctx := context.Background()
 
-	go processTxReceiptTopic(ctx, consumer, txnRepo, ethClient)
-	// go processBalanceTopic(ctx, cfg.Kafka.Brokers, ethRepo)
+	// Messages committed to the outbox by the API reach Kafka through the relay
+	outboxRelay := usecase.NewOutboxRelayUC(outboxRepo, &cfg.Outbox, producer, eventsProducer)
+	go outboxRelay.Run(ctx)
+
+	startBlockScanners(ctx, cfg, chainRepo, walletRepo, txnRepo, balanceRepo, relayerRepo, utxoRepo)
+
+	for _, consumer := range txConsumers {
+		go processTxReceiptTopic(ctx, consumer, txRetries, txnRepo, ethClient)
+	}
+	for _, consumer := range userOpConsumers {
+		go processUserOpReceiptTopic(ctx, consumer, userOpRetries, userOpRepo, smartAccountClient)
+	}
+
+	if *dev {
+		go serveAPI(cfg, dbPool, broker)
+	}
 
 	select {}
 }
 
-func processTxReceiptTopic(ctx context.Context, consumer *kafka.Reader, txnRepo repository.TransactionRepository, ethClient *ethereum.EthereumClient) {
-	for {
-		m, err := kafka.ReadNewMessage(ctx, consumer)
-		if err != nil {
-			log.Fatalf("Failed to read message: %v", err)
-		}
-		log.Printf("Received new message")
+// serveAPI runs the API on broker, for dev mode.
+func serveAPI(cfg *config.Config, dbPool *pgxpool.Pool, broker kafka.Broker) {
+	redisClient, err := redis.NewRedisClient(&cfg.Redis)
+	if err != nil {
+		log.Fatalf("Failed to initialize Redis client: %v", err)
+	}
+	defer redisClient.Close()
 
-		txnID := uuid.MustParse(string(m.Key))
-		txnData := m.Value
-		// TODO: Decode the transaction data
-		var txn domain.TxnMessage
-		err = json.Unmarshal(txnData, &txn)
-		if err != nil {
-			log.Fatalf("Failed to decode transaction data: %v", err)
-		}
+	userOpPublisher, err := broker.Publisher(cfg.Kafka.UserOpTopic)
+	if err != nil {
+		log.Fatalf("Failed to initialize Kafka user operation producer: %v", err)
+	}
+	defer userOpPublisher.Close()
 
-		// Fetch the transaction from the database
-		txnFound, err := txnRepo.GetTransaction(ctx, txnID)
+	router, err := app.NewAPIRouter(cfg, dbPool, redisClient, userOpPublisher, logger.NewLogger())
+	if err != nil {
+		log.Fatalf("Failed to initialize API: %v", err)
+	}
+	log.Fatal(router.Run(":8080"))
+}
+
+// newRetryConsumers returns the retry pipeline of topic, along with consumer and consumers for each of
+// its retry topics.
+func newRetryConsumers(broker kafka.Broker, cfg *config.KafkaConfig, topic string, consumer kafka.Subscriber) (*kafka.RetryPipeline, []kafka.Subscriber) {
+	retries, err := kafka.NewRetryPipeline(broker, cfg, topic)
+	if err != nil {
+		log.Fatalf("Failed to initialize Kafka retry topics of %s: %v", topic, err)
+	}
+
+	consumers := []kafka.Subscriber{consumer}
+	for _, retryTopic := range retries.RetryTopics() {
+		retryConsumer, err := broker.Subscriber(retryTopic, cfg.GroupID)
 		if err != nil {
-			log.Fatalf("Transaction not found: %v", err)
+			log.Fatalf("Failed to initialize Kafka consumer of %s: %v", retryTopic, err)
 		}
+		consumers = append(consumers, retryConsumer)
+	}
+	return retries, consumers
+}
 
-		if txnFound.Status == domain.StatusSubmitted {
-			// TODO: Process the transaction receipt
-			receipt, err := ethClient.GetTransactionReceipt(ctx, common.HexToHash(txn.TxHash))
-			if err != nil {
-				log.Fatalf("Failed to fetch transaction receipt: %v", err)
-			}
-			ethClient.ParseTransactionReceipt(receipt)
-			// Update the transaction status in the database
-			txnFound.Status = domain.StatusSuccess
-			txnFound.GasPrice = receipt.EffectiveGasPrice.String()
-			txnFound.GasLimit = strconv.FormatUint(receipt.GasUsed, 10)
-			txnFound.Nonce = int64(receipt.TransactionIndex)
-
-			err = txnRepo.UpdateTransaction(ctx, txnFound)
-			if err != nil {
-				log.Fatalf("Failed to update transaction status: %v", err)
-			}
-			log.Printf("Transaction status updated: (%v, %v)", txnFound.ID, txnFound.Status)
-		}
+// processTxReceiptTopic records the receipt of transactions that are already mined when their message
+// arrives. Transactions still in the mempool are followed by the block scanner of their chain, which
+// also rebroadcasts dropped ones and detects replaced ones.
+func processTxReceiptTopic(ctx context.Context, consumer kafka.Subscriber, retries *kafka.RetryPipeline, txnRepo repository.TransactionRepository, ethClient *ethereum.EthereumClient) {
+	err := kafka.ConsumeMessagesWithRetries(ctx, consumer, retries, func(ctx context.Context, m kafka.Message) error {
+		return handleTxReceiptMessage(ctx, m, txnRepo, ethClient)
+	})
+	log.Printf("Stopped consuming transaction messages of %s: %v", consumer.Topic(), err)
+}
+
+func handleTxReceiptMessage(ctx context.Context, m kafka.Message, txnRepo repository.TransactionRepository, ethClient *ethereum.EthereumClient) error {
+	log.Printf("Received new message")
+
+	txnID, err := uuid.Parse(string(m.Key))
+	if err != nil {
+		return kafka.Permanent(fmt.Errorf("invalid transaction ID %q: %w", m.Key, err))
+	}
+
+	var txn domain.TransactionEvent
+	if _, err := events.DecodeAs(m.Value, domain.EventTransactionSubmitted, &txn); err != nil {
+		return kafka.Permanent(fmt.Errorf("failed to decode transaction data: %w", err))
+	}
+
+	// Fetch the transaction from the database
+	txnFound, err := txnRepo.GetTransaction(ctx, txnID)
+	if err != nil {
+		return fmt.Errorf("transaction not found: %w", err)
+	}
+
+	if txnFound.Status != domain.StatusSubmitted || txnFound.BlockNumber != 0 {
+		return nil
+	}
+
+	receipt, err := ethClient.FindTransactionReceipt(ctx, common.HexToHash(txn.TxHash))
+	if err != nil {
+		return fmt.Errorf("failed to fetch transaction receipt: %w", err)
+	}
+	if receipt == nil {
+		log.Printf("Transaction %v not mined yet, leaving it to the block scanner", txnFound.ID)
+		return nil
+	}
+
+	ethClient.ParseTransactionReceipt(receipt)
+	reconcileReceipt(ctx, ethClient, &txnFound, receipt)
+
+	if err := txnRepo.UpdateTransaction(ctx, txnFound); err != nil {
+		return fmt.Errorf("failed to update transaction status: %w", err)
+	}
+	// The reservation is released by the block scanner of the transaction's chain once it has
+	// refreshed the stored balances, at the latest when the transaction is final
+	log.Printf("Transaction mined: (%v, block %d)", txnFound.ID, txnFound.BlockNumber)
+	return nil
+}
+
+func processUserOpReceiptTopic(ctx context.Context, consumer kafka.Subscriber, retries *kafka.RetryPipeline, userOpRepo repository.UserOperationRepository, smartAccountClient *erc4337.Client) {
+	err := kafka.ConsumeMessagesWithRetries(ctx, consumer, retries, func(ctx context.Context, m kafka.Message) error {
+		return handleUserOpReceiptMessage(ctx, m, userOpRepo, smartAccountClient)
+	})
+	log.Printf("Stopped consuming user operation messages of %s: %v", consumer.Topic(), err)
+}
+
+func handleUserOpReceiptMessage(ctx context.Context, m kafka.Message, userOpRepo repository.UserOperationRepository, smartAccountClient *erc4337.Client) error {
+	userOpID, err := uuid.Parse(string(m.Key))
+	if err != nil {
+		return kafka.Permanent(fmt.Errorf("invalid user operation ID %q: %w", m.Key, err))
+	}
+
+	var msg domain.UserOpMessage
+	if _, err := events.DecodeAs(m.Value, domain.EventUserOperationSubmitted, &msg); err != nil {
+		return kafka.Permanent(fmt.Errorf("failed to decode user operation message: %w", err))
+	}
+
+	userOp, err := userOpRepo.GetUserOperation(ctx, userOpID)
+	if err != nil {
+		return fmt.Errorf("user operation not found: %w", err)
+	}
+
+	if userOp.Status != domain.StatusSubmitted {
+		return nil
+	}
+
+	receipt, err := smartAccountClient.WaitForUserOperationReceipt(ctx, common.HexToHash(msg.UserOpHash))
+	if err != nil {
+		return fmt.Errorf("failed to fetch user operation receipt: %w", err)
+	}
+
+	userOp.Status = domain.StatusSuccess
+	if !receipt.Success {
+		userOp.Status = domain.StatusFailed
+	}
+	userOp.TxHash = receipt.TxHash.Hex()
+	userOp.ActualGasCost = receipt.ActualGasCost.String()
+
+	if err := userOpRepo.UpdateUserOperation(ctx, userOp); err != nil {
+		return fmt.Errorf("failed to update user operation status: %w", err)
 	}
+	log.Printf("User operation status updated: (%v, %v)", userOp.ID, userOp.Status)
+	return nil
 }
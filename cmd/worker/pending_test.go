@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+// stubPendingEth reports every unmined transaction in state, knows a deposit only when known, and
+// fails submissions with submitErr.
+type stubPendingEth struct {
+	*stubScannerEth
+	state     domain.TxState
+	known     bool
+	submitErr error
+}
+
+func (s *stubPendingEth) GetTransactionState(ctx context.Context, hash common.Hash, from common.Address, nonce uint64) (domain.TxState, error) {
+	return s.state, nil
+}
+
+func (s *stubPendingEth) HasTransaction(ctx context.Context, hash common.Hash) (bool, error) {
+	return s.known, nil
+}
+
+func (s *stubPendingEth) SubmitTransaction(ctx context.Context, signedTx *types.Transaction) (common.Hash, error) {
+	if s.submitErr != nil {
+		return common.Hash{}, s.submitErr
+	}
+	return s.stubScannerEth.SubmitTransaction(ctx, signedTx)
+}
+
+func TestTrackPending(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction domain.Direction
+		state     domain.TxState
+		known     bool
+		submitErr error
+		// noRawTx leaves the signed transaction out of the stored one
+		noRawTx bool
+		// wantRebroadcast tells whether the signed transaction is sent to the node again
+		wantRebroadcast bool
+		wantStatus      domain.Status
+		// wantReleased tells whether the reservation of the transaction is given back
+		wantReleased bool
+	}{
+		{
+			name:       "still in the mempool",
+			direction:  domain.DirectionOutbound,
+			state:      domain.TxStatePending,
+			wantStatus: domain.StatusSubmitted,
+		},
+		{
+			name:            "dropped and rebroadcast",
+			direction:       domain.DirectionOutbound,
+			state:           domain.TxStateDropped,
+			wantRebroadcast: true,
+			wantStatus:      domain.StatusSubmitted,
+		},
+		{
+			// The node picked the transaction up again before the rebroadcast
+			name:       "dropped and already known again",
+			direction:  domain.DirectionOutbound,
+			state:      domain.TxStateDropped,
+			submitErr:  errors.New("already known"),
+			wantStatus: domain.StatusSubmitted,
+		},
+		{
+			name:         "dropped and rejected on rebroadcast",
+			direction:    domain.DirectionOutbound,
+			state:        domain.TxStateDropped,
+			submitErr:    errors.New("nonce too low"),
+			wantStatus:   domain.StatusDropped,
+			wantReleased: true,
+		},
+		{
+			name:         "dropped without a stored signed transaction",
+			direction:    domain.DirectionOutbound,
+			state:        domain.TxStateDropped,
+			noRawTx:      true,
+			wantStatus:   domain.StatusDropped,
+			wantReleased: true,
+		},
+		{
+			name:         "replaced",
+			direction:    domain.DirectionOutbound,
+			state:        domain.TxStateReplaced,
+			wantStatus:   domain.StatusReplaced,
+			wantReleased: true,
+		},
+		{
+			// An orphaned deposit the node still knows is mined again
+			name:       "orphaned deposit still known",
+			direction:  domain.DirectionInbound,
+			known:      true,
+			wantStatus: domain.StatusSubmitted,
+		},
+		{
+			name:       "orphaned deposit no longer known",
+			direction:  domain.DirectionInbound,
+			wantStatus: domain.StatusDropped,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			wallet := domain.Wallet{ID: uuid.New(), UserID: uuid.New(), Address: "0x5FbDB2315678afecb367f032d93F642f64180aa3"}
+			chain := domain.Chain{ID: uuid.New(), Name: "Test", RequiredConfirmations: 2}
+
+			to := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+			signedTx := types.NewTx(&types.LegacyTx{Nonce: 7, To: &to, Value: big.NewInt(1), Gas: 21_000, GasPrice: big.NewInt(10_000_000_000)})
+			raw, err := signedTx.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			txn := domain.Transaction{
+				ID:          uuid.New(),
+				WalletID:    wallet.ID,
+				ChainID:     chain.ID,
+				Direction:   tt.direction,
+				FromAddress: wallet.Address,
+				ToAddress:   to.Hex(),
+				Amount:      "0.000000000000000001",
+				Nonce:       7,
+				Status:      domain.StatusSubmitted,
+				TxHash:      signedTx.Hash().Hex(),
+				RawTx:       hexutil.Encode(raw),
+			}
+			if tt.noRawTx {
+				txn.RawTx = ""
+			}
+
+			ethRepo := &stubPendingEth{
+				stubScannerEth: &stubScannerEth{head: 10, balance: big.NewInt(1_000_000_000_000_000_000)},
+				state:          tt.state,
+				known:          tt.known,
+				submitErr:      tt.submitErr,
+			}
+			txnRepo := &stubScannerTxnRepo{transactions: map[uuid.UUID]domain.Transaction{txn.ID: txn}}
+			balanceRepo := &memBalanceRepo{
+				chainID:      chain.ID,
+				balances:     map[uuid.UUID]*big.Int{},
+				reservations: map[uuid.UUID]*big.Int{txn.ID: big.NewInt(1)},
+			}
+			scanner := &blockScanner{
+				chain:       chain,
+				ethRepo:     ethRepo,
+				chainRepo:   &stubScannerChainRepo{},
+				walletRepo:  &stubScannerWalletRepo{wallet: wallet},
+				txnRepo:     txnRepo,
+				balanceRepo: balanceRepo,
+				relayerRepo: &memRelayerRepo{},
+				cfg:         &config.ScannerConfig{},
+			}
+
+			if err := scanner.trackPending(ctx, txn); err != nil {
+				t.Fatalf("trackPending() error = %v", err)
+			}
+
+			if got := txnRepo.transactions[txn.ID].Status; got != tt.wantStatus {
+				t.Errorf("Status = %s, want %s", got, tt.wantStatus)
+			}
+			rebroadcast := len(ethRepo.submitted) == 1 && ethRepo.submitted[0] == signedTx.Hash()
+			if rebroadcast != tt.wantRebroadcast || len(ethRepo.submitted) > 1 {
+				t.Errorf("Submitted %v, want a rebroadcast of %s: %t", ethRepo.submitted, signedTx.Hash(), tt.wantRebroadcast)
+			}
+			if _, held := balanceRepo.reservations[txn.ID]; held == tt.wantReleased {
+				t.Errorf("Reservation held = %t, want %t", held, !tt.wantReleased)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/events"
+	"mpc/internal/repository"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// revertedWithoutReason is stored when a reverted transaction's reason cannot be decoded.
+const revertedWithoutReason = "execution reverted"
+
+// reconcileReceipt copies the outcome of a mined transaction onto it: its block, the gas it used,
+// the effective gas price, the total fee and, when it reverted, the decoded revert reason.
+// The status is left alone; the block scanner turns it into success or failed once the block is final.
+func reconcileReceipt(ctx context.Context, ethRepo repository.EthereumRepository, txn *domain.Transaction, receipt *types.Receipt) {
+	txn.BlockNumber = receipt.BlockNumber.Int64()
+	txn.BlockHash = receipt.BlockHash.Hex()
+	txn.GasUsed = int64(receipt.GasUsed)
+	txn.EffectiveGasPrice = ""
+	txn.Fee = ""
+	txn.RevertReason = ""
+
+	if receipt.EffectiveGasPrice != nil {
+		txn.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
+		txn.Fee = new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice).String()
+	}
+
+	if receipt.Status == types.ReceiptStatusFailed {
+		reason, err := ethRepo.GetRevertReason(ctx, receipt.TxHash, receipt.BlockNumber)
+		if err != nil {
+			log.Printf("Failed to decode revert reason of %s: %v", receipt.TxHash.Hex(), err)
+		}
+		if reason == "" {
+			reason = revertedWithoutReason
+		}
+		txn.RevertReason = reason
+	}
+}
+
+// finalStatus returns the status of a transaction whose block is final.
+func finalStatus(txn domain.Transaction) domain.Status {
+	if txn.RevertReason != "" {
+		return domain.StatusFailed
+	}
+	return domain.StatusSuccess
+}
+
+// clearReceipt forgets the receipt of a transaction whose block was orphaned.
+func clearReceipt(txn *domain.Transaction) {
+	txn.BlockNumber = 0
+	txn.BlockHash = ""
+	txn.GasUsed = 0
+	txn.EffectiveGasPrice = ""
+	txn.Fee = ""
+	txn.RevertReason = ""
+}
+
+// saveStatus stores a transaction whose status changed, together with the event announcing the change
+// on the events topic.
+func saveStatus(ctx context.Context, txnRepo repository.TransactionRepository, eventsTopic string, txn domain.Transaction) error {
+	message, err := events.NewTransactionMessage(eventsTopic, txn)
+	if err != nil {
+		return err
+	}
+	return txnRepo.UpdateTransaction(ctx, txn, message)
+}
+
+// depositMessage returns the event announcing a deposit recorded with params.
+func depositMessage(eventsTopic string, params domain.CreateInboundTransactionParams) (domain.OutboxMessage, error) {
+	return events.NewWalletMessage(eventsTopic, params.WalletID, domain.EventWalletDepositReceived, domain.NewTransactionEvent(domain.Transaction{
+		ID:          params.ID,
+		WalletID:    params.WalletID,
+		ChainID:     params.ChainID,
+		Direction:   domain.DirectionInbound,
+		FromAddress: params.FromAddress,
+		ToAddress:   params.ToAddress,
+		Amount:      params.Amount,
+		TokenID:     params.TokenID,
+		Status:      params.Status,
+		TxHash:      params.TxHash,
+		BlockNumber: params.BlockNumber,
+	}))
+}
+
+// releaseReservation gives back the funds reserved for a transaction once they left the wallet
+// on chain, or once the transaction can no longer be mined.
+func releaseReservation(ctx context.Context, balanceRepo repository.BalanceRepository, txn domain.Transaction) {
+	if err := balanceRepo.ReleaseReservation(ctx, txn.ID); err != nil {
+		log.Printf("Failed to release balance reservation of transaction %s: %v", txn.ID, err)
+	}
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/events"
+	"mpc/internal/repository"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+// stubRevertEth decodes the revert reason of every reverted transaction as reason, or fails with err.
+type stubRevertEth struct {
+	repository.EthereumRepository
+	reason string
+	err    error
+}
+
+func (s *stubRevertEth) GetRevertReason(ctx context.Context, hash common.Hash, blockNumber *big.Int) (string, error) {
+	return s.reason, s.err
+}
+
+func TestReconcileReceipt(t *testing.T) {
+	tests := []struct {
+		name              string
+		status            uint64
+		effectiveGasPrice *big.Int
+		reason            string
+		reasonErr         error
+		wantFee           string
+		wantRevert        string
+		wantStatus        domain.Status
+	}{
+		{
+			name:              "successful",
+			status:            types.ReceiptStatusSuccessful,
+			effectiveGasPrice: big.NewInt(10_000_000_000),
+			wantFee:           "210000000000000",
+			wantStatus:        domain.StatusSuccess,
+		},
+		{
+			name:              "reverted with a reason",
+			status:            types.ReceiptStatusFailed,
+			effectiveGasPrice: big.NewInt(10_000_000_000),
+			reason:            "ERC20: transfer amount exceeds balance",
+			wantFee:           "210000000000000",
+			wantRevert:        "ERC20: transfer amount exceeds balance",
+			wantStatus:        domain.StatusFailed,
+		},
+		{
+			// A reverted transaction fails even when its reason cannot be read
+			name:              "reverted without a readable reason",
+			status:            types.ReceiptStatusFailed,
+			effectiveGasPrice: big.NewInt(10_000_000_000),
+			reasonErr:         errors.New("method not found"),
+			wantFee:           "210000000000000",
+			wantRevert:        revertedWithoutReason,
+			wantStatus:        domain.StatusFailed,
+		},
+		{
+			// Nodes from before London do not report an effective gas price
+			name:       "no effective gas price",
+			status:     types.ReceiptStatusSuccessful,
+			wantStatus: domain.StatusSuccess,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A transaction that was mined, orphaned and mined again keeps nothing of its first receipt
+			txn := domain.Transaction{Nonce: 42, Fee: "1", EffectiveGasPrice: "1", RevertReason: "stale"}
+			receipt := &types.Receipt{
+				Status:            tt.status,
+				TxHash:            common.HexToHash("0x01"),
+				BlockNumber:       big.NewInt(100),
+				BlockHash:         common.HexToHash("0x64"),
+				GasUsed:           21_000,
+				EffectiveGasPrice: tt.effectiveGasPrice,
+				// The index within the block is not the nonce
+				TransactionIndex: 3,
+			}
+
+			reconcileReceipt(context.Background(), &stubRevertEth{reason: tt.reason, err: tt.reasonErr}, &txn, receipt)
+
+			if txn.BlockNumber != 100 || txn.BlockHash != receipt.BlockHash.Hex() || txn.GasUsed != 21_000 {
+				t.Errorf("Receipt recorded at block %d (%s) with %d gas, want block 100 (%s) with 21000 gas", txn.BlockNumber, txn.BlockHash, txn.GasUsed, receipt.BlockHash.Hex())
+			}
+			if txn.Fee != tt.wantFee {
+				t.Errorf("Fee = %q, want %q", txn.Fee, tt.wantFee)
+			}
+			if txn.RevertReason != tt.wantRevert {
+				t.Errorf("RevertReason = %q, want %q", txn.RevertReason, tt.wantRevert)
+			}
+			if txn.Nonce != 42 {
+				t.Errorf("Nonce = %d, want the signed nonce 42", txn.Nonce)
+			}
+			if got := finalStatus(txn); got != tt.wantStatus {
+				t.Errorf("finalStatus() = %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestClearReceipt(t *testing.T) {
+	txn := domain.Transaction{
+		Nonce:             42,
+		TxHash:            common.HexToHash("0x01").Hex(),
+		BlockNumber:       100,
+		BlockHash:         common.HexToHash("0x64").Hex(),
+		GasUsed:           21_000,
+		EffectiveGasPrice: "10",
+		Fee:               "210000",
+		RevertReason:      "reverted",
+	}
+
+	clearReceipt(&txn)
+
+	want := domain.Transaction{Nonce: 42, TxHash: txn.TxHash}
+	if txn != want {
+		t.Errorf("clearReceipt() left %+v, want only the hash and nonce", txn)
+	}
+}
+
+// recordingTxnRepo records every update with the outbox messages written along with it.
+type recordingTxnRepo struct {
+	repository.TransactionRepository
+	updated  []domain.Transaction
+	messages [][]domain.OutboxMessage
+}
+
+func (r *recordingTxnRepo) UpdateTransaction(ctx context.Context, txn domain.Transaction, messages ...domain.OutboxMessage) error {
+	r.updated = append(r.updated, txn)
+	r.messages = append(r.messages, messages)
+	return nil
+}
+
+func TestSaveStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction domain.Direction
+		status    domain.Status
+		// wantEvent is the event written with the status; none means nothing is written
+		wantEvent domain.EventType
+	}{
+		{name: "confirmed", direction: domain.DirectionOutbound, status: domain.StatusSuccess, wantEvent: domain.EventTransactionConfirmed},
+		{name: "failed", direction: domain.DirectionOutbound, status: domain.StatusFailed, wantEvent: domain.EventTransactionFailed},
+		{name: "dropped", direction: domain.DirectionOutbound, status: domain.StatusDropped, wantEvent: domain.EventTransactionDropped},
+		{name: "replaced", direction: domain.DirectionOutbound, status: domain.StatusReplaced, wantEvent: domain.EventTransactionReplaced},
+		{name: "orphaned deposit dropped", direction: domain.DirectionInbound, status: domain.StatusDropped, wantEvent: domain.EventTransactionDropped},
+		{
+			// A status change that cannot be announced is not stored either
+			name:      "deposit replaced",
+			direction: domain.DirectionInbound,
+			status:    domain.StatusReplaced,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := domain.Transaction{
+				ID:          uuid.New(),
+				WalletID:    uuid.New(),
+				ChainID:     uuid.New(),
+				Direction:   tt.direction,
+				FromAddress: "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+				ToAddress:   "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045",
+				Amount:      "0.25",
+				Status:      tt.status,
+				TxHash:      common.HexToHash("0x01").Hex(),
+				BlockNumber: 100,
+			}
+			txnRepo := &recordingTxnRepo{}
+
+			err := saveStatus(context.Background(), txnRepo, "events", txn)
+
+			if tt.wantEvent == "" {
+				if err == nil || len(txnRepo.updated) != 0 {
+					t.Fatalf("saveStatus() error = %v with %d updates, want an error and no update", err, len(txnRepo.updated))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("saveStatus() error = %v", err)
+			}
+			if len(txnRepo.updated) != 1 || txnRepo.updated[0].Status != tt.status {
+				t.Fatalf("Updates = %v, want one update to %s", txnRepo.updated, tt.status)
+			}
+			if len(txnRepo.messages[0]) != 1 || txnRepo.messages[0][0].Topic != "events" {
+				t.Fatalf("Update wrote %v, want one message on the events topic", txnRepo.messages[0])
+			}
+			var event domain.TransactionEvent
+			if _, err := events.DecodeAs(txnRepo.messages[0][0].Payload, tt.wantEvent, &event); err != nil {
+				t.Fatalf("Failed to decode message as %s: %v", tt.wantEvent, err)
+			}
+			if event.TransactionID != txn.ID || event.Status != tt.status {
+				t.Errorf("Event about transaction %s with status %s, want %s with %s", event.TransactionID, event.Status, txn.ID, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,494 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/infrastructure/ethereum"
+	"mpc/internal/infrastructure/events"
+	"mpc/internal/repository"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// nativeDecimals is the number of decimals of the native currency on EVM chains.
+const nativeDecimals = 18
+
+// blockScanner follows one chain. It walks new blocks and records deposits into user wallets as
+// inbound transactions, storing the last scanned block so a restart resumes where it left off.
+// It also finalizes submitted transactions once their block has enough confirmations, moves
+// them back to submitted when a reorg orphans their block, and follows the ones still unmined.
+type blockScanner struct {
+	chain        domain.Chain
+	scanDeposits bool
+	ethRepo      repository.EthereumRepository
+	chainRepo    repository.ChainRepository
+	walletRepo   repository.WalletRepository
+	txnRepo      repository.TransactionRepository
+	balanceRepo  repository.BalanceRepository
+	relayerRepo  repository.RelayerRepository
+	cfg          *config.ScannerConfig
+	// eventsTopic is the topic state changes of transactions and deposits are announced on.
+	eventsTopic string
+}
+
+// startBlockScanners starts one scanner per chain, using the RPC URLs stored with the chain.
+func startBlockScanners(ctx context.Context, cfg *config.Config, chainRepo repository.ChainRepository, walletRepo repository.WalletRepository, txnRepo repository.TransactionRepository, balanceRepo repository.BalanceRepository, relayerRepo repository.RelayerRepository, utxoRepo repository.UTXORepository) {
+	chains, err := chainRepo.ListChains(ctx)
+	if err != nil {
+		log.Printf("Failed to list chains for block scanning: %v", err)
+		return
+	}
+
+	for _, chain := range chains {
+		if chain.Family == domain.ChainFamilyBitcoin {
+			startBitcoinScanner(ctx, cfg, chain, chainRepo, utxoRepo, txnRepo)
+			continue
+		}
+
+		ethCfg := cfg.Ethereum
+		ethCfg.URL = chain.RPCURL
+		ethCfg.FallbackURLs = chain.FallbackRPCURLs
+		ethClient, err := ethereum.NewEthereumClient(&ethCfg)
+		if err != nil {
+			log.Printf("Skipping block scanner for %s: %v", chain.Name, err)
+			continue
+		}
+
+		scanner := &blockScanner{
+			chain:        chain,
+			scanDeposits: cfg.Scanner.Enabled,
+			ethRepo:      ethClient,
+			chainRepo:    chainRepo,
+			walletRepo:   walletRepo,
+			txnRepo:      txnRepo,
+			balanceRepo:  balanceRepo,
+			relayerRepo:  relayerRepo,
+			cfg:          &cfg.Scanner,
+			eventsTopic:  cfg.Kafka.EventsTopic,
+		}
+		go scanner.run(ctx)
+	}
+}
+
+// run polls the chain on every new block. Blocks come from a head subscription when the RPC supports
+// one and from polling every PollInterval otherwise; blocks that arrive while a poll is running are
+// handled by a single follow-up poll.
+func (s *blockScanner) run(ctx context.Context) {
+	tokens, err := s.loadTokens(ctx)
+	if err != nil {
+		log.Printf("Block scanner for %s: %v", s.chain.Name, err)
+	}
+	tokenAddresses := make([]common.Address, 0, len(tokens))
+	for address := range tokens {
+		tokenAddresses = append(tokenAddresses, address)
+	}
+
+	events := s.ethRepo.WatchChain(ctx, tokenAddresses, s.cfg.PollInterval)
+	for event := range events {
+		if event.Reorg {
+			log.Printf("Logs of block %d on %s were removed", event.BlockNumber, s.chain.Name)
+		}
+		drain(events)
+
+		if err := s.poll(ctx); err != nil {
+			log.Printf("Block scanner for %s: %v", s.chain.Name, err)
+		}
+	}
+}
+
+// drain discards the events already queued, since one poll covers all of them.
+func drain(events <-chan domain.ChainEvent) {
+	for {
+		select {
+		case _, ok := <-events:
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (s *blockScanner) poll(ctx context.Context) error {
+	head, err := s.ethRepo.GetLatestBlockNumber(ctx)
+	if err != nil {
+		return err
+	}
+
+	if s.scanDeposits {
+		if err := s.scan(ctx, head); err != nil {
+			return err
+		}
+	}
+
+	return s.confirmTransactions(ctx, head)
+}
+
+// scan processes up to BatchSize blocks after the cursor, moving the cursor after each block.
+// When a block does not build on the last scanned one, the cursor is rewound by the confirmation
+// depth so the blocks that replaced the orphaned ones are scanned again.
+func (s *blockScanner) scan(ctx context.Context, head uint64) error {
+	next, parentHash, err := s.nextBlock(ctx, head)
+	if err != nil {
+		return err
+	}
+	if next > head {
+		return nil
+	}
+
+	last := head
+	if s.cfg.BatchSize > 0 && next+uint64(s.cfg.BatchSize)-1 < head {
+		last = next + uint64(s.cfg.BatchSize) - 1
+	}
+
+	wallets, err := s.loadWallets(ctx)
+	if err != nil {
+		return err
+	}
+
+	tokens, err := s.loadTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	watched := make(map[common.Address]bool, len(wallets))
+	for address := range wallets {
+		watched[address] = true
+	}
+	tokenAddresses := make([]common.Address, 0, len(tokens))
+	for address := range tokens {
+		tokenAddresses = append(tokenAddresses, address)
+	}
+
+	for number := next; number <= last; number++ {
+		block, err := s.ethRepo.ScanBlock(ctx, number, watched, tokenAddresses)
+		if err != nil {
+			return err
+		}
+
+		if parentHash != (common.Hash{}) && block.ParentHash != parentHash {
+			return s.rewind(ctx, number-1)
+		}
+
+		for _, deposit := range block.Deposits {
+			if err := s.recordDeposit(ctx, block, deposit, wallets[deposit.To], tokens); err != nil {
+				return err
+			}
+		}
+
+		if err := s.chainRepo.UpdateChainCursor(ctx, domain.ChainCursor{
+			ChainID:     s.chain.ID,
+			BlockNumber: int64(block.Number),
+			BlockHash:   block.Hash.Hex(),
+		}); err != nil {
+			return fmt.Errorf("failed to update cursor: %w", err)
+		}
+		parentHash = block.Hash
+
+		if len(block.Deposits) > 0 {
+			log.Printf("Recorded %d deposits on %s at block %d", len(block.Deposits), s.chain.Name, block.Number)
+		}
+	}
+
+	return nil
+}
+
+// rewind moves the cursor back from an orphaned block by the chain's confirmation depth.
+// Deposits recorded from orphaned blocks are rolled back by confirmTransactions.
+func (s *blockScanner) rewind(ctx context.Context, orphaned uint64) error {
+	target := uint64(0)
+	if depth := uint64(s.chain.RequiredConfirmations); orphaned > depth {
+		target = orphaned - depth
+	}
+
+	hash, err := s.ethRepo.GetBlockHash(ctx, target)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Reorg detected on %s at block %d, rescanning from block %d", s.chain.Name, orphaned, target+1)
+	if err := s.chainRepo.UpdateChainCursor(ctx, domain.ChainCursor{
+		ChainID:     s.chain.ID,
+		BlockNumber: int64(target),
+		BlockHash:   hash.Hex(),
+	}); err != nil {
+		return fmt.Errorf("failed to rewind cursor: %w", err)
+	}
+	return nil
+}
+
+// confirmTransactions finalizes submitted transactions whose block has the required number of
+// confirmations, as failed when they reverted and as successful otherwise. The balances a transaction
+// changed are refreshed, and its reservation released, as soon as its receipt shows up and again once
+// it is final. Transactions whose block is no longer canonical lose their block and go back to
+// submitted, announced by transaction.reorged, until their receipt shows up again; orphaned deposits
+// that will not be mined again are dropped. The receipts of all unmined transactions are looked up in
+// one batch per block. Transactions held back for a gas top-up are left alone until
+// settleSponsorships submits them.
+func (s *blockScanner) confirmTransactions(ctx context.Context, head uint64) error {
+	submitted, err := s.txnRepo.GetSubmittedTransactionsByChainID(ctx, s.chain.ID)
+	if err != nil {
+		return fmt.Errorf("failed to list submitted transactions: %w", err)
+	}
+
+	funding, err := s.settleSponsorships(ctx, submitted)
+	if err != nil {
+		return err
+	}
+
+	// Transactions waiting for their top-up are not on the chain yet
+	var transactions []domain.Transaction
+	for _, txn := range submitted {
+		if !funding[txn.ID] {
+			transactions = append(transactions, txn)
+		}
+	}
+
+	var unmined []common.Hash
+	for _, txn := range transactions {
+		if txn.BlockNumber == 0 {
+			unmined = append(unmined, common.HexToHash(txn.TxHash))
+		}
+	}
+	receipts, err := s.ethRepo.FindTransactionReceipts(ctx, unmined)
+	if err != nil {
+		return err
+	}
+
+	for _, txn := range transactions {
+		if err := s.confirmTransaction(ctx, txn, head, receipts); err != nil {
+			log.Printf("Failed to confirm transaction %s on %s: %v", txn.ID, s.chain.Name, err)
+		}
+	}
+	return nil
+}
+
+func (s *blockScanner) confirmTransaction(ctx context.Context, txn domain.Transaction, head uint64, receipts map[common.Hash]*types.Receipt) error {
+	if txn.BlockNumber == 0 {
+		receipt := receipts[common.HexToHash(txn.TxHash)]
+		if receipt == nil {
+			return s.trackPending(ctx, txn)
+		}
+		reconcileReceipt(ctx, s.ethRepo, &txn, receipt)
+		if err := s.txnRepo.UpdateTransaction(ctx, txn); err != nil {
+			return err
+		}
+		// The on-chain balance now reflects the transaction
+		if err := s.settle(ctx, txn); err != nil {
+			log.Printf("Failed to settle transaction %s on %s: %v", txn.ID, s.chain.Name, err)
+		}
+		return nil
+	}
+
+	canonical, err := s.ethRepo.GetBlockHash(ctx, uint64(txn.BlockNumber))
+	if err != nil {
+		return err
+	}
+
+	if canonical != common.HexToHash(txn.BlockHash) {
+		log.Printf("Block %d of transaction %s was orphaned, moving it back to submitted", txn.BlockNumber, txn.ID)
+		txn.Status = domain.StatusSubmitted
+		clearReceipt(&txn)
+		message, err := events.NewWalletMessage(s.eventsTopic, txn.WalletID, domain.EventTransactionReorged, domain.NewTransactionEvent(txn))
+		if err != nil {
+			return err
+		}
+		return s.txnRepo.UpdateTransaction(ctx, txn, message)
+	}
+
+	confirmations := int64(head) - txn.BlockNumber + 1
+	if confirmations < int64(s.chain.RequiredConfirmations) {
+		return nil
+	}
+
+	txn.Status = finalStatus(txn)
+	if err := saveStatus(ctx, s.txnRepo, s.eventsTopic, txn); err != nil {
+		return err
+	}
+	log.Printf("Transaction %s %s after %d confirmations", txn.ID, txn.Status, confirmations)
+
+	if err := s.settle(ctx, txn); err != nil {
+		log.Printf("Failed to settle transaction %s on %s: %v", txn.ID, s.chain.Name, err)
+	}
+	return nil
+}
+
+// trackPending follows a submitted transaction that has no receipt. A transaction the node dropped is
+// rebroadcast from its stored raw form and marked dropped only when that fails; one whose nonce was
+// used by another transaction is marked replaced. Deposits only lack a receipt when their block was
+// orphaned.
+func (s *blockScanner) trackPending(ctx context.Context, txn domain.Transaction) error {
+	if txn.Direction == domain.DirectionInbound {
+		return s.trackOrphanedDeposit(ctx, txn)
+	}
+
+	wallet, err := s.walletRepo.GetWallet(ctx, txn.WalletID)
+	if err != nil {
+		return fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	state, err := s.ethRepo.GetTransactionState(ctx, common.HexToHash(txn.TxHash), common.HexToAddress(wallet.Address), uint64(txn.Nonce))
+	if err != nil {
+		return err
+	}
+
+	switch state {
+	case domain.TxStateDropped:
+		if err := s.rebroadcast(ctx, txn); err != nil {
+			log.Printf("Transaction %s was dropped and could not be rebroadcast: %v", txn.ID, err)
+			txn.Status = domain.StatusDropped
+			if err := s.settle(ctx, txn); err != nil {
+				log.Printf("Failed to settle transaction %s on %s: %v", txn.ID, s.chain.Name, err)
+			}
+			return saveStatus(ctx, s.txnRepo, s.eventsTopic, txn)
+		}
+		log.Printf("Transaction %s was dropped, rebroadcast %s", txn.ID, txn.TxHash)
+	case domain.TxStateReplaced:
+		log.Printf("Nonce %d of transaction %s was used by another transaction", txn.Nonce, txn.ID)
+		txn.Status = domain.StatusReplaced
+		if err := s.settle(ctx, txn); err != nil {
+			log.Printf("Failed to settle transaction %s on %s: %v", txn.ID, s.chain.Name, err)
+		}
+		return saveStatus(ctx, s.txnRepo, s.eventsTopic, txn)
+	}
+	return nil
+}
+
+// trackOrphanedDeposit follows a deposit whose block was orphaned. Its transaction normally goes back
+// to the mempool and is mined again, which the receipt lookup picks up. A deposit the node no longer
+// knows, because the sender spent its nonce on another transaction, will not arrive and is marked
+// dropped.
+func (s *blockScanner) trackOrphanedDeposit(ctx context.Context, txn domain.Transaction) error {
+	known, err := s.ethRepo.HasTransaction(ctx, common.HexToHash(txn.TxHash))
+	if err != nil {
+		return err
+	}
+	if known {
+		return nil
+	}
+
+	log.Printf("Deposit %s in transaction %s was orphaned and is no longer known, marking it dropped", txn.ID, txn.TxHash)
+	txn.Status = domain.StatusDropped
+	return saveStatus(ctx, s.txnRepo, s.eventsTopic, txn)
+}
+
+func (s *blockScanner) rebroadcast(ctx context.Context, txn domain.Transaction) error {
+	if txn.RawTx == "" {
+		return errors.New("no signed transaction stored")
+	}
+
+	raw, err := hexutil.Decode(txn.RawTx)
+	if err != nil {
+		return fmt.Errorf("invalid signed transaction: %w", err)
+	}
+
+	var signedTx types.Transaction
+	if err := signedTx.UnmarshalBinary(raw); err != nil {
+		return fmt.Errorf("invalid signed transaction: %w", err)
+	}
+
+	// The node may have picked the transaction up again since it was reported missing
+	if _, err := s.ethRepo.SubmitTransaction(ctx, &signedTx); err != nil && !strings.Contains(err.Error(), "already known") {
+		return err
+	}
+	return nil
+}
+
+// nextBlock returns the first block to scan and the hash it must build on. A chain without a cursor
+// starts at the current head, so enabling the scanner does not backfill the whole chain history.
+func (s *blockScanner) nextBlock(ctx context.Context, head uint64) (uint64, common.Hash, error) {
+	cursor, err := s.chainRepo.GetChainCursor(ctx, s.chain.ID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return head, common.Hash{}, nil
+	}
+	if err != nil {
+		return 0, common.Hash{}, fmt.Errorf("failed to get cursor: %w", err)
+	}
+	return uint64(cursor.BlockNumber) + 1, common.HexToHash(cursor.BlockHash), nil
+}
+
+// recordDeposit stores a deposit as submitted; it becomes successful once its block is final.
+func (s *blockScanner) recordDeposit(ctx context.Context, block domain.ScannedBlock, deposit domain.Deposit, walletID uuid.UUID, tokens map[common.Address]domain.Token) error {
+	params := domain.CreateInboundTransactionParams{
+		ID:          uuid.New(),
+		WalletID:    walletID,
+		ChainID:     s.chain.ID,
+		FromAddress: deposit.From.Hex(),
+		ToAddress:   deposit.To.Hex(),
+		Amount:      formatUnits(deposit.Amount, nativeDecimals),
+		Status:      domain.StatusSubmitted,
+		TxHash:      deposit.TxHash.Hex(),
+		LogIndex:    deposit.LogIndex,
+		BlockNumber: int64(block.Number),
+		BlockHash:   block.Hash.Hex(),
+	}
+
+	if deposit.Token != (common.Address{}) {
+		token := tokens[deposit.Token]
+		params.TokenID = token.ID
+		params.Amount = formatUnits(deposit.Amount, token.Decimals)
+	}
+
+	message, err := depositMessage(s.eventsTopic, params)
+	if err != nil {
+		return err
+	}
+	if err := s.txnRepo.CreateInboundTransaction(ctx, params, message); err != nil {
+		return fmt.Errorf("failed to record deposit %s: %w", deposit.TxHash.Hex(), err)
+	}
+	return nil
+}
+
+func (s *blockScanner) loadWallets(ctx context.Context) (map[common.Address]uuid.UUID, error) {
+	wallets, err := s.walletRepo.ListWallets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list wallets: %w", err)
+	}
+
+	walletIDs := make(map[common.Address]uuid.UUID, len(wallets))
+	for _, wallet := range wallets {
+		walletIDs[common.HexToAddress(wallet.Address)] = wallet.ID
+	}
+	return walletIDs, nil
+}
+
+// loadTokens returns the chain's ERC-20 tokens by contract address. Entries without a real
+// contract address, such as the native currency placeholder, are skipped.
+func (s *blockScanner) loadTokens(ctx context.Context) (map[common.Address]domain.Token, error) {
+	tokens, err := s.chainRepo.GetTokensByChainID(ctx, s.chain.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	byAddress := make(map[common.Address]domain.Token, len(tokens))
+	for _, token := range tokens {
+		if !common.IsHexAddress(token.ContractAddress) {
+			continue
+		}
+		byAddress[common.HexToAddress(token.ContractAddress)] = token
+	}
+	return byAddress, nil
+}
+
+// formatUnits renders an integer amount in the token's display units, e.g. wei as ether.
+func formatUnits(amount *big.Int, decimals int) string {
+	if decimals <= 0 {
+		return amount.String()
+	}
+
+	unit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	formatted := new(big.Rat).SetFrac(amount, unit).FloatString(decimals)
+	formatted = strings.TrimRight(formatted, "0")
+	return strings.TrimSuffix(formatted, ".")
+}
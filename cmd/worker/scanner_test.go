@@ -0,0 +1,429 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/repository"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// stubBlockEth serves the blocks of a test chain. Block n has the hash of n and builds on n-1 unless
+// the test says otherwise, matching the canonical hashes of stubScannerEth.
+type stubBlockEth struct {
+	*stubScannerEth
+	blocks        map[uint64]domain.ScannedBlock
+	tokenBalances map[common.Address]*big.Int
+	scanned       []uint64
+}
+
+func (s *stubBlockEth) ScanBlock(ctx context.Context, number uint64, watched map[common.Address]bool, tokens []common.Address) (domain.ScannedBlock, error) {
+	s.scanned = append(s.scanned, number)
+	block := s.blocks[number]
+	block.Number = number
+	block.Hash = blockHash(number)
+	if block.ParentHash == (common.Hash{}) {
+		block.ParentHash = blockHash(number - 1)
+	}
+	return block, nil
+}
+
+func (s *stubBlockEth) GetTokenBalance(ctx context.Context, token common.Address, owner common.Address) (*big.Int, error) {
+	return s.tokenBalances[token], nil
+}
+
+func blockHash(number uint64) common.Hash {
+	return common.BigToHash(new(big.Int).SetUint64(number))
+}
+
+// memChainRepo keeps the cursor and the tokens of one chain.
+type memChainRepo struct {
+	repository.ChainRepository
+	cursor *domain.ChainCursor
+	tokens []domain.Token
+}
+
+func (r *memChainRepo) GetChainCursor(ctx context.Context, chainID uuid.UUID) (domain.ChainCursor, error) {
+	if r.cursor == nil {
+		return domain.ChainCursor{}, pgx.ErrNoRows
+	}
+	return *r.cursor, nil
+}
+
+func (r *memChainRepo) UpdateChainCursor(ctx context.Context, cursor domain.ChainCursor) error {
+	r.cursor = &cursor
+	return nil
+}
+
+func (r *memChainRepo) GetTokensByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.Token, error) {
+	return r.tokens, nil
+}
+
+// memDepositRepo records the deposits the scanner finds and the events announcing them.
+type memDepositRepo struct {
+	repository.TransactionRepository
+	deposits []domain.CreateInboundTransactionParams
+	messages int
+}
+
+func (r *memDepositRepo) CreateInboundTransaction(ctx context.Context, params domain.CreateInboundTransactionParams, messages ...domain.OutboxMessage) error {
+	r.deposits = append(r.deposits, params)
+	r.messages += len(messages)
+	return nil
+}
+
+func (r *memDepositRepo) GetSubmittedTransactionsByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.Transaction, error) {
+	return nil, nil
+}
+
+type stubWalletList struct {
+	repository.WalletRepository
+	wallets []domain.Wallet
+}
+
+func (r *stubWalletList) ListWallets(ctx context.Context) ([]domain.Wallet, error) {
+	return r.wallets, nil
+}
+
+func (r *stubWalletList) GetWallet(ctx context.Context, id uuid.UUID) (domain.Wallet, error) {
+	return r.wallets[0], nil
+}
+
+// recordingBalanceRepo records every balance stored, by token.
+type recordingBalanceRepo struct {
+	repository.BalanceRepository
+	balances map[uuid.UUID]*big.Int
+}
+
+func (r *recordingBalanceRepo) UpsertBalance(ctx context.Context, params domain.UpsertBalanceParams) error {
+	r.balances[params.TokenID] = params.Balance
+	return nil
+}
+
+func (r *recordingBalanceRepo) ReleaseReservation(ctx context.Context, transactionID uuid.UUID) error {
+	return nil
+}
+
+func TestScan(t *testing.T) {
+	wallet := domain.Wallet{ID: uuid.New(), Address: "0x5FbDB2315678afecb367f032d93F642f64180aa3"}
+	sender := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+	usdc := domain.Token{ID: uuid.New(), ContractAddress: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6}
+
+	cursorAt := func(number uint64, hash common.Hash) *domain.ChainCursor {
+		return &domain.ChainCursor{BlockNumber: int64(number), BlockHash: hash.Hex()}
+	}
+
+	tests := []struct {
+		name      string
+		cursor    *domain.ChainCursor
+		head      uint64
+		batchSize int
+		blocks    map[uint64]domain.ScannedBlock
+		// wantScanned are the blocks read from the node, wantCursor the block the cursor ends on
+		wantScanned []uint64
+		wantCursor  uint64
+		wantAmounts []string
+	}{
+		{
+			// Enabling the scanner does not backfill the chain history
+			name:        "no cursor starts at the head",
+			head:        10,
+			wantScanned: []uint64{10},
+			wantCursor:  10,
+		},
+		{
+			name:   "records native and token deposits",
+			cursor: cursorAt(7, blockHash(7)),
+			head:   9,
+			blocks: map[uint64]domain.ScannedBlock{
+				8: {Deposits: []domain.Deposit{{TxHash: common.HexToHash("0x08"), From: sender, To: common.HexToAddress(wallet.Address), Amount: big.NewInt(5e17)}}},
+				9: {Deposits: []domain.Deposit{{TxHash: common.HexToHash("0x09"), LogIndex: 3, From: sender, To: common.HexToAddress(wallet.Address), Token: common.HexToAddress(usdc.ContractAddress), Amount: big.NewInt(12_500_000)}}},
+			},
+			wantScanned: []uint64{8, 9},
+			wantCursor:  9,
+			wantAmounts: []string{"0.5", "12.5"},
+		},
+		{
+			name:        "batch size caps the blocks per poll",
+			cursor:      cursorAt(5, blockHash(5)),
+			head:        20,
+			batchSize:   2,
+			wantScanned: []uint64{6, 7},
+			wantCursor:  7,
+		},
+		{
+			// Block 9 does not build on the stored block 8, so 8 was orphaned; the cursor goes back by
+			// the confirmation depth of 2
+			name:        "reorg rewinds by the confirmation depth",
+			cursor:      cursorAt(8, common.HexToHash("0x0bad")),
+			head:        9,
+			wantScanned: []uint64{9},
+			wantCursor:  6,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ethRepo := &stubBlockEth{stubScannerEth: &stubScannerEth{head: tt.head}, blocks: tt.blocks}
+			chainRepo := &memChainRepo{cursor: tt.cursor, tokens: []domain.Token{usdc}}
+			txnRepo := &memDepositRepo{}
+			scanner := &blockScanner{
+				chain:        domain.Chain{ID: uuid.New(), Name: "Test", RequiredConfirmations: 2},
+				scanDeposits: true,
+				ethRepo:      ethRepo,
+				chainRepo:    chainRepo,
+				walletRepo:   &stubWalletList{wallets: []domain.Wallet{wallet}},
+				txnRepo:      txnRepo,
+				relayerRepo:  &memRelayerRepo{},
+				cfg:          &config.ScannerConfig{BatchSize: tt.batchSize},
+			}
+
+			if err := scanner.poll(context.Background()); err != nil {
+				t.Fatalf("poll() error = %v", err)
+			}
+
+			if fmt.Sprint(ethRepo.scanned) != fmt.Sprint(tt.wantScanned) {
+				t.Errorf("Scanned blocks %v, want %v", ethRepo.scanned, tt.wantScanned)
+			}
+			if chainRepo.cursor == nil || chainRepo.cursor.BlockNumber != int64(tt.wantCursor) || chainRepo.cursor.BlockHash != blockHash(tt.wantCursor).Hex() {
+				t.Errorf("Cursor = %+v, want block %d", chainRepo.cursor, tt.wantCursor)
+			}
+
+			if len(txnRepo.deposits) != len(tt.wantAmounts) || txnRepo.messages != len(tt.wantAmounts) {
+				t.Fatalf("Recorded %d deposits with %d events, want %d", len(txnRepo.deposits), txnRepo.messages, len(tt.wantAmounts))
+			}
+			for i, deposit := range txnRepo.deposits {
+				if deposit.Amount != tt.wantAmounts[i] || deposit.WalletID != wallet.ID || deposit.Status != domain.StatusSubmitted {
+					t.Errorf("Deposit %d = %s to %s as %s, want %s to %s as submitted", i, deposit.Amount, deposit.WalletID, deposit.Status, tt.wantAmounts[i], wallet.ID)
+				}
+			}
+			// Token deposits are linked to their token
+			if len(txnRepo.deposits) == 2 && (txnRepo.deposits[0].TokenID != uuid.Nil || txnRepo.deposits[1].TokenID != usdc.ID) {
+				t.Errorf("Deposit tokens = %s, %s, want native and %s", txnRepo.deposits[0].TokenID, txnRepo.deposits[1].TokenID, usdc.ID)
+			}
+		})
+	}
+}
+
+func TestConfirmTransaction(t *testing.T) {
+	const mined = 10
+
+	tests := []struct {
+		name string
+		head uint64
+		// blockHash is the block the transaction was recorded in, the canonical block 10 unless set
+		blockHash    common.Hash
+		revertReason string
+		// receipt is set when the transaction had no block yet and its receipt shows up
+		receipt    *types.Receipt
+		wantStatus domain.Status
+		wantBlock  int64
+	}{
+		{
+			name:       "waiting for confirmations",
+			head:       mined + 1,
+			wantStatus: domain.StatusSubmitted,
+			wantBlock:  mined,
+		},
+		{
+			name:       "final after the required confirmations",
+			head:       mined + 2,
+			wantStatus: domain.StatusSuccess,
+			wantBlock:  mined,
+		},
+		{
+			name:         "reverted transaction fails once final",
+			head:         mined + 2,
+			revertReason: "insufficient allowance",
+			wantStatus:   domain.StatusFailed,
+			wantBlock:    mined,
+		},
+		{
+			// The receipt is dropped until the transaction is mined again
+			name:       "orphaned block",
+			head:       mined + 2,
+			blockHash:  common.HexToHash("0x0bad"),
+			wantStatus: domain.StatusSubmitted,
+		},
+		{
+			name: "receipt shows up",
+			head: mined,
+			receipt: &types.Receipt{
+				Status:            types.ReceiptStatusSuccessful,
+				BlockNumber:       big.NewInt(mined),
+				BlockHash:         blockHash(mined),
+				GasUsed:           21_000,
+				EffectiveGasPrice: big.NewInt(10),
+			},
+			wantStatus: domain.StatusSubmitted,
+			wantBlock:  mined,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wallet := domain.Wallet{ID: uuid.New(), Address: "0x5FbDB2315678afecb367f032d93F642f64180aa3"}
+			chain := domain.Chain{ID: uuid.New(), Name: "Test", RequiredConfirmations: 3}
+			txn := domain.Transaction{
+				ID:           uuid.New(),
+				WalletID:     wallet.ID,
+				ChainID:      chain.ID,
+				Direction:    domain.DirectionOutbound,
+				FromAddress:  wallet.Address,
+				ToAddress:    "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045",
+				Amount:       "0.25",
+				Status:       domain.StatusSubmitted,
+				TxHash:       common.HexToHash("0x01").Hex(),
+				BlockNumber:  mined,
+				BlockHash:    blockHash(mined).Hex(),
+				GasUsed:      21_000,
+				Fee:          "210000",
+				RevertReason: tt.revertReason,
+			}
+			if tt.blockHash != (common.Hash{}) {
+				txn.BlockHash = tt.blockHash.Hex()
+			}
+
+			ethRepo := &stubScannerEth{head: tt.head, balance: big.NewInt(1), receipts: map[common.Hash]*types.Receipt{}}
+			if tt.receipt != nil {
+				txn.BlockNumber, txn.BlockHash, txn.GasUsed, txn.Fee = 0, "", 0, ""
+				tt.receipt.TxHash = common.HexToHash(txn.TxHash)
+				ethRepo.receipts[tt.receipt.TxHash] = tt.receipt
+			}
+			txnRepo := &stubScannerTxnRepo{transactions: map[uuid.UUID]domain.Transaction{txn.ID: txn}}
+			scanner := &blockScanner{
+				chain:       chain,
+				ethRepo:     ethRepo,
+				chainRepo:   &stubScannerChainRepo{},
+				walletRepo:  &stubScannerWalletRepo{wallet: wallet},
+				txnRepo:     txnRepo,
+				balanceRepo: &memBalanceRepo{chainID: chain.ID, balances: map[uuid.UUID]*big.Int{}, reservations: map[uuid.UUID]*big.Int{}},
+				relayerRepo: &memRelayerRepo{},
+				cfg:         &config.ScannerConfig{},
+			}
+
+			if err := scanner.poll(context.Background()); err != nil {
+				t.Fatalf("poll() error = %v", err)
+			}
+
+			got := txnRepo.transactions[txn.ID]
+			if got.Status != tt.wantStatus || got.BlockNumber != tt.wantBlock {
+				t.Errorf("Transaction is %s at block %d, want %s at block %d", got.Status, got.BlockNumber, tt.wantStatus, tt.wantBlock)
+			}
+			if tt.wantBlock == 0 && (got.Fee != "" || got.GasUsed != 0) {
+				t.Errorf("Orphaned transaction kept fee %s and gas %d", got.Fee, got.GasUsed)
+			}
+		})
+	}
+}
+
+func TestRefreshBalances(t *testing.T) {
+	usdc := domain.Token{ID: uuid.New(), ContractAddress: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Decimals: 6}
+
+	tests := []struct {
+		name    string
+		tokenID uuid.UUID
+		// want are the balances stored, by token; the native balance is stored under the nil token
+		want map[uuid.UUID]int64
+	}{
+		{
+			name: "native transfer",
+			want: map[uuid.UUID]int64{uuid.Nil: 900},
+		},
+		{
+			// The fee still changes the native balance
+			name:    "token transfer",
+			tokenID: usdc.ID,
+			want:    map[uuid.UUID]int64{uuid.Nil: 900, usdc.ID: 40},
+		},
+		{
+			name:    "token of another chain",
+			tokenID: uuid.New(),
+			want:    map[uuid.UUID]int64{uuid.Nil: 900},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wallet := domain.Wallet{ID: uuid.New(), Address: "0x5FbDB2315678afecb367f032d93F642f64180aa3"}
+			ethRepo := &stubBlockEth{
+				stubScannerEth: &stubScannerEth{balance: big.NewInt(900)},
+				tokenBalances:  map[common.Address]*big.Int{common.HexToAddress(usdc.ContractAddress): big.NewInt(40)},
+			}
+			balanceRepo := &recordingBalanceRepo{balances: map[uuid.UUID]*big.Int{}}
+			scanner := &blockScanner{
+				chain:       domain.Chain{ID: uuid.New(), Name: "Test"},
+				ethRepo:     ethRepo,
+				chainRepo:   &memChainRepo{tokens: []domain.Token{usdc}},
+				walletRepo:  &stubWalletList{wallets: []domain.Wallet{wallet}},
+				balanceRepo: balanceRepo,
+			}
+
+			if err := scanner.refreshBalances(context.Background(), domain.Transaction{WalletID: wallet.ID, TokenID: tt.tokenID}); err != nil {
+				t.Fatalf("refreshBalances() error = %v", err)
+			}
+
+			if len(balanceRepo.balances) != len(tt.want) {
+				t.Fatalf("Stored %d balances, want %d", len(balanceRepo.balances), len(tt.want))
+			}
+			for tokenID, want := range tt.want {
+				if got := balanceRepo.balances[tokenID]; got == nil || got.Int64() != want {
+					t.Errorf("Balance of token %s = %v, want %d", tokenID, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatUnits(t *testing.T) {
+	tests := []struct {
+		amount   int64
+		decimals int
+		want     string
+	}{
+		{amount: 1_500_000_000_000_000_000, decimals: 18, want: "1.5"},
+		{amount: 1, decimals: 18, want: "0.000000000000000001"},
+		{amount: 12_500_000, decimals: 6, want: "12.5"},
+		{amount: 100_000_000, decimals: 8, want: "1"},
+		{amount: 42, decimals: 0, want: "42"},
+	}
+	for _, tt := range tests {
+		if got := formatUnits(big.NewInt(tt.amount), tt.decimals); got != tt.want {
+			t.Errorf("formatUnits(%d, %d) = %s, want %s", tt.amount, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestDrain(t *testing.T) {
+	tests := []struct {
+		name   string
+		queued int
+		closed bool
+	}{
+		{name: "empty"},
+		{name: "queued blocks", queued: 3},
+		{name: "closed", queued: 2, closed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			events := make(chan domain.ChainEvent, 4)
+			for i := 0; i < tt.queued; i++ {
+				events <- domain.ChainEvent{BlockNumber: uint64(100 + i)}
+			}
+			if tt.closed {
+				close(events)
+			}
+
+			// drain returns without waiting for events that are not there yet
+			drain(events)
+
+			if len(events) != 0 {
+				t.Errorf("%d events left queued, want none", len(events))
+			}
+		})
+	}
+}
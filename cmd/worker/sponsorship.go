@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"mpc/internal/domain"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+// settleSponsorships follows the gas top-ups the relayer broadcast on this chain. The transaction a
+// top-up pays for is held back until the funding transaction is mined, along with the later
+// transactions of its wallet, which cannot be mined before it: they are submitted from their raw
+// form once the top-up succeeded, and failed when the top-up reverted, which gives the top-up back to
+// the user's budget. It returns the IDs of the submitted transactions still waiting for a top-up.
+func (s *blockScanner) settleSponsorships(ctx context.Context, submitted []domain.Transaction) (map[uuid.UUID]bool, error) {
+	sponsorships, err := s.relayerRepo.GetPendingGasSponsorshipsByChainID(ctx, s.chain.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending sponsorships: %w", err)
+	}
+	if len(sponsorships) == 0 {
+		return nil, nil
+	}
+
+	hashes := make([]common.Hash, 0, len(sponsorships))
+	for _, sponsorship := range sponsorships {
+		hashes = append(hashes, common.HexToHash(sponsorship.FundingTxHash))
+	}
+	receipts, err := s.ethRepo.FindTransactionReceipts(ctx, hashes)
+	if err != nil {
+		return nil, err
+	}
+
+	funding := make(map[uuid.UUID]bool)
+	for _, sponsorship := range sponsorships {
+		held := heldTransactions(sponsorship, submitted)
+		receipt := receipts[common.HexToHash(sponsorship.FundingTxHash)]
+		if receipt == nil {
+			for _, txn := range held {
+				funding[txn.ID] = true
+			}
+			continue
+		}
+		if err := s.settleSponsorship(ctx, sponsorship, receipt, held); err != nil {
+			log.Printf("Failed to settle sponsorship %s on %s: %v", sponsorship.ID, s.chain.Name, err)
+			for _, txn := range held {
+				funding[txn.ID] = true
+			}
+		}
+	}
+	return funding, nil
+}
+
+// heldTransactions returns the unmined transactions waiting for the top-up, in nonce order: the
+// transaction it pays for and every later outbound transaction of the same wallet. It is empty once
+// the sponsored transaction is no longer submitted.
+func heldTransactions(sponsorship domain.GasSponsorship, submitted []domain.Transaction) []domain.Transaction {
+	var sponsored *domain.Transaction
+	for i := range submitted {
+		if submitted[i].ID == sponsorship.TransactionID {
+			sponsored = &submitted[i]
+			break
+		}
+	}
+	if sponsored == nil || sponsored.BlockNumber != 0 {
+		return nil
+	}
+
+	var held []domain.Transaction
+	for _, txn := range submitted {
+		if txn.WalletID == sponsored.WalletID && txn.Direction == domain.DirectionOutbound && txn.BlockNumber == 0 && txn.Nonce >= sponsored.Nonce {
+			held = append(held, txn)
+		}
+	}
+	sort.Slice(held, func(i, j int) bool { return held[i].Nonce < held[j].Nonce })
+	return held
+}
+
+// settleSponsorship records the outcome of a mined top-up and submits or fails the transactions it
+// held back.
+func (s *blockScanner) settleSponsorship(ctx context.Context, sponsorship domain.GasSponsorship, receipt *types.Receipt, held []domain.Transaction) error {
+	fee := new(big.Int).SetUint64(receipt.GasUsed)
+	if receipt.EffectiveGasPrice != nil {
+		fee.Mul(fee, receipt.EffectiveGasPrice)
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		if err := s.relayerRepo.FailGasSponsorship(ctx, sponsorship, fee); err != nil {
+			return fmt.Errorf("failed to record reverted sponsorship: %w", err)
+		}
+
+		for _, txn := range held {
+			log.Printf("Top-up %s of transaction %s reverted, failing transaction %s", sponsorship.FundingTxHash, sponsorship.TransactionID, txn.ID)
+			txn.Status = domain.StatusFailed
+			if err := s.settle(ctx, txn); err != nil {
+				log.Printf("Failed to settle transaction %s on %s: %v", txn.ID, s.chain.Name, err)
+			}
+			if err := saveStatus(ctx, s.txnRepo, s.eventsTopic, txn); err != nil {
+				log.Printf("Failed to fail transaction %s on %s: %v", txn.ID, s.chain.Name, err)
+			}
+		}
+		return nil
+	}
+
+	if err := s.relayerRepo.ConfirmGasSponsorship(ctx, sponsorship.ID, fee); err != nil {
+		return fmt.Errorf("failed to record confirmed sponsorship: %w", err)
+	}
+
+	// From here on the transactions are followed like any other; trackPending rebroadcasts one if this
+	// submission did not reach the node
+	for _, txn := range held {
+		if err := s.rebroadcast(ctx, txn); err != nil {
+			log.Printf("Failed to submit transaction %s after its top-up: %v", txn.ID, err)
+		}
+	}
+	return nil
+}
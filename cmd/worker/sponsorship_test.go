@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/repository"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+// memRelayerRepo holds the gas sponsorships of one chain and the budget given back by failed ones.
+type memRelayerRepo struct {
+	repository.RelayerRepository
+	sponsorships map[uuid.UUID]*domain.GasSponsorship
+	released     *big.Int
+}
+
+func (r *memRelayerRepo) GetPendingGasSponsorshipsByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.GasSponsorship, error) {
+	var pending []domain.GasSponsorship
+	for _, sponsorship := range r.sponsorships {
+		if sponsorship.Status == domain.SponsorshipStatusPending {
+			pending = append(pending, *sponsorship)
+		}
+	}
+	return pending, nil
+}
+
+func (r *memRelayerRepo) ConfirmGasSponsorship(ctx context.Context, id uuid.UUID, fee *big.Int) error {
+	r.sponsorships[id].Status = domain.SponsorshipStatusConfirmed
+	r.sponsorships[id].FeeWei = fee
+	return nil
+}
+
+func (r *memRelayerRepo) FailGasSponsorship(ctx context.Context, sponsorship domain.GasSponsorship, fee *big.Int) error {
+	r.sponsorships[sponsorship.ID].Status = domain.SponsorshipStatusFailed
+	r.sponsorships[sponsorship.ID].FeeWei = fee
+	r.released.Add(r.released, sponsorship.AmountWei)
+	return nil
+}
+
+func TestSettleSponsorships(t *testing.T) {
+	const gasPrice = 10_000_000_000
+	topUp := big.NewInt(21_000 * gasPrice)
+	fundingHash := common.HexToHash("0xf0")
+
+	tests := []struct {
+		name string
+		// funding is the receipt status of the top-up, nil while it is not mined
+		funding         *uint64
+		wantSponsorship domain.SponsorshipStatus
+		wantStatus      domain.Status
+		wantSubmitted   bool
+		wantReleased    *big.Int
+	}{
+		{
+			name:            "top-up not mined yet",
+			wantSponsorship: domain.SponsorshipStatusPending,
+			wantStatus:      domain.StatusSubmitted,
+			wantReleased:    new(big.Int),
+		},
+		{
+			name:            "top-up mined",
+			funding:         func() *uint64 { s := types.ReceiptStatusSuccessful; return &s }(),
+			wantSponsorship: domain.SponsorshipStatusConfirmed,
+			wantStatus:      domain.StatusSubmitted,
+			wantSubmitted:   true,
+			wantReleased:    new(big.Int),
+		},
+		{
+			name:            "top-up reverted",
+			funding:         func() *uint64 { s := types.ReceiptStatusFailed; return &s }(),
+			wantSponsorship: domain.SponsorshipStatusFailed,
+			wantStatus:      domain.StatusFailed,
+			wantReleased:    topUp,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			wallet := domain.Wallet{ID: uuid.New(), UserID: uuid.New(), Address: "0x5FbDB2315678afecb367f032d93F642f64180aa3"}
+			chain := domain.Chain{ID: uuid.New(), Name: "Test", RequiredConfirmations: 2}
+
+			// A sequential batch of two transfers was kept back by the API until its top-up is mined;
+			// the top-up is recorded against the first one
+			to := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+			var signedTxs []*types.Transaction
+			transactions := map[uuid.UUID]domain.Transaction{}
+			reservations := map[uuid.UUID]*big.Int{}
+			var txnIDs []uuid.UUID
+			for nonce := uint64(7); nonce < 9; nonce++ {
+				signedTx := types.NewTx(&types.LegacyTx{Nonce: nonce, To: &to, Value: big.NewInt(1), Gas: 21_000, GasPrice: big.NewInt(gasPrice)})
+				raw, err := signedTx.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+				txn := domain.Transaction{
+					ID:          uuid.New(),
+					WalletID:    wallet.ID,
+					ChainID:     chain.ID,
+					Direction:   domain.DirectionOutbound,
+					FromAddress: wallet.Address,
+					ToAddress:   to.Hex(),
+					Amount:      "0.000000000000000001",
+					Nonce:       int64(nonce),
+					Status:      domain.StatusSubmitted,
+					TxHash:      signedTx.Hash().Hex(),
+					RawTx:       hexutil.Encode(raw),
+				}
+				signedTxs = append(signedTxs, signedTx)
+				transactions[txn.ID] = txn
+				reservations[txn.ID] = big.NewInt(1)
+				txnIDs = append(txnIDs, txn.ID)
+			}
+			sponsorship := &domain.GasSponsorship{
+				ID:            uuid.New(),
+				UserID:        wallet.UserID,
+				ChainID:       chain.ID,
+				TransactionID: txnIDs[0],
+				FundingTxHash: fundingHash.Hex(),
+				AmountWei:     topUp,
+				FeeWei:        new(big.Int),
+				Status:        domain.SponsorshipStatusPending,
+			}
+
+			ethRepo := &stubScannerEth{head: 10, balance: big.NewInt(1e18), receipts: map[common.Hash]*types.Receipt{}}
+			if tt.funding != nil {
+				ethRepo.receipts[fundingHash] = &types.Receipt{
+					Status:            *tt.funding,
+					TxHash:            fundingHash,
+					BlockNumber:       big.NewInt(10),
+					GasUsed:           21_000,
+					EffectiveGasPrice: big.NewInt(gasPrice),
+				}
+			}
+			txnRepo := &stubScannerTxnRepo{transactions: transactions}
+			relayerRepo := &memRelayerRepo{sponsorships: map[uuid.UUID]*domain.GasSponsorship{sponsorship.ID: sponsorship}, released: new(big.Int)}
+			balanceRepo := &memBalanceRepo{
+				chainID:      chain.ID,
+				balances:     map[uuid.UUID]*big.Int{},
+				reservations: reservations,
+			}
+			scanner := &blockScanner{
+				chain:       chain,
+				ethRepo:     ethRepo,
+				chainRepo:   &stubScannerChainRepo{},
+				walletRepo:  &stubScannerWalletRepo{wallet: wallet},
+				txnRepo:     txnRepo,
+				balanceRepo: balanceRepo,
+				relayerRepo: relayerRepo,
+				cfg:         &config.ScannerConfig{},
+			}
+
+			if err := scanner.poll(ctx); err != nil {
+				t.Fatalf("poll() error = %v", err)
+			}
+
+			if sponsorship.Status != tt.wantSponsorship {
+				t.Errorf("Sponsorship is %s, want %s", sponsorship.Status, tt.wantSponsorship)
+			}
+			if tt.funding != nil && sponsorship.FeeWei.Cmp(topUp) != 0 {
+				t.Errorf("Sponsorship fee = %s, want the %s the top-up paid", sponsorship.FeeWei, topUp)
+			}
+			for _, txnID := range txnIDs {
+				if got := txnRepo.transactions[txnID].Status; got != tt.wantStatus {
+					t.Errorf("Transaction is %s, want %s", got, tt.wantStatus)
+				}
+			}
+			// Both transfers go to the node, in nonce order
+			submitted := len(ethRepo.submitted) == 2 && ethRepo.submitted[0] == signedTxs[0].Hash() && ethRepo.submitted[1] == signedTxs[1].Hash()
+			if submitted != tt.wantSubmitted {
+				t.Errorf("Transactions submitted = %v, want %v", ethRepo.submitted, tt.wantSubmitted)
+			}
+			if relayerRepo.released.Cmp(tt.wantReleased) != 0 {
+				t.Errorf("Budget given back = %s, want %s", relayerRepo.released, tt.wantReleased)
+			}
+			// Only failed transactions give their reservations back
+			if released := len(balanceRepo.reservations) == 0; released != (tt.wantStatus == domain.StatusFailed) {
+				t.Errorf("Reservations released = %v for %s transactions", released, tt.wantStatus)
+			}
+			// Held transactions are not in the mempool, so they must not be followed there
+			if held := tt.funding == nil; held && ethRepo.pending != 0 {
+				t.Errorf("Held transactions were looked up in the mempool %d times", ethRepo.pending)
+			}
+		})
+	}
+}
+
+func TestHeldTransactions(t *testing.T) {
+	walletID := uuid.New()
+	sponsored := domain.Transaction{ID: uuid.New(), WalletID: walletID, Direction: domain.DirectionOutbound, Nonce: 5}
+	later := domain.Transaction{ID: uuid.New(), WalletID: walletID, Direction: domain.DirectionOutbound, Nonce: 6}
+	earlier := domain.Transaction{ID: uuid.New(), WalletID: walletID, Direction: domain.DirectionOutbound, Nonce: 4}
+	mined := domain.Transaction{ID: uuid.New(), WalletID: walletID, Direction: domain.DirectionOutbound, Nonce: 7, BlockNumber: 10}
+	deposit := domain.Transaction{ID: uuid.New(), WalletID: walletID, Direction: domain.DirectionInbound}
+	otherWallet := domain.Transaction{ID: uuid.New(), WalletID: uuid.New(), Direction: domain.DirectionOutbound, Nonce: 9}
+
+	tests := []struct {
+		name      string
+		submitted []domain.Transaction
+		want      []uuid.UUID
+	}{
+		{
+			name:      "later nonces of the wallet wait with it",
+			submitted: []domain.Transaction{later, earlier, sponsored, mined, deposit, otherWallet},
+			want:      []uuid.UUID{sponsored.ID, later.ID},
+		},
+		{
+			name:      "sponsored transaction no longer submitted",
+			submitted: []domain.Transaction{later, earlier},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			held := heldTransactions(domain.GasSponsorship{TransactionID: sponsored.ID}, tt.submitted)
+			var got []uuid.UUID
+			for _, txn := range held {
+				got = append(got, txn.ID)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("heldTransactions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Package app wires the services of the API, so that it runs on its own as well as inside the
+// worker in dev mode.
+package app
+
+import (
+	"fmt"
+	"mpc/internal/delivery/http"
+	"mpc/internal/infrastructure/auth"
+	"mpc/internal/infrastructure/bitcoin"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/infrastructure/disperse"
+	"mpc/internal/infrastructure/ens"
+	"mpc/internal/infrastructure/erc4337"
+	"mpc/internal/infrastructure/ethereum"
+	"mpc/internal/infrastructure/kafka"
+	"mpc/internal/infrastructure/redis"
+	"mpc/internal/infrastructure/safe"
+	"mpc/internal/repository/postgres"
+	"mpc/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/sirupsen/logrus"
+)
+
+// NewAPIRouter builds the repositories and usecases of the API and returns its router. Transaction
+// messages go through the outbox and are published by the worker; user operations are published
+// right away with userOpPublisher.
+func NewAPIRouter(cfg *config.Config, dbPool *pgxpool.Pool, redisClient *redis.RedisClient, userOpPublisher kafka.Publisher, log *logrus.Logger) (*gin.Engine, error) {
+	// jwt
+	jwtConfig := auth.NewJWTConfig(&cfg.JWT)
+	jwtService := auth.NewJWTService(jwtConfig, *redisClient)
+
+	// ethereum
+	ethClient, err := ethereum.NewEthereumClient(&cfg.Ethereum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Ethereum client: %w", err)
+	}
+
+	// erc4337
+	smartAccountClient, err := erc4337.NewClient(&cfg.Ethereum, &cfg.ERC4337)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize ERC-4337 client: %w", err)
+	}
+
+	// safe
+	safeClient, err := safe.NewClient(&cfg.Ethereum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize Safe client: %w", err)
+	}
+
+	// ens
+	ensClient, err := ens.NewClient(&cfg.Ethereum, &cfg.ENS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize ENS client: %w", err)
+	}
+
+	// disperse
+	disperseClient := disperse.NewClient(&cfg.Batch)
+
+	// repository
+	userRepo := postgres.NewUserRepo(dbPool)
+	walletRepo := postgres.NewWalletRepo(dbPool)
+	transactionRepo := postgres.NewTransactionRepo(dbPool)
+	relayerRepo := postgres.NewRelayerRepo(dbPool)
+	userOpRepo := postgres.NewUserOperationRepo(dbPool)
+	safeProposalRepo := postgres.NewSafeProposalRepo(dbPool)
+	balanceRepo := postgres.NewBalanceRepo(dbPool)
+	chainRepo := postgres.NewChainRepo(dbPool)
+	utxoRepo := postgres.NewUTXORepo(dbPool)
+
+	// usecase
+	walletUC := usecase.NewWalletUC(walletRepo, ethClient, balanceRepo, cfg.Kafka.EventsTopic)
+	authUC := usecase.NewAuthUC(userRepo, walletUC, *jwtService, cfg.Kafka.EventsTopic)
+	userUC := usecase.NewUserUC(userRepo)
+	relayerUC, err := usecase.NewRelayerUC(relayerRepo, chainRepo, ethClient, ethereum.NewChainClient(&cfg.Ethereum), &cfg.Relayer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize relayer: %w", err)
+	}
+	bitcoinUC := usecase.NewBitcoinUC(chainRepo, utxoRepo, walletUC, bitcoin.NewBackend)
+	evmFamily := usecase.NewEVMFamily(transactionRepo, ethClient, walletUC, relayerUC, disperseClient, ensClient, balanceRepo, &cfg.Batch, &cfg.Balance, cfg.Kafka.Topic, cfg.Kafka.EventsTopic)
+	families := usecase.NewChainFamilies(evmFamily, bitcoinUC)
+	txnUC := usecase.NewTxnUC(transactionRepo, chainRepo, walletUC, families, *redisClient, cfg.Kafka.Topic, cfg.Kafka.EventsTopic)
+	smartAccountUC := usecase.NewSmartAccountUC(userOpRepo, smartAccountClient, walletUC, userOpPublisher)
+	safeUC := usecase.NewSafeUC(safeProposalRepo, safeClient, ethClient, transactionRepo, ensClient, walletUC, evmFamily, cfg.Kafka.Topic, cfg.Kafka.EventsTopic)
+
+	// router
+	return http.NewRouter(&userUC, &walletUC, &txnUC, &authUC, &relayerUC, &smartAccountUC, &safeUC, &bitcoinUC, jwtService, log), nil
+}
@@ -38,7 +38,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, accessToken, refreshToken, err := h.authUC.Login(c, loginRequest.Email, loginRequest.Password)
+	user, accessToken, refreshToken, err := h.authUC.Login(c.Request.Context(), loginRequest.Email, loginRequest.Password)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusUnauthorized, err.Error())
 		return
@@ -68,7 +68,7 @@ func (h *AuthHandler) Signup(c *gin.Context) {
 		return
 	}
 
-	user, wallet, accessToken, refreshToken, err := h.authUC.Signup(c, domain.CreateUserParams(signupRequest))
+	user, wallet, accessToken, refreshToken, err := h.authUC.Signup(c.Request.Context(), domain.CreateUserParams(signupRequest))
 
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusInternalServerError, err.Error())
@@ -105,7 +105,7 @@ func (h *AuthHandler) Logout(c *gin.Context) {
 		return
 	}
 
-	h.authUC.Logout(c, token)
+	h.authUC.Logout(c.Request.Context(), token)
 	utils.SuccessResponse(c, http.StatusOK, gin.H{"message": "Logged out successfully"})
 }
 
@@ -125,7 +125,7 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		return
 	}
 
-	accessToken, refreshToken, err := h.authUC.RefreshToken(c, token)
+	accessToken, refreshToken, err := h.authUC.RefreshToken(c.Request.Context(), token)
 	if err != nil {
 		utils.ErrorResponse(c, http.StatusUnauthorized, err.Error())
 		return
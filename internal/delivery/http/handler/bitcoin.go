@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"errors"
+	"mpc/internal/usecase"
+	"mpc/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BitcoinHandler struct {
+	bitcoinUC usecase.BitcoinUseCase
+}
+
+func NewBitcoinHandler(bitcoinUC usecase.BitcoinUseCase) *BitcoinHandler {
+	return &BitcoinHandler{bitcoinUC: bitcoinUC}
+}
+
+// GetAddresses godoc
+// @Summary Get Bitcoin Addresses
+// @Description Get the P2WPKH and P2TR addresses of a wallet on a Bitcoin chain
+// @Tags bitcoin
+// @Accept json
+// @Produce json
+// @Param wallet_id path string true "Wallet ID"
+// @Param chain_id query string true "Chain ID"
+// @Success 200 {object} domain.BitcoinAddresses "Successful response"
+// @Failure 400 {string} string "Bad request error due to invalid input"
+// @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 500 {string} string "Internal server error"
+// @Router /bitcoin/addresses/{wallet_id} [get]
+// @Security ApiKeyAuth
+func (h *BitcoinHandler) GetAddresses(c *gin.Context) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Invalid user ID type")
+		return
+	}
+
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid wallet ID")
+		return
+	}
+
+	chainID, err := uuid.Parse(c.Query("chain_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid chain ID")
+		return
+	}
+
+	addresses, err := h.bitcoinUC.GetAddresses(c.Request.Context(), userID, walletID, chainID)
+	if err != nil {
+		utils.ErrorResponse(c, bitcoinErrorStatus(err), "Failed to get Bitcoin addresses: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, addresses)
+}
+
+// GetUTXOs godoc
+// @Summary Get Bitcoin UTXOs
+// @Description Get the unspent outputs of a wallet on a Bitcoin chain with its confirmed, unconfirmed and reserved totals in satoshis
+// @Tags bitcoin
+// @Accept json
+// @Produce json
+// @Param wallet_id path string true "Wallet ID"
+// @Param chain_id query string true "Chain ID"
+// @Success 200 {object} domain.BitcoinUTXOsResponse "Successful response"
+// @Failure 400 {string} string "Bad request error due to invalid input"
+// @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 500 {string} string "Internal server error"
+// @Router /bitcoin/utxos/{wallet_id} [get]
+// @Security ApiKeyAuth
+func (h *BitcoinHandler) GetUTXOs(c *gin.Context) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Invalid user ID type")
+		return
+	}
+
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid wallet ID")
+		return
+	}
+
+	chainID, err := uuid.Parse(c.Query("chain_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid chain ID")
+		return
+	}
+
+	utxos, err := h.bitcoinUC.GetUTXOs(c.Request.Context(), userID, walletID, chainID)
+	if err != nil {
+		utils.ErrorResponse(c, bitcoinErrorStatus(err), "Failed to get UTXOs: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, utxos)
+}
+
+// GetFeeRates godoc
+// @Summary Get Bitcoin Fee Rates
+// @Description Get the estimated fee rates, in sat/vB, for the low, medium and high fee priorities
+// @Tags bitcoin
+// @Accept json
+// @Produce json
+// @Param chain_id query string true "Chain ID"
+// @Success 200 {object} domain.BitcoinFeeRates "Successful response"
+// @Failure 400 {string} string "Bad request error due to invalid input"
+// @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 500 {string} string "Internal server error"
+// @Router /bitcoin/fee-rates [get]
+// @Security ApiKeyAuth
+func (h *BitcoinHandler) GetFeeRates(c *gin.Context) {
+	chainID, err := uuid.Parse(c.Query("chain_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid chain ID")
+		return
+	}
+
+	rates, err := h.bitcoinUC.EstimateFeeRates(c.Request.Context(), chainID)
+	if err != nil {
+		utils.ErrorResponse(c, bitcoinErrorStatus(err), "Failed to estimate fee rates: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, rates)
+}
+
+func bitcoinErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrUnsupportedChain):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
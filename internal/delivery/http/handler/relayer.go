@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"mpc/internal/domain"
+	"mpc/internal/usecase"
+	"mpc/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type RelayerHandler struct {
+	relayerUC usecase.RelayerUseCase
+}
+
+func NewRelayerHandler(relayerUC usecase.RelayerUseCase) *RelayerHandler {
+	return &RelayerHandler{relayerUC: relayerUC}
+}
+
+// GetSponsorships godoc
+// @Summary Get Gas Sponsorships
+// @Description List the gas top-ups the platform sent to the authenticated user's wallets
+// @Tags relayer
+// @Accept json
+// @Produce json
+// @Success 200 {array} domain.GasSponsorshipResponse "Successful response"
+// @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 500 {string} string "Internal server error"
+// @Router /sponsorships [get]
+// @Security ApiKeyAuth
+func (h *RelayerHandler) GetSponsorships(c *gin.Context) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Invalid user ID type")
+		return
+	}
+
+	sponsorships, err := h.relayerUC.GetSponsorships(c.Request.Context(), userID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get sponsorships: "+err.Error())
+		return
+	}
+
+	response := make([]domain.GasSponsorshipResponse, 0, len(sponsorships))
+	for _, s := range sponsorships {
+		response = append(response, domain.GasSponsorshipResponse{
+			ID:            s.ID,
+			ChainID:       s.ChainID,
+			TransactionID: s.TransactionID,
+			FundingTxHash: s.FundingTxHash,
+			AmountWei:     s.AmountWei.String(),
+			FeeWei:        s.FeeWei.String(),
+			Status:        string(s.Status),
+			CreatedAt:     s.CreatedAt,
+		})
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, response)
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"errors"
+	"mpc/internal/domain"
+	"mpc/internal/usecase"
+	"mpc/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SafeHandler struct {
+	safeUC usecase.SafeUseCase
+}
+
+func NewSafeHandler(safeUC usecase.SafeUseCase) *SafeHandler {
+	return &SafeHandler{safeUC: safeUC}
+}
+
+// ProposeTransaction godoc
+// @Summary Propose Safe Transaction
+// @Description Propose a Safe multisig transaction and sign it with one of the Safe owners' wallets
+// @Tags safe
+// @Accept json
+// @Produce json
+// @Param createSafeProposalRequest body domain.CreateSafeProposalRequest true "Create Safe Proposal Request"
+// @Success 201 {object} domain.SafeProposalResponse "Successful response"
+// @Failure 400 {string} string "Bad request error due to invalid input"
+// @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 404 {string} string "Wallet not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /safes/proposals [post]
+// @Security ApiKeyAuth
+func (h *SafeHandler) ProposeTransaction(c *gin.Context) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Invalid user ID type")
+		return
+	}
+
+	req, err := utils.ParseRequest[domain.CreateSafeProposalRequest](c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	proposal, err := h.safeUC.ProposeTransaction(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ErrorResponse(c, safeErrorStatus(err), "Failed to propose Safe transaction: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, proposal)
+}
+
+// GetProposal godoc
+// @Summary Get Safe Proposal
+// @Description Get a Safe transaction proposal and the signatures collected so far
+// @Tags safe
+// @Accept json
+// @Produce json
+// @Param id path string true "Proposal ID"
+// @Success 200 {object} domain.SafeProposalResponse "Successful response"
+// @Failure 400 {string} string "Bad request error due to invalid input"
+// @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 404 {string} string "Wallet or proposal not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /safes/proposals/{id} [get]
+// @Security ApiKeyAuth
+func (h *SafeHandler) GetProposal(c *gin.Context) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Invalid user ID type")
+		return
+	}
+
+	proposalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid proposal ID")
+		return
+	}
+
+	proposal, err := h.safeUC.GetProposal(c.Request.Context(), userID, proposalID)
+	if err != nil {
+		utils.ErrorResponse(c, safeErrorStatus(err), "Failed to get Safe proposal: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, proposal)
+}
+
+// AddSignature godoc
+// @Summary Add Safe Signature
+// @Description Add a Safe owner's signature to a proposal; the proposal is executed once the threshold is met
+// @Tags safe
+// @Accept json
+// @Produce json
+// @Param id path string true "Proposal ID"
+// @Param addSafeSignatureRequest body domain.AddSafeSignatureRequest true "Add Safe Signature Request"
+// @Success 200 {object} domain.SafeProposalResponse "Successful response"
+// @Failure 400 {string} string "Bad request error due to invalid input"
+// @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 404 {string} string "Proposal not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /safes/proposals/{id}/signatures [post]
+// @Security ApiKeyAuth
+func (h *SafeHandler) AddSignature(c *gin.Context) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Invalid user ID type")
+		return
+	}
+
+	proposalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid proposal ID")
+		return
+	}
+
+	req, err := utils.ParseRequest[domain.AddSafeSignatureRequest](c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	proposal, err := h.safeUC.AddSignature(c.Request.Context(), userID, proposalID, req.Signature)
+	if err != nil {
+		utils.ErrorResponse(c, safeErrorStatus(err), "Failed to add Safe signature: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, proposal)
+}
+
+// ExecuteProposal godoc
+// @Summary Execute Safe Proposal
+// @Description Submit execTransaction for a proposal that has reached its signature threshold, optionally with signatures of owners outside this service
+// @Tags safe
+// @Accept json
+// @Produce json
+// @Param id path string true "Proposal ID"
+// @Param executeSafeProposalRequest body domain.ExecuteSafeProposalRequest false "Execute Safe Proposal Request"
+// @Success 200 {object} domain.SafeProposalResponse "Successful response"
+// @Failure 400 {string} string "Bad request error due to invalid input"
+// @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 404 {string} string "Wallet or proposal not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /safes/proposals/{id}/execute [post]
+// @Security ApiKeyAuth
+func (h *SafeHandler) ExecuteProposal(c *gin.Context) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Invalid user ID type")
+		return
+	}
+
+	proposalID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid proposal ID")
+		return
+	}
+
+	// The body is optional; without one only the stored signatures are used
+	var req domain.ExecuteSafeProposalRequest
+	if c.Request.ContentLength != 0 {
+		req, err = utils.ParseRequest[domain.ExecuteSafeProposalRequest](c)
+		if err != nil {
+			utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+			return
+		}
+	}
+
+	proposal, err := h.safeUC.ExecuteProposal(c.Request.Context(), userID, proposalID, req.Signatures)
+	if err != nil {
+		utils.ErrorResponse(c, safeErrorStatus(err), "Failed to execute Safe proposal: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, proposal)
+}
+
+func safeErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrNotSafeOwner),
+		errors.Is(err, usecase.ErrProposalNotPending),
+		errors.Is(err, usecase.ErrThresholdNotReached),
+		errors.Is(err, usecase.ErrInvalidAddress),
+		errors.Is(err, usecase.ErrInsufficientBalance):
+		return http.StatusBadRequest
+	case errors.Is(err, usecase.ErrWalletNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
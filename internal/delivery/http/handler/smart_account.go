@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"mpc/internal/domain"
+	"mpc/internal/usecase"
+	"mpc/pkg/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SmartAccountHandler struct {
+	smartAccountUC usecase.SmartAccountUseCase
+}
+
+func NewSmartAccountHandler(smartAccountUC usecase.SmartAccountUseCase) *SmartAccountHandler {
+	return &SmartAccountHandler{smartAccountUC: smartAccountUC}
+}
+
+// GetSmartAccount godoc
+// @Summary Get Smart Account
+// @Description Get the ERC-4337 smart account controlled by a wallet, deployed or not
+// @Tags smart-account
+// @Accept json
+// @Produce json
+// @Param wallet_id path string true "Wallet ID"
+// @Success 200 {object} domain.SmartAccountResponse "Successful response"
+// @Failure 400 {string} string "Bad request error due to invalid input"
+// @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 500 {string} string "Internal server error"
+// @Router /smart-accounts/{wallet_id} [get]
+// @Security ApiKeyAuth
+func (h *SmartAccountHandler) GetSmartAccount(c *gin.Context) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Invalid user ID type")
+		return
+	}
+
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid wallet ID")
+		return
+	}
+
+	account, err := h.smartAccountUC.GetSmartAccount(c.Request.Context(), userID, walletID)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get smart account: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, account)
+}
+
+// SendUserOperation godoc
+// @Summary Send User Operation
+// @Description Build, sign and send an ERC-4337 user operation from the wallet's smart account
+// @Tags smart-account
+// @Accept json
+// @Produce json
+// @Param createUserOpRequest body domain.CreateUserOpRequest true "Create User Operation Request"
+// @Success 201 {object} domain.CreateUserOpResponse "Successful response"
+// @Failure 400 {string} string "Bad request error due to invalid input"
+// @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 500 {string} string "Internal server error"
+// @Router /user-operations [post]
+// @Security ApiKeyAuth
+func (h *SmartAccountHandler) SendUserOperation(c *gin.Context) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Invalid user ID type")
+		return
+	}
+
+	req, err := utils.ParseRequest[domain.CreateUserOpRequest](c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	userOp, err := h.smartAccountUC.SendUserOperation(c.Request.Context(), userID, req)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to send user operation: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, domain.CreateUserOpResponse{
+		ID:         userOp.ID,
+		Sender:     userOp.Sender,
+		UserOpHash: userOp.UserOpHash,
+	})
+}
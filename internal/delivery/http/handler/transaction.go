@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"mpc/internal/domain"
 	"mpc/internal/usecase"
 	"mpc/pkg/utils"
@@ -32,6 +33,7 @@ func (h *TxnHandler) GetTransactions(c *gin.Context) {
 // @Success 201 {object} docs.CreateTxnResponse "Successful response"
 // @Failure 400 {string} string "Bad request error due to invalid input"
 // @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 404 {string} string "Wallet not found"
 // @Failure 500 {string} string "Internal server error"
 // @Router /transactions/create [post]
 // @Security ApiKeyAuth
@@ -59,7 +61,14 @@ func (h *TxnHandler) CreateTransaction(c *gin.Context) {
 	// Create transaction
 	txnID, err := h.txnUC.CreateTransaction(c.Request.Context(), userID, req)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create transaction: "+err.Error())
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidAddress) || errors.Is(err, usecase.ErrInsufficientBalance) || errors.Is(err, usecase.ErrUnsupportedChain) {
+			status = http.StatusBadRequest
+		}
+		if errors.Is(err, usecase.ErrWalletNotFound) {
+			status = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, status, "Failed to create transaction: "+err.Error())
 		return
 	}
 	utils.SuccessResponse(c, http.StatusCreated, gin.H{
@@ -120,6 +129,7 @@ func (h *TxnHandler) SubmitTransaction(c *gin.Context) {
 // @Success 201 {object} docs.CreateTxnResponse "Successful response"
 // @Failure 400 {string} string "Bad request error due to invalid input"
 // @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 404 {string} string "Wallet not found"
 // @Failure 500 {string} string "Internal server error"
 // @Router /transactions [post]
 // @Security ApiKeyAuth
@@ -147,7 +157,14 @@ func (h *TxnHandler) CreateAndSubmitTransaction(c *gin.Context) {
 	// Create transaction
 	txnID, err := h.txnUC.CreateTransaction(c.Request.Context(), userID, req)
 	if err != nil {
-		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to create transaction: "+err.Error())
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidAddress) || errors.Is(err, usecase.ErrInsufficientBalance) || errors.Is(err, usecase.ErrUnsupportedChain) {
+			status = http.StatusBadRequest
+		}
+		if errors.Is(err, usecase.ErrWalletNotFound) {
+			status = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, status, "Failed to create transaction: "+err.Error())
 		return
 	}
 
@@ -160,3 +177,52 @@ func (h *TxnHandler) CreateAndSubmitTransaction(c *gin.Context) {
 
 	utils.SuccessResponse(c, http.StatusCreated, gin.H{"message": "Transaction created and submitted", "tx_hash": txn.TxHash})
 }
+
+// CreateBatchTransaction godoc
+// @Summary Create Batch Transaction
+// @Description Pay many recipients from one wallet, either as sequential transactions or through a single Disperse contract call
+// @Tags transaction
+// @Accept json
+// @Produce json
+// @Param createBatchTxnRequest body domain.CreateBatchTxnRequest true "Create Batch Transaction Request"
+// @Success 201 {object} domain.BatchTxnResponse "Successful response"
+// @Failure 400 {string} string "Bad request error due to invalid input"
+// @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 404 {string} string "Wallet not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /transactions/batch [post]
+// @Security ApiKeyAuth
+func (h *TxnHandler) CreateBatchTransaction(c *gin.Context) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Invalid user ID type")
+		return
+	}
+
+	req, err := utils.ParseRequest[domain.CreateBatchTxnRequest](c)
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	batch, err := h.txnUC.CreateBatchTransaction(c.Request.Context(), userID, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, usecase.ErrInvalidBatch) || errors.Is(err, usecase.ErrInsufficientBalance) || errors.Is(err, usecase.ErrInvalidAddress) || errors.Is(err, usecase.ErrUnsupportedChain) {
+			status = http.StatusBadRequest
+		}
+		if errors.Is(err, usecase.ErrWalletNotFound) {
+			status = http.StatusNotFound
+		}
+		utils.ErrorResponse(c, status, "Failed to create batch transaction: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusCreated, batch)
+}
@@ -1,10 +1,14 @@
 package handler
 
 import (
+	"errors"
+	"mpc/internal/domain"
 	"mpc/internal/usecase"
+	"mpc/pkg/utils"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type WalletHandler struct {
@@ -23,4 +27,101 @@ func (h *WalletHandler) CreateWallet(c *gin.Context) {
 func (h *WalletHandler) GetWallet(c *gin.Context) {
 	// Implement the handler logic here
 	c.JSON(http.StatusOK, gin.H{"message": "Wallet retrieved"})
-}
\ No newline at end of file
+}
+
+// GetBalances godoc
+// @Summary Get Wallet Balances
+// @Description Get the stored native and token balances of a wallet, with the time each was last read from the chain
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Param id path string true "Wallet ID"
+// @Success 200 {object} domain.WalletBalancesResponse "Successful response"
+// @Failure 400 {string} string "Bad request error due to invalid input"
+// @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 404 {string} string "Wallet not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /wallets/{id}/balances [get]
+// @Security ApiKeyAuth
+func (h *WalletHandler) GetBalances(c *gin.Context) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Invalid user ID type")
+		return
+	}
+
+	walletID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid wallet ID")
+		return
+	}
+
+	balances, err := (*h.walletUseCase).GetBalances(c.Request.Context(), userID, walletID)
+	if err != nil {
+		if errors.Is(err, usecase.ErrWalletNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get balances: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, balances)
+}
+
+// GetAddress godoc
+// @Summary Get Wallet Address
+// @Description Get the address of a wallet on chains of a family: evm, solana, aptos or near. Bitcoin addresses are listed under /bitcoin/addresses
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Param id path string true "Wallet ID"
+// @Param family path string true "Chain family"
+// @Success 200 {object} domain.WalletAddress "Successful response"
+// @Failure 400 {string} string "Bad request error due to invalid input"
+// @Failure 401 {string} string "Unauthorized error due to invalid token"
+// @Failure 404 {string} string "Wallet not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /wallets/{id}/addresses/{family} [get]
+// @Security ApiKeyAuth
+func (h *WalletHandler) GetAddress(c *gin.Context) {
+	userIDInterface, exists := c.Get("userID")
+	if !exists {
+		utils.ErrorResponse(c, http.StatusUnauthorized, "User not authenticated")
+		return
+	}
+
+	userID, ok := userIDInterface.(uuid.UUID)
+	if !ok {
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Invalid user ID type")
+		return
+	}
+
+	walletID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		utils.ErrorResponse(c, http.StatusBadRequest, "Invalid wallet ID")
+		return
+	}
+
+	address, err := (*h.walletUseCase).GetAddress(c.Request.Context(), userID, walletID, domain.ChainFamily(c.Param("family")))
+	if err != nil {
+		if errors.Is(err, usecase.ErrUnsupportedChain) {
+			utils.ErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if errors.Is(err, usecase.ErrWalletNotFound) {
+			utils.ErrorResponse(c, http.StatusNotFound, err.Error())
+			return
+		}
+		utils.ErrorResponse(c, http.StatusInternalServerError, "Failed to get address: "+err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, http.StatusOK, address)
+}
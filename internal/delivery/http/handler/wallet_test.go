@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"context"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/repository"
+	"mpc/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// stubWalletRepo serves wallets from a map, like the database would.
+type stubWalletRepo struct {
+	repository.WalletRepository
+	wallets map[uuid.UUID]domain.Wallet
+}
+
+func (r stubWalletRepo) GetWallet(ctx context.Context, id uuid.UUID) (domain.Wallet, error) {
+	wallet, ok := r.wallets[id]
+	if !ok {
+		return domain.Wallet{}, pgx.ErrNoRows
+	}
+	return wallet, nil
+}
+
+// stubBalanceRepo holds a single stored native balance per wallet, with nothing reserved.
+type stubBalanceRepo struct {
+	repository.BalanceRepository
+}
+
+func (stubBalanceRepo) GetBalancesByWalletID(ctx context.Context, walletID uuid.UUID) ([]domain.Balance, error) {
+	return []domain.Balance{{WalletID: walletID, ChainID: uuid.New(), Balance: big.NewInt(1_000), UpdatedAt: time.Now()}}, nil
+}
+
+func (stubBalanceRepo) GetReservedAmount(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) (*big.Int, error) {
+	return new(big.Int), nil
+}
+
+func TestGetBalances(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	owner := uuid.New()
+	wallet := domain.Wallet{ID: uuid.New(), UserID: owner, Address: "0x5FbDB2315678afecb367f032d93F642f64180aa3"}
+	walletUC := usecase.NewWalletUC(stubWalletRepo{wallets: map[uuid.UUID]domain.Wallet{wallet.ID: wallet}}, nil, stubBalanceRepo{}, "events")
+	h := NewWalletHandler(&walletUC)
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		walletID   string
+		wantStatus int
+	}{
+		{name: "own wallet", userID: owner, walletID: wallet.ID.String(), wantStatus: http.StatusOK},
+		{name: "wallet of another user", userID: uuid.New(), walletID: wallet.ID.String(), wantStatus: http.StatusNotFound},
+		{name: "unknown wallet", userID: owner, walletID: uuid.NewString(), wantStatus: http.StatusNotFound},
+		{name: "invalid wallet ID", userID: owner, walletID: "wallet", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/wallets/:id/balances", func(c *gin.Context) {
+				c.Set("userID", tt.userID)
+				h.GetBalances(c)
+			})
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/wallets/"+tt.walletID+"/balances", nil))
+			if w.Code != tt.wantStatus {
+				t.Errorf("GetBalances() status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+		})
+	}
+}
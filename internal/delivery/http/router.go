@@ -18,6 +18,10 @@ func NewRouter(
 	walletUC *usecase.WalletUseCase,
 	txnUC *usecase.TxnUseCase,
 	authUC *usecase.AuthUseCase,
+	relayerUC *usecase.RelayerUseCase,
+	smartAccountUC *usecase.SmartAccountUseCase,
+	safeUC *usecase.SafeUseCase,
+	bitcoinUC *usecase.BitcoinUseCase,
 	jwtService *auth.JWTService,
 	log *logrus.Logger,
 ) *gin.Engine {
@@ -31,6 +35,10 @@ func NewRouter(
 	userHandler := handler.NewUserHandler(userUC)
 	walletHandler := handler.NewWalletHandler(walletUC)
 	txnHandler := handler.NewTxnHandler(*txnUC)
+	relayerHandler := handler.NewRelayerHandler(*relayerUC)
+	smartAccountHandler := handler.NewSmartAccountHandler(*smartAccountUC)
+	safeHandler := handler.NewSafeHandler(*safeUC)
+	bitcoinHandler := handler.NewBitcoinHandler(*bitcoinUC)
 
 	v1 := router.Group("/api/v1")
 	{
@@ -60,6 +68,8 @@ func NewRouter(
 		{
 			wallets.POST("/", walletHandler.CreateWallet)
 			wallets.GET("/:id", walletHandler.GetWallet)
+			wallets.GET("/:id/balances", walletHandler.GetBalances)
+			wallets.GET("/:id/addresses/:family", walletHandler.GetAddress)
 		}
 
 		transactions := v1.Group("/transactions")
@@ -69,6 +79,42 @@ func NewRouter(
 			transactions.POST("/", txnHandler.CreateAndSubmitTransaction)
 			transactions.POST("/create", txnHandler.CreateTransaction)
 			transactions.POST("/submit", txnHandler.SubmitTransaction)
+			transactions.POST("/batch", txnHandler.CreateBatchTransaction)
+		}
+
+		sponsorships := v1.Group("/sponsorships")
+		sponsorships.Use(middleware.AuthMiddleware(*jwtService))
+		{
+			sponsorships.GET("/", relayerHandler.GetSponsorships)
+		}
+
+		smartAccounts := v1.Group("/smart-accounts")
+		smartAccounts.Use(middleware.AuthMiddleware(*jwtService))
+		{
+			smartAccounts.GET("/:wallet_id", smartAccountHandler.GetSmartAccount)
+		}
+
+		userOperations := v1.Group("/user-operations")
+		userOperations.Use(middleware.AuthMiddleware(*jwtService))
+		{
+			userOperations.POST("/", smartAccountHandler.SendUserOperation)
+		}
+
+		safes := v1.Group("/safes")
+		safes.Use(middleware.AuthMiddleware(*jwtService))
+		{
+			safes.POST("/proposals", safeHandler.ProposeTransaction)
+			safes.GET("/proposals/:id", safeHandler.GetProposal)
+			safes.POST("/proposals/:id/signatures", safeHandler.AddSignature)
+			safes.POST("/proposals/:id/execute", safeHandler.ExecuteProposal)
+		}
+
+		bitcoin := v1.Group("/bitcoin")
+		bitcoin.Use(middleware.AuthMiddleware(*jwtService))
+		{
+			bitcoin.GET("/addresses/:wallet_id", bitcoinHandler.GetAddresses)
+			bitcoin.GET("/utxos/:wallet_id", bitcoinHandler.GetUTXOs)
+			bitcoin.GET("/fee-rates", bitcoinHandler.GetFeeRates)
 		}
 	}
 
@@ -1,12 +1,56 @@
 package domain
 
-import "time"
+import (
+	"math/big"
+	"time"
 
+	"github.com/google/uuid"
+)
+
+// Balance is the last known on-chain balance of a wallet, in the token's smallest unit.
+// TokenID is uuid.Nil for the chain's native currency.
 type Balance struct {
-	ID        int64
-	WalletID  int64
-	ChainID   int64
-	TokenID   int64
-	Balance   float64
+	ID        uuid.UUID
+	WalletID  uuid.UUID
+	ChainID   uuid.UUID
+	TokenID   uuid.UUID
+	Balance   *big.Int
 	UpdatedAt time.Time
 }
+
+type UpsertBalanceParams struct {
+	WalletID uuid.UUID
+	ChainID  uuid.UUID
+	TokenID  uuid.UUID
+	Balance  *big.Int
+}
+
+// ReserveBalanceParams holds back Amount (value plus maximum fee, in wei) of the wallet's native
+// balance for a transaction until it is mined, fails or the reservation expires.
+type ReserveBalanceParams struct {
+	WalletID      uuid.UUID
+	ChainID       uuid.UUID
+	TransactionID uuid.UUID
+	Amount        *big.Int
+	// Total is the wallet's current on-chain balance the reservation is checked against
+	Total     *big.Int
+	ExpiresAt time.Time
+}
+
+type WalletBalance struct {
+	ChainID uuid.UUID `json:"chain_id"`
+	TokenID uuid.UUID `json:"token_id,omitempty"`
+	// Balance is the total in the token's smallest unit, e.g. wei
+	Balance string `json:"balance"`
+	// Pending is held back for in-flight transactions and Available is what is left to spend.
+	// Only the native currency is reserved, so token balances report no pending amount.
+	Pending   string `json:"pending"`
+	Available string `json:"available"`
+	// UpdatedAt is when the balance was last read from the chain
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type WalletBalancesResponse struct {
+	WalletID uuid.UUID       `json:"wallet_id"`
+	Balances []WalletBalance `json:"balances"`
+}
@@ -0,0 +1,110 @@
+package domain
+
+import "github.com/google/uuid"
+
+// UTXO is an unspent transaction output paying to one of a wallet's Bitcoin addresses.
+type UTXO struct {
+	ID       uuid.UUID `json:"id"`
+	WalletID uuid.UUID `json:"wallet_id"`
+	ChainID  uuid.UUID `json:"chain_id"`
+	TxID     string    `json:"tx_hash"`
+	Vout     uint32    `json:"vout"`
+	Amount   int64     `json:"amount"` // satoshis
+	Address  string    `json:"address"`
+	// PkScript is the output script, needed to sign the input that spends it.
+	PkScript []byte `json:"-"`
+	// BlockNumber is zero while the output is unconfirmed.
+	BlockNumber int64  `json:"block_number"`
+	BlockHash   string `json:"block_hash,omitempty"`
+	// ReservedBy is the pending outbound transaction that spends the output, if any.
+	ReservedBy uuid.UUID `json:"reserved_by"`
+}
+
+// BitcoinAddress is an address handed out for a wallet on one Bitcoin chain.
+type BitcoinAddress struct {
+	WalletID uuid.UUID
+	ChainID  uuid.UUID
+	Address  string
+	// Change addresses are derived from the wallet key at DerivationIndex; receive addresses use the key itself.
+	Change          bool
+	DerivationIndex uint32
+	Used            bool
+}
+
+// BitcoinAddresses are the receive addresses derived from a wallet key on one Bitcoin network.
+type BitcoinAddresses struct {
+	WalletID uuid.UUID `json:"wallet_id"`
+	ChainID  uuid.UUID `json:"chain_id"`
+	// P2WPKH is the native SegWit v0 address.
+	P2WPKH string `json:"p2wpkh"`
+	// P2TR is the Taproot address with a BIP-86 key-path only output key.
+	P2TR string `json:"p2tr"`
+}
+
+// BitcoinUTXOsResponse is a wallet's UTXO set on one Bitcoin chain, with its totals in satoshis.
+type BitcoinUTXOsResponse struct {
+	WalletID    uuid.UUID `json:"wallet_id"`
+	ChainID     uuid.UUID `json:"chain_id"`
+	Confirmed   int64     `json:"confirmed"`
+	Unconfirmed int64     `json:"unconfirmed"`
+	Reserved    int64     `json:"reserved"`
+	UTXOs       []UTXO    `json:"utxos"`
+}
+
+// CoinSelection is the strategy used to pick the UTXOs a Bitcoin transaction spends.
+type CoinSelection string
+
+const (
+	// CoinSelectionBranchAndBound looks for inputs that pay the amount and fee without change,
+	// and falls back to largest-first when there is no such set.
+	CoinSelectionBranchAndBound CoinSelection = "branch_and_bound"
+	// CoinSelectionLargestFirst spends the largest outputs first, using the fewest inputs.
+	CoinSelectionLargestFirst CoinSelection = "largest_first"
+	// CoinSelectionPrivacy spends all outputs of an address together, so no address is linked
+	// to the transaction without being emptied, and prefers paying from a single address.
+	CoinSelectionPrivacy CoinSelection = "privacy"
+)
+
+// FeePriority picks the confirmation target used to estimate a Bitcoin fee rate.
+type FeePriority string
+
+const (
+	FeePriorityLow    FeePriority = "low"
+	FeePriorityMedium FeePriority = "medium"
+	FeePriorityHigh   FeePriority = "high"
+)
+
+// BitcoinFeeRates are the estimated fee rates, in sat/vB, for each priority.
+type BitcoinFeeRates struct {
+	ChainID uuid.UUID `json:"chain_id"`
+	Low     int64     `json:"low"`
+	Medium  int64     `json:"medium"`
+	High    int64     `json:"high"`
+}
+
+// BitcoinBlock is a block with the parts of its transactions the UTXO tracker needs.
+type BitcoinBlock struct {
+	Number       uint64
+	Hash         string
+	ParentHash   string
+	Transactions []BitcoinTransaction
+}
+
+type BitcoinTransaction struct {
+	TxID    string
+	Inputs  []BitcoinOutPoint
+	Outputs []BitcoinOutput
+}
+
+// BitcoinOutPoint is the output an input spends.
+type BitcoinOutPoint struct {
+	TxID string
+	Vout uint32
+}
+
+type BitcoinOutput struct {
+	Vout     uint32
+	Amount   int64 // satoshis
+	Address  string
+	PkScript []byte
+}
@@ -1,13 +1,45 @@
 package domain
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChainFamily groups chains that share address, transaction and signature formats.
+type ChainFamily string
+
+const (
+	ChainFamilyEVM     ChainFamily = "evm"
+	ChainFamilyBitcoin ChainFamily = "bitcoin"
+	// Solana, Aptos and Near sign with Ed25519.
+	ChainFamilySolana ChainFamily = "solana"
+	ChainFamilyAptos  ChainFamily = "aptos"
+	ChainFamilyNear   ChainFamily = "near"
+)
 
 type Chain struct {
-	ID             int64
-	Name           string
-	ChainID        string
-	RPCURL         string
-	NativeCurrency string
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
+	ID     uuid.UUID
+	Name   string
+	Family ChainFamily
+	// ChainID is the EIP-155 chain ID on EVM chains and the network name on Bitcoin chains.
+	ChainID string
+	RPCURL  string
+	// FallbackRPCURLs are used when RPCURL is unhealthy.
+	FallbackRPCURLs []string
+	NativeCurrency  string
+	ExplorerURL     string
+	// RequiredConfirmations is the number of blocks, including the one with the transaction,
+	// before a transaction on this chain is considered final.
+	RequiredConfirmations int
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+}
+
+// ChainCursor is the last block the block scanner has processed on a chain.
+type ChainCursor struct {
+	ChainID     uuid.UUID
+	BlockNumber int64
+	BlockHash   string
+	UpdatedAt   time.Time
 }
@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+)
+
+type Direction string
+
+const (
+	DirectionOutbound Direction = "outbound"
+	DirectionInbound  Direction = "inbound"
+)
+
+// NativeTransferLogIndex is the log index recorded for deposits carried by the transaction value.
+const NativeTransferLogIndex = -1
+
+// Deposit is a transfer into a watched address found while scanning a block.
+type Deposit struct {
+	TxHash   common.Hash
+	LogIndex int
+	From     common.Address
+	To       common.Address
+	// Token is the ERC-20 contract, or the zero address for native transfers.
+	Token  common.Address
+	Amount *big.Int
+}
+
+// ScannedBlock is a block together with the deposits found in it.
+type ScannedBlock struct {
+	Number     uint64
+	Hash       common.Hash
+	ParentHash common.Hash
+	Deposits   []Deposit
+}
+
+type CreateInboundTransactionParams struct {
+	ID          uuid.UUID
+	WalletID    uuid.UUID
+	ChainID     uuid.UUID
+	FromAddress string
+	ToAddress   string
+	Amount      string
+	TokenID     uuid.UUID
+	Status      Status
+	TxHash      string
+	LogIndex    int
+	BlockNumber int64
+	BlockHash   string
+}
+
+// ChainEvent tells a chain follower that the chain moved. Reorg is set when a block it may have
+// processed was orphaned.
+type ChainEvent struct {
+	BlockNumber uint64
+	Reorg       bool
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType names what happened; each type has a versioned schema for its payload.
+type EventType string
+
+const (
+	EventTransactionCreated   EventType = "transaction.created"
+	EventTransactionSigned    EventType = "transaction.signed"
+	EventTransactionSubmitted EventType = "transaction.submitted"
+	EventTransactionConfirmed EventType = "transaction.confirmed"
+	EventTransactionFailed    EventType = "transaction.failed"
+	EventTransactionDropped   EventType = "transaction.dropped"
+	EventTransactionReplaced  EventType = "transaction.replaced"
+	EventTransactionReorged   EventType = "transaction.reorged"
+
+	EventUserOperationSubmitted EventType = "user_operation.submitted"
+
+	EventWalletCreated         EventType = "wallet.created"
+	EventWalletDepositReceived EventType = "wallet.deposit_received"
+
+	EventUserRegistered   EventType = "user.registered"
+	EventUserOTPRequested EventType = "user.otp_requested"
+)
+
+// Event is the envelope of every Kafka message. Payload holds the event of Type, encoded with the
+// schema of Version.
+type Event struct {
+	ID         uuid.UUID       `json:"id"`
+	Type       EventType       `json:"type"`
+	Version    int             `json:"version"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// TransactionEvent is the payload of the transaction events and of deposits into a wallet. The
+// receipt fields are only set once the transaction is mined. Amount is in the token's display units,
+// as stored; Fee is in the smallest unit of the native coin, as computed from the receipt.
+type TransactionEvent struct {
+	TransactionID uuid.UUID `json:"transaction_id"`
+	WalletID      uuid.UUID `json:"wallet_id"`
+	ChainID       uuid.UUID `json:"chain_id"`
+	Direction     Direction `json:"direction"`
+	FromAddress   string    `json:"from_address"`
+	ToAddress     string    `json:"to_address"`
+	Amount        string    `json:"amount"`
+	TokenID       uuid.UUID `json:"token_id"`
+	Status        Status    `json:"status"`
+	TxHash        string    `json:"tx_hash,omitempty"`
+	BlockNumber   int64     `json:"block_number,omitempty"`
+	Fee           string    `json:"fee,omitempty"`
+	RevertReason  string    `json:"revert_reason,omitempty"`
+}
+
+func NewTransactionEvent(txn Transaction) TransactionEvent {
+	direction := txn.Direction
+	if direction == "" {
+		direction = DirectionOutbound
+	}
+
+	return TransactionEvent{
+		TransactionID: txn.ID,
+		WalletID:      txn.WalletID,
+		ChainID:       txn.ChainID,
+		Direction:     direction,
+		FromAddress:   txn.FromAddress,
+		ToAddress:     txn.ToAddress,
+		Amount:        txn.Amount,
+		TokenID:       txn.TokenID,
+		Status:        txn.Status,
+		TxHash:        txn.TxHash,
+		BlockNumber:   txn.BlockNumber,
+		Fee:           txn.Fee,
+		RevertReason:  txn.RevertReason,
+	}
+}
+
+// WalletEvent is the payload of wallet.created.
+type WalletEvent struct {
+	WalletID uuid.UUID `json:"wallet_id"`
+	UserID   uuid.UUID `json:"user_id"`
+	Address  string    `json:"address"`
+}
+
+// UserEvent is the payload of user.registered.
+type UserEvent struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+}
+
+// OTPRequestedEvent is the payload of user.otp_requested.
+type OTPRequestedEvent struct {
+	Email string `json:"email"`
+}
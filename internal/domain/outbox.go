@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxMessage is a Kafka message stored with the state change it announces and published by the
+// outbox relay once that change is committed.
+type OutboxMessage struct {
+	ID      uuid.UUID
+	Topic   string
+	Key     []byte
+	Payload []byte
+	// Attempts counts failed publishes; LastError is the error of the latest one.
+	Attempts  int
+	LastError string
+	// Seq is the order the message was written in.
+	Seq       int64
+	CreatedAt time.Time
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type FundingPolicy string
+
+const (
+	// FundingPolicyNone disables gas sponsorship entirely.
+	FundingPolicyNone FundingPolicy = "none"
+	// FundingPolicyTopUp sends native currency from a relayer wallet to the
+	// user wallet when it cannot cover the gas of a transaction.
+	FundingPolicyTopUp FundingPolicy = "topup"
+	// FundingPolicyRelay would have a relayer wallet submit the user's transaction as a
+	// meta-transaction through a trusted forwarder. It is not supported: the relayer refuses
+	// to start with it.
+	FundingPolicyRelay FundingPolicy = "relay"
+)
+
+// SponsorshipStatus tells whether the funding transaction of a gas sponsorship was mined.
+type SponsorshipStatus string
+
+const (
+	// SponsorshipStatusPending means the funding transaction was broadcast but is not mined yet. The
+	// sponsored transaction is held back until it is.
+	SponsorshipStatusPending SponsorshipStatus = "pending"
+	// SponsorshipStatusConfirmed means the funding transaction was mined and its fee is known.
+	SponsorshipStatusConfirmed SponsorshipStatus = "confirmed"
+	// SponsorshipStatusFailed means the funding transaction reverted; its amount went back to the budget.
+	SponsorshipStatusFailed SponsorshipStatus = "failed"
+)
+
+type RelayerWallet struct {
+	ID                  uuid.UUID
+	ChainID             uuid.UUID
+	Address             string
+	EncryptedPrivateKey []byte
+	Active              bool
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+type CreateRelayerWalletParams struct {
+	ChainID             uuid.UUID
+	Address             string
+	EncryptedPrivateKey []byte
+}
+
+type SponsorshipBudget struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	ChainID   uuid.UUID
+	BudgetWei *big.Int
+	SpentWei  *big.Int
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Remaining returns the part of the budget that has not been spent yet.
+func (b SponsorshipBudget) Remaining() *big.Int {
+	remaining := new(big.Int).Sub(b.BudgetWei, b.SpentWei)
+	if remaining.Sign() < 0 {
+		return new(big.Int)
+	}
+	return remaining
+}
+
+type GasSponsorship struct {
+	ID              uuid.UUID
+	UserID          uuid.UUID
+	ChainID         uuid.UUID
+	RelayerWalletID uuid.UUID
+	TransactionID   uuid.UUID
+	FundingTxHash   string
+	AmountWei       *big.Int
+	FeeWei          *big.Int
+	Status          SponsorshipStatus
+	CreatedAt       time.Time
+}
+
+type CreateGasSponsorshipParams struct {
+	UserID          uuid.UUID
+	ChainID         uuid.UUID
+	RelayerWalletID uuid.UUID
+	TransactionID   uuid.UUID
+	FundingTxHash   string
+	AmountWei       *big.Int
+	FeeWei          *big.Int
+	Status          SponsorshipStatus
+}
+
+type GasSponsorshipResponse struct {
+	ID            uuid.UUID `json:"id"`
+	ChainID       uuid.UUID `json:"chain_id"`
+	TransactionID uuid.UUID `json:"transaction_id"`
+	FundingTxHash string    `json:"funding_tx_hash"`
+	AmountWei     string    `json:"amount_wei"`
+	FeeWei        string    `json:"fee_wei"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+}
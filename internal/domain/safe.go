@@ -0,0 +1,108 @@
+package domain
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+)
+
+// SafeTransaction is a Safe (Gnosis Safe) CALL without gas refunds,
+// so safeTxGas, baseGas, gasPrice, gasToken and refundReceiver are always zero.
+type SafeTransaction struct {
+	Safe  common.Address
+	To    common.Address
+	Value *big.Int
+	Data  []byte
+	Nonce *big.Int
+}
+
+type SafeInfo struct {
+	Owners    []common.Address
+	Threshold int
+	Nonce     *big.Int
+}
+
+type SafeProposal struct {
+	ID          uuid.UUID
+	WalletID    uuid.UUID
+	ChainID     uuid.UUID
+	SafeAddress string
+	ToAddress   string
+	Value       string
+	Data        []byte
+	Nonce       string
+	SafeTxHash  string
+	Threshold   int
+	Status      Status
+	ExecTxHash  string
+	// ExecTransactionID is the transaction that carries execTransaction once the proposal is submitted
+	ExecTransactionID uuid.UUID
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+type CreateSafeProposalParams struct {
+	ID          uuid.UUID
+	WalletID    uuid.UUID
+	ChainID     uuid.UUID
+	SafeAddress string
+	ToAddress   string
+	Value       string
+	Data        []byte
+	Nonce       string
+	SafeTxHash  string
+	Threshold   int
+	Status      Status
+}
+
+type SafeSignature struct {
+	ID         uuid.UUID
+	ProposalID uuid.UUID
+	Owner      string
+	Signature  []byte
+	CreatedAt  time.Time
+}
+
+type CreateSafeProposalRequest struct {
+	WalletID    uuid.UUID `json:"wallet_id" binding:"required"`
+	ChainID     uuid.UUID `json:"chain_id" binding:"required"`
+	SafeAddress string    `json:"safe_address" binding:"required"`
+	ToAddress   string    `json:"to_address" binding:"required"`
+	Amount      string    `json:"amount" binding:"required"`
+	Data        string    `json:"data"`
+}
+
+type AddSafeSignatureRequest struct {
+	Signature string `json:"signature" binding:"required"`
+}
+
+// ExecuteSafeProposalRequest carries signatures of Safe owners outside this service, collected off
+// chain, that are needed to reach the threshold.
+type ExecuteSafeProposalRequest struct {
+	Signatures []string `json:"signatures"`
+}
+
+type SafeSignatureResponse struct {
+	Owner     string `json:"owner"`
+	Signature string `json:"signature"`
+}
+
+type SafeProposalResponse struct {
+	ID          uuid.UUID `json:"id"`
+	WalletID    uuid.UUID `json:"wallet_id"`
+	ChainID     uuid.UUID `json:"chain_id"`
+	SafeAddress string    `json:"safe_address"`
+	ToAddress   string    `json:"to_address"`
+	Value       string    `json:"value"`
+	Data        string    `json:"data"`
+	Nonce       string    `json:"nonce"`
+	SafeTxHash  string    `json:"safe_tx_hash"`
+	Threshold   int       `json:"threshold"`
+	Status      Status    `json:"status"`
+	ExecTxHash  string    `json:"exec_tx_hash,omitempty"`
+	// ExecTransactionID is the transaction that carries execTransaction, listed with the wallet transactions
+	ExecTransactionID *uuid.UUID              `json:"exec_transaction_id,omitempty"`
+	Signatures        []SafeSignatureResponse `json:"signatures"`
+}
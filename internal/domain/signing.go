@@ -0,0 +1,42 @@
+package domain
+
+// SignatureScheme is the algorithm a signing payload is signed with.
+type SignatureScheme string
+
+const (
+	// SignatureSchemeECDSA signs with ECDSA over secp256k1. Signatures are 65 bytes, R || S || V, so
+	// EVM chains can recover the signer; other families re-encode them.
+	SignatureSchemeECDSA SignatureScheme = "ecdsa-secp256k1"
+	// SignatureSchemeSchnorr signs with BIP-340 Schnorr over secp256k1. Signatures are 64 bytes.
+	SignatureSchemeSchnorr SignatureScheme = "schnorr-secp256k1"
+	// SignatureSchemeEd25519 signs with the wallet's Ed25519 key. The digest is the whole message,
+	// since Ed25519 hashes it itself. Signatures are 64 bytes.
+	SignatureSchemeEd25519 SignatureScheme = "ed25519"
+)
+
+// SigningPayload is one digest a transaction needs signed, with the key that must sign it.
+type SigningPayload struct {
+	Digest []byte
+	Scheme SignatureScheme
+	// ChainCode and Path select a non-hardened BIP-32 child of the wallet key. Without a Path the
+	// wallet key itself signs. They do not apply to Ed25519.
+	ChainCode []byte
+	Path      []uint32
+	// TaprootTweak signs with the key tweaked into a BIP-86 Taproot output key.
+	TaprootTweak bool
+}
+
+// UnsignedTransaction is a transaction built by a chain family and waiting to be signed. Payload is
+// the family's own encoding and is opaque to everything else.
+type UnsignedTransaction struct {
+	Payload   []byte
+	ToAddress string
+	ToENSName string
+}
+
+// SignedTransaction is a transaction with all its signatures, ready to broadcast.
+type SignedTransaction struct {
+	// Payload is the network encoding of the transaction.
+	Payload []byte
+	TxHash  string
+}
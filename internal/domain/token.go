@@ -1,10 +1,14 @@
 package domain
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Token struct {
-	ID              int64
-	ChainID         int64
+	ID              uuid.UUID
+	ChainID         uuid.UUID
 	ContractAddress string
 	Name            string
 	Symbol          string
@@ -13,14 +13,20 @@ const (
 	StatusSuccess   Status = "success"
 	StatusFailed    Status = "failed"
 	StatusSubmitted Status = "submitted"
+	// StatusDropped means the node forgot the transaction and it could not be rebroadcast.
+	StatusDropped Status = "dropped"
+	// StatusReplaced means another transaction with the same nonce was mined instead.
+	StatusReplaced Status = "replaced"
 )
 
 type Transaction struct {
 	ID          uuid.UUID
 	WalletID    uuid.UUID
 	ChainID     uuid.UUID
+	Direction   Direction
 	FromAddress string
 	ToAddress   string
+	ToENSName   string
 	Amount      string
 	TokenID     uuid.UUID
 	GasPrice    string
@@ -28,8 +34,20 @@ type Transaction struct {
 	Nonce       int64
 	Status      Status
 	TxHash      string
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	BlockNumber int64
+	BlockHash   string
+	// GasUsed, EffectiveGasPrice and Fee (in wei) come from the receipt once the transaction is mined
+	GasUsed           int64
+	EffectiveGasPrice string
+	Fee               string
+	// RevertReason is set when the transaction was mined but reverted
+	RevertReason string
+	// RawTx is the hex encoded signed transaction, kept to rebroadcast it if the node drops it
+	RawTx string
+	// ParentID links a recipient of a Disperse batch to the transaction paying it, whose status it follows
+	ParentID  uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
 }
 
 type CreateTransactionParams struct {
@@ -38,6 +56,7 @@ type CreateTransactionParams struct {
 	ChainID     uuid.UUID
 	FromAddress string
 	ToAddress   string
+	ToENSName   string
 	Amount      string
 	TokenID     uuid.UUID
 	GasPrice    string
@@ -45,18 +64,42 @@ type CreateTransactionParams struct {
 	Nonce       int64
 	UnsignedTx  string
 	Status      Status
+	ParentID    uuid.UUID
 }
 
 type SubmitTransactionParams struct {
 	ID uuid.UUID
 }
 
+// TxState is what the node knows about a submitted transaction that has no receipt.
+type TxState string
+
+const (
+	// TxStatePending means the transaction is waiting in the mempool.
+	TxStatePending TxState = "pending"
+	// TxStateMined means the transaction was included in a block.
+	TxStateMined TxState = "mined"
+	// TxStateDropped means the node does not know the transaction and its nonce is still unused.
+	TxStateDropped TxState = "dropped"
+	// TxStateReplaced means the nonce was used by a different transaction.
+	TxStateReplaced TxState = "replaced"
+)
+
+// CreateTxnRequest accepts a hex address (EIP-55 checksummed when mixed case) or an ENS name as ToAddress
+// on EVM chains, and an address of the chain's network on Bitcoin chains.
 type CreateTxnRequest struct {
 	WalletID  uuid.UUID `json:"wallet_id" binding:"required"`
 	ChainID   uuid.UUID `json:"chain_id" binding:"required"`
 	ToAddress string    `json:"to_address" binding:"required"`
 	Amount    string    `json:"amount" binding:"required"`
 	TokenID   uuid.UUID `json:"token_id" binding:"required"`
+	// ChainFamily must match the chain's family when set; it defaults to the chain's family.
+	ChainFamily ChainFamily `json:"chain_family"`
+	// CoinSelection, FeeRate (in sat/vB) and FeePriority only apply to Bitcoin chains. FeeRate
+	// overrides the rate estimated for FeePriority.
+	CoinSelection CoinSelection `json:"coin_selection"`
+	FeeRate       int64         `json:"fee_rate"`
+	FeePriority   FeePriority   `json:"fee_priority"`
 }
 
 type CreateTxnResponse struct {
@@ -71,7 +114,50 @@ type SubmitTxnResponse struct {
 	TxHash string `json:"tx_hash"`
 }
 
+// TxnMessage is version 1 of the transaction.submitted payload, from before the message carried the
+// whole transaction.
 type TxnMessage struct {
 	ChainID uuid.UUID `json:"chain_id"`
 	TxHash  string    `json:"tx_hash"`
 }
+
+type BatchMode string
+
+const (
+	// BatchModeSequential sends one transaction per recipient with consecutive nonces.
+	BatchModeSequential BatchMode = "sequential"
+	// BatchModeDisperse pays every recipient in a single call to the Disperse contract.
+	BatchModeDisperse BatchMode = "disperse"
+)
+
+type BatchRecipient struct {
+	// ToAddress is a hex address (EIP-55 checksummed when mixed case) or an ENS name.
+	ToAddress string `json:"to_address" binding:"required"`
+	Amount    string `json:"amount" binding:"required"`
+}
+
+type CreateBatchTxnRequest struct {
+	WalletID   uuid.UUID        `json:"wallet_id" binding:"required"`
+	ChainID    uuid.UUID        `json:"chain_id" binding:"required"`
+	TokenID    uuid.UUID        `json:"token_id" binding:"required"`
+	Mode       BatchMode        `json:"mode" binding:"omitempty,oneof=sequential disperse"`
+	Recipients []BatchRecipient `json:"recipients" binding:"required,min=1,dive"`
+}
+
+type BatchTxnItem struct {
+	TxnID     uuid.UUID `json:"txn_id,omitempty"`
+	ToAddress string    `json:"to_address"`
+	ToENSName string    `json:"to_ens_name,omitempty"`
+	Amount    string    `json:"amount"`
+	Nonce     int64     `json:"nonce"`
+	TxHash    string    `json:"tx_hash,omitempty"`
+	Status    Status    `json:"status"`
+	Error     string    `json:"error,omitempty"`
+}
+
+type BatchTxnResponse struct {
+	Mode BatchMode `json:"mode"`
+	// ParentTxnID is the transaction of the Disperse call paying every recipient in disperse mode
+	ParentTxnID uuid.UUID      `json:"parent_txn_id,omitempty"`
+	Items       []BatchTxnItem `json:"items"`
+}
@@ -29,6 +29,7 @@ type UpdateUserParams struct {
 }
 
 type CreateHashedUserParams struct {
+	ID           uuid.UUID
 	Email        string
 	PasswordHash string
 }
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/google/uuid"
+)
+
+// UserOperation is an ERC-4337 (EntryPoint v0.6) user operation.
+type UserOperation struct {
+	Sender               common.Address
+	Nonce                *big.Int
+	InitCode             []byte
+	CallData             []byte
+	CallGasLimit         *big.Int
+	VerificationGasLimit *big.Int
+	PreVerificationGas   *big.Int
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+	PaymasterAndData     []byte
+	Signature            []byte
+}
+
+type UserOperationReceipt struct {
+	UserOpHash    common.Hash
+	Success       bool
+	Reason        string
+	ActualGasCost *big.Int
+	TxHash        common.Hash
+}
+
+// UserOperationTxn is a user operation sent on behalf of a wallet through its smart account.
+type UserOperationTxn struct {
+	ID            uuid.UUID
+	WalletID      uuid.UUID
+	ChainID       uuid.UUID
+	Sender        string
+	Nonce         string
+	ToAddress     string
+	Amount        string
+	UserOpHash    string
+	Status        Status
+	TxHash        string
+	ActualGasCost string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+type CreateUserOperationTxnParams struct {
+	ID         uuid.UUID
+	WalletID   uuid.UUID
+	ChainID    uuid.UUID
+	Sender     string
+	Nonce      string
+	ToAddress  string
+	Amount     string
+	UserOpHash string
+	Status     Status
+}
+
+type CreateUserOpRequest struct {
+	WalletID  uuid.UUID `json:"wallet_id" binding:"required"`
+	ChainID   uuid.UUID `json:"chain_id" binding:"required"`
+	ToAddress string    `json:"to_address" binding:"required"`
+	Amount    string    `json:"amount" binding:"required"`
+	Data      string    `json:"data"`
+}
+
+type CreateUserOpResponse struct {
+	ID         uuid.UUID `json:"id"`
+	Sender     string    `json:"sender"`
+	UserOpHash string    `json:"user_op_hash"`
+}
+
+type SmartAccountResponse struct {
+	WalletID uuid.UUID `json:"wallet_id"`
+	Owner    string    `json:"owner"`
+	Address  string    `json:"address"`
+	Deployed bool      `json:"deployed"`
+}
+
+// UserOpMessage is the payload of user_operation.submitted.
+type UserOpMessage struct {
+	ChainID    uuid.UUID `json:"chain_id"`
+	UserOpHash string    `json:"user_op_hash"`
+}
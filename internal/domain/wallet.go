@@ -16,6 +16,7 @@ type Wallet struct {
 }
 
 type CreateWalletParams struct {
+	ID                  uuid.UUID
 	UserID              uuid.UUID
 	Address             string
 	EncryptedPrivateKey []byte
@@ -26,3 +27,10 @@ type CreateWalletResponse struct {
 	UserID  uuid.UUID `json:"user_id"`
 	Address string    `json:"address"`
 }
+
+// WalletAddress is the address of a wallet on chains of one family.
+type WalletAddress struct {
+	WalletID uuid.UUID   `json:"wallet_id"`
+	Family   ChainFamily `json:"family"`
+	Address  string      `json:"address"`
+}
@@ -0,0 +1,114 @@
+// Package bitcoin derives Bitcoin addresses from wallet keys, builds PSBTs spending P2WPKH and P2TR
+// outputs and completes them with signatures of their signature hashes, and talks to Bitcoin nodes
+// through a pluggable backend.
+package bitcoin
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+
+	"mpc/internal/domain"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ErrUnknownNetwork = errors.New("unknown Bitcoin network")
+
+// NetworkParams returns the parameters of a network by the name stored as a Bitcoin chain's chain ID.
+func NetworkParams(network string) (*chaincfg.Params, error) {
+	for _, params := range []*chaincfg.Params{&chaincfg.MainNetParams, &chaincfg.TestNet3Params, &chaincfg.SigNetParams, &chaincfg.RegressionNetParams} {
+		if params.Name == network {
+			return params, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownNetwork, network)
+}
+
+// PrivateKey converts a wallet key to its btcec form. Both use secp256k1, so an EVM wallet key
+// controls Bitcoin addresses as well.
+func PrivateKey(key *ecdsa.PrivateKey) *btcec.PrivateKey {
+	privateKey, _ := btcec.PrivKeyFromBytes(crypto.FromECDSA(key))
+	return privateKey
+}
+
+// changeChainCodeTag domain-separates the chain code of change keys from other uses of the public key.
+const changeChainCodeTag = "mpc/bitcoin/change"
+
+// ChangeChainCode is the BIP-32 chain code that turns a wallet key into an extended key. It is a hash
+// of the public key, so change keys can always be derived again from the wallet key alone.
+func ChangeChainCode(publicKey *btcec.PublicKey) []byte {
+	chainCode := sha256.Sum256(append([]byte(changeChainCodeTag), publicKey.SerializeCompressed()...))
+	return chainCode[:]
+}
+
+// ChangePath is the derivation path of the change key at index, relative to the wallet key.
+func ChangePath(index uint32) []uint32 {
+	return []uint32{1, index}
+}
+
+// ChangeKey derives the key of the change address at index, the non-hardened child m/1/index of the
+// wallet key extended with ChangeChainCode.
+func ChangeKey(key *btcec.PrivateKey, index uint32) (*btcec.PrivateKey, error) {
+	return DeriveKey(key, ChangeChainCode(key.PubKey()), ChangePath(index))
+}
+
+// DeriveKey derives the non-hardened BIP-32 child of a key at path.
+func DeriveKey(key *btcec.PrivateKey, chainCode []byte, path []uint32) (*btcec.PrivateKey, error) {
+	extended := hdkeychain.NewExtendedKey(chaincfg.MainNetParams.HDPrivateKeyID[:], key.Serialize(), chainCode, []byte{0, 0, 0, 0}, 0, 0, true)
+	for _, index := range path {
+		if index >= hdkeychain.HardenedKeyStart {
+			return nil, fmt.Errorf("hardened index %d cannot be derived", index)
+		}
+		child, err := extended.Derive(index)
+		if err != nil {
+			return nil, err
+		}
+		extended = child
+	}
+	return extended.ECPrivKey()
+}
+
+// Addresses derives the P2WPKH and P2TR addresses of a public key. The Taproot output key commits
+// to no script, as in BIP-86, so it can only be spent through the key path.
+func Addresses(publicKey *btcec.PublicKey, params *chaincfg.Params) (p2wpkh *btcutil.AddressWitnessPubKeyHash, p2tr *btcutil.AddressTaproot, err error) {
+	p2wpkh, err = btcutil.NewAddressWitnessPubKeyHash(btcutil.Hash160(publicKey.SerializeCompressed()), params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	outputKey := txscript.ComputeTaprootKeyNoScript(publicKey)
+	p2tr, err = btcutil.NewAddressTaproot(schnorr.SerializePubKey(outputKey), params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p2wpkh, p2tr, nil
+}
+
+// DeriveAddresses returns the addresses of a wallet key in their encoded form.
+func DeriveAddresses(key *ecdsa.PrivateKey, params *chaincfg.Params) (domain.BitcoinAddresses, error) {
+	p2wpkh, p2tr, err := Addresses(PrivateKey(key).PubKey(), params)
+	if err != nil {
+		return domain.BitcoinAddresses{}, err
+	}
+	return domain.BitcoinAddresses{P2WPKH: p2wpkh.EncodeAddress(), P2TR: p2tr.EncodeAddress()}, nil
+}
+
+// DecodeAddress parses an address and makes sure it belongs to the network.
+func DecodeAddress(address string, params *chaincfg.Params) (btcutil.Address, error) {
+	decoded, err := btcutil.DecodeAddress(address, params)
+	if err != nil {
+		return nil, err
+	}
+	if !decoded.IsForNet(params) {
+		return nil, fmt.Errorf("address %s is not for %s", address, params.Name)
+	}
+	return decoded, nil
+}
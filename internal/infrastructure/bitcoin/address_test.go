@@ -0,0 +1,80 @@
+package bitcoin
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/hdkeychain"
+	"github.com/btcsuite/btcd/chaincfg"
+)
+
+// bipTestSeed is the seed of the mnemonic "abandon abandon abandon abandon abandon abandon abandon
+// abandon abandon abandon abandon about" that BIP-84 and BIP-86 derive their test vectors from.
+const bipTestSeed = "5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4"
+
+func TestAddresses(t *testing.T) {
+	tests := []struct {
+		name string
+		// purpose is the BIP-44 purpose of the account the key is derived from
+		purpose   uint32
+		publicKey string
+		p2wpkh    string
+		p2tr      string
+	}{
+		{
+			name:      "BIP-84 m/84'/0'/0'/0/0",
+			purpose:   84,
+			publicKey: "0330d54fd0dd420a6e5f8d3624f5f3482cae350f79d5f0753bf5beef9c2d91af3c",
+			p2wpkh:    "bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu",
+		},
+		{
+			name:    "BIP-86 m/86'/0'/0'/0/0",
+			purpose: 86,
+			p2tr:    "bc1p5cyxnuxmeuwuvkwfem96lqzszd02n6xdcjrs20cac6yqjjwudpxqkedrcr",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seed, err := hex.DecodeString(bipTestSeed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			key, err := hdkeychain.NewMaster(seed, &chaincfg.MainNetParams)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, index := range []uint32{hdkeychain.HardenedKeyStart + tt.purpose, hdkeychain.HardenedKeyStart, hdkeychain.HardenedKeyStart, 0, 0} {
+				if key, err = key.Derive(index); err != nil {
+					t.Fatal(err)
+				}
+			}
+			publicKey, err := key.ECPubKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.publicKey != "" && hex.EncodeToString(publicKey.SerializeCompressed()) != tt.publicKey {
+				t.Fatalf("public key = %x, want %s", publicKey.SerializeCompressed(), tt.publicKey)
+			}
+
+			p2wpkh, p2tr, err := Addresses(publicKey, &chaincfg.MainNetParams)
+			if err != nil {
+				t.Fatalf("Addresses() error = %v", err)
+			}
+			if tt.p2wpkh != "" && p2wpkh.EncodeAddress() != tt.p2wpkh {
+				t.Errorf("P2WPKH address = %s, want %s", p2wpkh.EncodeAddress(), tt.p2wpkh)
+			}
+			if tt.p2tr != "" && p2tr.EncodeAddress() != tt.p2tr {
+				t.Errorf("P2TR address = %s, want %s", p2tr.EncodeAddress(), tt.p2tr)
+			}
+		})
+	}
+}
+
+func TestDecodeAddress(t *testing.T) {
+	if _, err := DecodeAddress("bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", &chaincfg.MainNetParams); err != nil {
+		t.Fatalf("DecodeAddress() error = %v", err)
+	}
+	if _, err := DecodeAddress("bc1qcr8te4kr609gcawutmrza0j4xv80jy8z306fyu", &chaincfg.TestNet3Params); err == nil {
+		t.Fatal("DecodeAddress() of a mainnet address on testnet succeeded")
+	}
+}
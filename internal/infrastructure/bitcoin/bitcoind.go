@@ -0,0 +1,242 @@
+package bitcoin
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+
+	"mpc/internal/domain"
+	"mpc/internal/repository"
+
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/wire"
+)
+
+// rpcVerifyAlreadyInChain is the error code of sendrawtransaction for a transaction already in a block.
+const rpcVerifyAlreadyInChain = -27
+
+// fallbackFeeRate is used, in sat/vB, when the node has too little data to estimate fees, as on regtest.
+const fallbackFeeRate = 2
+
+// NewBackend returns the backend for a Bitcoin chain. The chain's RPC URL points at a bitcoind
+// JSON-RPC server, with the RPC credentials as the URL's user info.
+func NewBackend(chain domain.Chain) (repository.BitcoinBackend, error) {
+	return NewBitcoindClient(chain.RPCURL)
+}
+
+// BitcoindClient is a BitcoinBackend backed by the JSON-RPC interface of Bitcoin Core. It needs no
+// node wallet: the block scanner finds the outputs paying to wallet addresses in the blocks it fetches.
+type BitcoindClient struct {
+	url      string
+	user     string
+	password string
+	http     *http.Client
+	nextID   atomic.Int64
+}
+
+// Ensure BitcoindClient implements BitcoinBackend
+var _ repository.BitcoinBackend = (*BitcoindClient)(nil)
+
+func NewBitcoindClient(rawURL string) (*BitcoindClient, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bitcoind URL: %w", err)
+	}
+
+	client := &BitcoindClient{http: &http.Client{}}
+	if parsed.User != nil {
+		client.user = parsed.User.Username()
+		client.password, _ = parsed.User.Password()
+		parsed.User = nil
+	}
+	client.url = parsed.String()
+	return client, nil
+}
+
+type rpcRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      int64         `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type rpcResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("bitcoind error %d: %s", e.Code, e.Message)
+}
+
+func (c *BitcoindClient) call(ctx context.Context, method string, result interface{}, params ...interface{}) error {
+	body, err := json.Marshal(rpcRequest{JSONRPC: "1.0", ID: c.nextID.Add(1), Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.user != "" {
+		req.SetBasicAuth(c.user, c.password)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	// bitcoind answers errors with a non-200 status and the error in the body
+	var response rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode %s response (status %d): %w", method, resp.StatusCode, err)
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	if result == nil {
+		return nil
+	}
+	return json.Unmarshal(response.Result, result)
+}
+
+func (c *BitcoindClient) GetBlockCount(ctx context.Context) (uint64, error) {
+	var count uint64
+	if err := c.call(ctx, "getblockcount", &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (c *BitcoindClient) GetBlockHash(ctx context.Context, number uint64) (string, error) {
+	var hash string
+	if err := c.call(ctx, "getblockhash", &hash, number); err != nil {
+		return "", err
+	}
+	return hash, nil
+}
+
+// GetBlock fetches a block with its decoded transactions. Coinbase inputs spend nothing and are skipped.
+func (c *BitcoindClient) GetBlock(ctx context.Context, hash string) (domain.BitcoinBlock, error) {
+	var result struct {
+		Hash              string `json:"hash"`
+		Height            uint64 `json:"height"`
+		PreviousBlockHash string `json:"previousblockhash"`
+		Tx                []struct {
+			TxID string `json:"txid"`
+			Vin  []struct {
+				TxID string `json:"txid"`
+				Vout uint32 `json:"vout"`
+			} `json:"vin"`
+			Vout []struct {
+				Value        float64 `json:"value"`
+				N            uint32  `json:"n"`
+				ScriptPubKey struct {
+					Hex     string `json:"hex"`
+					Address string `json:"address"`
+				} `json:"scriptPubKey"`
+			} `json:"vout"`
+		} `json:"tx"`
+	}
+	// Verbosity 2 includes the decoded transactions
+	if err := c.call(ctx, "getblock", &result, hash, 2); err != nil {
+		return domain.BitcoinBlock{}, err
+	}
+
+	block := domain.BitcoinBlock{
+		Number:       result.Height,
+		Hash:         result.Hash,
+		ParentHash:   result.PreviousBlockHash,
+		Transactions: make([]domain.BitcoinTransaction, 0, len(result.Tx)),
+	}
+	for _, tx := range result.Tx {
+		transaction := domain.BitcoinTransaction{TxID: tx.TxID}
+		for _, in := range tx.Vin {
+			if in.TxID == "" {
+				continue
+			}
+			transaction.Inputs = append(transaction.Inputs, domain.BitcoinOutPoint{TxID: in.TxID, Vout: in.Vout})
+		}
+		for _, out := range tx.Vout {
+			// Outputs without an address, such as OP_RETURN data, cannot pay a wallet
+			if out.ScriptPubKey.Address == "" {
+				continue
+			}
+			amount, err := btcutil.NewAmount(out.Value)
+			if err != nil {
+				return domain.BitcoinBlock{}, fmt.Errorf("invalid amount of %s:%d: %w", tx.TxID, out.N, err)
+			}
+			pkScript, err := hex.DecodeString(out.ScriptPubKey.Hex)
+			if err != nil {
+				return domain.BitcoinBlock{}, fmt.Errorf("invalid script of %s:%d: %w", tx.TxID, out.N, err)
+			}
+			transaction.Outputs = append(transaction.Outputs, domain.BitcoinOutput{
+				Vout:     out.N,
+				Amount:   int64(amount),
+				Address:  out.ScriptPubKey.Address,
+				PkScript: pkScript,
+			})
+		}
+		block.Transactions = append(block.Transactions, transaction)
+	}
+	return block, nil
+}
+
+// EstimateFeeRate returns the fee rate, in sat/vB, for confirmation within targetBlocks.
+func (c *BitcoindClient) EstimateFeeRate(ctx context.Context, targetBlocks int) (int64, error) {
+	var result struct {
+		FeeRate float64  `json:"feerate"` // BTC/kvB
+		Errors  []string `json:"errors"`
+	}
+	if err := c.call(ctx, "estimatesmartfee", &result, targetBlocks); err != nil {
+		return 0, err
+	}
+	if result.FeeRate <= 0 {
+		return fallbackFeeRate, nil
+	}
+
+	satPerKvB, err := btcutil.NewAmount(result.FeeRate)
+	if err != nil {
+		return 0, err
+	}
+	return int64(math.Ceil(float64(satPerKvB) / 1000)), nil
+}
+
+// Broadcast submits a signed transaction and returns its ID. A transaction the node already has,
+// in its mempool or in a block, counts as broadcast, so rebroadcasting is safe.
+func (c *BitcoindClient) Broadcast(ctx context.Context, rawTx []byte) (string, error) {
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+		return "", fmt.Errorf("invalid transaction: %w", err)
+	}
+
+	var txID string
+	if err := c.call(ctx, "sendrawtransaction", &txID, hex.EncodeToString(rawTx)); err != nil {
+		var rpcErr *rpcError
+		if !errors.As(err, &rpcErr) {
+			return "", err
+		}
+		if rpcErr.Code == rpcVerifyAlreadyInChain || strings.Contains(rpcErr.Message, "txn-already-known") || strings.Contains(rpcErr.Message, "txn-already-in-mempool") {
+			return tx.TxHash().String(), nil
+		}
+		return "", fmt.Errorf("transaction rejected: %w", err)
+	}
+	return txID, nil
+}
@@ -0,0 +1,227 @@
+package bitcoin
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"mpc/internal/domain"
+)
+
+// bnbMaxTries bounds the branch-and-bound search, as in Bitcoin Core.
+const bnbMaxTries = 100000
+
+// Selection is the UTXOs chosen to fund a payment, with the fee they pay and the change left over.
+type Selection struct {
+	Inputs []domain.UTXO
+	Fee    int64 // satoshis
+	// Change is zero when what is left is below the dust limit and goes to the fee instead.
+	Change int64
+}
+
+// SelectCoins picks the UTXOs that pay amount to paymentScript at feeRate, sending change to
+// changeScript. An empty strategy means branch-and-bound.
+func SelectCoins(strategy domain.CoinSelection, utxos []domain.UTXO, amount int64, paymentScript []byte, changeScript []byte, feeRate int64) (Selection, error) {
+	s := coinSelector{
+		amount:    amount,
+		feeRate:   feeRate,
+		baseFee:   int64(txOverheadVSize+outputVSize(paymentScript)) * feeRate,
+		changeFee: int64(outputVSize(changeScript)) * feeRate,
+	}
+	s.costOfChange = s.changeFee + int64(inputVSize(changeScript))*feeRate
+
+	switch strategy {
+	case "", domain.CoinSelectionBranchAndBound:
+		if inputs := s.branchAndBound(utxos); inputs != nil {
+			return s.finish(inputs, false)
+		}
+		return s.finish(s.largestFirst(utxos), true)
+	case domain.CoinSelectionLargestFirst:
+		return s.finish(s.largestFirst(utxos), true)
+	case domain.CoinSelectionPrivacy:
+		return s.finish(s.privacy(utxos), true)
+	default:
+		return Selection{}, fmt.Errorf("unknown coin selection strategy %q", strategy)
+	}
+}
+
+type coinSelector struct {
+	amount  int64
+	feeRate int64
+	// baseFee pays for the transaction overhead and the payment output.
+	baseFee int64
+	// changeFee pays for the change output; costOfChange also covers spending it later.
+	changeFee    int64
+	costOfChange int64
+}
+
+// effectiveValue is what an output adds to the transaction after paying for its own input.
+func (s coinSelector) effectiveValue(utxo domain.UTXO) int64 {
+	return utxo.Amount - int64(inputVSize(utxo.PkScript))*s.feeRate
+}
+
+// target is the effective value the inputs must add up to.
+func (s coinSelector) target() int64 {
+	return s.amount + s.baseFee
+}
+
+// finish works out the fee and change of the inputs. Without allowChange, or when the change would
+// be dust, everything above the amount goes to the fee.
+func (s coinSelector) finish(inputs []domain.UTXO, allowChange bool) (Selection, error) {
+	var total, fee int64
+	for _, utxo := range inputs {
+		total += utxo.Amount
+		fee += int64(inputVSize(utxo.PkScript)) * s.feeRate
+	}
+	fee += s.baseFee
+
+	if total < s.amount+fee {
+		return Selection{}, fmt.Errorf("%w: have %d sat, need %d sat", ErrInsufficientFunds, total, s.amount+fee)
+	}
+
+	selection := Selection{Inputs: inputs, Fee: total - s.amount}
+	if change := total - s.amount - fee - s.changeFee; allowChange && change >= dustLimit {
+		selection.Change = change
+		selection.Fee = fee + s.changeFee
+	}
+	return selection, nil
+}
+
+// spendable returns the outputs worth spending at the fee rate, largest effective value first.
+func (s coinSelector) spendable(utxos []domain.UTXO) []domain.UTXO {
+	spendable := make([]domain.UTXO, 0, len(utxos))
+	for _, utxo := range utxos {
+		if s.effectiveValue(utxo) > 0 {
+			spendable = append(spendable, utxo)
+		}
+	}
+	sort.SliceStable(spendable, func(i, j int) bool {
+		return s.effectiveValue(spendable[i]) > s.effectiveValue(spendable[j])
+	})
+	return spendable
+}
+
+// largestFirst adds the largest outputs until they cover the target. When all outputs fall short it
+// returns them all, and finish reports the shortfall.
+func (s coinSelector) largestFirst(utxos []domain.UTXO) []domain.UTXO {
+	var (
+		selected []domain.UTXO
+		sum      int64
+	)
+	for _, utxo := range s.spendable(utxos) {
+		selected = append(selected, utxo)
+		sum += s.effectiveValue(utxo)
+		if sum >= s.target()+s.changeFee {
+			break
+		}
+	}
+	return selected
+}
+
+// branchAndBound searches for inputs whose effective value lands between the target and the target
+// plus the cost of change, so no change output is needed. Of the sets it finds it keeps the one that
+// overpays the least. It returns nil when there is no such set.
+func (s coinSelector) branchAndBound(utxos []domain.UTXO) []domain.UTXO {
+	candidates := s.spendable(utxos)
+	values := make([]int64, len(candidates))
+	remaining := make([]int64, len(candidates)+1)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		values[i] = s.effectiveValue(candidates[i])
+		remaining[i] = remaining[i+1] + values[i]
+	}
+
+	target := s.target()
+	upper := target + s.costOfChange
+	var (
+		best       []int
+		bestExcess int64 = math.MaxInt64
+		selected   []int
+		tries      = bnbMaxTries
+	)
+
+	var search func(i int, sum int64)
+	search = func(i int, sum int64) {
+		tries--
+		if tries < 0 || sum > upper {
+			return
+		}
+		if sum >= target {
+			if excess := sum - target; excess < bestExcess {
+				bestExcess = excess
+				best = append(best[:0], selected...)
+			}
+			return
+		}
+		if i == len(candidates) || sum+remaining[i] < target {
+			return
+		}
+
+		selected = append(selected, i)
+		search(i+1, sum+values[i])
+		selected = selected[:len(selected)-1]
+
+		// Having left out this output, including an equal one next would only repeat the sets above
+		next := i + 1
+		for next < len(candidates) && values[next] == values[i] {
+			next++
+		}
+		search(next, sum)
+	}
+	search(0, 0)
+
+	if bestExcess == math.MaxInt64 {
+		return nil
+	}
+	inputs := make([]domain.UTXO, 0, len(best))
+	for _, i := range best {
+		inputs = append(inputs, candidates[i])
+	}
+	return inputs
+}
+
+// privacy spends whole addresses, so an address is never left holding outputs the transaction links
+// to the wallet. It pays from the smallest single address that covers the target and otherwise
+// combines addresses largest first.
+func (s coinSelector) privacy(utxos []domain.UTXO) []domain.UTXO {
+	type group struct {
+		utxos []domain.UTXO
+		value int64
+	}
+
+	var groups []*group
+	byAddress := make(map[string]*group)
+	for _, utxo := range utxos {
+		g, ok := byAddress[utxo.Address]
+		if !ok {
+			g = &group{}
+			byAddress[utxo.Address] = g
+			groups = append(groups, g)
+		}
+		g.utxos = append(g.utxos, utxo)
+		g.value += s.effectiveValue(utxo)
+	}
+	sort.SliceStable(groups, func(i, j int) bool { return groups[i].value > groups[j].value })
+
+	target := s.target() + s.changeFee
+	for i := len(groups) - 1; i >= 0; i-- {
+		if groups[i].value >= target {
+			return groups[i].utxos
+		}
+	}
+
+	var (
+		selected []domain.UTXO
+		sum      int64
+	)
+	for _, g := range groups {
+		if g.value <= 0 {
+			break
+		}
+		selected = append(selected, g.utxos...)
+		sum += g.value
+		if sum >= target {
+			break
+		}
+	}
+	return selected
+}
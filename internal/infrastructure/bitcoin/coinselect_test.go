@@ -0,0 +1,148 @@
+package bitcoin
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"mpc/internal/domain"
+)
+
+// p2wpkhScript is a P2WPKH output script; only its type matters to coin selection.
+var p2wpkhScript = append([]byte{0x00, 0x14}, make([]byte, 20)...)
+
+func utxo(address string, amount int64) domain.UTXO {
+	return domain.UTXO{Address: address, Amount: amount, PkScript: p2wpkhScript}
+}
+
+// At 1 sat/vB with P2WPKH scripts, the overhead and payment output cost 42 sat, every input 68 sat
+// and a change output 31 sat.
+func TestSelectCoins(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategy   domain.CoinSelection
+		utxos      []domain.UTXO
+		amount     int64
+		wantInputs []int64
+		wantFee    int64
+		wantChange int64
+	}{
+		{
+			name:       "branch and bound exact match",
+			utxos:      []domain.UTXO{utxo("a", 5000), utxo("a", 10110), utxo("a", 20000)},
+			amount:     10000,
+			wantInputs: []int64{10110},
+			wantFee:    110,
+		},
+		{
+			name:       "branch and bound combines inputs",
+			strategy:   domain.CoinSelectionBranchAndBound,
+			utxos:      []domain.UTXO{utxo("a", 4068), utxo("a", 20000), utxo("a", 6110)},
+			amount:     10000,
+			wantInputs: []int64{6110, 4068},
+			wantFee:    178,
+		},
+		{
+			name:       "branch and bound within the cost of change leaves no change",
+			utxos:      []domain.UTXO{utxo("a", 10200)},
+			amount:     10000,
+			wantInputs: []int64{10200},
+			wantFee:    200,
+		},
+		{
+			name:       "branch and bound falls back to largest first",
+			utxos:      []domain.UTXO{utxo("a", 50000)},
+			amount:     10000,
+			wantInputs: []int64{50000},
+			wantFee:    141,
+			wantChange: 39859,
+		},
+		{
+			name:       "largest first",
+			strategy:   domain.CoinSelectionLargestFirst,
+			utxos:      []domain.UTXO{utxo("a", 1000), utxo("a", 30000), utxo("a", 20000)},
+			amount:     40000,
+			wantInputs: []int64{30000, 20000},
+			wantFee:    209,
+			wantChange: 9791,
+		},
+		{
+			name:       "largest first skips outputs worth less than their input",
+			strategy:   domain.CoinSelectionLargestFirst,
+			utxos:      []domain.UTXO{utxo("a", 60), utxo("a", 50000)},
+			amount:     10000,
+			wantInputs: []int64{50000},
+			wantFee:    141,
+			wantChange: 39859,
+		},
+		{
+			name:       "dust change goes to the fee",
+			strategy:   domain.CoinSelectionLargestFirst,
+			utxos:      []domain.UTXO{utxo("a", 10500)},
+			amount:     10000,
+			wantInputs: []int64{10500},
+			wantFee:    500,
+		},
+		{
+			name:       "privacy spends the smallest address that covers the payment",
+			strategy:   domain.CoinSelectionPrivacy,
+			utxos:      []domain.UTXO{utxo("a", 3000), utxo("b", 20000), utxo("a", 4000), utxo("c", 9000)},
+			amount:     5000,
+			wantInputs: []int64{3000, 4000},
+			wantFee:    209,
+			wantChange: 1791,
+		},
+		{
+			name:       "privacy combines whole addresses largest first",
+			strategy:   domain.CoinSelectionPrivacy,
+			utxos:      []domain.UTXO{utxo("a", 3000), utxo("b", 20000), utxo("a", 4000), utxo("c", 9000)},
+			amount:     25000,
+			wantInputs: []int64{20000, 9000},
+			wantFee:    209,
+			wantChange: 3791,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selection, err := SelectCoins(tt.strategy, tt.utxos, tt.amount, p2wpkhScript, p2wpkhScript, 1)
+			if err != nil {
+				t.Fatalf("SelectCoins() error = %v", err)
+			}
+
+			var inputs []int64
+			var total int64
+			for _, input := range selection.Inputs {
+				inputs = append(inputs, input.Amount)
+				total += input.Amount
+			}
+			if fmt.Sprint(inputs) != fmt.Sprint(tt.wantInputs) {
+				t.Errorf("inputs = %v, want %v", inputs, tt.wantInputs)
+			}
+			if selection.Fee != tt.wantFee || selection.Change != tt.wantChange {
+				t.Errorf("fee = %d, change = %d, want %d and %d", selection.Fee, selection.Change, tt.wantFee, tt.wantChange)
+			}
+			if total != tt.amount+selection.Fee+selection.Change {
+				t.Errorf("inputs of %d sat do not add up to amount, fee and change", total)
+			}
+		})
+	}
+}
+
+func TestSelectCoinsInsufficientFunds(t *testing.T) {
+	utxos := []domain.UTXO{utxo("a", 4000), utxo("b", 6000)}
+
+	for _, strategy := range []domain.CoinSelection{domain.CoinSelectionBranchAndBound, domain.CoinSelectionLargestFirst, domain.CoinSelectionPrivacy} {
+		t.Run(string(strategy), func(t *testing.T) {
+			// The outputs hold the amount, but not the fee on top of it
+			if _, err := SelectCoins(strategy, utxos, 10000, p2wpkhScript, p2wpkhScript, 1); !errors.Is(err, ErrInsufficientFunds) {
+				t.Fatalf("SelectCoins() error = %v, want %v", err, ErrInsufficientFunds)
+			}
+		})
+	}
+}
+
+func TestSelectCoinsUnknownStrategy(t *testing.T) {
+	if _, err := SelectCoins("random", []domain.UTXO{utxo("a", 50000)}, 10000, p2wpkhScript, p2wpkhScript, 1); err == nil {
+		t.Fatal("SelectCoins() with an unknown strategy succeeded")
+	}
+}
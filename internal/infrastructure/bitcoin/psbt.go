@@ -0,0 +1,269 @@
+package bitcoin
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand"
+
+	"mpc/internal/domain"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/ecdsa"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+)
+
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// Virtual sizes, in vbytes, used to estimate the fee before the transaction is signed.
+const (
+	txOverheadVSize   = 11
+	p2wpkhInputVSize  = 68
+	p2trInputVSize    = 58
+	p2wpkhOutputVSize = 31
+	p2trOutputVSize   = 43
+)
+
+// dustLimit is the smallest change output worth creating; smaller change is left to the fee.
+const dustLimit = 546
+
+// Payment is one output of a transaction to build.
+type Payment struct {
+	Address btcutil.Address
+	Amount  int64 // satoshis
+}
+
+// BuildPSBT creates an unsigned PSBT spending the selected UTXOs to the payment, with the selection's
+// change, if any, going to the change address. Each input records the key origin found in keys.
+// Inputs and outputs are shuffled so the position of the change output does not give it away.
+func BuildPSBT(selection Selection, payment Payment, change btcutil.Address, keys KeyRing) (*psbt.Packet, error) {
+	paymentScript, err := txscript.PayToAddrScript(payment.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid payment address: %w", err)
+	}
+
+	outputs := []*wire.TxOut{wire.NewTxOut(payment.Amount, paymentScript)}
+	if selection.Change > 0 {
+		changeScript, err := txscript.PayToAddrScript(change)
+		if err != nil {
+			return nil, fmt.Errorf("invalid change address: %w", err)
+		}
+		outputs = append(outputs, wire.NewTxOut(selection.Change, changeScript))
+	}
+	rand.Shuffle(len(outputs), func(i, j int) { outputs[i], outputs[j] = outputs[j], outputs[i] })
+
+	utxos := append([]domain.UTXO(nil), selection.Inputs...)
+	rand.Shuffle(len(utxos), func(i, j int) { utxos[i], utxos[j] = utxos[j], utxos[i] })
+
+	inputs := make([]*wire.OutPoint, 0, len(utxos))
+	for _, utxo := range utxos {
+		hash, err := chainhash.NewHashFromStr(utxo.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid UTXO %s:%d: %w", utxo.TxID, utxo.Vout, err)
+		}
+		inputs = append(inputs, wire.NewOutPoint(hash, utxo.Vout))
+	}
+
+	packet, err := psbt.New(inputs, outputs, 2, 0, make([]uint32, len(inputs)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create PSBT: %w", err)
+	}
+	for i, utxo := range utxos {
+		packet.Inputs[i].WitnessUtxo = wire.NewTxOut(utxo.Amount, utxo.PkScript)
+	}
+	if err := AddKeyOrigins(packet, keys); err != nil {
+		return nil, err
+	}
+	return packet, nil
+}
+
+// Fee returns what a packet pays in fees: the value of its inputs minus the value of its outputs.
+func Fee(packet *psbt.Packet) int64 {
+	var fee int64
+	for _, input := range packet.Inputs {
+		if input.WitnessUtxo != nil {
+			fee += input.WitnessUtxo.Value
+		}
+	}
+	for _, output := range packet.UnsignedTx.TxOut {
+		fee -= output.Value
+	}
+	return fee
+}
+
+// KeyOrigin is the public key behind an output script and its derivation path from the wallet key.
+type KeyOrigin struct {
+	PublicKey *btcec.PublicKey
+	Path      []uint32
+}
+
+// KeyRing holds the public keys of a wallet by the output scripts they can spend.
+type KeyRing map[string]KeyOrigin
+
+// Add makes the key's P2WPKH and P2TR outputs spendable.
+func (k KeyRing) Add(publicKey *btcec.PublicKey, path []uint32, params *chaincfg.Params) error {
+	p2wpkh, p2tr, err := Addresses(publicKey, params)
+	if err != nil {
+		return err
+	}
+	for _, address := range []btcutil.Address{p2wpkh, p2tr} {
+		script, err := txscript.PayToAddrScript(address)
+		if err != nil {
+			return err
+		}
+		k[string(script)] = KeyOrigin{PublicKey: publicKey, Path: path}
+	}
+	return nil
+}
+
+// AddKeyOrigins records on every input the public key that spends it and its derivation path, in the
+// BIP-32 derivation fields of the PSBT, so the packet carries everything needed to sign it.
+func AddKeyOrigins(packet *psbt.Packet, keys KeyRing) error {
+	for i := range packet.Inputs {
+		input := &packet.Inputs[i]
+		if input.WitnessUtxo == nil {
+			return fmt.Errorf("input %d has no witness UTXO", i)
+		}
+		origin, ok := keys[string(input.WitnessUtxo.PkScript)]
+		if !ok {
+			return fmt.Errorf("no key for input %d", i)
+		}
+
+		if txscript.IsPayToTaproot(input.WitnessUtxo.PkScript) {
+			xOnly := schnorr.SerializePubKey(origin.PublicKey)
+			input.TaprootInternalKey = xOnly
+			input.TaprootBip32Derivation = []*psbt.TaprootBip32Derivation{{XOnlyPubKey: xOnly, Bip32Path: origin.Path}}
+			continue
+		}
+		input.Bip32Derivation = []*psbt.Bip32Derivation{{PubKey: origin.PublicKey.SerializeCompressed(), Bip32Path: origin.Path}}
+	}
+	return nil
+}
+
+// SigningPayloads returns the signature hash of every input of the packet: an ECDSA one for P2WPKH
+// inputs and a BIP-86 key-path Schnorr one for P2TR inputs. Inputs spent by change keys name their
+// path, with chainCode, the chain code of the wallet key.
+func SigningPayloads(packet *psbt.Packet, chainCode []byte) ([]domain.SigningPayload, error) {
+	tx := packet.UnsignedTx
+
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut, len(tx.TxIn))
+	for i, txIn := range tx.TxIn {
+		if packet.Inputs[i].WitnessUtxo == nil {
+			return nil, fmt.Errorf("input %d has no witness UTXO", i)
+		}
+		prevOuts[txIn.PreviousOutPoint] = packet.Inputs[i].WitnessUtxo
+	}
+	prevOutFetcher := txscript.NewMultiPrevOutFetcher(prevOuts)
+	sigHashes := txscript.NewTxSigHashes(tx, prevOutFetcher)
+
+	payloads := make([]domain.SigningPayload, len(tx.TxIn))
+	for i := range tx.TxIn {
+		input := packet.Inputs[i]
+		utxo := input.WitnessUtxo
+
+		var (
+			payload domain.SigningPayload
+			err     error
+		)
+		switch txscript.GetScriptClass(utxo.PkScript) {
+		case txscript.WitnessV0PubKeyHashTy:
+			if len(input.Bip32Derivation) == 0 {
+				return nil, fmt.Errorf("input %d has no key origin", i)
+			}
+			payload = domain.SigningPayload{Scheme: domain.SignatureSchemeECDSA, Path: input.Bip32Derivation[0].Bip32Path}
+			payload.Digest, err = txscript.CalcWitnessSigHash(utxo.PkScript, sigHashes, txscript.SigHashAll, tx, i, utxo.Value)
+		case txscript.WitnessV1TaprootTy:
+			if len(input.TaprootBip32Derivation) == 0 {
+				return nil, fmt.Errorf("input %d has no key origin", i)
+			}
+			payload = domain.SigningPayload{Scheme: domain.SignatureSchemeSchnorr, Path: input.TaprootBip32Derivation[0].Bip32Path, TaprootTweak: true}
+			payload.Digest, err = txscript.CalcTaprootSignatureHash(sigHashes, txscript.SigHashDefault, tx, i, prevOutFetcher)
+		default:
+			return nil, fmt.Errorf("input %d has an unsupported script type", i)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash input %d: %w", i, err)
+		}
+		if len(payload.Path) > 0 {
+			payload.ChainCode = chainCode
+		}
+		payloads[i] = payload
+	}
+	return payloads, nil
+}
+
+// AddSignatures adds one signature per input, in the order of SigningPayloads. ECDSA signatures come
+// as R || S || V and are DER encoded here.
+func AddSignatures(packet *psbt.Packet, signatures [][]byte) error {
+	if len(signatures) != len(packet.Inputs) {
+		return fmt.Errorf("got %d signatures for %d inputs", len(signatures), len(packet.Inputs))
+	}
+
+	updater, err := psbt.NewUpdater(packet)
+	if err != nil {
+		return err
+	}
+
+	for i, signature := range signatures {
+		input := packet.Inputs[i]
+		switch txscript.GetScriptClass(input.WitnessUtxo.PkScript) {
+		case txscript.WitnessV0PubKeyHashTy:
+			if len(signature) < 64 {
+				return fmt.Errorf("input %d: invalid ECDSA signature", i)
+			}
+			var r, s btcec.ModNScalar
+			r.SetByteSlice(signature[:32])
+			s.SetByteSlice(signature[32:64])
+			der := append(ecdsa.NewSignature(&r, &s).Serialize(), byte(txscript.SigHashAll))
+			if _, err := updater.Sign(i, der, input.Bip32Derivation[0].PubKey, nil, nil); err != nil {
+				return fmt.Errorf("failed to add signature to input %d: %w", i, err)
+			}
+		case txscript.WitnessV1TaprootTy:
+			if len(signature) != schnorr.SignatureSize {
+				return fmt.Errorf("input %d: invalid Schnorr signature", i)
+			}
+			packet.Inputs[i].TaprootKeySpendSig = signature
+		default:
+			return fmt.Errorf("input %d has an unsupported script type", i)
+		}
+	}
+	return nil
+}
+
+// ExtractTransaction finalizes a fully signed packet and returns the serialized network transaction.
+func ExtractTransaction(packet *psbt.Packet) (*wire.MsgTx, []byte, error) {
+	if err := psbt.MaybeFinalizeAll(packet); err != nil {
+		return nil, nil, fmt.Errorf("failed to finalize PSBT: %w", err)
+	}
+
+	tx, err := psbt.Extract(packet)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to extract transaction: %w", err)
+	}
+
+	var raw bytes.Buffer
+	if err := tx.Serialize(&raw); err != nil {
+		return nil, nil, err
+	}
+	return tx, raw.Bytes(), nil
+}
+
+func inputVSize(pkScript []byte) int {
+	if txscript.IsPayToTaproot(pkScript) {
+		return p2trInputVSize
+	}
+	return p2wpkhInputVSize
+}
+
+func outputVSize(pkScript []byte) int {
+	if txscript.IsPayToTaproot(pkScript) {
+		return p2trOutputVSize
+	}
+	return p2wpkhOutputVSize
+}
@@ -0,0 +1,163 @@
+package bitcoin
+
+import (
+	"bytes"
+	"testing"
+
+	"mpc/internal/domain"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// signLocally signs the payloads with key the way the wallet signer does.
+func signLocally(t *testing.T, key *btcec.PrivateKey, payloads []domain.SigningPayload) [][]byte {
+	t.Helper()
+
+	signatures := make([][]byte, 0, len(payloads))
+	for _, payload := range payloads {
+		signingKey := key
+		if len(payload.Path) > 0 {
+			child, err := DeriveKey(key, payload.ChainCode, payload.Path)
+			if err != nil {
+				t.Fatalf("DeriveKey() error = %v", err)
+			}
+			signingKey = child
+		}
+
+		switch payload.Scheme {
+		case domain.SignatureSchemeECDSA:
+			signature, err := crypto.Sign(payload.Digest, signingKey.ToECDSA())
+			if err != nil {
+				t.Fatal(err)
+			}
+			signatures = append(signatures, signature)
+		case domain.SignatureSchemeSchnorr:
+			if payload.TaprootTweak {
+				signingKey = txscript.TweakTaprootPrivKey(*signingKey, nil)
+			}
+			signature, err := schnorr.Sign(signingKey, payload.Digest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			signatures = append(signatures, signature.Serialize())
+		default:
+			t.Fatalf("unexpected signature scheme %q", payload.Scheme)
+		}
+	}
+	return signatures
+}
+
+func TestPSBTRoundTrip(t *testing.T) {
+	params := &chaincfg.RegressionNetParams
+	key, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x11}, 32))
+	changeKey, err := ChangeKey(key, 3)
+	if err != nil {
+		t.Fatalf("ChangeKey() error = %v", err)
+	}
+
+	keys := KeyRing{}
+	if err := keys.Add(key.PubKey(), nil, params); err != nil {
+		t.Fatal(err)
+	}
+	if err := keys.Add(changeKey.PubKey(), ChangePath(3), params); err != nil {
+		t.Fatal(err)
+	}
+
+	walletP2WPKH, _, err := Addresses(key.PubKey(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, changeP2TR, err := Addresses(changeKey.PubKey(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	walletScript, _ := txscript.PayToAddrScript(walletP2WPKH)
+	changeScript, _ := txscript.PayToAddrScript(changeP2TR)
+
+	// One input of each kind: the wallet key's P2WPKH output and a change key's P2TR output
+	selection := Selection{
+		Inputs: []domain.UTXO{
+			{TxID: "1111111111111111111111111111111111111111111111111111111111111111", Vout: 0, Amount: 60000, PkScript: walletScript},
+			{TxID: "2222222222222222222222222222222222222222222222222222222222222222", Vout: 1, Amount: 40000, PkScript: changeScript},
+		},
+		Fee:    500,
+		Change: 29500,
+	}
+	recipientKey, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x22}, 32))
+	recipient, _, err := Addresses(recipientKey.PubKey(), params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	packet, err := BuildPSBT(selection, Payment{Address: recipient, Amount: 70000}, changeP2TR, keys)
+	if err != nil {
+		t.Fatalf("BuildPSBT() error = %v", err)
+	}
+	if fee := Fee(packet); fee != selection.Fee {
+		t.Fatalf("Fee() = %d, want %d", fee, selection.Fee)
+	}
+
+	payloads, err := SigningPayloads(packet, ChangeChainCode(key.PubKey()))
+	if err != nil {
+		t.Fatalf("SigningPayloads() error = %v", err)
+	}
+	if err := AddSignatures(packet, signLocally(t, key, payloads)); err != nil {
+		t.Fatalf("AddSignatures() error = %v", err)
+	}
+
+	// Read the inputs back in the shuffled order of the packet before it is finalized
+	prevOuts := make(map[wire.OutPoint]*wire.TxOut)
+	for i, txIn := range packet.UnsignedTx.TxIn {
+		prevOuts[txIn.PreviousOutPoint] = packet.Inputs[i].WitnessUtxo
+	}
+
+	tx, raw, err := ExtractTransaction(packet)
+	if err != nil {
+		t.Fatalf("ExtractTransaction() error = %v", err)
+	}
+	if len(raw) == 0 || len(tx.TxIn) != 2 || len(tx.TxOut) != 2 {
+		t.Fatalf("extracted %d bytes with %d inputs and %d outputs", len(raw), len(tx.TxIn), len(tx.TxOut))
+	}
+
+	prevOutFetcher := txscript.NewMultiPrevOutFetcher(prevOuts)
+	sigHashes := txscript.NewTxSigHashes(tx, prevOutFetcher)
+	for i, txIn := range tx.TxIn {
+		prevOut := prevOuts[txIn.PreviousOutPoint]
+		engine, err := txscript.NewEngine(prevOut.PkScript, tx, i, txscript.StandardVerifyFlags, nil, sigHashes, prevOut.Value, prevOutFetcher)
+		if err != nil {
+			t.Fatalf("NewEngine() for input %d error = %v", i, err)
+		}
+		if err := engine.Execute(); err != nil {
+			t.Fatalf("input %d does not verify: %v", i, err)
+		}
+	}
+}
+
+func TestAddSignaturesCount(t *testing.T) {
+	key, _ := btcec.PrivKeyFromBytes(bytes.Repeat([]byte{0x11}, 32))
+	keys := KeyRing{}
+	if err := keys.Add(key.PubKey(), nil, &chaincfg.RegressionNetParams); err != nil {
+		t.Fatal(err)
+	}
+	p2wpkh, _, err := Addresses(key.PubKey(), &chaincfg.RegressionNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	script, _ := txscript.PayToAddrScript(p2wpkh)
+
+	packet, err := BuildPSBT(Selection{
+		Inputs: []domain.UTXO{{TxID: "1111111111111111111111111111111111111111111111111111111111111111", Amount: 60000, PkScript: script}},
+		Fee:    1000,
+	}, Payment{Address: p2wpkh, Amount: 59000}, p2wpkh, keys)
+	if err != nil {
+		t.Fatalf("BuildPSBT() error = %v", err)
+	}
+	if err := AddSignatures(packet, nil); err == nil {
+		t.Fatal("AddSignatures() without signatures succeeded")
+	}
+}
@@ -1,6 +1,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/kelseyhightower/envconfig"
 	"github.com/sirupsen/logrus"
 )
@@ -13,6 +15,13 @@ type Config struct {
 	Ethereum EthereumConfig
 	Kafka    KafkaConfig
 	Mail     MailConfig
+	Relayer  RelayerConfig
+	ERC4337  ERC4337Config
+	Batch    BatchConfig
+	ENS      ENSConfig
+	Scanner  ScannerConfig
+	Balance  BalanceConfig
+	Outbox   OutboxConfig
 }
 
 type AppConfig struct {
@@ -38,20 +47,114 @@ type RedisConfig struct {
 type EthereumConfig struct {
 	URL       string `envconfig:"ETHEREUM_URL"`
 	SecretKey string `envconfig:"ETHEREUM_SECRET_KEY"`
+	// FallbackURLs are used when URL is unhealthy; calls go to the healthiest endpoint.
+	FallbackURLs []string `envconfig:"ETHEREUM_FALLBACK_URLS"`
+	// HealthCheckInterval is how often every endpoint's latest block is polled.
+	HealthCheckInterval time.Duration `envconfig:"ETHEREUM_HEALTH_CHECK_INTERVAL" default:"15s"`
+	// MaxBlockLag is how many blocks an endpoint may trail the others before it is avoided.
+	MaxBlockLag uint64 `envconfig:"ETHEREUM_MAX_BLOCK_LAG" default:"5"`
+	// Cooldown is how long an endpoint is avoided after a transport failure. Once it is over the
+	// endpoint starts afresh and is tried again.
+	Cooldown time.Duration `envconfig:"ETHEREUM_COOLDOWN" default:"30s"`
+	// MaxRetries and RetryBackoff control retries of read calls after transport failures.
+	MaxRetries   int           `envconfig:"ETHEREUM_MAX_RETRIES" default:"3"`
+	RetryBackoff time.Duration `envconfig:"ETHEREUM_RETRY_BACKOFF" default:"200ms"`
+	// ReadTimeout bounds every attempt of a read call, SubmitTimeout the broadcast of a transaction
+	// and ReceiptTimeout the wait for a transaction to be mined.
+	ReadTimeout    time.Duration `envconfig:"ETHEREUM_READ_TIMEOUT" default:"10s"`
+	SubmitTimeout  time.Duration `envconfig:"ETHEREUM_SUBMIT_TIMEOUT" default:"30s"`
+	ReceiptTimeout time.Duration `envconfig:"ETHEREUM_RECEIPT_TIMEOUT" default:"5m"`
+}
+
+type RelayerConfig struct {
+	// FundingPolicy is none or topup. Relaying meta-transactions is not supported.
+	FundingPolicy string `envconfig:"RELAYER_FUNDING_POLICY" default:"none"`
+	// DefaultBudgetWei is the sponsorship budget granted to every user per chain.
+	DefaultBudgetWei string `envconfig:"RELAYER_DEFAULT_BUDGET_WEI" default:"10000000000000000"`
+	// MaxTopUpWei caps a single top-up sent to a user wallet.
+	MaxTopUpWei string `envconfig:"RELAYER_MAX_TOPUP_WEI" default:"5000000000000000"`
+	// TopUpBufferPercent is added on top of the shortfall to absorb gas price movements.
+	TopUpBufferPercent int `envconfig:"RELAYER_TOPUP_BUFFER_PERCENT" default:"20"`
+}
+
+type ERC4337Config struct {
+	// BundlerURL defaults to the Ethereum RPC URL when empty.
+	BundlerURL     string `envconfig:"ERC4337_BUNDLER_URL"`
+	EntryPoint     string `envconfig:"ERC4337_ENTRY_POINT" default:"0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789"`
+	AccountFactory string `envconfig:"ERC4337_ACCOUNT_FACTORY" default:"0x9406Cc6185a346906296840746125a0E44976454"`
+	Salt           int64  `envconfig:"ERC4337_SALT" default:"0"`
+}
+
+type BatchConfig struct {
+	// DisperseContract is the Disperse contract used to pay many recipients in one transaction.
+	DisperseContract string `envconfig:"BATCH_DISPERSE_CONTRACT" default:"0xD152f549545093347A162Dce210e7293f1452150"`
+	// MaxRecipients caps the number of recipients accepted in one batch.
+	MaxRecipients int `envconfig:"BATCH_MAX_RECIPIENTS" default:"100"`
+}
+
+type ENSConfig struct {
+	// Registry is the ENS registry, deployed at the same address on mainnet and the public testnets.
+	Registry string `envconfig:"ENS_REGISTRY" default:"0x00000000000C2E074eC69A0dFb2997BA6C7d2e1e"`
+}
+
+type ScannerConfig struct {
+	// Enabled turns deposit scanning on; confirmations are tracked regardless.
+	Enabled bool `envconfig:"SCANNER_ENABLED" default:"true"`
+	// PollInterval is how often the chain head is polled when the RPC only supports HTTP.
+	PollInterval time.Duration `envconfig:"SCANNER_POLL_INTERVAL" default:"15s"`
+	// BatchSize caps the number of blocks scanned per chain on each poll.
+	BatchSize int `envconfig:"SCANNER_BATCH_SIZE" default:"50"`
+}
+
+type BalanceConfig struct {
+	// ReservationTTL is how long funds stay reserved for a transaction that is never mined.
+	ReservationTTL time.Duration `envconfig:"BALANCE_RESERVATION_TTL" default:"24h"`
+}
+
+type OutboxConfig struct {
+	// PollInterval is how often the relay looks for messages to publish.
+	PollInterval time.Duration `envconfig:"OUTBOX_POLL_INTERVAL" default:"1s"`
+	// BatchSize caps the number of messages published on each poll.
+	BatchSize int `envconfig:"OUTBOX_BATCH_SIZE" default:"100"`
+	// Lease is how long claimed messages stay with the relay that claimed them. Messages it has not
+	// marked by then are claimed again, so it must exceed the time a batch takes to publish.
+	Lease time.Duration `envconfig:"OUTBOX_LEASE" default:"1m"`
+	// RetryBackoff is the wait after the first failed publish; it doubles with every further
+	// failure up to MaxRetryBackoff.
+	RetryBackoff    time.Duration `envconfig:"OUTBOX_RETRY_BACKOFF" default:"1s"`
+	MaxRetryBackoff time.Duration `envconfig:"OUTBOX_MAX_RETRY_BACKOFF" default:"5m"`
 }
 
 type KafkaConfig struct {
-	Brokers []string `envconfig:"KAFKA_BROKERS" split_words:"true"`
-	Topic   string   `envconfig:"KAFKA_TOPIC"`
+	Brokers     []string `envconfig:"KAFKA_BROKERS" split_words:"true"`
+	Topic       string   `envconfig:"KAFKA_TOPIC"`
+	UserOpTopic string   `envconfig:"KAFKA_USER_OP_TOPIC" default:"user_op_topic"`
+	// EventsTopic is the public topic every transaction state change is announced on, keyed by wallet.
+	EventsTopic string `envconfig:"KAFKA_EVENTS_TOPIC" default:"wallet_events"`
+	// GroupID is the consumer group of the worker; its replicas share the partitions of each topic.
+	GroupID string `envconfig:"KAFKA_GROUP_ID" default:"mpc-worker"`
+	// TopicPartitions is the number of partitions topics are created with. Messages of one key stay
+	// in order on their partition, so it bounds the consumers of a group that share the work.
+	TopicPartitions int `envconfig:"KAFKA_TOPIC_PARTITIONS" default:"6"`
+	// MaxRetries is the number of retry topics a message that failed to process goes through
+	// before it lands in the dead-letter topic.
+	MaxRetries int `envconfig:"KAFKA_MAX_RETRIES" default:"3"`
+	// RetryBackoff is the wait before the first retry; it doubles with every further retry.
+	RetryBackoff time.Duration `envconfig:"KAFKA_RETRY_BACKOFF" default:"30s"`
 }
 
 type MailConfig struct {
-	SMTPHost      string `envconfig:"SMTP_HOST"`
-	SMTPPort      int    `envconfig:"SMTP_PORT" default:"587"`
-	SMTPUsername  string `envconfig:"SMTP_USERNAME"`
-	SMTPPassword  string `envconfig:"SMTP_PASSWORD"`
-	FromEmail     string `envconfig:"FROM_EMAIL"`
-	OTPExpiration string `envconfig:"OTP_EXPIRATION"`
+	SMTPHost     string `envconfig:"SMTP_HOST"`
+	SMTPPort     int    `envconfig:"SMTP_PORT" default:"587"`
+	SMTPUsername string `envconfig:"SMTP_USERNAME"`
+	SMTPPassword string `envconfig:"SMTP_PASSWORD"`
+	FromEmail    string `envconfig:"FROM_EMAIL"`
+	// OTPExpiration is how long a mailed OTP stays valid, e.g. "5m".
+	OTPExpiration time.Duration `envconfig:"OTP_EXPIRATION" default:"5m"`
+	// NumWorkers is the number of OTP mail consumers the mail service runs.
+	NumWorkers int `envconfig:"MAIL_NUM_WORKERS" default:"1"`
+	// GroupID is the consumer group of the mail service.
+	GroupID string `envconfig:"MAIL_GROUP_ID" default:"mpc-mail"`
 }
 
 func Load(log *logrus.Logger) (*Config, error) {
@@ -0,0 +1,70 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Platform-owned hot wallets used to sponsor gas for user wallets
+CREATE TABLE relayer_wallets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    chain_id UUID NOT NULL,
+    address VARCHAR(42) NOT NULL,
+    encrypted_private_key BYTEA NOT NULL,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_chain_relayer_wallet
+        FOREIGN KEY (chain_id)
+        REFERENCES chains (id)
+        ON DELETE CASCADE,
+    CONSTRAINT uq_relayer_wallet_chain_address UNIQUE (chain_id, address)
+);
+
+-- Per-user gas sponsorship budgets
+CREATE TABLE sponsorship_budgets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    chain_id UUID NOT NULL,
+    budget_wei NUMERIC(78, 0) NOT NULL,
+    spent_wei NUMERIC(78, 0) NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user_sponsorship_budget
+        FOREIGN KEY (user_id)
+        REFERENCES users (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_chain_sponsorship_budget
+        FOREIGN KEY (chain_id)
+        REFERENCES chains (id)
+        ON DELETE CASCADE,
+    CONSTRAINT uq_sponsorship_budget_user_chain UNIQUE (user_id, chain_id)
+);
+
+-- Accounting of every top-up sent by a relayer wallet
+CREATE TABLE gas_sponsorships (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    chain_id UUID NOT NULL,
+    relayer_wallet_id UUID NOT NULL,
+    transaction_id UUID,
+    funding_tx_hash VARCHAR(66) NOT NULL,
+    amount_wei NUMERIC(78, 0) NOT NULL,
+    fee_wei NUMERIC(78, 0) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user_gas_sponsorship
+        FOREIGN KEY (user_id)
+        REFERENCES users (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_relayer_wallet_gas_sponsorship
+        FOREIGN KEY (relayer_wallet_id)
+        REFERENCES relayer_wallets (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_transaction_gas_sponsorship
+        FOREIGN KEY (transaction_id)
+        REFERENCES transactions (id)
+        ON DELETE SET NULL
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS gas_sponsorships;
+DROP TABLE IF EXISTS sponsorship_budgets;
+DROP TABLE IF EXISTS relayer_wallets;
+-- +goose StatementEnd
@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+-- ERC-4337 user operations sent through the smart account of a wallet
+CREATE TABLE user_operations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL,
+    chain_id UUID NOT NULL,
+    sender VARCHAR(42) NOT NULL,
+    nonce VARCHAR(78) NOT NULL,
+    to_address VARCHAR(42) NOT NULL,
+    amount VARCHAR(255) NOT NULL,
+    user_op_hash VARCHAR(66) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    tx_hash VARCHAR(66),
+    actual_gas_cost VARCHAR(78),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_wallet_user_operation
+        FOREIGN KEY (wallet_id)
+        REFERENCES wallets (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_chain_user_operation
+        FOREIGN KEY (chain_id)
+        REFERENCES chains (id)
+        ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_user_operations_user_op_hash ON user_operations (user_op_hash);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS user_operations;
+-- +goose StatementEnd
@@ -0,0 +1,48 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Safe transactions proposed by a wallet that is one of the Safe owners
+CREATE TABLE safe_proposals (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL,
+    chain_id UUID NOT NULL,
+    safe_address VARCHAR(42) NOT NULL,
+    to_address VARCHAR(42) NOT NULL,
+    value VARCHAR(78) NOT NULL,
+    data BYTEA NOT NULL,
+    nonce VARCHAR(78) NOT NULL,
+    safe_tx_hash VARCHAR(66) NOT NULL,
+    threshold INT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    exec_tx_hash VARCHAR(66),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_wallet_safe_proposal
+        FOREIGN KEY (wallet_id)
+        REFERENCES wallets (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_chain_safe_proposal
+        FOREIGN KEY (chain_id)
+        REFERENCES chains (id)
+        ON DELETE CASCADE
+);
+
+-- Owner signatures collected for a proposal
+CREATE TABLE safe_signatures (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    proposal_id UUID NOT NULL,
+    owner VARCHAR(42) NOT NULL,
+    signature BYTEA NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_proposal_safe_signature
+        FOREIGN KEY (proposal_id)
+        REFERENCES safe_proposals (id)
+        ON DELETE CASCADE,
+    CONSTRAINT uq_safe_signature_proposal_owner UNIQUE (proposal_id, owner)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS safe_signatures;
+DROP TABLE IF EXISTS safe_proposals;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Inbound transactions are deposits found by the block scanner
+ALTER TABLE transactions ADD COLUMN direction VARCHAR(10) NOT NULL DEFAULT 'outbound';
+ALTER TABLE transactions ADD COLUMN from_address VARCHAR(42);
+-- Index of the ERC-20 Transfer log, or -1 for a native transfer
+ALTER TABLE transactions ADD COLUMN log_index INT;
+
+-- Rescanning a block must never record the same deposit twice
+CREATE UNIQUE INDEX idx_transactions_inbound ON transactions (chain_id, tx_hash, log_index, to_address)
+    WHERE direction = 'inbound';
+
+-- Last block processed by the block scanner for each chain
+CREATE TABLE chain_cursors (
+    chain_id UUID PRIMARY KEY,
+    block_number BIGINT NOT NULL,
+    block_hash VARCHAR(66) NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_chain_cursor
+        FOREIGN KEY (chain_id)
+        REFERENCES chains (id)
+        ON DELETE CASCADE
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS chain_cursors;
+DROP INDEX IF EXISTS idx_transactions_inbound;
+ALTER TABLE transactions DROP COLUMN log_index;
+ALTER TABLE transactions DROP COLUMN from_address;
+ALTER TABLE transactions DROP COLUMN direction;
+-- +goose StatementEnd
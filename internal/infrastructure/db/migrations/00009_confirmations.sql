@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Block that included the transaction, re-checked until it is deep enough to be final
+ALTER TABLE transactions ADD COLUMN block_number BIGINT;
+ALTER TABLE transactions ADD COLUMN block_hash VARCHAR(66);
+CREATE INDEX idx_transactions_submitted ON transactions (chain_id) WHERE status = 'submitted';
+
+-- Number of blocks, including the one with the transaction, before it is considered final
+ALTER TABLE chains ADD COLUMN required_confirmations INT NOT NULL DEFAULT 12;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chains DROP COLUMN required_confirmations;
+DROP INDEX IF EXISTS idx_transactions_submitted;
+ALTER TABLE transactions DROP COLUMN block_hash;
+ALTER TABLE transactions DROP COLUMN block_number;
+-- +goose StatementEnd
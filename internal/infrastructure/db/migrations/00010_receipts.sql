@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Cost and outcome of the transaction, taken from its receipt
+ALTER TABLE transactions ADD COLUMN gas_used BIGINT;
+ALTER TABLE transactions ADD COLUMN effective_gas_price VARCHAR(78);
+-- Total fee paid in wei (gas_used * effective_gas_price)
+ALTER TABLE transactions ADD COLUMN fee VARCHAR(78);
+ALTER TABLE transactions ADD COLUMN revert_reason TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions DROP COLUMN revert_reason;
+ALTER TABLE transactions DROP COLUMN fee;
+ALTER TABLE transactions DROP COLUMN effective_gas_price;
+ALTER TABLE transactions DROP COLUMN gas_used;
+-- +goose StatementEnd
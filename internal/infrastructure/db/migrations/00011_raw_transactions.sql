@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Signed transaction as submitted, rebroadcast when the node drops it from its mempool
+ALTER TABLE transactions ADD COLUMN raw_tx TEXT;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE transactions DROP COLUMN raw_tx;
+-- +goose StatementEnd
@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- One row per wallet, chain and token; the native currency has no token
+ALTER TABLE balances ADD CONSTRAINT uq_balances_wallet_chain_token
+    UNIQUE NULLS NOT DISTINCT (wallet_id, chain_id, token_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE balances DROP CONSTRAINT uq_balances_wallet_chain_token;
+-- +goose StatementEnd
@@ -0,0 +1,33 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Native currency held back for transactions that are created but not yet mined.
+-- A reservation stops counting once it is released or expires.
+CREATE TABLE balance_reservations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL,
+    chain_id UUID NOT NULL,
+    -- Not a foreign key: batch transfers reserve their funds before their rows are written
+    transaction_id UUID NOT NULL UNIQUE,
+    -- Amount plus the maximum fee, in wei
+    amount NUMERIC(78, 0) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    released_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT fk_reservation_wallet
+        FOREIGN KEY (wallet_id)
+        REFERENCES wallets (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_reservation_chain
+        FOREIGN KEY (chain_id)
+        REFERENCES chains (id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_balance_reservations_active ON balance_reservations (wallet_id, chain_id)
+    WHERE released_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS balance_reservations;
+-- +goose StatementEnd
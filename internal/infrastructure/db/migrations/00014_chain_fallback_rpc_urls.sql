@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Extra RPC endpoints of the chain, used when rpc_url is unhealthy
+ALTER TABLE chains ADD COLUMN fallback_rpc_urls TEXT[] NOT NULL DEFAULT '{}';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chains DROP COLUMN fallback_rpc_urls;
+-- +goose StatementEnd
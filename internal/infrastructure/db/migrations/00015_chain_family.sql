@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The family decides how addresses, transactions and signatures work on the chain.
+-- For Bitcoin chains, chain_id holds the network name (mainnet, testnet3, signet or regtest).
+ALTER TABLE chains ADD COLUMN family VARCHAR(20) NOT NULL DEFAULT 'evm';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE chains DROP COLUMN family;
+-- +goose StatementEnd
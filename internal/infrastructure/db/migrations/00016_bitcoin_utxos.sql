@@ -0,0 +1,71 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Bitcoin addresses handed out for a wallet; the block scanner tracks outputs paying to them.
+-- Receive addresses use the wallet key itself, change addresses a key derived at derivation_index.
+CREATE TABLE bitcoin_addresses (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL,
+    chain_id UUID NOT NULL,
+    address VARCHAR(90) NOT NULL,
+    change BOOLEAN NOT NULL DEFAULT FALSE,
+    derivation_index INT NOT NULL DEFAULT 0,
+    -- Set once an output pays to the address, so change is never sent to the same address twice
+    used BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_bitcoin_addresses_chain_address UNIQUE (chain_id, address),
+    CONSTRAINT fk_bitcoin_address_wallet
+        FOREIGN KEY (wallet_id)
+        REFERENCES wallets (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_bitcoin_address_chain
+        FOREIGN KEY (chain_id)
+        REFERENCES chains (id)
+        ON DELETE CASCADE
+);
+
+CREATE INDEX idx_bitcoin_addresses_wallet ON bitcoin_addresses (wallet_id, chain_id);
+
+-- Outputs paying to wallet addresses. An output is unconfirmed while block_number is NULL,
+-- reserved while an outbound transaction that spends it is pending and spent once that
+-- spend is in a block.
+CREATE TABLE utxos (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    wallet_id UUID NOT NULL,
+    chain_id UUID NOT NULL,
+    tx_hash VARCHAR(64) NOT NULL,
+    vout INT NOT NULL,
+    address VARCHAR(90) NOT NULL,
+    -- In satoshis
+    amount BIGINT NOT NULL,
+    pk_script BYTEA NOT NULL,
+    block_number BIGINT,
+    block_hash VARCHAR(64),
+    reserved_by UUID,
+    spent_tx_hash VARCHAR(64),
+    spent_block_number BIGINT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_utxos_outpoint UNIQUE (chain_id, tx_hash, vout),
+    CONSTRAINT fk_utxo_wallet
+        FOREIGN KEY (wallet_id)
+        REFERENCES wallets (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_utxo_chain
+        FOREIGN KEY (chain_id)
+        REFERENCES chains (id)
+        ON DELETE CASCADE,
+    CONSTRAINT fk_utxo_reserved_by
+        FOREIGN KEY (reserved_by)
+        REFERENCES transactions (id)
+        ON DELETE SET NULL
+);
+
+CREATE INDEX idx_utxos_unspent ON utxos (wallet_id, chain_id)
+    WHERE spent_tx_hash IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS utxos;
+DROP TABLE IF EXISTS bitcoin_addresses;
+-- +goose StatementEnd
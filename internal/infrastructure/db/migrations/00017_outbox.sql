@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Kafka messages written in the same database transaction as the state change they announce.
+-- The outbox relay publishes them in order and marks them sent, retrying failed publishes after
+-- next_attempt_at.
+CREATE TABLE outbox_messages (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    topic VARCHAR(255) NOT NULL,
+    key BYTEA,
+    payload BYTEA NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_outbox_messages_pending ON outbox_messages (next_attempt_at, created_at)
+    WHERE sent_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS outbox_messages;
+-- +goose StatementEnd
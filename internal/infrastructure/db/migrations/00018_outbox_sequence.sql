@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Messages written in the same database transaction share their created_at; the sequence keeps
+-- them in the order they were written, which the events of a wallet must be published in.
+ALTER TABLE outbox_messages ADD COLUMN seq BIGSERIAL;
+
+DROP INDEX IF EXISTS idx_outbox_messages_pending;
+CREATE INDEX idx_outbox_messages_pending ON outbox_messages (next_attempt_at, seq)
+    WHERE sent_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_outbox_messages_pending;
+CREATE INDEX idx_outbox_messages_pending ON outbox_messages (next_attempt_at, created_at)
+    WHERE sent_at IS NULL;
+
+ALTER TABLE outbox_messages DROP COLUMN seq;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The relay only publishes the oldest unsent message of each key, so it looks up older unsent
+-- messages with the same topic and key.
+CREATE INDEX idx_outbox_messages_pending_key ON outbox_messages (topic, key, seq)
+    WHERE sent_at IS NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_outbox_messages_pending_key;
+-- +goose StatementEnd
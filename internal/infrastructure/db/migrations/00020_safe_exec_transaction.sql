@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The execTransaction of an executed proposal is stored as a transaction, so the receipt worker and
+-- the block scanner track it like any other transaction of the wallet.
+ALTER TABLE safe_proposals
+    ADD COLUMN exec_transaction_id UUID,
+    ADD CONSTRAINT fk_transaction_safe_proposal
+        FOREIGN KEY (exec_transaction_id)
+        REFERENCES transactions (id)
+        ON DELETE SET NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE safe_proposals
+    DROP CONSTRAINT IF EXISTS fk_transaction_safe_proposal,
+    DROP COLUMN IF EXISTS exec_transaction_id;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The relay claims messages by leasing them until locked_until and publishes them after the claim is
+-- committed. Messages a relay has not marked sent or failed by then, because it stopped, are claimed
+-- again once the lease runs out.
+ALTER TABLE outbox_messages ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE outbox_messages DROP COLUMN locked_until;
+-- +goose StatementEnd
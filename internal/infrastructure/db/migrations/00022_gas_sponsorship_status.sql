@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A top-up is recorded as pending as soon as its funding transaction is broadcast, and the
+-- sponsored transaction is held back until the worker sees the funding mined. Its fee is only
+-- known from the receipt. Earlier top-ups were recorded after they were mined.
+ALTER TABLE gas_sponsorships ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'confirmed';
+CREATE INDEX idx_gas_sponsorships_pending ON gas_sponsorships (chain_id) WHERE status = 'pending';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_gas_sponsorships_pending;
+ALTER TABLE gas_sponsorships DROP COLUMN status;
+-- +goose StatementEnd
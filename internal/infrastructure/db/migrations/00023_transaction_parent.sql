@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A Disperse batch is one on-chain transaction paying several recipients. It is stored once, as the
+-- parent carrying the hash, nonce, fee and balance reservation, and every recipient gets a row
+-- linked to it. Recipient rows have no hash of their own, so the worker only follows the parent,
+-- and their status follows the parent's.
+ALTER TABLE transactions ADD COLUMN parent_id UUID REFERENCES transactions (id) ON DELETE CASCADE;
+CREATE INDEX idx_transactions_parent_id ON transactions (parent_id) WHERE parent_id IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_transactions_parent_id;
+ALTER TABLE transactions DROP COLUMN IF EXISTS parent_id;
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- name: UpsertBalance :exec
+INSERT INTO balances (wallet_id, chain_id, token_id, balance)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (wallet_id, chain_id, token_id) DO UPDATE
+SET balance = EXCLUDED.balance, updated_at = CURRENT_TIMESTAMP;
+
+-- name: GetBalancesByWalletID :many
+SELECT * FROM balances
+WHERE wallet_id = $1
+ORDER BY chain_id, token_id NULLS FIRST;
+
+-- name: LockWallet :exec
+SELECT id FROM wallets
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetReservedAmount :one
+SELECT COALESCE(SUM(amount), 0)::numeric AS reserved FROM balance_reservations
+WHERE wallet_id = $1 AND chain_id = $2 AND released_at IS NULL AND expires_at > CURRENT_TIMESTAMP;
+
+-- name: CreateBalanceReservation :exec
+INSERT INTO balance_reservations (wallet_id, chain_id, transaction_id, amount, expires_at)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: ReleaseBalanceReservation :exec
+UPDATE balance_reservations
+SET released_at = CURRENT_TIMESTAMP
+WHERE transaction_id = $1 AND released_at IS NULL;
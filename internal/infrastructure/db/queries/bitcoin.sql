@@ -0,0 +1,71 @@
+-- name: CreateBitcoinAddress :exec
+INSERT INTO bitcoin_addresses (wallet_id, chain_id, address, change, derivation_index)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (chain_id, address) DO NOTHING;
+
+-- name: GetUnusedChangeAddress :one
+SELECT * FROM bitcoin_addresses
+WHERE wallet_id = $1 AND chain_id = $2 AND change AND NOT used
+ORDER BY derivation_index
+LIMIT 1;
+
+-- name: GetNextChangeIndex :one
+SELECT COALESCE(MAX(derivation_index) + 1, 0)::int AS next_index FROM bitcoin_addresses
+WHERE wallet_id = $1 AND chain_id = $2 AND change;
+
+-- name: ListBitcoinAddressesByChainID :many
+SELECT * FROM bitcoin_addresses
+WHERE chain_id = $1
+ORDER BY created_at;
+
+-- name: ListBitcoinAddressesByWalletID :many
+SELECT * FROM bitcoin_addresses
+WHERE wallet_id = $1 AND chain_id = $2
+ORDER BY change, derivation_index;
+
+-- name: MarkBitcoinAddressUsed :exec
+UPDATE bitcoin_addresses
+SET used = TRUE
+WHERE chain_id = $1 AND address = $2;
+
+-- name: UpsertUTXO :exec
+INSERT INTO utxos (wallet_id, chain_id, tx_hash, vout, address, amount, pk_script, block_number, block_hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT (chain_id, tx_hash, vout) DO UPDATE
+SET block_number = COALESCE(EXCLUDED.block_number, utxos.block_number),
+    block_hash = COALESCE(EXCLUDED.block_hash, utxos.block_hash),
+    updated_at = CURRENT_TIMESTAMP;
+
+-- name: ListUnspentUTXOsByWalletID :many
+SELECT * FROM utxos
+WHERE wallet_id = $1 AND chain_id = $2 AND spent_tx_hash IS NULL
+ORDER BY amount DESC;
+
+-- name: ListUnspentUTXOsByChainID :many
+SELECT * FROM utxos
+WHERE chain_id = $1 AND spent_tx_hash IS NULL;
+
+-- name: ReserveUTXO :execrows
+UPDATE utxos
+SET reserved_by = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $1 AND reserved_by IS NULL AND spent_tx_hash IS NULL;
+
+-- name: ReleaseUTXOs :exec
+UPDATE utxos
+SET reserved_by = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE reserved_by = $1 AND spent_tx_hash IS NULL;
+
+-- name: SpendUTXO :exec
+UPDATE utxos
+SET spent_tx_hash = $4, spent_block_number = $5, updated_at = CURRENT_TIMESTAMP
+WHERE chain_id = $1 AND tx_hash = $2 AND vout = $3;
+
+-- name: UnconfirmUTXOs :exec
+UPDATE utxos
+SET block_number = NULL, block_hash = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE chain_id = $1 AND block_number > $2;
+
+-- name: UnspendUTXOs :exec
+UPDATE utxos
+SET spent_tx_hash = NULL, spent_block_number = NULL, updated_at = CURRENT_TIMESTAMP
+WHERE chain_id = $1 AND spent_block_number > $2;
@@ -0,0 +1,23 @@
+-- name: GetChain :one
+SELECT * FROM chains
+WHERE id = $1 LIMIT 1;
+
+-- name: GetChainCursor :one
+SELECT * FROM chain_cursors
+WHERE chain_id = $1 LIMIT 1;
+
+-- name: ListChains :many
+SELECT * FROM chains
+ORDER BY name;
+
+-- name: ListTokensByChainID :many
+SELECT * FROM tokens
+WHERE chain_id = $1;
+
+-- name: UpsertChainCursor :exec
+INSERT INTO chain_cursors (chain_id, block_number, block_hash)
+VALUES ($1, $2, $3)
+ON CONFLICT (chain_id) DO UPDATE
+SET block_number = EXCLUDED.block_number,
+    block_hash = EXCLUDED.block_hash,
+    updated_at = CURRENT_TIMESTAMP;
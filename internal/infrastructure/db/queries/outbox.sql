@@ -0,0 +1,30 @@
+-- name: CreateOutboxMessage :exec
+INSERT INTO outbox_messages (id, topic, key, payload)
+VALUES ($1, $2, $3, $4);
+
+-- name: ClaimOutboxMessages :many
+UPDATE outbox_messages
+SET locked_until = $1
+WHERE id IN (
+  SELECT m.id FROM outbox_messages m
+  WHERE m.sent_at IS NULL AND m.next_attempt_at <= CURRENT_TIMESTAMP
+    AND (m.locked_until IS NULL OR m.locked_until <= CURRENT_TIMESTAMP)
+    AND NOT EXISTS (
+      SELECT 1 FROM outbox_messages o
+      WHERE o.sent_at IS NULL AND o.topic = m.topic AND o.key = m.key AND o.seq < m.seq
+    )
+  ORDER BY m.seq
+  LIMIT $2
+  FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkOutboxMessageSent :exec
+UPDATE outbox_messages
+SET sent_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = NULL, locked_until = NULL
+WHERE id = $1;
+
+-- name: MarkOutboxMessageFailed :exec
+UPDATE outbox_messages
+SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3, locked_until = NULL
+WHERE id = $1;
@@ -0,0 +1,48 @@
+-- name: CreateRelayerWallet :one
+INSERT INTO relayer_wallets (chain_id, address, encrypted_private_key)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: ListActiveRelayerWallets :many
+SELECT * FROM relayer_wallets
+WHERE chain_id = $1 AND active = TRUE
+ORDER BY created_at;
+
+-- name: EnsureSponsorshipBudget :one
+INSERT INTO sponsorship_budgets (user_id, chain_id, budget_wei)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, chain_id) DO UPDATE SET updated_at = sponsorship_budgets.updated_at
+RETURNING *;
+
+-- name: ReserveSponsorship :one
+UPDATE sponsorship_budgets
+SET spent_wei = spent_wei + sqlc.arg(amount), updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg(user_id) AND chain_id = sqlc.arg(chain_id)
+  AND spent_wei + sqlc.arg(amount) <= budget_wei
+RETURNING *;
+
+-- name: ReleaseSponsorship :one
+UPDATE sponsorship_budgets
+SET spent_wei = GREATEST(spent_wei - sqlc.arg(amount), 0), updated_at = CURRENT_TIMESTAMP
+WHERE user_id = sqlc.arg(user_id) AND chain_id = sqlc.arg(chain_id)
+RETURNING *;
+
+-- name: CreateGasSponsorship :one
+INSERT INTO gas_sponsorships (user_id, chain_id, relayer_wallet_id, transaction_id, funding_tx_hash, amount_wei, fee_wei, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: ListGasSponsorshipsByUserID :many
+SELECT * FROM gas_sponsorships
+WHERE user_id = $1
+ORDER BY created_at DESC;
+
+-- name: ListPendingGasSponsorshipsByChainID :many
+SELECT * FROM gas_sponsorships
+WHERE chain_id = $1 AND status = 'pending'
+ORDER BY created_at;
+
+-- name: UpdateGasSponsorshipStatus :exec
+UPDATE gas_sponsorships
+SET status = $2, fee_wei = $3
+WHERE id = $1;
@@ -0,0 +1,25 @@
+-- name: CreateSafeProposal :one
+INSERT INTO safe_proposals (id, wallet_id, chain_id, safe_address, to_address, value, data, nonce, safe_tx_hash, threshold, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetSafeProposal :one
+SELECT * FROM safe_proposals
+WHERE id = $1 LIMIT 1;
+
+-- name: UpdateSafeProposal :one
+UPDATE safe_proposals
+SET (status, exec_tx_hash, exec_transaction_id, updated_at) = ($2, $3, $4, CURRENT_TIMESTAMP)
+WHERE id = $1
+RETURNING *;
+
+-- name: UpsertSafeSignature :one
+INSERT INTO safe_signatures (proposal_id, owner, signature)
+VALUES ($1, $2, $3)
+ON CONFLICT (proposal_id, owner) DO UPDATE SET signature = EXCLUDED.signature
+RETURNING *;
+
+-- name: ListSafeSignatures :many
+SELECT * FROM safe_signatures
+WHERE proposal_id = $1
+ORDER BY owner;
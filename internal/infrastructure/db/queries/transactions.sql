@@ -1,6 +1,6 @@
 -- name: CreateTransaction :one
-INSERT INTO transactions (id, wallet_id , chain_id, to_address, amount, token_id, gas_price, gas_limit, nonce, status)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+INSERT INTO transactions (id, wallet_id , chain_id, to_address, amount, token_id, gas_price, gas_limit, nonce, status, to_ens_name, parent_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 RETURNING *;
 
 -- name: GetTransaction :one
@@ -13,6 +13,25 @@ WHERE wallet_id = $1;
 
 -- name: UpdateTransaction :one
 UPDATE transactions 
-SET (status, tx_hash, gas_price, gas_limit, nonce) = ($2, $3, $4, $5, $6)
+SET (status, tx_hash, gas_price, gas_limit, nonce, block_number, block_hash, gas_used, effective_gas_price, fee, revert_reason, raw_tx) = ($2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 WHERE id = $1
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: UpdateChildTransactionStatus :exec
+UPDATE transactions
+SET status = $2
+WHERE parent_id = $1;
+
+-- name: CreateInboundTransaction :execrows
+INSERT INTO transactions (id, wallet_id, chain_id, from_address, to_address, amount, token_id, status, tx_hash, log_index, block_number, block_hash, direction)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, 'inbound')
+ON CONFLICT (chain_id, tx_hash, log_index, to_address) WHERE direction = 'inbound' DO NOTHING;
+
+-- name: GetSubmittedTransactionsByChainID :many
+SELECT * FROM transactions
+WHERE chain_id = $1 AND status = 'submitted' AND tx_hash IS NOT NULL
+ORDER BY created_at;
+
+-- name: GetNextNonce :one
+SELECT COALESCE(MAX(nonce) + 1, 0)::bigint AS next_nonce FROM transactions
+WHERE wallet_id = $1 AND chain_id = $2 AND direction = 'outbound' AND status = 'submitted' AND tx_hash IS NOT NULL;
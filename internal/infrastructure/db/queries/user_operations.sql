@@ -0,0 +1,14 @@
+-- name: CreateUserOperation :one
+INSERT INTO user_operations (id, wallet_id, chain_id, sender, nonce, to_address, amount, user_op_hash, status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetUserOperation :one
+SELECT * FROM user_operations
+WHERE id = $1 LIMIT 1;
+
+-- name: UpdateUserOperation :one
+UPDATE user_operations
+SET (status, tx_hash, actual_gas_cost, updated_at) = ($2, $3, $4, CURRENT_TIMESTAMP)
+WHERE id = $1
+RETURNING *;
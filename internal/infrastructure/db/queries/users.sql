@@ -1,6 +1,6 @@
 -- name: CreateUser :one
-INSERT INTO users (email, password_hash)
-VALUES ($1, $2)
+INSERT INTO users (id, email, password_hash)
+VALUES ($1, $2, $3)
 RETURNING *;
 
 -- name: GetUser :one
@@ -1,6 +1,6 @@
 -- name: CreateWallet :one
-INSERT INTO wallets (user_id, address, encrypted_private_key)
-VALUES ($1, $2, $3)
+INSERT INTO wallets (id, user_id, address, encrypted_private_key)
+VALUES ($1, $2, $3, $4)
 RETURNING *;
 
 -- name: GetWallet :one
@@ -14,3 +14,7 @@ WHERE address = $1 LIMIT 1;
 -- name: GetWalletByUserID :one
 SELECT * FROM wallets
 WHERE user_id = $1 LIMIT 1;
+
+
+-- name: ListWallets :many
+SELECT * FROM wallets;
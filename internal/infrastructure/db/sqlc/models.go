@@ -17,15 +17,120 @@ type Balance struct {
 	UpdatedAt pgtype.Timestamptz
 }
 
+type BalanceReservation struct {
+	ID            pgtype.UUID
+	WalletID      pgtype.UUID
+	ChainID       pgtype.UUID
+	TransactionID pgtype.UUID
+	Amount        pgtype.Numeric
+	ExpiresAt     pgtype.Timestamptz
+	ReleasedAt    pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+}
+
+type BitcoinAddress struct {
+	ID              pgtype.UUID
+	WalletID        pgtype.UUID
+	ChainID         pgtype.UUID
+	Address         string
+	Change          bool
+	DerivationIndex int32
+	Used            bool
+	CreatedAt       pgtype.Timestamptz
+}
+
 type Chain struct {
-	ID             pgtype.UUID
-	Name           string
-	ChainID        string
-	RpcUrl         string
-	NativeCurrency string
-	CreatedAt      pgtype.Timestamptz
-	UpdatedAt      pgtype.Timestamptz
-	ExplorerUrl    pgtype.Text
+	ID                    pgtype.UUID
+	Name                  string
+	ChainID               string
+	RpcUrl                string
+	NativeCurrency        string
+	CreatedAt             pgtype.Timestamptz
+	UpdatedAt             pgtype.Timestamptz
+	ExplorerUrl           pgtype.Text
+	RequiredConfirmations int32
+	FallbackRpcUrls       []string
+	Family                string
+}
+
+type ChainCursor struct {
+	ChainID     pgtype.UUID
+	BlockNumber int64
+	BlockHash   string
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type GasSponsorship struct {
+	ID              pgtype.UUID
+	UserID          pgtype.UUID
+	ChainID         pgtype.UUID
+	RelayerWalletID pgtype.UUID
+	TransactionID   pgtype.UUID
+	FundingTxHash   string
+	AmountWei       pgtype.Numeric
+	FeeWei          pgtype.Numeric
+	CreatedAt       pgtype.Timestamptz
+	Status          string
+}
+
+type OutboxMessage struct {
+	ID            pgtype.UUID
+	Topic         string
+	Key           []byte
+	Payload       []byte
+	Attempts      int32
+	LastError     pgtype.Text
+	NextAttemptAt pgtype.Timestamptz
+	SentAt        pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+	Seq           int64
+	LockedUntil   pgtype.Timestamptz
+}
+
+type RelayerWallet struct {
+	ID                  pgtype.UUID
+	ChainID             pgtype.UUID
+	Address             string
+	EncryptedPrivateKey []byte
+	Active              bool
+	CreatedAt           pgtype.Timestamptz
+	UpdatedAt           pgtype.Timestamptz
+}
+
+type SafeProposal struct {
+	ID                pgtype.UUID
+	WalletID          pgtype.UUID
+	ChainID           pgtype.UUID
+	SafeAddress       string
+	ToAddress         string
+	Value             string
+	Data              []byte
+	Nonce             string
+	SafeTxHash        string
+	Threshold         int32
+	Status            string
+	ExecTxHash        pgtype.Text
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+	ExecTransactionID pgtype.UUID
+}
+
+type SafeSignature struct {
+	ID         pgtype.UUID
+	ProposalID pgtype.UUID
+	Owner      string
+	Signature  []byte
+	CreatedAt  pgtype.Timestamptz
+}
+
+type SponsorshipBudget struct {
+	ID        pgtype.UUID
+	UserID    pgtype.UUID
+	ChainID   pgtype.UUID
+	BudgetWei pgtype.Numeric
+	SpentWei  pgtype.Numeric
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
 }
 
 type Token struct {
@@ -40,19 +145,31 @@ type Token struct {
 }
 
 type Transaction struct {
-	ID        pgtype.UUID
-	WalletID  pgtype.UUID
-	ChainID   pgtype.UUID
-	ToAddress string
-	Amount    string
-	TokenID   pgtype.UUID
-	GasPrice  pgtype.Text
-	GasLimit  pgtype.Text
-	Nonce     pgtype.Int8
-	Status    string
-	TxHash    pgtype.Text
-	CreatedAt pgtype.Timestamptz
-	UpdatedAt pgtype.Timestamptz
+	ID                pgtype.UUID
+	WalletID          pgtype.UUID
+	ChainID           pgtype.UUID
+	ToAddress         string
+	Amount            string
+	TokenID           pgtype.UUID
+	GasPrice          pgtype.Text
+	GasLimit          pgtype.Text
+	Nonce             pgtype.Int8
+	Status            string
+	TxHash            pgtype.Text
+	CreatedAt         pgtype.Timestamptz
+	UpdatedAt         pgtype.Timestamptz
+	ToEnsName         pgtype.Text
+	Direction         string
+	FromAddress       pgtype.Text
+	LogIndex          pgtype.Int4
+	BlockNumber       pgtype.Int8
+	BlockHash         pgtype.Text
+	GasUsed           pgtype.Int8
+	EffectiveGasPrice pgtype.Text
+	Fee               pgtype.Text
+	RevertReason      pgtype.Text
+	RawTx             pgtype.Text
+	ParentID          pgtype.UUID
 }
 
 type User struct {
@@ -63,6 +180,40 @@ type User struct {
 	UpdatedAt    pgtype.Timestamptz
 }
 
+type UserOperation struct {
+	ID            pgtype.UUID
+	WalletID      pgtype.UUID
+	ChainID       pgtype.UUID
+	Sender        string
+	Nonce         string
+	ToAddress     string
+	Amount        string
+	UserOpHash    string
+	Status        string
+	TxHash        pgtype.Text
+	ActualGasCost pgtype.Text
+	CreatedAt     pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
+type Utxo struct {
+	ID               pgtype.UUID
+	WalletID         pgtype.UUID
+	ChainID          pgtype.UUID
+	TxHash           string
+	Vout             int32
+	Address          string
+	Amount           int64
+	PkScript         []byte
+	BlockNumber      pgtype.Int8
+	BlockHash        pgtype.Text
+	ReservedBy       pgtype.UUID
+	SpentTxHash      pgtype.Text
+	SpentBlockNumber pgtype.Int8
+	CreatedAt        pgtype.Timestamptz
+	UpdatedAt        pgtype.Timestamptz
+}
+
 type Wallet struct {
 	ID                  pgtype.UUID
 	UserID              pgtype.UUID
@@ -11,10 +11,52 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createInboundTransaction = `-- name: CreateInboundTransaction :execrows
+INSERT INTO transactions (id, wallet_id, chain_id, from_address, to_address, amount, token_id, status, tx_hash, log_index, block_number, block_hash, direction)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, 'inbound')
+ON CONFLICT (chain_id, tx_hash, log_index, to_address) WHERE direction = 'inbound' DO NOTHING
+`
+
+type CreateInboundTransactionParams struct {
+	ID          pgtype.UUID
+	WalletID    pgtype.UUID
+	ChainID     pgtype.UUID
+	FromAddress pgtype.Text
+	ToAddress   string
+	Amount      string
+	TokenID     pgtype.UUID
+	Status      string
+	TxHash      pgtype.Text
+	LogIndex    pgtype.Int4
+	BlockNumber pgtype.Int8
+	BlockHash   pgtype.Text
+}
+
+func (q *Queries) CreateInboundTransaction(ctx context.Context, arg CreateInboundTransactionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, createInboundTransaction,
+		arg.ID,
+		arg.WalletID,
+		arg.ChainID,
+		arg.FromAddress,
+		arg.ToAddress,
+		arg.Amount,
+		arg.TokenID,
+		arg.Status,
+		arg.TxHash,
+		arg.LogIndex,
+		arg.BlockNumber,
+		arg.BlockHash,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const createTransaction = `-- name: CreateTransaction :one
-INSERT INTO transactions (id, wallet_id , chain_id, to_address, amount, token_id, gas_price, gas_limit, nonce, status)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-RETURNING id, wallet_id, chain_id, to_address, amount, token_id, gas_price, gas_limit, nonce, status, tx_hash, created_at, updated_at
+INSERT INTO transactions (id, wallet_id , chain_id, to_address, amount, token_id, gas_price, gas_limit, nonce, status, to_ens_name, parent_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+RETURNING id, wallet_id, chain_id, to_address, amount, token_id, gas_price, gas_limit, nonce, status, tx_hash, created_at, updated_at, to_ens_name, direction, from_address, log_index, block_number, block_hash, gas_used, effective_gas_price, fee, revert_reason, raw_tx, parent_id
 `
 
 type CreateTransactionParams struct {
@@ -28,6 +70,8 @@ type CreateTransactionParams struct {
 	GasLimit  pgtype.Text
 	Nonce     pgtype.Int8
 	Status    string
+	ToEnsName pgtype.Text
+	ParentID  pgtype.UUID
 }
 
 func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionParams) (Transaction, error) {
@@ -42,6 +86,8 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		arg.GasLimit,
 		arg.Nonce,
 		arg.Status,
+		arg.ToEnsName,
+		arg.ParentID,
 	)
 	var i Transaction
 	err := row.Scan(
@@ -58,12 +104,93 @@ func (q *Queries) CreateTransaction(ctx context.Context, arg CreateTransactionPa
 		&i.TxHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ToEnsName,
+		&i.Direction,
+		&i.FromAddress,
+		&i.LogIndex,
+		&i.BlockNumber,
+		&i.BlockHash,
+		&i.GasUsed,
+		&i.EffectiveGasPrice,
+		&i.Fee,
+		&i.RevertReason,
+		&i.RawTx,
+		&i.ParentID,
 	)
 	return i, err
 }
 
+const getNextNonce = `-- name: GetNextNonce :one
+SELECT COALESCE(MAX(nonce) + 1, 0)::bigint AS next_nonce FROM transactions
+WHERE wallet_id = $1 AND chain_id = $2 AND direction = 'outbound' AND status = 'submitted' AND tx_hash IS NOT NULL
+`
+
+type GetNextNonceParams struct {
+	WalletID pgtype.UUID
+	ChainID  pgtype.UUID
+}
+
+func (q *Queries) GetNextNonce(ctx context.Context, arg GetNextNonceParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getNextNonce, arg.WalletID, arg.ChainID)
+	var next_nonce int64
+	err := row.Scan(&next_nonce)
+	return next_nonce, err
+}
+
+const getSubmittedTransactionsByChainID = `-- name: GetSubmittedTransactionsByChainID :many
+SELECT id, wallet_id, chain_id, to_address, amount, token_id, gas_price, gas_limit, nonce, status, tx_hash, created_at, updated_at, to_ens_name, direction, from_address, log_index, block_number, block_hash, gas_used, effective_gas_price, fee, revert_reason, raw_tx, parent_id FROM transactions
+WHERE chain_id = $1 AND status = 'submitted' AND tx_hash IS NOT NULL
+ORDER BY created_at
+`
+
+func (q *Queries) GetSubmittedTransactionsByChainID(ctx context.Context, chainID pgtype.UUID) ([]Transaction, error) {
+	rows, err := q.db.Query(ctx, getSubmittedTransactionsByChainID, chainID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transaction
+	for rows.Next() {
+		var i Transaction
+		if err := rows.Scan(
+			&i.ID,
+			&i.WalletID,
+			&i.ChainID,
+			&i.ToAddress,
+			&i.Amount,
+			&i.TokenID,
+			&i.GasPrice,
+			&i.GasLimit,
+			&i.Nonce,
+			&i.Status,
+			&i.TxHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.ToEnsName,
+			&i.Direction,
+			&i.FromAddress,
+			&i.LogIndex,
+			&i.BlockNumber,
+			&i.BlockHash,
+			&i.GasUsed,
+			&i.EffectiveGasPrice,
+			&i.Fee,
+			&i.RevertReason,
+			&i.RawTx,
+			&i.ParentID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getTransaction = `-- name: GetTransaction :one
-SELECT id, wallet_id, chain_id, to_address, amount, token_id, gas_price, gas_limit, nonce, status, tx_hash, created_at, updated_at FROM transactions
+SELECT id, wallet_id, chain_id, to_address, amount, token_id, gas_price, gas_limit, nonce, status, tx_hash, created_at, updated_at, to_ens_name, direction, from_address, log_index, block_number, block_hash, gas_used, effective_gas_price, fee, revert_reason, raw_tx, parent_id FROM transactions
 WHERE id = $1 LIMIT 1
 `
 
@@ -84,12 +211,24 @@ func (q *Queries) GetTransaction(ctx context.Context, id pgtype.UUID) (Transacti
 		&i.TxHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ToEnsName,
+		&i.Direction,
+		&i.FromAddress,
+		&i.LogIndex,
+		&i.BlockNumber,
+		&i.BlockHash,
+		&i.GasUsed,
+		&i.EffectiveGasPrice,
+		&i.Fee,
+		&i.RevertReason,
+		&i.RawTx,
+		&i.ParentID,
 	)
 	return i, err
 }
 
 const getTransactionsByWalletID = `-- name: GetTransactionsByWalletID :many
-SELECT id, wallet_id, chain_id, to_address, amount, token_id, gas_price, gas_limit, nonce, status, tx_hash, created_at, updated_at FROM transactions
+SELECT id, wallet_id, chain_id, to_address, amount, token_id, gas_price, gas_limit, nonce, status, tx_hash, created_at, updated_at, to_ens_name, direction, from_address, log_index, block_number, block_hash, gas_used, effective_gas_price, fee, revert_reason, raw_tx, parent_id FROM transactions
 WHERE wallet_id = $1
 `
 
@@ -116,6 +255,18 @@ func (q *Queries) GetTransactionsByWalletID(ctx context.Context, walletID pgtype
 			&i.TxHash,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.ToEnsName,
+			&i.Direction,
+			&i.FromAddress,
+			&i.LogIndex,
+			&i.BlockNumber,
+			&i.BlockHash,
+			&i.GasUsed,
+			&i.EffectiveGasPrice,
+			&i.Fee,
+			&i.RevertReason,
+			&i.RawTx,
+			&i.ParentID,
 		); err != nil {
 			return nil, err
 		}
@@ -127,20 +278,43 @@ func (q *Queries) GetTransactionsByWalletID(ctx context.Context, walletID pgtype
 	return items, nil
 }
 
+const updateChildTransactionStatus = `-- name: UpdateChildTransactionStatus :exec
+UPDATE transactions
+SET status = $2
+WHERE parent_id = $1
+`
+
+type UpdateChildTransactionStatusParams struct {
+	ParentID pgtype.UUID
+	Status   string
+}
+
+func (q *Queries) UpdateChildTransactionStatus(ctx context.Context, arg UpdateChildTransactionStatusParams) error {
+	_, err := q.db.Exec(ctx, updateChildTransactionStatus, arg.ParentID, arg.Status)
+	return err
+}
+
 const updateTransaction = `-- name: UpdateTransaction :one
 UPDATE transactions 
-SET (status, tx_hash, gas_price, gas_limit, nonce) = ($2, $3, $4, $5, $6)
+SET (status, tx_hash, gas_price, gas_limit, nonce, block_number, block_hash, gas_used, effective_gas_price, fee, revert_reason, raw_tx, parent_id) = ($2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
 WHERE id = $1
-RETURNING id, wallet_id, chain_id, to_address, amount, token_id, gas_price, gas_limit, nonce, status, tx_hash, created_at, updated_at
+RETURNING id, wallet_id, chain_id, to_address, amount, token_id, gas_price, gas_limit, nonce, status, tx_hash, created_at, updated_at, to_ens_name, direction, from_address, log_index, block_number, block_hash, gas_used, effective_gas_price, fee, revert_reason, raw_tx, parent_id
 `
 
 type UpdateTransactionParams struct {
-	ID       pgtype.UUID
-	Status   string
-	TxHash   pgtype.Text
-	GasPrice pgtype.Text
-	GasLimit pgtype.Text
-	Nonce    pgtype.Int8
+	ID                pgtype.UUID
+	Status            string
+	TxHash            pgtype.Text
+	GasPrice          pgtype.Text
+	GasLimit          pgtype.Text
+	Nonce             pgtype.Int8
+	BlockNumber       pgtype.Int8
+	BlockHash         pgtype.Text
+	GasUsed           pgtype.Int8
+	EffectiveGasPrice pgtype.Text
+	Fee               pgtype.Text
+	RevertReason      pgtype.Text
+	RawTx             pgtype.Text
 }
 
 func (q *Queries) UpdateTransaction(ctx context.Context, arg UpdateTransactionParams) (Transaction, error) {
@@ -151,6 +325,13 @@ func (q *Queries) UpdateTransaction(ctx context.Context, arg UpdateTransactionPa
 		arg.GasPrice,
 		arg.GasLimit,
 		arg.Nonce,
+		arg.BlockNumber,
+		arg.BlockHash,
+		arg.GasUsed,
+		arg.EffectiveGasPrice,
+		arg.Fee,
+		arg.RevertReason,
+		arg.RawTx,
 	)
 	var i Transaction
 	err := row.Scan(
@@ -167,6 +348,18 @@ func (q *Queries) UpdateTransaction(ctx context.Context, arg UpdateTransactionPa
 		&i.TxHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.ToEnsName,
+		&i.Direction,
+		&i.FromAddress,
+		&i.LogIndex,
+		&i.BlockNumber,
+		&i.BlockHash,
+		&i.GasUsed,
+		&i.EffectiveGasPrice,
+		&i.Fee,
+		&i.RevertReason,
+		&i.RawTx,
+		&i.ParentID,
 	)
 	return i, err
 }
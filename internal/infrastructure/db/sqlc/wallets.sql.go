@@ -12,19 +12,20 @@ import (
 )
 
 const createWallet = `-- name: CreateWallet :one
-INSERT INTO wallets (user_id, address, encrypted_private_key)
-VALUES ($1, $2, $3)
+INSERT INTO wallets (id, user_id, address, encrypted_private_key)
+VALUES ($1, $2, $3, $4)
 RETURNING id, user_id, address, encrypted_private_key, created_at, updated_at
 `
 
 type CreateWalletParams struct {
+	ID                  pgtype.UUID
 	UserID              pgtype.UUID
 	Address             string
 	EncryptedPrivateKey []byte
 }
 
 func (q *Queries) CreateWallet(ctx context.Context, arg CreateWalletParams) (Wallet, error) {
-	row := q.db.QueryRow(ctx, createWallet, arg.UserID, arg.Address, arg.EncryptedPrivateKey)
+	row := q.db.QueryRow(ctx, createWallet, arg.ID, arg.UserID, arg.Address, arg.EncryptedPrivateKey)
 	var i Wallet
 	err := row.Scan(
 		&i.ID,
@@ -93,3 +94,34 @@ func (q *Queries) GetWalletByUserID(ctx context.Context, userID pgtype.UUID) (Wa
 	)
 	return i, err
 }
+
+const listWallets = `-- name: ListWallets :many
+SELECT id, user_id, address, encrypted_private_key, created_at, updated_at FROM wallets
+`
+
+func (q *Queries) ListWallets(ctx context.Context) ([]Wallet, error) {
+	rows, err := q.db.Query(ctx, listWallets)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Wallet
+	for rows.Next() {
+		var i Wallet
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Address,
+			&i.EncryptedPrivateKey,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
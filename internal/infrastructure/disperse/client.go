@@ -0,0 +1,49 @@
+// Package disperse provides an implementation of the DisperseRepository interface
+// for paying many recipients in a single transaction through the Disperse contract.
+package disperse
+
+import (
+	"math/big"
+	"strings"
+
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/repository"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const disperseABIJSON = `[
+	{"inputs":[{"name":"recipients","type":"address[]"},{"name":"values","type":"uint256[]"}],"name":"disperseEther","outputs":[],"stateMutability":"payable","type":"function"}
+]`
+
+var disperseABI = mustParseABI(disperseABIJSON)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+type Client struct {
+	contract common.Address
+}
+
+func NewClient(cfg *config.BatchConfig) *Client {
+	return &Client{contract: common.HexToAddress(cfg.DisperseContract)}
+}
+
+// Ensure Client implements DisperseRepository
+var _ repository.DisperseRepository = (*Client)(nil)
+
+// ContractAddress returns the address of the Disperse contract.
+func (c *Client) ContractAddress() common.Address {
+	return c.contract
+}
+
+// EncodeDisperseEther encodes a disperseEther call. The transaction must carry the sum of values.
+func (c *Client) EncodeDisperseEther(recipients []common.Address, values []*big.Int) ([]byte, error) {
+	return disperseABI.Pack("disperseEther", recipients, values)
+}
@@ -0,0 +1,89 @@
+package eddsa
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"mpc/internal/domain"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"golang.org/x/crypto/sha3"
+)
+
+var (
+	ErrUnsupportedFamily = errors.New("chain family does not use Ed25519")
+	ErrInvalidAddress    = errors.New("invalid address")
+)
+
+// aptosSingleKeyScheme is the authentication key scheme byte of single Ed25519 key accounts on Aptos.
+const aptosSingleKeyScheme = 0x00
+
+// nearAccountID matches named Near accounts: dot-separated parts of lowercase letters and digits,
+// optionally joined by single '-' or '_'.
+var nearAccountID = regexp.MustCompile(`^(([a-z\d]+[\-_])*[a-z\d]+\.)*([a-z\d]+[\-_])*[a-z\d]+$`)
+
+// IsEd25519Family reports whether chains of the family sign with Ed25519.
+func IsEd25519Family(family domain.ChainFamily) bool {
+	switch family {
+	case domain.ChainFamilySolana, domain.ChainFamilyAptos, domain.ChainFamilyNear:
+		return true
+	}
+	return false
+}
+
+// Address encodes a public key as an address on chains of the family:
+//   - Solana: the base58 public key.
+//   - Aptos: the hex SHA3-256 authentication key of a single Ed25519 key account, which is the
+//     address of accounts that never rotated their key.
+//   - Near: the implicit account ID, the lowercase hex public key.
+func Address(family domain.ChainFamily, publicKey ed25519.PublicKey) (string, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("invalid Ed25519 public key length %d", len(publicKey))
+	}
+
+	switch family {
+	case domain.ChainFamilySolana:
+		return base58.Encode(publicKey), nil
+	case domain.ChainFamilyAptos:
+		authKey := sha3.Sum256(append(append([]byte{}, publicKey...), aptosSingleKeyScheme))
+		return "0x" + hex.EncodeToString(authKey[:]), nil
+	case domain.ChainFamilyNear:
+		return hex.EncodeToString(publicKey), nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedFamily, family)
+	}
+}
+
+// ValidateAddress checks that address is well formed on chains of the family and returns it in its
+// canonical form. Near also accepts named accounts, which are not derived from a key.
+func ValidateAddress(family domain.ChainFamily, address string) (string, error) {
+	address = strings.TrimSpace(address)
+
+	switch family {
+	case domain.ChainFamilySolana:
+		if len(base58.Decode(address)) != ed25519.PublicKeySize {
+			return "", fmt.Errorf("%w: %q is not a Solana address", ErrInvalidAddress, address)
+		}
+		return address, nil
+	case domain.ChainFamilyAptos:
+		digits := strings.TrimPrefix(strings.ToLower(address), "0x")
+		if len(digits) != 64 || !strings.HasPrefix(strings.ToLower(address), "0x") {
+			return "", fmt.Errorf("%w: %q is not a long form Aptos address", ErrInvalidAddress, address)
+		}
+		if _, err := hex.DecodeString(digits); err != nil {
+			return "", fmt.Errorf("%w: %q is not a long form Aptos address", ErrInvalidAddress, address)
+		}
+		return "0x" + digits, nil
+	case domain.ChainFamilyNear:
+		if len(address) < 2 || len(address) > 64 || !nearAccountID.MatchString(address) {
+			return "", fmt.Errorf("%w: %q is not a Near account ID", ErrInvalidAddress, address)
+		}
+		return address, nil
+	default:
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedFamily, family)
+	}
+}
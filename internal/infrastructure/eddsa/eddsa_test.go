@@ -0,0 +1,135 @@
+package eddsa
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"mpc/internal/domain"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatalf("Failed to decode %q: %v", s, err)
+	}
+	return b
+}
+
+// RFC 8032, section 7.1, test 1.
+const (
+	rfc8032Seed      = "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"
+	rfc8032PublicKey = "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+	rfc8032Signature = "e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b"
+)
+
+func TestLocalSignerSign(t *testing.T) {
+	signer := NewLocalSigner(ed25519.NewKeyFromSeed(mustDecodeHex(t, rfc8032Seed)))
+
+	if got := hex.EncodeToString(signer.PublicKey()); got != rfc8032PublicKey {
+		t.Fatalf("PublicKey() = %s, want %s", got, rfc8032PublicKey)
+	}
+
+	signature, err := signer.Sign(nil)
+	if err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if got := hex.EncodeToString(signature); got != rfc8032Signature {
+		t.Fatalf("Sign() = %s, want %s", got, rfc8032Signature)
+	}
+
+	if err := Verify(signer.PublicKey(), nil, signature); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := Verify(signer.PublicKey(), []byte("tampered"), signature); !errors.Is(err, ErrInvalidSignature) {
+		t.Fatalf("Verify() of another message error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
+
+// SLIP-10, test vector 1 for ed25519, chain m.
+func TestKeyFromSeed(t *testing.T) {
+	key := KeyFromSeed(mustDecodeHex(t, "000102030405060708090a0b0c0d0e0f"))
+
+	if got, want := hex.EncodeToString(key.Seed()), "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7"; got != want {
+		t.Fatalf("private key = %s, want %s", got, want)
+	}
+	if got, want := hex.EncodeToString(key.Public().(ed25519.PublicKey)), "a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed"; got != want {
+		t.Fatalf("public key = %s, want %s", got, want)
+	}
+}
+
+func TestAddress(t *testing.T) {
+	publicKey := ed25519.PublicKey(mustDecodeHex(t, rfc8032PublicKey))
+
+	tests := []struct {
+		family domain.ChainFamily
+		want   string
+	}{
+		{domain.ChainFamilySolana, "FVen3X669xLzsi6N2V91DoiyzHzg1uAgqiT8jZ9nS96Z"},
+		{domain.ChainFamilyAptos, "0x63c5215e87770d17b9f4cd47c777e322f4eb152cfd2054c1080fd9d57c48913b"},
+		{domain.ChainFamilyNear, rfc8032PublicKey},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.family), func(t *testing.T) {
+			got, err := Address(tt.family, publicKey)
+			if err != nil {
+				t.Fatalf("Address() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Address() = %s, want %s", got, tt.want)
+			}
+
+			canonical, err := ValidateAddress(tt.family, got)
+			if err != nil {
+				t.Fatalf("ValidateAddress() error = %v", err)
+			}
+			if canonical != got {
+				t.Fatalf("ValidateAddress() = %s, want %s", canonical, got)
+			}
+		})
+	}
+
+	if _, err := Address(domain.ChainFamilyEVM, publicKey); !errors.Is(err, ErrUnsupportedFamily) {
+		t.Fatalf("Address() on an EVM chain error = %v, want %v", err, ErrUnsupportedFamily)
+	}
+}
+
+func TestValidateAddress(t *testing.T) {
+	tests := []struct {
+		family  domain.ChainFamily
+		address string
+		want    string
+		wantErr bool
+	}{
+		{domain.ChainFamilySolana, "11111111111111111111111111111111", "11111111111111111111111111111111", false},
+		{domain.ChainFamilySolana, "FVen3X669xLzsi6N2V91Doiy", "", true},
+		{domain.ChainFamilySolana, "0OIl", "", true},
+		{domain.ChainFamilyAptos, "0x63C5215E87770D17B9F4CD47C777E322F4EB152CFD2054C1080FD9D57C48913B", "0x63c5215e87770d17b9f4cd47c777e322f4eb152cfd2054c1080fd9d57c48913b", false},
+		{domain.ChainFamilyAptos, "0x1", "", true},
+		{domain.ChainFamilyAptos, "63c5215e87770d17b9f4cd47c777e322f4eb152cfd2054c1080fd9d57c48913b", "", true},
+		{domain.ChainFamilyNear, "alice.near", "alice.near", false},
+		{domain.ChainFamilyNear, "app_1-beta.alice.testnet", "app_1-beta.alice.testnet", false},
+		{domain.ChainFamilyNear, "Alice.near", "", true},
+		{domain.ChainFamilyNear, "alice..near", "", true},
+		{domain.ChainFamilyNear, "a", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.family)+"/"+tt.address, func(t *testing.T) {
+			got, err := ValidateAddress(tt.family, tt.address)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAddress) {
+					t.Fatalf("ValidateAddress() error = %v, want %v", err, ErrInvalidAddress)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ValidateAddress() error = %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("ValidateAddress() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
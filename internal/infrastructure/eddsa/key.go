@@ -0,0 +1,67 @@
+// Package eddsa derives Ed25519 keys from wallet keys, signs with them and encodes their public keys
+// as addresses of the chains that use Ed25519: Solana, Aptos and Near.
+package eddsa
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha512"
+	"errors"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var ErrInvalidSignature = errors.New("invalid Ed25519 signature")
+
+// slip10Tag is the HMAC key SLIP-10 uses to derive Ed25519 master keys from a seed.
+const slip10Tag = "ed25519 seed"
+
+// Signer signs messages with an Ed25519 key. LocalSigner holds the whole key; a threshold
+// implementation such as FROST would hold one share and produce the same signatures together with
+// the other signers.
+type Signer interface {
+	PublicKey() ed25519.PublicKey
+	Sign(message []byte) ([]byte, error)
+}
+
+// LocalSigner signs with an Ed25519 private key held in memory.
+type LocalSigner struct {
+	key ed25519.PrivateKey
+}
+
+func NewLocalSigner(key ed25519.PrivateKey) *LocalSigner {
+	return &LocalSigner{key: key}
+}
+
+// Ensure LocalSigner implements Signer
+var _ Signer = (*LocalSigner)(nil)
+
+func (s *LocalSigner) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+func (s *LocalSigner) Sign(message []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, message), nil
+}
+
+// KeyFromSeed returns the SLIP-10 Ed25519 master key of seed.
+func KeyFromSeed(seed []byte) ed25519.PrivateKey {
+	mac := hmac.New(sha512.New, []byte(slip10Tag))
+	mac.Write(seed)
+	return ed25519.NewKeyFromSeed(mac.Sum(nil)[:ed25519.SeedSize])
+}
+
+// WalletKey derives the Ed25519 key of a wallet, the SLIP-10 master key seeded with its secp256k1
+// key. The wallet key stays the only secret to store and back up.
+func WalletKey(key *ecdsa.PrivateKey) ed25519.PrivateKey {
+	return KeyFromSeed(crypto.FromECDSA(key))
+}
+
+// Verify checks an Ed25519 signature of message.
+func Verify(publicKey ed25519.PublicKey, message []byte, signature []byte) error {
+	if len(publicKey) != ed25519.PublicKeySize || !ed25519.Verify(publicKey, message, signature) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
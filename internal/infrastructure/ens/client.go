@@ -0,0 +1,115 @@
+// Package ens provides an implementation of the ENSRepository interface
+// for resolving ENS names through the registry on chain.
+package ens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/repository"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const (
+	registryABIJSON = `[
+		{"inputs":[{"name":"node","type":"bytes32"}],"name":"resolver","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+	]`
+	resolverABIJSON = `[
+		{"inputs":[{"name":"node","type":"bytes32"}],"name":"addr","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+	]`
+)
+
+var (
+	registryABI = mustParseABI(registryABIJSON)
+	resolverABI = mustParseABI(resolverABIJSON)
+
+	// ErrNameNotFound is returned when a name has no resolver or no address record.
+	ErrNameNotFound = errors.New("ENS name not found")
+)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+type Client struct {
+	eth      *ethclient.Client
+	registry common.Address
+}
+
+func NewClient(ethCfg *config.EthereumConfig, cfg *config.ENSConfig) (*Client, error) {
+	eth, err := ethclient.Dial(ethCfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ethereum client: %w", err)
+	}
+	return &Client{eth: eth, registry: common.HexToAddress(cfg.Registry)}, nil
+}
+
+// Ensure Client implements ENSRepository
+var _ repository.ENSRepository = (*Client)(nil)
+
+// ResolveName returns the address an ENS name points to.
+func (c *Client) ResolveName(ctx context.Context, name string) (common.Address, error) {
+	node := NameHash(name)
+
+	var resolver common.Address
+	if err := c.call(ctx, c.registry, registryABI, "resolver", node, &resolver); err != nil {
+		return common.Address{}, err
+	}
+	if resolver == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%w: %s has no resolver", ErrNameNotFound, name)
+	}
+
+	var address common.Address
+	if err := c.call(ctx, resolver, resolverABI, "addr", node, &address); err != nil {
+		return common.Address{}, err
+	}
+	if address == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("%w: %s has no address record", ErrNameNotFound, name)
+	}
+
+	return address, nil
+}
+
+// NameHash computes the EIP-137 namehash of an already normalized name.
+func NameHash(name string) common.Hash {
+	var node common.Hash
+	if name == "" {
+		return node
+	}
+
+	labels := strings.Split(name, ".")
+	for i := len(labels) - 1; i >= 0; i-- {
+		labelHash := crypto.Keccak256Hash([]byte(labels[i]))
+		node = crypto.Keccak256Hash(node.Bytes(), labelHash.Bytes())
+	}
+	return node
+}
+
+func (c *Client) call(ctx context.Context, contract common.Address, contractABI abi.ABI, method string, node common.Hash, result interface{}) error {
+	data, err := contractABI.Pack(method, node)
+	if err != nil {
+		return err
+	}
+
+	out, err := c.eth.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to call %s on ENS contract: %w", method, err)
+	}
+
+	if err := contractABI.UnpackIntoInterface(result, method, out); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package ens
+
+import "testing"
+
+// The vectors are the examples of EIP-137.
+func TestNameHash(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "", want: "0x0000000000000000000000000000000000000000000000000000000000000000"},
+		{name: "eth", want: "0x93cdeb708b7545dc668eb9280176169d1c33cfd8ed6f04690a0bcc88a93fc4ae"},
+		{name: "foo.eth", want: "0xde9b09fd7c5f901e23a3f19fecc54828e9c848539801e86591bd9801b019f84f"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NameHash(tt.name); got.Hex() != tt.want {
+				t.Errorf("NameHash(%q) = %s, want %s", tt.name, got.Hex(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,305 @@
+// Package erc4337 provides an implementation of the SmartAccountRepository interface
+// for operating ERC-4337 smart accounts owned by wallet keys.
+package erc4337
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/repository"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// SimpleAccountFactory (v0.6)
+	factoryABIJSON = `[
+		{"inputs":[{"name":"owner","type":"address"},{"name":"salt","type":"uint256"}],"name":"createAccount","outputs":[{"name":"ret","type":"address"}],"stateMutability":"nonpayable","type":"function"},
+		{"inputs":[{"name":"owner","type":"address"},{"name":"salt","type":"uint256"}],"name":"getAddress","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"}
+	]`
+	// SimpleAccount (v0.6)
+	accountABIJSON = `[
+		{"inputs":[{"name":"dest","type":"address"},{"name":"value","type":"uint256"},{"name":"func","type":"bytes"}],"name":"execute","outputs":[],"stateMutability":"nonpayable","type":"function"}
+	]`
+	// EntryPoint (v0.6)
+	entryPointABIJSON = `[
+		{"inputs":[{"name":"sender","type":"address"},{"name":"key","type":"uint192"}],"name":"getNonce","outputs":[{"name":"nonce","type":"uint256"}],"stateMutability":"view","type":"function"}
+	]`
+)
+
+var (
+	factoryABI    = mustParseABI(factoryABIJSON)
+	accountABI    = mustParseABI(accountABIJSON)
+	entryPointABI = mustParseABI(entryPointABIJSON)
+
+	// dummySignature lets bundlers simulate validation before the operation is signed.
+	dummySignature = common.FromHex("0xfffffffffffffffffffffffffffffff0000000000000000000000000000000007aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa1c")
+
+	// Fallback gas limits used when the bundler cannot estimate the operation
+	defaultCallGasLimit         = big.NewInt(100_000)
+	defaultVerificationGasLimit = big.NewInt(500_000)
+	defaultPreVerificationGas   = big.NewInt(60_000)
+)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// Client talks to the chain for account state and to a bundler for user operations.
+type Client struct {
+	eth        *ethclient.Client
+	bundler    *rpc.Client
+	entryPoint common.Address
+	factory    common.Address
+	salt       *big.Int
+}
+
+func NewClient(ethCfg *config.EthereumConfig, cfg *config.ERC4337Config) (*Client, error) {
+	eth, err := ethclient.Dial(ethCfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ethereum client: %w", err)
+	}
+
+	bundlerURL := cfg.BundlerURL
+	if bundlerURL == "" {
+		bundlerURL = ethCfg.URL
+	}
+	bundler, err := rpc.Dial(bundlerURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to bundler: %w", err)
+	}
+
+	return &Client{
+		eth:        eth,
+		bundler:    bundler,
+		entryPoint: common.HexToAddress(cfg.EntryPoint),
+		factory:    common.HexToAddress(cfg.AccountFactory),
+		salt:       big.NewInt(cfg.Salt),
+	}, nil
+}
+
+// Ensure Client implements SmartAccountRepository
+var _ repository.SmartAccountRepository = (*Client)(nil)
+
+// GetAccountAddress returns the counterfactual address of the smart account owned by owner.
+// The address is valid whether or not the account has been deployed yet.
+func (c *Client) GetAccountAddress(ctx context.Context, owner common.Address) (common.Address, error) {
+	data, err := factoryABI.Pack("getAddress", owner, c.salt)
+	if err != nil {
+		return common.Address{}, err
+	}
+
+	out, err := c.eth.CallContract(ctx, ethereum.CallMsg{To: &c.factory, Data: data}, nil)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to compute account address: %w", err)
+	}
+
+	var account common.Address
+	if err := factoryABI.UnpackIntoInterface(&account, "getAddress", out); err != nil {
+		return common.Address{}, fmt.Errorf("failed to decode account address: %w", err)
+	}
+
+	return account, nil
+}
+
+// IsDeployed reports whether the smart account already has code on chain.
+func (c *Client) IsDeployed(ctx context.Context, account common.Address) (bool, error) {
+	code, err := c.eth.CodeAt(ctx, account, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to get account code: %w", err)
+	}
+	return len(code) > 0, nil
+}
+
+// BuildUserOperation creates an unsigned user operation that makes the owner's smart account
+// call to with value and data. The account is deployed by the same operation if needed.
+func (c *Client) BuildUserOperation(ctx context.Context, owner common.Address, to common.Address, value *big.Int, data []byte) (*domain.UserOperation, error) {
+	sender, err := c.GetAccountAddress(ctx, owner)
+	if err != nil {
+		return nil, err
+	}
+
+	deployed, err := c.IsDeployed(ctx, sender)
+	if err != nil {
+		return nil, err
+	}
+
+	var initCode []byte
+	if !deployed {
+		createAccount, err := factoryABI.Pack("createAccount", owner, c.salt)
+		if err != nil {
+			return nil, err
+		}
+		initCode = append(c.factory.Bytes(), createAccount...)
+	}
+
+	nonce, err := c.getNonce(ctx, sender)
+	if err != nil {
+		return nil, err
+	}
+
+	callData, err := accountABI.Pack("execute", to, value, data)
+	if err != nil {
+		return nil, err
+	}
+
+	maxPriorityFee, err := c.eth.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest gas tip: %w", err)
+	}
+
+	head, err := c.eth.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest header: %w", err)
+	}
+
+	maxFee := new(big.Int).Set(maxPriorityFee)
+	if head.BaseFee != nil {
+		maxFee.Add(maxFee, new(big.Int).Mul(head.BaseFee, big.NewInt(2)))
+	}
+
+	userOp := &domain.UserOperation{
+		Sender:               sender,
+		Nonce:                nonce,
+		InitCode:             initCode,
+		CallData:             callData,
+		CallGasLimit:         defaultCallGasLimit,
+		VerificationGasLimit: defaultVerificationGasLimit,
+		PreVerificationGas:   defaultPreVerificationGas,
+		MaxFeePerGas:         maxFee,
+		MaxPriorityFeePerGas: maxPriorityFee,
+		Signature:            dummySignature,
+	}
+
+	var estimate gasEstimateJSON
+	if err := c.bundler.CallContext(ctx, &estimate, "eth_estimateUserOperationGas", toJSON(userOp), c.entryPoint); err == nil {
+		if estimate.CallGasLimit != nil {
+			userOp.CallGasLimit = estimate.CallGasLimit.ToInt()
+		}
+		if estimate.VerificationGasLimit != nil {
+			userOp.VerificationGasLimit = estimate.VerificationGasLimit.ToInt()
+		}
+		if estimate.PreVerificationGas != nil {
+			userOp.PreVerificationGas = estimate.PreVerificationGas.ToInt()
+		}
+	}
+	userOp.Signature = nil
+
+	return userOp, nil
+}
+
+// SignUserOperation signs the user operation hash with the owner key, as expected by SimpleAccount
+// (an EIP-191 personal signature). It returns the user operation hash.
+func (c *Client) SignUserOperation(ctx context.Context, userOp *domain.UserOperation, privateKey *ecdsa.PrivateKey) (common.Hash, error) {
+	chainID, err := c.eth.ChainID(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+
+	hash, err := UserOperationHash(userOp, c.entryPoint, chainID)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash user operation: %w", err)
+	}
+
+	signature, err := crypto.Sign(accounts.TextHash(hash.Bytes()), privateKey)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to sign user operation: %w", err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+
+	userOp.Signature = signature
+	return hash, nil
+}
+
+// SendUserOperation submits a signed user operation to the bundler and returns its hash.
+func (c *Client) SendUserOperation(ctx context.Context, userOp *domain.UserOperation) (common.Hash, error) {
+	var hash common.Hash
+	if err := c.bundler.CallContext(ctx, &hash, "eth_sendUserOperation", toJSON(userOp), c.entryPoint); err != nil {
+		return common.Hash{}, fmt.Errorf("failed to send user operation: %w", err)
+	}
+	return hash, nil
+}
+
+// GetUserOperationReceipt returns the receipt of a user operation, or nil if it has not been included yet.
+func (c *Client) GetUserOperationReceipt(ctx context.Context, userOpHash common.Hash) (*domain.UserOperationReceipt, error) {
+	var receipt *userOperationReceiptJSON
+	if err := c.bundler.CallContext(ctx, &receipt, "eth_getUserOperationReceipt", userOpHash); err != nil {
+		return nil, fmt.Errorf("failed to get user operation receipt: %w", err)
+	}
+	if receipt == nil {
+		return nil, nil
+	}
+
+	actualGasCost := new(big.Int)
+	if receipt.ActualGasCost != nil {
+		actualGasCost = receipt.ActualGasCost.ToInt()
+	}
+
+	return &domain.UserOperationReceipt{
+		UserOpHash:    receipt.UserOpHash,
+		Success:       receipt.Success,
+		Reason:        receipt.Reason,
+		ActualGasCost: actualGasCost,
+		TxHash:        receipt.Receipt.TransactionHash,
+	}, nil
+}
+
+// WaitForUserOperationReceipt polls the bundler until the user operation is included on chain.
+func (c *Client) WaitForUserOperationReceipt(ctx context.Context, userOpHash common.Hash) (*domain.UserOperationReceipt, error) {
+	ticker := time.NewTicker(5 * time.Second) // Poll every 5 seconds
+	defer ticker.Stop()
+
+	timeout := time.After(5 * time.Minute) // Set a 5-minute timeout
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timeout:
+			return nil, fmt.Errorf("timeout waiting for user operation receipt")
+		case <-ticker.C:
+			receipt, err := c.GetUserOperationReceipt(ctx, userOpHash)
+			if err != nil {
+				return nil, err
+			}
+			if receipt != nil {
+				return receipt, nil
+			}
+		}
+	}
+}
+
+func (c *Client) getNonce(ctx context.Context, sender common.Address) (*big.Int, error) {
+	data, err := entryPointABI.Pack("getNonce", sender, big.NewInt(0))
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := c.eth.CallContract(ctx, ethereum.CallMsg{To: &c.entryPoint, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account nonce: %w", err)
+	}
+
+	var nonce *big.Int
+	if err := entryPointABI.UnpackIntoInterface(&nonce, "getNonce", out); err != nil {
+		return nil, fmt.Errorf("failed to decode account nonce: %w", err)
+	}
+
+	return nonce, nil
+}
@@ -0,0 +1,185 @@
+package erc4337
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"mpc/internal/domain"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// bundlerStub is a bundler serving the eth_ methods the client uses over JSON-RPC. It accepts a user
+// operation only if it is signed by owner, as SimpleAccount would, and includes it when told to.
+type bundlerStub struct {
+	owner   common.Address
+	chainID *big.Int
+
+	mu       sync.Mutex
+	receipts map[common.Hash]*userOperationReceiptJSON
+}
+
+// newBundlerStub serves a bundlerStub over HTTP and returns a client connected to it, to be used
+// both as the bundler and as the Ethereum node.
+func newBundlerStub(t *testing.T, owner common.Address) (*bundlerStub, *Client) {
+	t.Helper()
+
+	stub := &bundlerStub{owner: owner, chainID: big.NewInt(11155111), receipts: make(map[common.Hash]*userOperationReceiptJSON)}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", stub); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	t.Cleanup(server.Stop)
+
+	rpcClient, err := rpc.Dial(httpServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(rpcClient.Close)
+
+	return stub, &Client{
+		eth:        ethclient.NewClient(rpcClient),
+		bundler:    rpcClient,
+		entryPoint: entryPointV06,
+		salt:       new(big.Int),
+	}
+}
+
+func (s *bundlerStub) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(s.chainID)
+}
+
+func (s *bundlerStub) SendUserOperation(op userOperationJSON, entryPoint common.Address) (common.Hash, error) {
+	if entryPoint != entryPointV06 {
+		return common.Hash{}, errors.New("unsupported entry point")
+	}
+
+	userOp := fromJSON(op)
+	hash, err := UserOperationHash(userOp, entryPoint, s.chainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	if len(userOp.Signature) != crypto.SignatureLength {
+		return common.Hash{}, errors.New("invalid signature length")
+	}
+	signature := common.CopyBytes(userOp.Signature)
+	signature[crypto.RecoveryIDOffset] -= 27
+	publicKey, err := crypto.SigToPub(accounts.TextHash(hash.Bytes()), signature)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if crypto.PubkeyToAddress(*publicKey) != s.owner {
+		return common.Hash{}, errors.New("AA24 signature error")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.receipts[hash] = nil
+	return hash, nil
+}
+
+func (s *bundlerStub) GetUserOperationReceipt(hash common.Hash) (*userOperationReceiptJSON, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.receipts[hash], nil
+}
+
+// include records a sent user operation as mined in txHash.
+func (s *bundlerStub) include(hash common.Hash, txHash common.Hash) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	receipt := &userOperationReceiptJSON{UserOpHash: hash, Success: true, ActualGasCost: (*hexutil.Big)(big.NewInt(21_000))}
+	receipt.Receipt.TransactionHash = txHash
+	s.receipts[hash] = receipt
+}
+
+func fromJSON(op userOperationJSON) *domain.UserOperation {
+	return &domain.UserOperation{
+		Sender:               op.Sender,
+		Nonce:                op.Nonce.ToInt(),
+		InitCode:             op.InitCode,
+		CallData:             op.CallData,
+		CallGasLimit:         op.CallGasLimit.ToInt(),
+		VerificationGasLimit: op.VerificationGasLimit.ToInt(),
+		PreVerificationGas:   op.PreVerificationGas.ToInt(),
+		MaxFeePerGas:         op.MaxFeePerGas.ToInt(),
+		MaxPriorityFeePerGas: op.MaxPriorityFeePerGas.ToInt(),
+		PaymasterAndData:     op.PaymasterAndData,
+		Signature:            op.Signature,
+	}
+}
+
+func TestSendUserOperation(t *testing.T) {
+	ctx := context.Background()
+
+	owner, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stub, client := newBundlerStub(t, crypto.PubkeyToAddress(owner.PublicKey))
+
+	userOp := &domain.UserOperation{
+		Sender:               common.HexToAddress("0x2C4F58D3E1b0bb5F7a4cB6d1A0C7B2fD3e5B8a91"),
+		Nonce:                big.NewInt(1),
+		CallData:             []byte{0xb6, 0x1d, 0x27, 0xf6},
+		CallGasLimit:         defaultCallGasLimit,
+		VerificationGasLimit: defaultVerificationGasLimit,
+		PreVerificationGas:   defaultPreVerificationGas,
+		MaxFeePerGas:         big.NewInt(30_000_000_000),
+		MaxPriorityFeePerGas: big.NewInt(1_000_000_000),
+	}
+
+	hash, err := client.SignUserOperation(ctx, userOp, owner)
+	if err != nil {
+		t.Fatalf("SignUserOperation() error = %v", err)
+	}
+
+	sent, err := client.SendUserOperation(ctx, userOp)
+	if err != nil {
+		t.Fatalf("SendUserOperation() error = %v", err)
+	}
+	if sent != hash {
+		t.Fatalf("SendUserOperation() = %s, want the signed hash %s", sent.Hex(), hash.Hex())
+	}
+
+	receipt, err := client.GetUserOperationReceipt(ctx, hash)
+	if err != nil {
+		t.Fatalf("GetUserOperationReceipt() error = %v", err)
+	}
+	if receipt != nil {
+		t.Fatalf("GetUserOperationReceipt() = %+v before inclusion, want nil", receipt)
+	}
+
+	txHash := common.HexToHash("0x01")
+	stub.include(hash, txHash)
+	receipt, err = client.GetUserOperationReceipt(ctx, hash)
+	if err != nil {
+		t.Fatalf("GetUserOperationReceipt() error = %v", err)
+	}
+	if receipt == nil || !receipt.Success || receipt.TxHash != txHash || receipt.ActualGasCost.Int64() != 21_000 {
+		t.Fatalf("GetUserOperationReceipt() = %+v, want a successful receipt in %s", receipt, txHash.Hex())
+	}
+
+	// A signature by another key is rejected, like an account validating against its owner would
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.SignUserOperation(ctx, userOp, other); err != nil {
+		t.Fatalf("SignUserOperation() error = %v", err)
+	}
+	if _, err := client.SendUserOperation(ctx, userOp); err == nil {
+		t.Fatal("SendUserOperation() succeeded with a signature by another key")
+	}
+}
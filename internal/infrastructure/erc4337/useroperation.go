@@ -0,0 +1,109 @@
+package erc4337
+
+import (
+	"math/big"
+	"mpc/internal/domain"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	addressType, _ = abi.NewType("address", "", nil)
+	uint256Type, _ = abi.NewType("uint256", "", nil)
+	bytes32Type, _ = abi.NewType("bytes32", "", nil)
+
+	packedUserOpArgs = abi.Arguments{
+		{Type: addressType}, // sender
+		{Type: uint256Type}, // nonce
+		{Type: bytes32Type}, // keccak256(initCode)
+		{Type: bytes32Type}, // keccak256(callData)
+		{Type: uint256Type}, // callGasLimit
+		{Type: uint256Type}, // verificationGasLimit
+		{Type: uint256Type}, // preVerificationGas
+		{Type: uint256Type}, // maxFeePerGas
+		{Type: uint256Type}, // maxPriorityFeePerGas
+		{Type: bytes32Type}, // keccak256(paymasterAndData)
+	}
+
+	userOpHashArgs = abi.Arguments{
+		{Type: bytes32Type}, // keccak256(packed user operation)
+		{Type: addressType}, // entry point
+		{Type: uint256Type}, // chain ID
+	}
+)
+
+// UserOperationHash computes the hash the EntryPoint (v0.6) asks the account to validate.
+func UserOperationHash(userOp *domain.UserOperation, entryPoint common.Address, chainID *big.Int) (common.Hash, error) {
+	packed, err := packedUserOpArgs.Pack(
+		userOp.Sender,
+		userOp.Nonce,
+		crypto.Keccak256Hash(userOp.InitCode),
+		crypto.Keccak256Hash(userOp.CallData),
+		userOp.CallGasLimit,
+		userOp.VerificationGasLimit,
+		userOp.PreVerificationGas,
+		userOp.MaxFeePerGas,
+		userOp.MaxPriorityFeePerGas,
+		crypto.Keccak256Hash(userOp.PaymasterAndData),
+	)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	encoded, err := userOpHashArgs.Pack(crypto.Keccak256Hash(packed), entryPoint, chainID)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return crypto.Keccak256Hash(encoded), nil
+}
+
+// userOperationJSON is the JSON-RPC representation expected by bundlers.
+type userOperationJSON struct {
+	Sender               common.Address `json:"sender"`
+	Nonce                *hexutil.Big   `json:"nonce"`
+	InitCode             hexutil.Bytes  `json:"initCode"`
+	CallData             hexutil.Bytes  `json:"callData"`
+	CallGasLimit         *hexutil.Big   `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big   `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big   `json:"preVerificationGas"`
+	MaxFeePerGas         *hexutil.Big   `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big   `json:"maxPriorityFeePerGas"`
+	PaymasterAndData     hexutil.Bytes  `json:"paymasterAndData"`
+	Signature            hexutil.Bytes  `json:"signature"`
+}
+
+func toJSON(userOp *domain.UserOperation) userOperationJSON {
+	return userOperationJSON{
+		Sender:               userOp.Sender,
+		Nonce:                (*hexutil.Big)(userOp.Nonce),
+		InitCode:             userOp.InitCode,
+		CallData:             userOp.CallData,
+		CallGasLimit:         (*hexutil.Big)(userOp.CallGasLimit),
+		VerificationGasLimit: (*hexutil.Big)(userOp.VerificationGasLimit),
+		PreVerificationGas:   (*hexutil.Big)(userOp.PreVerificationGas),
+		MaxFeePerGas:         (*hexutil.Big)(userOp.MaxFeePerGas),
+		MaxPriorityFeePerGas: (*hexutil.Big)(userOp.MaxPriorityFeePerGas),
+		PaymasterAndData:     userOp.PaymasterAndData,
+		Signature:            userOp.Signature,
+	}
+}
+
+type gasEstimateJSON struct {
+	CallGasLimit         *hexutil.Big `json:"callGasLimit"`
+	VerificationGasLimit *hexutil.Big `json:"verificationGasLimit"`
+	PreVerificationGas   *hexutil.Big `json:"preVerificationGas"`
+}
+
+type userOperationReceiptJSON struct {
+	UserOpHash    common.Hash  `json:"userOpHash"`
+	Success       bool         `json:"success"`
+	Reason        string       `json:"reason"`
+	ActualGasCost *hexutil.Big `json:"actualGasCost"`
+	Receipt       struct {
+		TransactionHash common.Hash `json:"transactionHash"`
+	} `json:"receipt"`
+}
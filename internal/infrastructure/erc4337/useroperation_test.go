@@ -0,0 +1,68 @@
+package erc4337
+
+import (
+	"math/big"
+	"mpc/internal/domain"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// entryPointV06 is the canonical EntryPoint v0.6 deployment, the default of ERC4337_ENTRY_POINT.
+var entryPointV06 = common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+
+// The expected hashes are keccak256(abi.encode(keccak256(pack(userOp)), entryPoint, chainId)) as in
+// EntryPoint.getUserOpHash (v0.6), encoded word by word rather than with the abi package.
+func TestUserOperationHash(t *testing.T) {
+	sender := common.HexToAddress("0x2C4F58D3E1b0bb5F7a4cB6d1A0C7B2fD3e5B8a91")
+	// SimpleAccount.execute(0xd8dA...6045, 0.1 ether, "")
+	callData := hexutil.MustDecode("0xb61d27f6000000000000000000000000d8da6bf26964af9d7eed9e03e53415d37aa96045000000000000000000000000000000000000000000000000016345785d8a000000000000000000000000000000000000000000000000000000000000000000600000000000000000000000000000000000000000000000000000000000000000")
+
+	tests := []struct {
+		name             string
+		nonce            int64
+		initCode         []byte
+		paymasterAndData []byte
+		want             string
+	}{
+		{
+			name:  "first operation deploys the account",
+			nonce: 0,
+			// SimpleAccountFactory.createAccount(0xd8dA...6045, 0)
+			initCode: hexutil.MustDecode("0x9406cc6185a346906296840746125a0e449764545fbfb9cf000000000000000000000000d8da6bf26964af9d7eed9e03e53415d37aa960450000000000000000000000000000000000000000000000000000000000000000"),
+			want:     "0x78dc53c98ce1ec9c40e0eca91f1269e598e989237a201984f153bbe4ab46c6b7",
+		},
+		{
+			name:             "deployed account with a paymaster",
+			nonce:            5,
+			paymasterAndData: hexutil.MustDecode("0xe93eca6595fe94091dc1af46aac2a8b5d79907700000000000000000000000000000000000000000000000000000000000000001"),
+			want:             "0x35ca300d00779d0b2222b6131718c3cc4d39a7d401167d12f60ed2c2a12e573b",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userOp := &domain.UserOperation{
+				Sender:               sender,
+				Nonce:                big.NewInt(tt.nonce),
+				InitCode:             tt.initCode,
+				CallData:             callData,
+				CallGasLimit:         big.NewInt(100_000),
+				VerificationGasLimit: big.NewInt(500_000),
+				PreVerificationGas:   big.NewInt(60_000),
+				MaxFeePerGas:         big.NewInt(30_000_000_000),
+				MaxPriorityFeePerGas: big.NewInt(1_000_000_000),
+				PaymasterAndData:     tt.paymasterAndData,
+				// The signature is not part of the hash
+				Signature: dummySignature,
+			}
+			got, err := UserOperationHash(userOp, entryPointV06, big.NewInt(11155111))
+			if err != nil {
+				t.Fatalf("UserOperationHash() error = %v", err)
+			}
+			if got.Hex() != tt.want {
+				t.Errorf("UserOperationHash() = %s, want %s", got.Hex(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package ethereum
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const erc20ABIJSON = `[
+	{"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+var erc20ABI = mustParseABI(erc20ABIJSON)
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// GetTokenBalance returns the ERC-20 balance of owner, in the token's smallest unit.
+func (c *EthereumClient) GetTokenBalance(ctx context.Context, token common.Address, owner common.Address) (*big.Int, error) {
+	data, err := erc20ABI.Pack("balanceOf", owner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode balanceOf: %w", err)
+	}
+
+	result, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call balanceOf on %s: %w", token.Hex(), err)
+	}
+
+	values, err := erc20ABI.Unpack("balanceOf", result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode balanceOf: %w", err)
+	}
+	return values[0].(*big.Int), nil
+}
@@ -14,6 +14,7 @@ import (
 	"math/big"
 	"time"
 
+	"mpc/internal/domain"
 	"mpc/internal/infrastructure/config"
 	"mpc/internal/repository"
 
@@ -25,18 +26,36 @@ import (
 )
 
 // EthereumClient represents a client for interacting with the Ethereum blockchain.
+// Calls are spread over the configured RPC URL and its fallbacks, see rpcPool.
 type EthereumClient struct {
-	client    *ethclient.Client
-	secretKey string
+	pool           *rpcPool
+	secretKey      string
+	receiptTimeout time.Duration
 }
 
 func NewEthereumClient(cfg *config.EthereumConfig) (*EthereumClient, error) {
-	client, err := ethclient.Dial(cfg.URL)
+	pool, err := newRPCPool(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to Ethereum client: %w", err)
 	}
 
-	return &EthereumClient{client: client, secretKey: cfg.SecretKey}, nil
+	return &EthereumClient{pool: pool, secretKey: cfg.SecretKey, receiptTimeout: cfg.ReceiptTimeout}, nil
+}
+
+// NewChainClient returns a constructor of clients for EVM chains. Each client connects to the RPC
+// URLs stored with its chain and takes the rest of its settings from cfg.
+func NewChainClient(cfg *config.EthereumConfig) func(chain domain.Chain) (repository.EthereumRepository, error) {
+	return func(chain domain.Chain) (repository.EthereumRepository, error) {
+		chainCfg := *cfg
+		chainCfg.URL = chain.RPCURL
+		chainCfg.FallbackURLs = chain.FallbackRPCURLs
+		return NewEthereumClient(&chainCfg)
+	}
+}
+
+// Close stops the health checks and closes the connections to every RPC endpoint.
+func (c *EthereumClient) Close() {
+	c.pool.close()
 }
 
 // Ensure EthereumClient implements EthereumRepository
@@ -44,7 +63,11 @@ var _ repository.EthereumRepository = (*EthereumClient)(nil)
 
 // CreateWallet generates a new Ethereum wallet.
 // It returns the private key, the associated Ethereum address, and any error encountered.
-func (c *EthereumClient) CreateWallet() (*ecdsa.PrivateKey, common.Address, error) {
+func (c *EthereumClient) CreateWallet(ctx context.Context) (*ecdsa.PrivateKey, common.Address, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, common.Address{}, err
+	}
+
 	privateKey, err := crypto.GenerateKey()
 	if err != nil {
 		return nil, common.Address{}, err
@@ -62,8 +85,10 @@ func (c *EthereumClient) CreateWallet() (*ecdsa.PrivateKey, common.Address, erro
 
 // GetBalance retrieves the balance of the given Ethereum address.
 // It returns the balance as a big.Int and any error encountered.
-func (c *EthereumClient) GetBalance(address common.Address) (*big.Int, error) {
-	balance, err := c.client.BalanceAt(context.Background(), address, nil)
+func (c *EthereumClient) GetBalance(ctx context.Context, address common.Address) (*big.Int, error) {
+	balance, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.BalanceAt(ctx, address, nil)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get balance: %w", err)
 	}
@@ -74,26 +99,55 @@ func (c *EthereumClient) GetBalance(address common.Address) (*big.Int, error) {
 // CreateUnsignedTransaction creates an unsigned Ethereum transaction.
 // It takes the sender's address, recipient's address, and the amount to send.
 // Returns the unsigned transaction and any error encountered.
-func (c *EthereumClient) CreateUnsignedTransaction(from common.Address, to common.Address, amount *big.Int) (*types.Transaction, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func (c *EthereumClient) CreateUnsignedTransaction(ctx context.Context, from common.Address, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return c.CreateUnsignedContractTransaction(ctx, from, to, amount, nil)
+}
+
+// CreateUnsignedContractTransaction creates an unsigned Ethereum transaction carrying call data.
+// Unlike plain transfers, a failed gas estimation is returned as an error since the call would likely revert.
+func (c *EthereumClient) CreateUnsignedContractTransaction(ctx context.Context, from common.Address, to common.Address, amount *big.Int, data []byte) (*types.Transaction, error) {
+	nonce, err := c.GetPendingNonce(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.CreateUnsignedTransactionWithNonce(ctx, from, to, amount, data, nonce)
+}
 
-	nonce, err := c.client.PendingNonceAt(ctx, from)
+// GetPendingNonce returns the next nonce for the address, including transactions still in the mempool.
+func (c *EthereumClient) GetPendingNonce(ctx context.Context, address common.Address) (uint64, error) {
+	nonce, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.PendingNonceAt(ctx, address)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get nonce: %w", err)
+		return 0, fmt.Errorf("failed to get nonce: %w", err)
 	}
 
-	gasPrice, err := c.client.SuggestGasPrice(ctx)
+	return nonce, nil
+}
+
+// CreateUnsignedTransactionWithNonce creates an unsigned Ethereum transaction using the given nonce.
+// It lets callers send several transactions from the same address without waiting for each to land.
+func (c *EthereumClient) CreateUnsignedTransactionWithNonce(ctx context.Context, from common.Address, to common.Address, amount *big.Int, data []byte, nonce uint64) (*types.Transaction, error) {
+	gasPrice, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.SuggestGasPrice(ctx)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to suggest gas price: %w", err)
 	}
 
-	gasLimit, err := c.client.EstimateGas(ctx, ethereum.CallMsg{
-		From:  from,
-		To:    &to,
-		Value: amount,
+	gasLimit, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.EstimateGas(ctx, ethereum.CallMsg{
+			From:  from,
+			To:    &to,
+			Value: amount,
+			Data:  data,
+		})
 	})
 	if err != nil {
+		if len(data) > 0 {
+			return nil, fmt.Errorf("failed to estimate gas: %w", err)
+		}
 		// If estimation fails, use a default value
 		gasLimit = uint64(21000)
 	}
@@ -104,6 +158,7 @@ func (c *EthereumClient) CreateUnsignedTransaction(from common.Address, to commo
 		Value:    amount,
 		Gas:      gasLimit,
 		GasPrice: gasPrice,
+		Data:     data,
 	}
 
 	return types.NewTx(txData), nil
@@ -111,13 +166,12 @@ func (c *EthereumClient) CreateUnsignedTransaction(from common.Address, to commo
 
 // SignTransaction signs an Ethereum transaction with the given private key.
 // It returns the signed transaction and any error encountered.
-func (c *EthereumClient) SignTransaction(tx *types.Transaction, privateKey *ecdsa.PrivateKey) (*types.Transaction, error) {
-	chainID, err := c.client.NetworkID(context.Background())
+func (c *EthereumClient) SignTransaction(ctx context.Context, tx *types.Transaction, privateKey *ecdsa.PrivateKey) (*types.Transaction, error) {
+	signer, err := c.signer(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get network ID: %w", err)
+		return nil, err
 	}
 
-	signer := types.NewEIP155Signer(chainID)
 	signedTx, err := types.SignTx(tx, signer, privateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to sign transaction: %w", err)
@@ -126,10 +180,46 @@ func (c *EthereumClient) SignTransaction(tx *types.Transaction, privateKey *ecds
 	return signedTx, nil
 }
 
+// SigningHash returns the hash a key signs to authorize the transaction, for signing it elsewhere.
+func (c *EthereumClient) SigningHash(ctx context.Context, tx *types.Transaction) (common.Hash, error) {
+	signer, err := c.signer(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return signer.Hash(tx), nil
+}
+
+// AddSignature attaches a 65-byte R || S || V signature of the transaction's signing hash.
+func (c *EthereumClient) AddSignature(ctx context.Context, tx *types.Transaction, signature []byte) (*types.Transaction, error) {
+	signer, err := c.signer(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	signedTx, err := tx.WithSignature(signer, signature)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add signature: %w", err)
+	}
+	return signedTx, nil
+}
+
+// signer returns the EIP-155 signer of the connected network.
+func (c *EthereumClient) signer(ctx context.Context) (types.Signer, error) {
+	chainID, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (*big.Int, error) {
+		return client.NetworkID(ctx)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get network ID: %w", err)
+	}
+	return types.NewEIP155Signer(chainID), nil
+}
+
 // SubmitTransaction submits a signed transaction to the Ethereum network.
 // It returns the transaction hash and any error encountered.
-func (c *EthereumClient) SubmitTransaction(signedTx *types.Transaction) (common.Hash, error) {
-	err := c.client.SendTransaction(context.Background(), signedTx)
+func (c *EthereumClient) SubmitTransaction(ctx context.Context, signedTx *types.Transaction) (common.Hash, error) {
+	err := c.pool.write(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		return client.SendTransaction(ctx, signedTx)
+	})
 	if err != nil {
 		return common.Hash{}, fmt.Errorf("failed to send transaction: %w", err)
 	}
@@ -139,8 +229,10 @@ func (c *EthereumClient) SubmitTransaction(signedTx *types.Transaction) (common.
 
 // WaitForTxn waits for a transaction to be mined and returns its receipt.
 // It takes the transaction hash and returns the transaction receipt and any error encountered.
-func (c *EthereumClient) WaitForTxn(hash common.Hash) (*types.Receipt, error) {
-	receipt, err := c.client.TransactionReceipt(context.Background(), hash)
+func (c *EthereumClient) WaitForTxn(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	receipt, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (*types.Receipt, error) {
+		return client.TransactionReceipt(ctx, hash)
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
 	}
@@ -150,7 +242,11 @@ func (c *EthereumClient) WaitForTxn(hash common.Hash) (*types.Receipt, error) {
 
 // EncryptPrivateKey encrypts the given private key data using AES-GCM encryption.
 // It returns the encrypted data and any error encountered.
-func (c *EthereumClient) EncryptPrivateKey(data []byte) ([]byte, error) {
+func (c *EthereumClient) EncryptPrivateKey(ctx context.Context, data []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Define or retrieve the AES key (this should be securely stored and managed)
 	aesKey := []byte(c.secretKey)
 
@@ -180,7 +276,11 @@ func (c *EthereumClient) EncryptPrivateKey(data []byte) ([]byte, error) {
 
 // DecryptPrivateKey decrypts the given encrypted private key data using AES-GCM.
 // It returns the decrypted data and any error encountered.
-func (c *EthereumClient) DecryptPrivateKey(ciphertext []byte) ([]byte, error) {
+func (c *EthereumClient) DecryptPrivateKey(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	aesKey := []byte(c.secretKey)
 
 	block, err := aes.NewCipher(aesKey)
@@ -210,34 +310,25 @@ func (c *EthereumClient) DecryptPrivateKey(ciphertext []byte) ([]byte, error) {
 	return plaintext, nil
 }
 
+// GetTransactionReceipt waits for the transaction to be mined, looking its receipt up once per new block.
 func (c *EthereumClient) GetTransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
-	ticker := time.NewTicker(5 * time.Second) // Poll every 5 seconds
-	defer ticker.Stop()
-
-	timeout := time.After(5 * time.Minute) // Set a 5-minute timeout
-
-	for {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-timeout:
-			return nil, fmt.Errorf("timeout waiting for transaction receipt")
-		case <-ticker.C:
-			receipt, err := c.client.TransactionReceipt(ctx, hash)
-			if err == nil {
-				return receipt, nil
-			}
-			if err != ethereum.NotFound {
-				return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
-			}
-
-			// If the transaction is successful, return the receipt
-			if receipt.Status == 1 {
-				return receipt, nil
-			}
-			// If err == ethereum.NotFound, continue polling
+	ctx, cancel := context.WithTimeout(ctx, c.receiptTimeout)
+	defer cancel()
+
+	for range c.WatchChain(ctx, nil, receiptPollInterval) {
+		receipt, err := c.FindTransactionReceipt(ctx, hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
 		}
+		if receipt != nil {
+			return receipt, nil
+		}
+	}
+
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("timeout waiting for transaction receipt")
 	}
+	return nil, ctx.Err()
 }
 
 func (c *EthereumClient) ParseTransactionReceipt(receipt *types.Receipt) {
@@ -0,0 +1,122 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"mpc/internal/infrastructure/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestCallTimeouts(t *testing.T) {
+	// hang is how long a hung endpoint takes to answer; every call must give up well before
+	const hang = 2 * time.Second
+
+	address := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	getBalance := func(ctx context.Context, c *EthereumClient) error {
+		_, err := c.GetBalance(ctx, address)
+		return err
+	}
+	submit := func(ctx context.Context, c *EthereumClient) error {
+		_, err := c.SubmitTransaction(ctx, types.NewTx(&types.LegacyTx{To: &address, Value: big.NewInt(1), Gas: 21_000, GasPrice: big.NewInt(1)}))
+		return err
+	}
+	waitForReceipt := func(ctx context.Context, c *EthereumClient) error {
+		_, err := c.GetTransactionReceipt(ctx, common.HexToHash("0x01"))
+		return err
+	}
+
+	tests := []struct {
+		name  string
+		cfg   config.EthereumConfig
+		delay time.Duration
+		// cancelAfter cancels the caller's context after the given time
+		cancelAfter time.Duration
+		call        func(ctx context.Context, c *EthereumClient) error
+		// wantErr is the error the call ends with, matched with errors.Is; nil means success
+		wantErr error
+		// wantMessage is part of the error message, when the error has no sentinel
+		wantMessage string
+	}{
+		{
+			name:  "read answered in time",
+			cfg:   config.EthereumConfig{ReadTimeout: time.Second},
+			delay: 10 * time.Millisecond,
+			call:  getBalance,
+		},
+		{
+			name:    "hung read bounded by ReadTimeout",
+			cfg:     config.EthereumConfig{ReadTimeout: 50 * time.Millisecond},
+			delay:   hang,
+			call:    getBalance,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			name:    "hung submission bounded by SubmitTimeout",
+			cfg:     config.EthereumConfig{ReadTimeout: time.Minute, SubmitTimeout: 50 * time.Millisecond},
+			delay:   hang,
+			call:    submit,
+			wantErr: context.DeadlineExceeded,
+		},
+		{
+			// The caller's cancellation, such as a closed HTTP request, ends the call before its timeout
+			name:        "read cancelled by the caller",
+			cfg:         config.EthereumConfig{ReadTimeout: time.Minute, MaxRetries: 3, RetryBackoff: time.Minute},
+			delay:       hang,
+			cancelAfter: 50 * time.Millisecond,
+			call:        getBalance,
+			wantErr:     context.Canceled,
+		},
+		{
+			name:        "receipt wait bounded by ReceiptTimeout",
+			cfg:         config.EthereumConfig{ReadTimeout: time.Second, ReceiptTimeout: 100 * time.Millisecond},
+			call:        waitForReceipt,
+			wantMessage: "timeout waiting for transaction receipt",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node := newStubNode(t)
+			node.delay.Store(int64(tt.delay))
+			tt.cfg.URL = node.url
+			client, err := NewEthereumClient(&tt.cfg)
+			if err != nil {
+				t.Fatalf("NewEthereumClient() error = %v", err)
+			}
+			t.Cleanup(client.Close)
+
+			ctx := context.Background()
+			if tt.cancelAfter > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithCancel(ctx)
+				time.AfterFunc(tt.cancelAfter, cancel)
+			}
+
+			start := time.Now()
+			err = tt.call(ctx, client)
+			if elapsed := time.Since(start); elapsed > hang/2 {
+				t.Errorf("call took %s, want it bounded by its timeout", elapsed)
+			}
+			switch {
+			case tt.wantErr == nil && tt.wantMessage == "":
+				if err != nil {
+					t.Errorf("call error = %v, want none", err)
+				}
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("call error = %v, want %v", err, tt.wantErr)
+				}
+			default:
+				if err == nil || !strings.Contains(err.Error(), tt.wantMessage) {
+					t.Errorf("call error = %v, want %q", err, tt.wantMessage)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"mpc/internal/infrastructure/config"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// healthCheckTimeout bounds the block number request of a single health check.
+const healthCheckTimeout = 5 * time.Second
+
+// ewmaWeight is the weight of the newest sample in the latency and error rate averages.
+const ewmaWeight = 0.2
+
+// endpoint is one RPC node of the pool together with its health statistics.
+type endpoint struct {
+	url    string
+	client *ethclient.Client
+
+	mu        sync.Mutex
+	latency   time.Duration
+	errorRate float64
+	head      uint64
+	// coolingUntil is when the endpoint may be used again after its last transport failure
+	coolingUntil time.Time
+}
+
+// record folds the outcome of one call into the endpoint's averages.
+// Only transport failures count as errors; answers from the node, such as a revert, do not. A
+// transport failure also puts the endpoint in cooldown.
+func (e *endpoint) record(elapsed time.Duration, err error, cooldown time.Duration) {
+	failed := 0.0
+	if isTransportError(err) {
+		failed = 1
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if failed == 1 && cooldown > 0 {
+		e.coolingUntil = time.Now().Add(cooldown)
+	}
+	if e.latency == 0 {
+		e.latency = elapsed
+	} else {
+		e.latency = time.Duration(ewmaWeight*float64(elapsed) + (1-ewmaWeight)*float64(e.latency))
+	}
+	e.errorRate = ewmaWeight*failed + (1-ewmaWeight)*e.errorRate
+}
+
+// score ranks the endpoint, lower being better. Endpoints lagging the best known head by more than
+// maxLag blocks rank behind every endpoint that is in sync, and endpoints in cooldown behind those.
+// An endpoint whose cooldown is over forgets its averages, so it is tried again as if it were new.
+func (e *endpoint) score(bestHead uint64, maxLag uint64, now time.Time) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.coolingUntil.IsZero() && !now.Before(e.coolingUntil) {
+		e.coolingUntil = time.Time{}
+		e.latency = 0
+		e.errorRate = 0
+	}
+
+	score := e.errorRate*1000 + float64(e.latency.Milliseconds())
+	if bestHead > e.head && bestHead-e.head > maxLag {
+		score += 1e6
+	}
+	if !e.coolingUntil.IsZero() {
+		score += 1e7
+	}
+	return score
+}
+
+// rpcPool spreads calls over several RPC endpoints of one chain. Endpoints are ranked by cooldown,
+// block lag, error rate and latency, kept current by a periodic health check. Idempotent reads fail over to the
+// next endpoint and are retried with exponential backoff; writes go to the best endpoint only.
+type rpcPool struct {
+	endpoints []*endpoint
+	cfg       *config.EthereumConfig
+	stop      chan struct{}
+}
+
+func newRPCPool(cfg *config.EthereumConfig) (*rpcPool, error) {
+	urls := append([]string{cfg.URL}, cfg.FallbackURLs...)
+
+	pool := &rpcPool{cfg: cfg, stop: make(chan struct{})}
+	var dialErr error
+	for _, url := range urls {
+		if url == "" {
+			continue
+		}
+		client, err := ethclient.Dial(url)
+		if err != nil {
+			dialErr = err
+			log.Printf("Skipping RPC endpoint %s: %v", url, err)
+			continue
+		}
+		pool.endpoints = append(pool.endpoints, &endpoint{url: url, client: client})
+	}
+
+	if len(pool.endpoints) == 0 {
+		if dialErr == nil {
+			dialErr = errors.New("no RPC URL configured")
+		}
+		return nil, dialErr
+	}
+
+	if len(pool.endpoints) > 1 && cfg.HealthCheckInterval > 0 {
+		pool.checkHealth()
+		go pool.runHealthChecks()
+	}
+	return pool, nil
+}
+
+func (p *rpcPool) runHealthChecks() {
+	ticker := time.NewTicker(p.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stop:
+			return
+		case <-ticker.C:
+			p.checkHealth()
+		}
+	}
+}
+
+// checkHealth asks every endpoint for its latest block, recording latency, errors and head.
+func (p *rpcPool) checkHealth() {
+	var wg sync.WaitGroup
+	for _, e := range p.endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+			defer cancel()
+
+			start := time.Now()
+			head, err := e.client.BlockNumber(ctx)
+			e.record(time.Since(start), err, p.cfg.Cooldown)
+			if err != nil {
+				return
+			}
+			e.mu.Lock()
+			e.head = head
+			e.mu.Unlock()
+		}(e)
+	}
+	wg.Wait()
+}
+
+// ranked returns the endpoints from healthiest to least healthy.
+func (p *rpcPool) ranked() []*endpoint {
+	var bestHead uint64
+	for _, e := range p.endpoints {
+		e.mu.Lock()
+		if e.head > bestHead {
+			bestHead = e.head
+		}
+		e.mu.Unlock()
+	}
+
+	now := time.Now()
+	scores := make(map[*endpoint]float64, len(p.endpoints))
+	for _, e := range p.endpoints {
+		scores[e] = e.score(bestHead, p.cfg.MaxBlockLag, now)
+	}
+
+	ranked := append([]*endpoint(nil), p.endpoints...)
+	sort.SliceStable(ranked, func(i, j int) bool { return scores[ranked[i]] < scores[ranked[j]] })
+	return ranked
+}
+
+// read runs an idempotent call, moving to the next endpoint after a transport failure and backing
+// off exponentially between attempts. Each attempt is bounded by ReadTimeout, so a hung endpoint
+// counts as a failure instead of blocking the caller.
+func (p *rpcPool) read(ctx context.Context, call func(ctx context.Context, client *ethclient.Client) error) error {
+	ranked := p.ranked()
+
+	var err error
+	for attempt := 0; attempt <= p.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			backoff := p.cfg.RetryBackoff << (attempt - 1)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+
+		e := ranked[attempt%len(ranked)]
+		err = p.call(ctx, e, p.cfg.ReadTimeout, call)
+		if !isTransportError(err) || ctx.Err() != nil {
+			return err
+		}
+		log.Printf("RPC call to %s failed (attempt %d): %v", e.url, attempt+1, err)
+	}
+	return fmt.Errorf("all RPC attempts failed: %w", err)
+}
+
+// readCall is read for calls returning a value.
+func readCall[T any](ctx context.Context, p *rpcPool, call func(ctx context.Context, client *ethclient.Client) (T, error)) (T, error) {
+	var result T
+	err := p.read(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		var err error
+		result, err = call(ctx, client)
+		return err
+	})
+	return result, err
+}
+
+// write runs a call that must not be repeated, such as sending a transaction, on the best endpoint
+// within SubmitTimeout.
+func (p *rpcPool) write(ctx context.Context, call func(ctx context.Context, client *ethclient.Client) error) error {
+	return p.call(ctx, p.ranked()[0], p.cfg.SubmitTimeout, call)
+}
+
+func (p *rpcPool) call(ctx context.Context, e *endpoint, timeout time.Duration, call func(ctx context.Context, client *ethclient.Client) error) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := call(ctx, e.client)
+	e.record(time.Since(start), err, p.cfg.Cooldown)
+	return err
+}
+
+func (p *rpcPool) close() {
+	close(p.stop)
+	for _, e := range p.endpoints {
+		e.client.Close()
+	}
+}
+
+// isTransportError reports whether err means the endpoint could not serve the call, as opposed to
+// the node answering with an error or a missing result.
+func isTransportError(err error) bool {
+	if err == nil || errors.Is(err, ethereum.NotFound) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var rpcErr rpc.Error
+	return !errors.As(err, &rpcErr)
+}
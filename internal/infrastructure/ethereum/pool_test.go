@@ -0,0 +1,207 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mpc/internal/infrastructure/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// stubNode is an RPC endpoint answering eth_blockNumber, and eth_getBalance, eth_sendRawTransaction
+// and eth_getTransactionReceipt for a chain where nothing is ever mined. It can be made slow or made
+// to fail with HTTP 503, and counts the block number calls it served.
+type stubNode struct {
+	url     string
+	delay   atomic.Int64
+	failing atomic.Bool
+	calls   atomic.Int64
+}
+
+type stubEth struct {
+	node *stubNode
+}
+
+func (s stubEth) BlockNumber() hexutil.Uint64 {
+	s.node.calls.Add(1)
+	return 100
+}
+
+func (s stubEth) GetBalance(address common.Address, block string) *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(1))
+}
+
+func (s stubEth) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	return tx.Hash(), nil
+}
+
+func (s stubEth) GetTransactionReceipt(hash common.Hash) *types.Receipt {
+	return nil
+}
+
+func newStubNode(t *testing.T) *stubNode {
+	t.Helper()
+
+	node := &stubNode{}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", stubEth{node: node}); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if node.failing.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		select {
+		case <-time.After(time.Duration(node.delay.Load())):
+		case <-r.Context().Done():
+			return
+		}
+		server.ServeHTTP(w, r)
+	}))
+	t.Cleanup(httpServer.Close)
+	t.Cleanup(server.Stop)
+
+	node.url = httpServer.URL
+	return node
+}
+
+// newTestPool returns a pool over the nodes, in order, without background health checks.
+func newTestPool(t *testing.T, cfg config.EthereumConfig, nodes ...*stubNode) *rpcPool {
+	t.Helper()
+
+	cfg.URL = nodes[0].url
+	for _, node := range nodes[1:] {
+		cfg.FallbackURLs = append(cfg.FallbackURLs, node.url)
+	}
+	cfg.HealthCheckInterval = 0
+	pool, err := newRPCPool(&cfg)
+	if err != nil {
+		t.Fatalf("newRPCPool() error = %v", err)
+	}
+	t.Cleanup(pool.close)
+	return pool
+}
+
+func blockNumber(ctx context.Context, pool *rpcPool) (uint64, error) {
+	return readCall(ctx, pool, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.BlockNumber(ctx)
+	})
+}
+
+func TestRPCPool(t *testing.T) {
+	cfg := config.EthereumConfig{
+		MaxRetries:    2,
+		RetryBackoff:  time.Millisecond,
+		ReadTimeout:   200 * time.Millisecond,
+		SubmitTimeout: 200 * time.Millisecond,
+		Cooldown:      300 * time.Millisecond,
+	}
+
+	tests := []struct {
+		name string
+		// breakPrimary makes the primary endpoint unhealthy
+		breakPrimary func(primary *stubNode)
+	}{
+		{name: "erroring endpoint", breakPrimary: func(primary *stubNode) { primary.failing.Store(true) }},
+		{name: "hung endpoint", breakPrimary: func(primary *stubNode) { primary.delay.Store(int64(time.Second)) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			primary, fallback := newStubNode(t), newStubNode(t)
+			pool := newTestPool(t, cfg, primary, fallback)
+
+			// A slow first health check ranks the fallback behind the primary
+			fallback.delay.Store(int64(10 * time.Millisecond))
+			pool.checkHealth()
+			fallback.delay.Store(0)
+			fallback.calls.Store(0)
+
+			if _, err := blockNumber(ctx, pool); err != nil {
+				t.Fatalf("blockNumber() error = %v", err)
+			}
+			if primary.calls.Load() != 2 || fallback.calls.Load() != 0 {
+				t.Fatalf("healthy pool served %d/%d calls from primary/fallback, want 2/0", primary.calls.Load(), fallback.calls.Load())
+			}
+
+			// The call fails over to the fallback, and the primary is demoted
+			tt.breakPrimary(primary)
+			if _, err := blockNumber(ctx, pool); err != nil {
+				t.Fatalf("blockNumber() error = %v, want a failover", err)
+			}
+			if fallback.calls.Load() != 1 {
+				t.Fatalf("fallback served %d calls, want 1", fallback.calls.Load())
+			}
+			if best := pool.ranked()[0]; best.url != fallback.url {
+				t.Fatalf("best endpoint = %s, want the fallback %s", best.url, fallback.url)
+			}
+
+			// Once healthy again the primary stays demoted until its cooldown is over
+			primary.failing.Store(false)
+			primary.delay.Store(0)
+			served := primary.calls.Load()
+			if _, err := blockNumber(ctx, pool); err != nil {
+				t.Fatalf("blockNumber() error = %v", err)
+			}
+			if primary.calls.Load() != served {
+				t.Fatal("primary was used during its cooldown")
+			}
+
+			time.Sleep(cfg.Cooldown)
+			if best := pool.ranked()[0]; best.url != primary.url {
+				t.Fatalf("best endpoint after cooldown = %s, want the primary %s", best.url, primary.url)
+			}
+			if _, err := blockNumber(ctx, pool); err != nil {
+				t.Fatalf("blockNumber() error = %v", err)
+			}
+			if primary.calls.Load() != served+1 {
+				t.Fatal("primary was not used after its cooldown")
+			}
+		})
+	}
+}
+
+func TestRPCPoolSlowEndpoint(t *testing.T) {
+	ctx := context.Background()
+	primary, fallback := newStubNode(t), newStubNode(t)
+	primary.delay.Store(int64(50 * time.Millisecond))
+	pool := newTestPool(t, config.EthereumConfig{ReadTimeout: time.Second, Cooldown: time.Minute}, primary, fallback)
+
+	// Both endpoints answer; the slower one ranks behind once its latency is known
+	pool.checkHealth()
+	if best := pool.ranked()[0]; best.url != fallback.url {
+		t.Fatalf("best endpoint = %s, want the faster fallback %s", best.url, fallback.url)
+	}
+	if _, err := blockNumber(ctx, pool); err != nil {
+		t.Fatalf("blockNumber() error = %v", err)
+	}
+	if fallback.calls.Load() != 2 {
+		t.Fatalf("fallback served %d calls, want the health check and the read", fallback.calls.Load())
+	}
+}
+
+func TestRPCPoolAllEndpointsDown(t *testing.T) {
+	primary, fallback := newStubNode(t), newStubNode(t)
+	primary.failing.Store(true)
+	fallback.failing.Store(true)
+	pool := newTestPool(t, config.EthereumConfig{MaxRetries: 2, RetryBackoff: time.Millisecond, ReadTimeout: time.Second}, primary, fallback)
+
+	if _, err := blockNumber(context.Background(), pool); err == nil {
+		t.Fatal("blockNumber() succeeded with every endpoint down")
+	}
+}
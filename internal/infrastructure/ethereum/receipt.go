@@ -0,0 +1,126 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"mpc/internal/domain"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// GetRevertReason replays a reverted transaction against the state before its block and decodes
+// the reason it reverted. Reverts without an Error(string) payload are returned as raw hex data.
+func (c *EthereumClient) GetRevertReason(ctx context.Context, hash common.Hash, blockNumber *big.Int) (string, error) {
+	tx, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (*types.Transaction, error) {
+		tx, _, err := client.TransactionByHash(ctx, hash)
+		return tx, err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return "", fmt.Errorf("failed to recover sender: %w", err)
+	}
+
+	msg := ethereum.CallMsg{
+		From:     from,
+		To:       tx.To(),
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	}
+	parent := new(big.Int).Sub(blockNumber, big.NewInt(1))
+
+	_, err = readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) ([]byte, error) {
+		return client.CallContract(ctx, msg, parent)
+	})
+	if err == nil {
+		// The call succeeds on the parent state, e.g. because it depended on an earlier transaction in the block
+		return "", nil
+	}
+
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err.Error(), nil
+	}
+
+	data, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err.Error(), nil
+	}
+
+	revertData, decodeErr := hexutil.Decode(data)
+	if decodeErr != nil {
+		return err.Error(), nil
+	}
+
+	if reason, unpackErr := abi.UnpackRevert(revertData); unpackErr == nil {
+		return reason, nil
+	}
+	return data, nil
+}
+
+// HasTransaction reports whether the node knows a transaction, mined or waiting in its mempool.
+func (c *EthereumClient) HasTransaction(ctx context.Context, hash common.Hash) (bool, error) {
+	err := c.pool.read(ctx, func(ctx context.Context, client *ethclient.Client) error {
+		_, _, err := client.TransactionByHash(ctx, hash)
+		return err
+	})
+	if errors.Is(err, ethereum.NotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get transaction: %w", err)
+	}
+	return true, nil
+}
+
+// GetTransactionState tells whether a submitted transaction without a receipt is still in the mempool,
+// was dropped by the node, or lost its nonce to another transaction from the same sender.
+func (c *EthereumClient) GetTransactionState(ctx context.Context, hash common.Hash, from common.Address, nonce uint64) (domain.TxState, error) {
+	var isPending bool
+	err := c.pool.read(ctx, func(ctx context.Context, client *ethclient.Client) (err error) {
+		_, isPending, err = client.TransactionByHash(ctx, hash)
+		return err
+	})
+	if err == nil {
+		if isPending {
+			return domain.TxStatePending, nil
+		}
+		return domain.TxStateMined, nil
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return "", fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	confirmedNonce, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.NonceAt(ctx, from, nil)
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get nonce: %w", err)
+	}
+	if confirmedNonce <= nonce {
+		return domain.TxStateDropped, nil
+	}
+
+	// The transaction may have been mined between the two lookups
+	_, err = readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (*types.Receipt, error) {
+		return client.TransactionReceipt(ctx, hash)
+	})
+	if err == nil {
+		return domain.TxStateMined, nil
+	}
+	return domain.TxStateReplaced, nil
+}
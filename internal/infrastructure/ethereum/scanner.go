@@ -0,0 +1,141 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"mpc/internal/domain"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// transferEventTopic is the topic of the ERC-20 Transfer(address,address,uint256) event.
+var transferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// GetLatestBlockNumber returns the number of the most recent block.
+func (c *EthereumClient) GetLatestBlockNumber(ctx context.Context) (uint64, error) {
+	number, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (uint64, error) {
+		return client.BlockNumber(ctx)
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get latest block number: %w", err)
+	}
+	return number, nil
+}
+
+// GetBlockHash returns the hash of the canonical block at the given height.
+func (c *EthereumClient) GetBlockHash(ctx context.Context, number uint64) (common.Hash, error) {
+	header, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (*types.Header, error) {
+		return client.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	})
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get header of block %d: %w", number, err)
+	}
+	return header.Hash(), nil
+}
+
+// FindTransactionReceipt returns the receipt of a mined transaction, or nil if it is not in a block yet.
+// Unlike GetTransactionReceipt it does not wait for the transaction to be mined.
+func (c *EthereumClient) FindTransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	receipt, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (*types.Receipt, error) {
+		return client.TransactionReceipt(ctx, hash)
+	})
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction receipt: %w", err)
+	}
+	return receipt, nil
+}
+
+// ScanBlock finds native transfers and ERC-20 Transfer logs of the given tokens into watched addresses.
+// Native transfers made by contracts (internal transactions) are not visible here.
+func (c *EthereumClient) ScanBlock(ctx context.Context, number uint64, watched map[common.Address]bool, tokens []common.Address) (domain.ScannedBlock, error) {
+	block, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (*types.Block, error) {
+		return client.BlockByNumber(ctx, new(big.Int).SetUint64(number))
+	})
+	if err != nil {
+		return domain.ScannedBlock{}, fmt.Errorf("failed to get block %d: %w", number, err)
+	}
+
+	scanned := domain.ScannedBlock{
+		Number:     block.NumberU64(),
+		Hash:       block.Hash(),
+		ParentHash: block.ParentHash(),
+	}
+
+	for _, tx := range block.Transactions() {
+		if tx.To() == nil || !watched[*tx.To()] || tx.Value().Sign() <= 0 {
+			continue
+		}
+
+		receipt, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) (*types.Receipt, error) {
+			return client.TransactionReceipt(ctx, tx.Hash())
+		})
+		if err != nil {
+			return domain.ScannedBlock{}, fmt.Errorf("failed to get receipt of %s: %w", tx.Hash().Hex(), err)
+		}
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			continue
+		}
+
+		from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+		if err != nil {
+			return domain.ScannedBlock{}, fmt.Errorf("failed to recover sender of %s: %w", tx.Hash().Hex(), err)
+		}
+
+		scanned.Deposits = append(scanned.Deposits, domain.Deposit{
+			TxHash:   tx.Hash(),
+			LogIndex: domain.NativeTransferLogIndex,
+			From:     from,
+			To:       *tx.To(),
+			Amount:   tx.Value(),
+		})
+	}
+
+	if len(tokens) == 0 {
+		return scanned, nil
+	}
+
+	hash := block.Hash()
+	logs, err := readCall(ctx, c.pool, func(ctx context.Context, client *ethclient.Client) ([]types.Log, error) {
+		return client.FilterLogs(ctx, ethereum.FilterQuery{
+			BlockHash: &hash,
+			Addresses: tokens,
+			Topics:    [][]common.Hash{{transferEventTopic}},
+		})
+	})
+	if err != nil {
+		return domain.ScannedBlock{}, fmt.Errorf("failed to get Transfer logs of block %d: %w", number, err)
+	}
+
+	for _, log := range logs {
+		// ERC-721 transfers share the event signature but index the token ID as a fourth topic
+		if log.Removed || len(log.Topics) != 3 {
+			continue
+		}
+
+		to := common.BytesToAddress(log.Topics[2].Bytes())
+		if !watched[to] {
+			continue
+		}
+
+		scanned.Deposits = append(scanned.Deposits, domain.Deposit{
+			TxHash:   log.TxHash,
+			LogIndex: int(log.Index),
+			From:     common.BytesToAddress(log.Topics[1].Bytes()),
+			To:       to,
+			Token:    log.Address,
+			Amount:   new(big.Int).SetBytes(log.Data),
+		})
+	}
+
+	return scanned, nil
+}
@@ -0,0 +1,194 @@
+// Package simchain runs an in-process Ethereum chain for integration tests.
+//
+// Chain is an EthereumClient connected over IPC to go-ethereum's simulated backend, so it implements
+// EthereumRepository exactly as a client of a real node would, subscriptions included. Blocks are only
+// produced on demand, and helpers fund wallets, mine blocks and deploy a test ERC-20.
+package simchain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/infrastructure/ethereum"
+	"mpc/internal/repository"
+
+	geth "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ChainID is the chain ID of every simulated chain.
+var ChainID = params.AllDevChainProtocolChanges.ChainID
+
+// faucetBalance is the genesis balance of the account that funds wallets and deploys contracts.
+var faucetBalance = new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(params.Ether))
+
+// erc20Bytecode deploys a minimal ERC-20 with 18 decimals. It supports balanceOf, transfer and an
+// unrestricted mint(address,uint256), and emits Transfer for transfers and mints; minting is a
+// transfer from the zero address. Balances are stored at the slot equal to the holder's address.
+var erc20Bytecode = common.FromHex("6100af8061000d6000396000f360003560e01c806370a0823114610036578063a9059cbb1461004e57806340c10f1914610065578063313ce5671461004357600080fd5b6004355460005260206000f35b601260005260206000f35b60243533548181106100aa5781900333553361006b565b60243560005b60043580548301815582600052907fddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef60206000a3600160005260206000f35b600080fd")
+
+// mintSelector is the selector of mint(address,uint256).
+var mintSelector = crypto.Keccak256([]byte("mint(address,uint256)"))[:4]
+
+// Chain is a simulated chain together with an EthereumClient connected to it.
+type Chain struct {
+	*ethereum.EthereumClient
+
+	backend *simulated.Backend
+	faucet  *ecdsa.PrivateKey
+	dir     string
+	cfg     config.EthereumConfig
+}
+
+// Ensure Chain implements EthereumRepository
+var _ repository.EthereumRepository = (*Chain)(nil)
+
+// New starts a simulated chain and connects a client to it. The URLs of cfg are replaced by the
+// chain's IPC endpoint; the rest of cfg, such as the secret key and timeouts, is used as is.
+func New(cfg config.EthereumConfig) (*Chain, error) {
+	faucet, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "simchain")
+	if err != nil {
+		return nil, err
+	}
+	ipcPath := filepath.Join(dir, "geth.ipc")
+
+	backend := simulated.NewBackend(
+		types.GenesisAlloc{crypto.PubkeyToAddress(faucet.PublicKey): {Balance: faucetBalance}},
+		func(nodeConf *node.Config, ethConf *ethconfig.Config) {
+			nodeConf.IPCPath = ipcPath
+		},
+	)
+
+	cfg.URL = ipcPath
+	cfg.FallbackURLs = nil
+	client, err := ethereum.NewEthereumClient(&cfg)
+	if err != nil {
+		backend.Close()
+		os.RemoveAll(dir)
+		return nil, err
+	}
+
+	return &Chain{EthereumClient: client, backend: backend, faucet: faucet, dir: dir, cfg: cfg}, nil
+}
+
+// URL returns the endpoint of the chain, for components that connect on their own.
+func (c *Chain) URL() string {
+	return c.cfg.URL
+}
+
+// Config returns the configuration the chain's client was created with.
+func (c *Chain) Config() config.EthereumConfig {
+	return c.cfg
+}
+
+// Close disconnects the client and stops the chain.
+func (c *Chain) Close() error {
+	c.EthereumClient.Close()
+	err := c.backend.Close()
+	return errors.Join(err, os.RemoveAll(c.dir))
+}
+
+// Mine seals the given number of blocks, including pending transactions in the first one.
+func (c *Chain) Mine(blocks int) {
+	for i := 0; i < blocks; i++ {
+		c.backend.Commit()
+	}
+}
+
+// Fund sends amount wei from the faucet to the address and mines the transfer.
+func (c *Chain) Fund(ctx context.Context, to common.Address, amount *big.Int) error {
+	_, err := c.send(ctx, &to, amount, nil)
+	if err != nil {
+		return fmt.Errorf("failed to fund %s: %w", to.Hex(), err)
+	}
+	return nil
+}
+
+// DeployERC20 deploys the test ERC-20 and returns its address.
+func (c *Chain) DeployERC20(ctx context.Context) (common.Address, error) {
+	receipt, err := c.send(ctx, nil, new(big.Int), erc20Bytecode)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to deploy ERC-20: %w", err)
+	}
+	return receipt.ContractAddress, nil
+}
+
+// MintERC20 mints amount tokens of the test ERC-20 to the address and mines the mint.
+func (c *Chain) MintERC20(ctx context.Context, token common.Address, to common.Address, amount *big.Int) error {
+	data := append([]byte{}, mintSelector...)
+	data = append(data, common.LeftPadBytes(to.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+
+	if _, err := c.send(ctx, &token, new(big.Int), data); err != nil {
+		return fmt.Errorf("failed to mint to %s: %w", to.Hex(), err)
+	}
+	return nil
+}
+
+// send signs a transaction from the faucet, mines it and returns its receipt.
+// A nil to deploys data as a contract.
+func (c *Chain) send(ctx context.Context, to *common.Address, value *big.Int, data []byte) (*types.Receipt, error) {
+	client := c.backend.Client()
+	from := crypto.PubkeyToAddress(c.faucet.PublicKey)
+
+	nonce, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	head, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	tip, err := client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gas, err := client.EstimateGas(ctx, geth.CallMsg{From: from, To: to, Value: value, Data: data})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := types.SignNewTx(c.faucet, types.LatestSignerForChainID(ChainID), &types.DynamicFeeTx{
+		ChainID:   ChainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(2))),
+		Gas:       gas,
+		To:        to,
+		Value:     value,
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		return nil, err
+	}
+	c.backend.Commit()
+
+	receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("transaction %s reverted", tx.Hash().Hex())
+	}
+	return receipt, nil
+}
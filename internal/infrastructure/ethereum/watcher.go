@@ -0,0 +1,175 @@
+package ethereum
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"mpc/internal/domain"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// receiptBatchSize caps the number of receipts requested in one JSON-RPC batch.
+const receiptBatchSize = 100
+
+// receiptPollInterval is how often GetTransactionReceipt checks for new blocks over HTTP.
+const receiptPollInterval = 5 * time.Second
+
+// WatchChain reports new blocks of the chain. On endpoints that support subscriptions (WebSocket or
+// IPC) it subscribes to new heads and to Transfer logs of the given tokens, so removed logs signal a
+// reorg right away. Over HTTP, or while a dropped subscription is being re-established, it polls the
+// latest block every pollInterval. The channel is closed when ctx is done.
+func (c *EthereumClient) WatchChain(ctx context.Context, tokens []common.Address, pollInterval time.Duration) <-chan domain.ChainEvent {
+	events := make(chan domain.ChainEvent, 16)
+
+	go func() {
+		defer close(events)
+
+		for ctx.Err() == nil {
+			e := c.pool.subscriptionEndpoint()
+			if e != nil {
+				err := watchSubscriptions(ctx, e.client, tokens, events)
+				if ctx.Err() != nil {
+					return
+				}
+				log.Printf("Subscription to %s ended, polling until it is re-established: %v", e.url, err)
+				if errors.Is(err, rpc.ErrNotificationsUnsupported) {
+					e = nil
+				}
+			}
+
+			// Without subscriptions poll forever, otherwise for one interval before resubscribing
+			c.pollHeads(ctx, pollInterval, e == nil, events)
+		}
+	}()
+
+	return events
+}
+
+func watchSubscriptions(ctx context.Context, client *ethclient.Client, tokens []common.Address, events chan<- domain.ChainEvent) error {
+	heads := make(chan *types.Header, 16)
+	headSub, err := client.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return err
+	}
+	defer headSub.Unsubscribe()
+
+	logs := make(chan types.Log, 64)
+	var logErr <-chan error
+	if len(tokens) > 0 {
+		logSub, err := client.SubscribeFilterLogs(ctx, ethereum.FilterQuery{
+			Addresses: tokens,
+			Topics:    [][]common.Hash{{transferEventTopic}},
+		}, logs)
+		if err != nil {
+			return err
+		}
+		defer logSub.Unsubscribe()
+		logErr = logSub.Err()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-headSub.Err():
+			return err
+		case err := <-logErr:
+			return err
+		case head := <-heads:
+			send(ctx, events, domain.ChainEvent{BlockNumber: head.Number.Uint64()})
+		case l := <-logs:
+			// Logs of new blocks are covered by the head; a removed log means its block was orphaned
+			if l.Removed {
+				send(ctx, events, domain.ChainEvent{BlockNumber: l.BlockNumber, Reorg: true})
+			}
+		}
+	}
+}
+
+// pollHeads reports the latest block whenever it changes. Unless forever is set it returns after one interval.
+func (c *EthereumClient) pollHeads(ctx context.Context, interval time.Duration, forever bool, events chan<- domain.ChainEvent) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last uint64
+	for {
+		number, err := c.GetLatestBlockNumber(ctx)
+		if err != nil {
+			log.Printf("Failed to poll latest block: %v", err)
+		} else if number != last {
+			last = number
+			send(ctx, events, domain.ChainEvent{BlockNumber: number})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !forever {
+			return
+		}
+	}
+}
+
+func send(ctx context.Context, events chan<- domain.ChainEvent, event domain.ChainEvent) {
+	select {
+	case events <- event:
+	case <-ctx.Done():
+	}
+}
+
+// subscriptionEndpoint returns the healthiest endpoint reachable over WebSocket or IPC, if any.
+func (p *rpcPool) subscriptionEndpoint() *endpoint {
+	for _, e := range p.ranked() {
+		if !strings.HasPrefix(e.url, "http://") && !strings.HasPrefix(e.url, "https://") {
+			return e
+		}
+	}
+	return nil
+}
+
+// FindTransactionReceipts looks up the receipts of many transactions in JSON-RPC batches.
+// Transactions that are not mined yet are missing from the result.
+func (c *EthereumClient) FindTransactionReceipts(ctx context.Context, hashes []common.Hash) (map[common.Hash]*types.Receipt, error) {
+	receipts := make(map[common.Hash]*types.Receipt, len(hashes))
+
+	for start := 0; start < len(hashes); start += receiptBatchSize {
+		end := min(start+receiptBatchSize, len(hashes))
+		chunk := hashes[start:end]
+
+		results := make([]*types.Receipt, len(chunk))
+		batch := make([]rpc.BatchElem, len(chunk))
+		for i, hash := range chunk {
+			batch[i] = rpc.BatchElem{Method: "eth_getTransactionReceipt", Args: []interface{}{hash}, Result: &results[i]}
+		}
+
+		err := c.pool.read(ctx, func(ctx context.Context, client *ethclient.Client) error {
+			return client.Client().BatchCallContext(ctx, batch)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get transaction receipts: %w", err)
+		}
+
+		for i, elem := range batch {
+			if elem.Error != nil {
+				log.Printf("Failed to get receipt of %s: %v", chunk[i].Hex(), elem.Error)
+				continue
+			}
+			if results[i] != nil {
+				receipts[chunk[i]] = results[i]
+			}
+		}
+	}
+
+	return receipts, nil
+}
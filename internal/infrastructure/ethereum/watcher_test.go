@@ -0,0 +1,163 @@
+package ethereum
+
+import (
+	"context"
+	"math/big"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// stubChain is an RPC service whose head the test moves by hand. Subscribers to new heads get the
+// current head, and subscribers to logs get a removed Transfer log of the block before it.
+type stubChain struct {
+	head atomic.Uint64
+}
+
+func (s *stubChain) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(s.head.Load())
+}
+
+func (s *stubChain) NewHeads(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	header := &types.Header{Number: new(big.Int).SetUint64(s.head.Load()), Difficulty: new(big.Int)}
+	if err := notifier.Notify(sub.ID, header); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+func (s *stubChain) Logs(ctx context.Context, crit map[string]interface{}) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	removed := types.Log{
+		Address:     common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3"),
+		Topics:      []common.Hash{transferEventTopic},
+		BlockNumber: s.head.Load() - 1,
+		Removed:     true,
+	}
+	if err := notifier.Notify(sub.ID, removed); err != nil {
+		return nil, err
+	}
+	return sub, nil
+}
+
+// newStubChain serves chain over HTTP, or over WebSocket when websocket is set, and returns its URL.
+func newStubChain(t *testing.T, chain *stubChain, websocket bool) string {
+	t.Helper()
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", chain); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(server.Stop)
+
+	if !websocket {
+		httpServer := httptest.NewServer(server)
+		t.Cleanup(httpServer.Close)
+		return httpServer.URL
+	}
+	wsServer := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	t.Cleanup(wsServer.Close)
+	return "ws" + strings.TrimPrefix(wsServer.URL, "http")
+}
+
+// nextEvents waits for n events, failing the test when they do not arrive in time.
+func nextEvents(t *testing.T, events <-chan domain.ChainEvent, n int) []domain.ChainEvent {
+	t.Helper()
+
+	var received []domain.ChainEvent
+	timeout := time.After(5 * time.Second)
+	for len(received) < n {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				t.Fatalf("events closed after %v, want %d events", received, n)
+			}
+			received = append(received, event)
+		case <-timeout:
+			t.Fatalf("received %v, want %d events", received, n)
+		}
+	}
+	return received
+}
+
+func TestWatchChain(t *testing.T) {
+	tokens := []common.Address{common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")}
+
+	tests := []struct {
+		name      string
+		websocket bool
+		// want are the events reported for a chain at block 100, in any order
+		want []domain.ChainEvent
+	}{
+		{
+			name: "polling over HTTP",
+			want: []domain.ChainEvent{{BlockNumber: 100}},
+		},
+		{
+			// A removed log reports the reorg of its block without waiting for the next head
+			name:      "subscriptions over WebSocket",
+			websocket: true,
+			want:      []domain.ChainEvent{{BlockNumber: 100}, {BlockNumber: 99, Reorg: true}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := &stubChain{}
+			chain.head.Store(100)
+			client, err := NewEthereumClient(&config.EthereumConfig{URL: newStubChain(t, chain, tt.websocket), ReadTimeout: time.Second})
+			if err != nil {
+				t.Fatalf("NewEthereumClient() error = %v", err)
+			}
+			t.Cleanup(client.Close)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			events := client.WatchChain(ctx, tokens, 10*time.Millisecond)
+
+			got := nextEvents(t, events, len(tt.want))
+			for _, want := range tt.want {
+				found := false
+				for _, event := range got {
+					found = found || event == want
+				}
+				if !found {
+					t.Errorf("events = %v, want %v", got, tt.want)
+				}
+			}
+
+			if !tt.websocket {
+				// An unchanged head is not reported again, a new one is
+				time.Sleep(30 * time.Millisecond)
+				chain.head.Store(101)
+				if got := nextEvents(t, events, 1); !reflect.DeepEqual(got, []domain.ChainEvent{{BlockNumber: 101}}) {
+					t.Errorf("events after the next block = %v, want block 101", got)
+				}
+			}
+
+			// The channel is closed once the watch is cancelled
+			cancel()
+			for range events {
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"mpc/internal/domain"
+)
+
+// upgrades turn the payloads of older event versions into the next version.
+var upgrades = map[domain.EventType]map[int]Upgrade{
+	domain.EventTransactionSubmitted: {1: upgradeTransactionSubmittedV1},
+}
+
+// registry holds the schemas embedded in the binary.
+var registry = mustNewRegistry()
+
+func mustNewRegistry() *Registry {
+	r, err := NewRegistry(schemaFiles, upgrades)
+	if err != nil {
+		panic(fmt.Sprintf("invalid event schemas: %v", err))
+	}
+	return r
+}
+
+// Encode wraps payload in an event of the latest version of eventType.
+func Encode(eventType domain.EventType, payload any) ([]byte, error) {
+	return registry.Encode(eventType, payload)
+}
+
+// Decode reads an event of any type, upgraded to the latest version.
+func Decode(data []byte) (domain.Event, error) {
+	return registry.Decode(data)
+}
+
+// DecodeAs reads an event of eventType into payload.
+func DecodeAs(data []byte, eventType domain.EventType, payload any) (domain.Event, error) {
+	return registry.DecodeAs(data, eventType, payload)
+}
+
+// upgradeTransactionSubmittedV1 reads the message the worker got before events were versioned,
+// which only had the chain and hash; the transaction ID was the message key.
+func upgradeTransactionSubmittedV1(payload json.RawMessage) (json.RawMessage, error) {
+	var v1 domain.TxnMessage
+	if err := json.Unmarshal(payload, &v1); err != nil {
+		return nil, err
+	}
+	return json.Marshal(domain.TransactionEvent{
+		ChainID:   v1.ChainID,
+		Direction: domain.DirectionOutbound,
+		Status:    domain.StatusSubmitted,
+		TxHash:    v1.TxHash,
+	})
+}
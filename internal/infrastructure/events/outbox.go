@@ -0,0 +1,66 @@
+package events
+
+import (
+	"fmt"
+	"mpc/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// NewWalletMessage returns an outbox message publishing an event about a wallet on topic. Messages
+// are keyed by the wallet, so the events of a wallet reach consumers in the order they happened.
+func NewWalletMessage(topic string, walletID uuid.UUID, eventType domain.EventType, payload any) (domain.OutboxMessage, error) {
+	return newMessage(topic, walletID, eventType, payload)
+}
+
+// NewUserMessage returns an outbox message publishing an event about a user on topic, keyed by the
+// user.
+func NewUserMessage(topic string, userID uuid.UUID, eventType domain.EventType, payload any) (domain.OutboxMessage, error) {
+	return newMessage(topic, userID, eventType, payload)
+}
+
+func newMessage(topic string, key uuid.UUID, eventType domain.EventType, payload any) (domain.OutboxMessage, error) {
+	data, err := Encode(eventType, payload)
+	if err != nil {
+		return domain.OutboxMessage{}, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+
+	return domain.OutboxMessage{
+		ID:      uuid.New(),
+		Topic:   topic,
+		Key:     []byte(key.String()),
+		Payload: data,
+	}, nil
+}
+
+// NewTransactionMessage returns the outbox message announcing on topic that txn reached its status.
+func NewTransactionMessage(topic string, txn domain.Transaction) (domain.OutboxMessage, error) {
+	eventType, ok := TransactionEventType(txn)
+	if !ok {
+		return domain.OutboxMessage{}, fmt.Errorf("no event for %s transactions with status %s", txn.Direction, txn.Status)
+	}
+	return NewWalletMessage(topic, txn.WalletID, eventType, domain.NewTransactionEvent(txn))
+}
+
+// TransactionEventType returns the event announcing that txn reached its status. Deposits are
+// announced by wallet.deposit_received when they are found, so an inbound transaction only has an
+// event of its own once it is final.
+func TransactionEventType(txn domain.Transaction) (domain.EventType, bool) {
+	inbound := txn.Direction == domain.DirectionInbound
+
+	switch txn.Status {
+	case domain.StatusPending:
+		return domain.EventTransactionCreated, !inbound
+	case domain.StatusSubmitted:
+		return domain.EventTransactionSubmitted, !inbound
+	case domain.StatusSuccess:
+		return domain.EventTransactionConfirmed, true
+	case domain.StatusFailed:
+		return domain.EventTransactionFailed, true
+	case domain.StatusDropped:
+		return domain.EventTransactionDropped, true
+	case domain.StatusReplaced:
+		return domain.EventTransactionReplaced, !inbound
+	}
+	return "", false
+}
@@ -0,0 +1,69 @@
+package events
+
+import (
+	"mpc/internal/domain"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNewTransactionMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction domain.Direction
+		status    domain.Status
+		want      domain.EventType
+		// wantNone means the status is not announced for this direction
+		wantNone bool
+	}{
+		{name: "created", direction: domain.DirectionOutbound, status: domain.StatusPending, want: domain.EventTransactionCreated},
+		{name: "submitted", direction: domain.DirectionOutbound, status: domain.StatusSubmitted, want: domain.EventTransactionSubmitted},
+		{name: "confirmed", direction: domain.DirectionOutbound, status: domain.StatusSuccess, want: domain.EventTransactionConfirmed},
+		{name: "failed", direction: domain.DirectionOutbound, status: domain.StatusFailed, want: domain.EventTransactionFailed},
+		{name: "dropped", direction: domain.DirectionOutbound, status: domain.StatusDropped, want: domain.EventTransactionDropped},
+		{name: "replaced", direction: domain.DirectionOutbound, status: domain.StatusReplaced, want: domain.EventTransactionReplaced},
+		// Deposits are announced by wallet.deposit_received until they are final
+		{name: "deposit pending", direction: domain.DirectionInbound, status: domain.StatusPending, wantNone: true},
+		{name: "deposit submitted", direction: domain.DirectionInbound, status: domain.StatusSubmitted, wantNone: true},
+		{name: "deposit confirmed", direction: domain.DirectionInbound, status: domain.StatusSuccess, want: domain.EventTransactionConfirmed},
+		{name: "deposit orphaned", direction: domain.DirectionInbound, status: domain.StatusDropped, want: domain.EventTransactionDropped},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			txn := domain.Transaction{
+				ID:          uuid.New(),
+				WalletID:    uuid.New(),
+				ChainID:     uuid.MustParse(testChainID),
+				Direction:   tt.direction,
+				FromAddress: "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+				ToAddress:   "0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512",
+				Amount:      "0.001",
+				Status:      tt.status,
+				TxHash:      testTxHash,
+				BlockNumber: 100,
+			}
+
+			message, err := NewTransactionMessage("events", txn)
+			if tt.wantNone {
+				if err == nil {
+					t.Fatalf("NewTransactionMessage() = %s, want no event", message.Payload)
+				}
+				return
+			}
+			// The payload must also match the schema of the event
+			if err != nil {
+				t.Fatalf("NewTransactionMessage() error = %v", err)
+			}
+			event, err := Decode(message.Payload)
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if event.Type != tt.want {
+				t.Errorf("Event type = %s, want %s", event.Type, tt.want)
+			}
+			if string(message.Key) != txn.WalletID.String() {
+				t.Errorf("Message key = %s, want the wallet %s", message.Key, txn.WalletID)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package events
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"mpc/internal/domain"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrUnknownEvent = errors.New("unknown event")
+	ErrInvalidEvent = errors.New("invalid event")
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Upgrade turns the payload of one version of an event into the payload of the next version.
+type Upgrade func(payload json.RawMessage) (json.RawMessage, error)
+
+type eventVersion struct {
+	Type    domain.EventType
+	Version int
+}
+
+// Registry holds the payload schemas of every version of every event type. Events are produced with
+// the latest version of their type and validated against its schema; consumed events are validated
+// against the schema of their version and upgraded to the latest one, so consumers only handle a
+// single payload shape per type.
+type Registry struct {
+	schemas  map[eventVersion]*schema
+	latest   map[domain.EventType]int
+	upgrades map[eventVersion]Upgrade
+}
+
+// NewRegistry loads the schemas of the directory schemas, named <type>.v<version>.json. Every version
+// but the latest of a type needs an upgrade to the next one.
+func NewRegistry(schemas fs.FS, upgrades map[domain.EventType]map[int]Upgrade) (*Registry, error) {
+	r := &Registry{
+		schemas:  make(map[eventVersion]*schema),
+		latest:   make(map[domain.EventType]int),
+		upgrades: make(map[eventVersion]Upgrade),
+	}
+
+	files, err := fs.Glob(schemas, "schemas/*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list event schemas: %w", err)
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".json")
+		i := strings.LastIndex(name, ".v")
+		if i < 0 {
+			return nil, fmt.Errorf("event schema %s is not named <type>.v<version>.json", file)
+		}
+		version, err := strconv.Atoi(name[i+2:])
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("event schema %s has an invalid version", file)
+		}
+		eventType := domain.EventType(name[:i])
+
+		data, err := fs.ReadFile(schemas, file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read event schema %s: %w", file, err)
+		}
+		s, err := parseSchema(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse event schema %s: %w", file, err)
+		}
+
+		r.schemas[eventVersion{eventType, version}] = s
+		r.latest[eventType] = max(r.latest[eventType], version)
+	}
+
+	for eventType, byVersion := range upgrades {
+		for version, upgrade := range byVersion {
+			r.upgrades[eventVersion{eventType, version}] = upgrade
+		}
+	}
+	for eventType, latest := range r.latest {
+		for version := 1; version <= latest; version++ {
+			if _, ok := r.schemas[eventVersion{eventType, version}]; !ok {
+				return nil, fmt.Errorf("event %s has no schema for version %d", eventType, version)
+			}
+			if _, ok := r.upgrades[eventVersion{eventType, version}]; !ok && version < latest {
+				return nil, fmt.Errorf("event %s has no upgrade from version %d", eventType, version)
+			}
+		}
+	}
+	return r, nil
+}
+
+// Encode wraps payload in an event of the latest version of eventType, after checking it against
+// that version's schema.
+func (r *Registry) Encode(eventType domain.EventType, payload any) ([]byte, error) {
+	version, ok := r.latest[eventType]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownEvent, eventType)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+	if err := r.validate(eventType, version, data); err != nil {
+		return nil, err
+	}
+
+	event, err := json.Marshal(domain.Event{
+		ID:         uuid.New(),
+		Type:       eventType,
+		Version:    version,
+		OccurredAt: time.Now().UTC(),
+		Payload:    data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+	return event, nil
+}
+
+// Decode reads an event, checks its payload against the schema of its version, and upgrades the
+// payload to the latest version. The returned event carries the latest version and payload.
+func (r *Registry) Decode(data []byte) (domain.Event, error) {
+	var event domain.Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		return domain.Event{}, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	if event.Type == "" || event.Version < 1 || len(event.Payload) == 0 {
+		return domain.Event{}, fmt.Errorf("%w: missing type, version or payload", ErrInvalidEvent)
+	}
+	return r.upgrade(event)
+}
+
+// DecodeAs decodes an event of eventType into payload, which must point to the Go type of the latest
+// version. A message without an envelope, as published before events were versioned, is read as
+// version 1 of eventType.
+func (r *Registry) DecodeAs(data []byte, eventType domain.EventType, payload any) (domain.Event, error) {
+	var (
+		event domain.Event
+		err   error
+	)
+	if isEnvelope(data) {
+		event, err = r.Decode(data)
+		if err == nil && event.Type != eventType {
+			err = fmt.Errorf("%w: got %s, want %s", ErrInvalidEvent, event.Type, eventType)
+		}
+	} else {
+		event, err = r.upgrade(domain.Event{Type: eventType, Version: 1, Payload: data})
+	}
+	if err != nil {
+		return domain.Event{}, err
+	}
+
+	if err := json.Unmarshal(event.Payload, payload); err != nil {
+		return domain.Event{}, fmt.Errorf("failed to unmarshal %s payload: %w", eventType, err)
+	}
+	return event, nil
+}
+
+func (r *Registry) upgrade(event domain.Event) (domain.Event, error) {
+	latest, ok := r.latest[event.Type]
+	if !ok {
+		return domain.Event{}, fmt.Errorf("%w: %s", ErrUnknownEvent, event.Type)
+	}
+	if event.Version > latest {
+		return domain.Event{}, fmt.Errorf("%w: %s version %d is newer than %d", ErrUnknownEvent, event.Type, event.Version, latest)
+	}
+	if err := r.validate(event.Type, event.Version, event.Payload); err != nil {
+		return domain.Event{}, err
+	}
+
+	for event.Version < latest {
+		payload, err := r.upgrades[eventVersion{event.Type, event.Version}](event.Payload)
+		if err != nil {
+			return domain.Event{}, fmt.Errorf("failed to upgrade %s from version %d: %w", event.Type, event.Version, err)
+		}
+		event.Payload = payload
+		event.Version++
+	}
+	return event, nil
+}
+
+func (r *Registry) validate(eventType domain.EventType, version int, payload []byte) error {
+	var value any
+	if err := json.Unmarshal(payload, &value); err != nil {
+		return fmt.Errorf("%w: %s payload is not JSON: %v", ErrInvalidEvent, eventType, err)
+	}
+	if err := r.schemas[eventVersion{eventType, version}].validate("payload", value); err != nil {
+		return fmt.Errorf("%w: %s version %d: %v", ErrInvalidEvent, eventType, version, err)
+	}
+	return nil
+}
+
+// isEnvelope reports whether data is an event rather than a bare payload.
+func isEnvelope(data []byte) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return false
+	}
+	_, hasType := fields["type"]
+	_, hasPayload := fields["payload"]
+	return hasType && hasPayload
+}
@@ -0,0 +1,179 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"mpc/internal/domain"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/uuid"
+)
+
+const (
+	testChainID = "1ec0a60a-08fe-4fb2-a6b2-ca2f506f8275"
+	testTxHash  = "0x1dd41fd648b9feb3b4ee6a8e7ed0e964d01d20188b9873650fcdded8b8e6a566"
+)
+
+func submittedEvent() domain.TransactionEvent {
+	return domain.NewTransactionEvent(domain.Transaction{
+		ID:          uuid.MustParse("48f8fc39-e2c5-467a-8429-14d68a1a4e37"),
+		WalletID:    uuid.MustParse("9a3c2a51-5d0e-4a43-9d43-35f4c2b0f1c7"),
+		ChainID:     uuid.MustParse(testChainID),
+		FromAddress: "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+		ToAddress:   "0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512",
+		Amount:      "1000000000000000",
+		Status:      domain.StatusSubmitted,
+		TxHash:      testTxHash,
+	})
+}
+
+// envelope returns an event of the given version around payload.
+func envelope(t *testing.T, eventType domain.EventType, version int, payload string) []byte {
+	t.Helper()
+	data, err := json.Marshal(domain.Event{ID: uuid.New(), Type: eventType, Version: version, Payload: json.RawMessage(payload)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestEncode(t *testing.T) {
+	data, err := Encode(domain.EventTransactionSubmitted, submittedEvent())
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+
+	event, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if event.Type != domain.EventTransactionSubmitted || event.Version != 2 || event.ID == uuid.Nil || event.OccurredAt.IsZero() {
+		t.Fatalf("Decode() = %+v, want a version 2 %s event with an ID and time", event, domain.EventTransactionSubmitted)
+	}
+
+	invalid := submittedEvent()
+	invalid.TxHash = ""
+	if _, err := Encode(domain.EventTransactionSubmitted, invalid); !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("Encode() without a hash error = %v, want %v", err, ErrInvalidEvent)
+	}
+	if _, err := Encode("transaction.unknown", submittedEvent()); !errors.Is(err, ErrUnknownEvent) {
+		t.Fatalf("Encode() of an unknown type error = %v, want %v", err, ErrUnknownEvent)
+	}
+
+	// The events of the wallet and auth usecases match their schemas
+	if _, err := Encode(domain.EventWalletCreated, domain.WalletEvent{WalletID: uuid.New(), UserID: uuid.New(), Address: "0x5FbDB2315678afecb367f032d93F642f64180aa3"}); err != nil {
+		t.Fatalf("Encode() of %s error = %v", domain.EventWalletCreated, err)
+	}
+	if _, err := Encode(domain.EventUserRegistered, domain.UserEvent{UserID: uuid.New(), Email: "alice@example.com"}); err != nil {
+		t.Fatalf("Encode() of %s error = %v", domain.EventUserRegistered, err)
+	}
+
+	// Orphaned transactions of both directions are announced back in submitted
+	reorged := submittedEvent()
+	reorged.Direction = domain.DirectionInbound
+	if _, err := Encode(domain.EventTransactionReorged, reorged); err != nil {
+		t.Fatalf("Encode() of an inbound %s error = %v", domain.EventTransactionReorged, err)
+	}
+}
+
+func TestDecodeAs(t *testing.T) {
+	v2, err := json.Marshal(submittedEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1 := `{"chain_id": "` + testChainID + `", "tx_hash": "` + testTxHash + `"}`
+
+	tests := []struct {
+		name string
+		data []byte
+		// wantErr is the expected error, nil when the event decodes
+		wantErr error
+	}{
+		{"latest version", envelope(t, domain.EventTransactionSubmitted, 2, string(v2)), nil},
+		{"version 1 upgraded", envelope(t, domain.EventTransactionSubmitted, 1, v1), nil},
+		{"bare version 1 payload", []byte(v1), nil},
+		{"unknown version", envelope(t, domain.EventTransactionSubmitted, 3, string(v2)), ErrUnknownEvent},
+		{"unknown type", envelope(t, "transaction.unknown", 1, v1), ErrUnknownEvent},
+		{"other type", envelope(t, domain.EventTransactionConfirmed, 1, string(v2)), ErrInvalidEvent},
+		{"missing required field", envelope(t, domain.EventTransactionSubmitted, 1, `{"chain_id": "`+testChainID+`"}`), ErrInvalidEvent},
+		{"wrong type", envelope(t, domain.EventTransactionSubmitted, 1, `{"chain_id": "`+testChainID+`", "tx_hash": 42}`), ErrInvalidEvent},
+		{"payload of another version", envelope(t, domain.EventTransactionSubmitted, 2, v1), ErrInvalidEvent},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload domain.TransactionEvent
+			event, err := DecodeAs(tt.data, domain.EventTransactionSubmitted, &payload)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DecodeAs() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeAs() error = %v", err)
+			}
+
+			// Every version reaches the consumer as the latest one
+			if event.Version != 2 {
+				t.Errorf("DecodeAs() version = %d, want 2", event.Version)
+			}
+			if payload.ChainID.String() != testChainID || payload.TxHash != testTxHash || payload.Status != domain.StatusSubmitted || payload.Direction != domain.DirectionOutbound {
+				t.Errorf("DecodeAs() payload = %+v", payload)
+			}
+		})
+	}
+}
+
+func TestNewRegistry(t *testing.T) {
+	object := &fstest.MapFile{Data: []byte(`{"type": "object"}`)}
+
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		upgrades map[domain.EventType]map[int]Upgrade
+		// wantErr is a part of the expected error, empty when the registry loads
+		wantErr string
+	}{
+		{
+			name:     "versions with upgrades",
+			files:    fstest.MapFS{"schemas/a.v1.json": object, "schemas/a.v2.json": object},
+			upgrades: map[domain.EventType]map[int]Upgrade{"a": {1: func(p json.RawMessage) (json.RawMessage, error) { return p, nil }}},
+		},
+		{
+			name:    "missing upgrade",
+			files:   fstest.MapFS{"schemas/a.v1.json": object, "schemas/a.v2.json": object},
+			wantErr: "no upgrade from version 1",
+		},
+		{
+			name:    "missing version",
+			files:   fstest.MapFS{"schemas/a.v2.json": object},
+			wantErr: "no schema for version 1",
+		},
+		{
+			name:    "unversioned name",
+			files:   fstest.MapFS{"schemas/a.json": object},
+			wantErr: "is not named",
+		},
+		{
+			name:    "invalid schema",
+			files:   fstest.MapFS{"schemas/a.v1.json": &fstest.MapFile{Data: []byte(`{`)}},
+			wantErr: "failed to parse",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRegistry(tt.files, tt.upgrades)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("NewRegistry() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("NewRegistry() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// schema is the subset of JSON Schema the event schemas use: type, properties, required,
+// additionalProperties, items, enum, const, format (uuid and date-time), pattern, minLength and
+// minimum. Other keywords are accepted and ignored, so the files stay valid for standard tools.
+type schema struct {
+	Type                 schemaTypes        `json:"type"`
+	Properties           map[string]*schema `json:"properties"`
+	Required             []string           `json:"required"`
+	AdditionalProperties *bool              `json:"additionalProperties"`
+	Items                *schema            `json:"items"`
+	Enum                 []any              `json:"enum"`
+	Const                any                `json:"const"`
+	Format               string             `json:"format"`
+	Pattern              string             `json:"pattern"`
+	MinLength            *int               `json:"minLength"`
+	Minimum              *float64           `json:"minimum"`
+
+	pattern *regexp.Regexp
+}
+
+// schemaTypes is the type keyword, either a single type or a list of them.
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = schemaTypes{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return fmt.Errorf("type must be a string or a list of strings: %w", err)
+	}
+	*t = list
+	return nil
+}
+
+func parseSchema(data []byte) (*schema, error) {
+	var s schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	if err := s.compile(); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (s *schema) compile() error {
+	if s.Pattern != "" {
+		pattern, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", s.Pattern, err)
+		}
+		s.pattern = pattern
+	}
+	for name, property := range s.Properties {
+		if err := property.compile(); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+	if s.Items != nil {
+		return s.Items.compile()
+	}
+	return nil
+}
+
+// validate checks a value decoded by encoding/json into an any against the schema. path locates the
+// value in error messages.
+func (s *schema) validate(path string, value any) error {
+	if len(s.Type) > 0 && !s.hasType(value) {
+		return fmt.Errorf("%s: must be of type %s", path, strings.Join(s.Type, " or "))
+	}
+	if s.Const != nil && !equal(value, s.Const) {
+		return fmt.Errorf("%s: must be %v", path, s.Const)
+	}
+	if len(s.Enum) > 0 && !s.inEnum(value) {
+		return fmt.Errorf("%s: must be one of %v", path, s.Enum)
+	}
+
+	switch v := value.(type) {
+	case string:
+		return s.validateString(path, v)
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			return fmt.Errorf("%s: must be at least %v", path, *s.Minimum)
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		return s.validateObject(path, v)
+	}
+	return nil
+}
+
+func (s *schema) validateString(path string, v string) error {
+	if s.MinLength != nil && len([]rune(v)) < *s.MinLength {
+		return fmt.Errorf("%s: must be at least %d characters", path, *s.MinLength)
+	}
+	if s.pattern != nil && !s.pattern.MatchString(v) {
+		return fmt.Errorf("%s: must match %s", path, s.Pattern)
+	}
+
+	switch s.Format {
+	case "uuid":
+		if _, err := uuid.Parse(v); err != nil {
+			return fmt.Errorf("%s: must be a UUID", path)
+		}
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+			return fmt.Errorf("%s: must be an RFC 3339 date-time", path)
+		}
+	}
+	return nil
+}
+
+func (s *schema) validateObject(path string, v map[string]any) error {
+	for _, name := range s.Required {
+		if _, ok := v[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, name)
+		}
+	}
+
+	// Sorted, so the same payload always reports the same error
+	names := make([]string, 0, len(v))
+	for name := range v {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := s.Properties[name]
+		if !ok {
+			if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+				return fmt.Errorf("%s: unknown property %q", path, name)
+			}
+			continue
+		}
+		if err := property.validate(path+"."+name, v[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *schema) hasType(value any) bool {
+	for _, t := range s.Type {
+		switch t {
+		case "null":
+			if value == nil {
+				return true
+			}
+		case "boolean":
+			if _, ok := value.(bool); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := value.(float64); ok {
+				return true
+			}
+		case "integer":
+			if n, ok := value.(float64); ok && n == math.Trunc(n) {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]any); ok {
+				return true
+			}
+		case "object":
+			if _, ok := value.(map[string]any); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (s *schema) inEnum(value any) bool {
+	for _, allowed := range s.Enum {
+		if equal(value, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares scalar JSON values.
+func equal(a, b any) bool {
+	switch a.(type) {
+	case []any, map[string]any:
+		return false
+	}
+	switch b.(type) {
+	case []any, map[string]any:
+		return false
+	}
+	return a == b
+}
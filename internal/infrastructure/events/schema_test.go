@@ -0,0 +1,78 @@
+package events
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const testSchema = `{
+  "type": "object",
+  "properties": {
+    "id": {"type": "string", "format": "uuid"},
+    "name": {"type": "string", "minLength": 2, "pattern": "^[a-z]+$"},
+    "kind": {"type": "string", "enum": ["a", "b"]},
+    "version": {"const": 1},
+    "count": {"type": "integer", "minimum": 0},
+    "at": {"type": "string", "format": "date-time"},
+    "note": {"type": ["string", "null"]},
+    "tags": {"type": "array", "items": {"type": "string"}}
+  },
+  "required": ["id", "name"],
+  "additionalProperties": false
+}`
+
+func TestSchemaValidate(t *testing.T) {
+	s, err := parseSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("parseSchema() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		payload string
+		// wantErr is a part of the expected error, empty when the payload is valid
+		wantErr string
+	}{
+		{"valid", `{"id": "48f8fc39-e2c5-467a-8429-14d68a1a4e37", "name": "alice", "kind": "a", "version": 1, "count": 3, "at": "2024-11-07T10:00:00Z", "note": null, "tags": ["x"]}`, ""},
+		{"only required", `{"id": "48f8fc39-e2c5-467a-8429-14d68a1a4e37", "name": "bob"}`, ""},
+		{"missing required", `{"id": "48f8fc39-e2c5-467a-8429-14d68a1a4e37"}`, `missing required property "name"`},
+		{"wrong type", `{"id": "48f8fc39-e2c5-467a-8429-14d68a1a4e37", "name": 42}`, "payload.name: must be of type string"},
+		{"not an object", `[]`, "payload: must be of type object"},
+		{"unknown property", `{"id": "48f8fc39-e2c5-467a-8429-14d68a1a4e37", "name": "bob", "extra": true}`, `unknown property "extra"`},
+		{"invalid uuid", `{"id": "not-a-uuid", "name": "bob"}`, "payload.id: must be a UUID"},
+		{"too short", `{"id": "48f8fc39-e2c5-467a-8429-14d68a1a4e37", "name": "b"}`, "at least 2 characters"},
+		{"pattern mismatch", `{"id": "48f8fc39-e2c5-467a-8429-14d68a1a4e37", "name": "Bob"}`, "must match"},
+		{"not in enum", `{"id": "48f8fc39-e2c5-467a-8429-14d68a1a4e37", "name": "bob", "kind": "c"}`, "must be one of"},
+		{"const mismatch", `{"id": "48f8fc39-e2c5-467a-8429-14d68a1a4e37", "name": "bob", "version": 2}`, "payload.version: must be 1"},
+		{"not an integer", `{"id": "48f8fc39-e2c5-467a-8429-14d68a1a4e37", "name": "bob", "count": 1.5}`, "must be of type integer"},
+		{"below minimum", `{"id": "48f8fc39-e2c5-467a-8429-14d68a1a4e37", "name": "bob", "count": -1}`, "at least 0"},
+		{"invalid date-time", `{"id": "48f8fc39-e2c5-467a-8429-14d68a1a4e37", "name": "bob", "at": "yesterday"}`, "RFC 3339"},
+		{"invalid item", `{"id": "48f8fc39-e2c5-467a-8429-14d68a1a4e37", "name": "bob", "tags": ["x", 1]}`, "payload.tags[1]: must be of type string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.payload), &value); err != nil {
+				t.Fatalf("invalid test payload: %v", err)
+			}
+
+			err := s.validate("payload", value)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("validate() error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseSchemaInvalidPattern(t *testing.T) {
+	if _, err := parseSchema([]byte(`{"properties": {"name": {"pattern": "("}}}`)); err == nil {
+		t.Fatal("parseSchema() of an invalid pattern succeeded")
+	}
+}
@@ -0,0 +1,98 @@
+package kafka
+
+import (
+	"context"
+	"mpc/internal/infrastructure/config"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Publisher writes messages to a topic.
+type Publisher interface {
+	Topic() string
+	Publish(ctx context.Context, messages ...Message) error
+	Close() error
+}
+
+// Subscriber reads the messages of a topic as a member of a consumer group. The members of a group
+// share the topic, and a group resumes after the last message it committed.
+type Subscriber interface {
+	Topic() string
+	// Fetch blocks until the next message is available. It returns io.EOF once the subscriber is closed.
+	Fetch(ctx context.Context) (Message, error)
+	Commit(ctx context.Context, messages ...Message) error
+	Close() error
+}
+
+// Broker creates the publishers and subscribers of topics.
+type Broker interface {
+	Publisher(topic string) (Publisher, error)
+	Subscriber(topic string, groupID string) (Subscriber, error)
+}
+
+type kafkaBroker struct {
+	cfg *config.KafkaConfig
+}
+
+// NewKafkaBroker returns a broker backed by the Kafka cluster of cfg.
+func NewKafkaBroker(cfg *config.KafkaConfig) Broker {
+	return &kafkaBroker{cfg: cfg}
+}
+
+var _ Broker = (*kafkaBroker)(nil)
+
+func (b *kafkaBroker) Publisher(topic string) (Publisher, error) {
+	writer, err := NewKafkaProducer(b.cfg, WithTopic(topic))
+	if err != nil {
+		return nil, err
+	}
+	return &kafkaPublisher{writer: writer}, nil
+}
+
+func (b *kafkaBroker) Subscriber(topic string, groupID string) (Subscriber, error) {
+	reader, err := NewKafkaConsumer(b.cfg, WithTopic(topic), WithGroupID(groupID))
+	if err != nil {
+		return nil, err
+	}
+	return &kafkaSubscriber{reader: reader}, nil
+}
+
+type kafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+var _ Publisher = (*kafkaPublisher)(nil)
+
+func (p *kafkaPublisher) Topic() string {
+	return p.writer.Topic
+}
+
+func (p *kafkaPublisher) Publish(ctx context.Context, messages ...Message) error {
+	return p.writer.WriteMessages(ctx, messages...)
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+type kafkaSubscriber struct {
+	reader *kafka.Reader
+}
+
+var _ Subscriber = (*kafkaSubscriber)(nil)
+
+func (s *kafkaSubscriber) Topic() string {
+	return s.reader.Config().Topic
+}
+
+func (s *kafkaSubscriber) Fetch(ctx context.Context) (Message, error) {
+	return s.reader.FetchMessage(ctx)
+}
+
+func (s *kafkaSubscriber) Commit(ctx context.Context, messages ...Message) error {
+	return s.reader.CommitMessages(ctx, messages...)
+}
+
+func (s *kafkaSubscriber) Close() error {
+	return s.reader.Close()
+}
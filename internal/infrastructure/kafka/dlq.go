@@ -0,0 +1,110 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mpc/internal/infrastructure/config"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// maxDeadLetterBytes bounds the size of a single message read from a dead-letter topic.
+const maxDeadLetterBytes = 10 << 20
+
+// DeadLetter is a message of a dead-letter topic along with the failure metadata it carries.
+type DeadLetter struct {
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+
+	OriginalTopic     string
+	OriginalPartition int
+	OriginalOffset    int64
+	Attempts          int
+	Error             string
+	FailedAt          time.Time
+}
+
+// ReadDeadLetters reads up to limit messages of one partition of the dead-letter topic of topic,
+// starting at offset. Reading does not consume them; dead letters stay in the topic until it
+// expires them.
+func ReadDeadLetters(ctx context.Context, cfg *config.KafkaConfig, topic string, partition int, offset int64, limit int) ([]DeadLetter, error) {
+	conn, err := kafka.DialLeader(ctx, "tcp", cfg.Brokers[0], DLQTopic(topic), partition)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s/%d: %w", DLQTopic(topic), partition, err)
+	}
+	defer conn.Close()
+
+	first, last, err := conn.ReadOffsets()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read offsets of %s/%d: %w", DLQTopic(topic), partition, err)
+	}
+	offset = max(offset, first)
+	if offset >= last {
+		return nil, nil
+	}
+	if _, err := conn.Seek(offset, kafka.SeekAbsolute); err != nil {
+		return nil, fmt.Errorf("failed to seek to offset %d: %w", offset, err)
+	}
+
+	var letters []DeadLetter
+	for len(letters) < limit && offset < last {
+		if deadline, ok := ctx.Deadline(); ok {
+			conn.SetReadDeadline(deadline)
+		} else {
+			conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+		}
+
+		m, err := conn.ReadMessage(maxDeadLetterBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read message %d: %w", offset, err)
+		}
+		letters = append(letters, newDeadLetter(partition, m))
+		offset = m.Offset + 1
+	}
+	return letters, nil
+}
+
+// ReplayDeadLetter publishes a dead letter to its original topic again, without its failure
+// metadata, so it is processed as a new message with a fresh set of retries.
+func ReplayDeadLetter(ctx context.Context, cfg *config.KafkaConfig, letter DeadLetter) error {
+	if letter.OriginalTopic == "" {
+		return errors.New("dead letter has no original topic")
+	}
+
+	producer, err := NewKafkaProducer(cfg, WithTopic(letter.OriginalTopic))
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	if err := producer.WriteMessages(ctx, kafka.Message{
+		Key:   letter.Key,
+		Value: letter.Value,
+	}); err != nil {
+		return fmt.Errorf("failed to publish message to %s: %w", letter.OriginalTopic, err)
+	}
+	return nil
+}
+
+func newDeadLetter(partition int, m kafka.Message) DeadLetter {
+	originalOffset, _ := strconv.ParseInt(header(m, HeaderOriginalOffset), 10, 64)
+	failedAt, _ := time.Parse(time.RFC3339, header(m, HeaderFailedAt))
+
+	return DeadLetter{
+		Partition:         partition,
+		Offset:            m.Offset,
+		Key:               m.Key,
+		Value:             m.Value,
+		OriginalTopic:     header(m, HeaderOriginalTopic),
+		OriginalPartition: headerInt(m, HeaderOriginalPartition),
+		OriginalOffset:    originalOffset,
+		Attempts:          headerInt(m, HeaderAttempt),
+		Error:             header(m, HeaderError),
+		FailedAt:          failedAt,
+	}
+}
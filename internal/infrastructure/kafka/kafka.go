@@ -2,7 +2,10 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"mpc/internal/infrastructure/config"
 	"net"
 	"strconv"
@@ -13,16 +16,24 @@ import (
 
 type Writer = kafka.Writer
 type Reader = kafka.Reader
+type Message = kafka.Message
+type Header = kafka.Header
+
+// WriteErrors is returned by Publish when only some messages of a batch failed; it holds the error
+// of each message, nil for the ones that were written.
+type WriteErrors = kafka.WriteErrors
 
 type kafkaOptions struct {
-	Topic string
+	Topic   string
+	GroupID string
 }
 
 type KafkaOption func(*kafkaOptions)
 
 func defaultKafkaOptions(cfg *config.KafkaConfig) kafkaOptions {
 	return kafkaOptions{
-		Topic: cfg.Topic,
+		Topic:   cfg.Topic,
+		GroupID: cfg.GroupID,
 	}
 }
 
@@ -32,6 +43,13 @@ func WithTopic(topic string) KafkaOption {
 	}
 }
 
+// WithGroupID sets the consumer group a consumer joins.
+func WithGroupID(groupID string) KafkaOption {
+	return func(o *kafkaOptions) {
+		o.GroupID = groupID
+	}
+}
+
 func NewKafkaProducer(cfg *config.KafkaConfig, opts ...KafkaOption) (*Writer, error) {
 	options := defaultKafkaOptions(cfg)
 	for _, opt := range opts {
@@ -39,7 +57,7 @@ func NewKafkaProducer(cfg *config.KafkaConfig, opts ...KafkaOption) (*Writer, er
 	}
 	fmt.Print("Run into this")
 
-	err := createTopicIfNotExists(cfg.Brokers, options.Topic)
+	err := createTopicIfNotExists(cfg.Brokers, options.Topic, cfg.TopicPartitions)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create topic: %w", err)
 	}
@@ -52,14 +70,21 @@ func NewKafkaProducer(cfg *config.KafkaConfig, opts ...KafkaOption) (*Writer, er
 	return producer, nil
 }
 
+// NewKafkaConsumer returns a consumer in a consumer group. The members of a group share the topic's
+// partitions, and each resumes from the offset its group last committed, so messages published
+// while no consumer runs are read on start. Offsets are only committed by ConsumeMessages, after
+// the message is processed.
 func NewKafkaConsumer(cfg *config.KafkaConfig, opts ...KafkaOption) (*kafka.Reader, error) {
 	options := defaultKafkaOptions(cfg)
 	for _, opt := range opts {
 		opt(&options)
 	}
+	if options.GroupID == "" {
+		return nil, fmt.Errorf("no consumer group for topic %s", options.Topic)
+	}
 
 	if options.Topic != cfg.Topic {
-		err := createTopicIfNotExists(cfg.Brokers, options.Topic)
+		err := createTopicIfNotExists(cfg.Brokers, options.Topic, cfg.TopicPartitions)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create topic: %w", err)
 		}
@@ -68,6 +93,9 @@ func NewKafkaConsumer(cfg *config.KafkaConfig, opts ...KafkaOption) (*kafka.Read
 	consumer := kafka.NewReader(kafka.ReaderConfig{
 		Brokers: cfg.Brokers,
 		Topic:   options.Topic,
+		GroupID: options.GroupID,
+		// A new group starts from the oldest message rather than skipping what is already there
+		StartOffset: kafka.FirstOffset,
 	})
 	return consumer, nil
 }
@@ -89,7 +117,9 @@ func CloseKafkaConnections(producer *kafka.Writer, consumers ...*kafka.Reader) e
 	return err
 }
 
-func createTopicIfNotExists(brokers []string, topic string) error {
+// createTopicIfNotExists creates topic with the given number of partitions. A topic that exists
+// keeps the partitions it has.
+func createTopicIfNotExists(brokers []string, topic string, partitions int) error {
 	// Add timeout for initial connection
 	dialer := &kafka.Dialer{
 		Timeout:   10 * time.Second,
@@ -122,7 +152,7 @@ func createTopicIfNotExists(brokers []string, topic string) error {
 	topicConfigs := []kafka.TopicConfig{
 		{
 			Topic:             topic,
-			NumPartitions:     1,
+			NumPartitions:     partitions,
 			ReplicationFactor: 1,
 		},
 	}
@@ -148,26 +178,64 @@ func PublishMessage(producer *kafka.Writer, key, value []byte) error {
 	)
 }
 
-func ConsumeMessages(consumer *kafka.Reader, handler func(kafka.Message) error) error {
+// ConsumeMessages hands every message of the consumer's partitions to handler and commits its offset
+// once handler succeeded, so a message is only skipped on restart after it was processed. A message
+// the handler fails on is not committed: ConsumeMessages stops and returns the error, and the
+// message is read again once the consumer is restarted. Use ConsumeMessagesWithRetries to go on
+// with the partition while the message is retried. It also returns when the context is cancelled
+// or the consumer is closed.
+func ConsumeMessages(ctx context.Context, consumer Subscriber, handler func(ctx context.Context, m kafka.Message) error) error {
 	for {
-		msg, err := consumer.ReadMessage(context.Background())
+		m, err := consumer.Fetch(ctx)
 		if err != nil {
-			return err
+			if ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return err
+			}
+			log.Printf("Failed to fetch message from %s: %v", consumer.Topic(), err)
+			continue
 		}
-		if err := handler(msg); err != nil {
-			return err
+
+		// Committing a later message would commit this one as well, so the partition stops here
+		if err := handler(ctx, m); err != nil {
+			return fmt.Errorf("failed to process message %d of %s/%d: %w", m.Offset, m.Topic, m.Partition, err)
+		}
+
+		if err := consumer.Commit(ctx, m); err != nil {
+			if ctx.Err() != nil {
+				return err
+			}
+			log.Printf("Failed to commit message %d of %s/%d: %v", m.Offset, m.Topic, m.Partition, err)
 		}
 	}
 }
 
-// ReadNewMessage reads only new messages published after this function is called
-func ReadNewMessage(ctx context.Context, reader *kafka.Reader) (kafka.Message, error) {
-	// First, seek to the end of the topic
-	err := reader.SetOffset(kafka.LastOffset)
-	if err != nil {
-		return kafka.Message{}, fmt.Errorf("failed to set offset: %w", err)
-	}
+// ConsumeMessagesWithRetries works like ConsumeMessages, but hands a message the handler fails on to retries
+// and commits it once it is there, so the partition goes on. Messages read from a retry topic are only handled once they are due.
+func ConsumeMessagesWithRetries(ctx context.Context, consumer Subscriber, retries *RetryPipeline, handler func(ctx context.Context, m kafka.Message) error) error {
+	return ConsumeMessages(ctx, consumer, func(ctx context.Context, m kafka.Message) error {
+		if err := waitUntilDue(ctx, m); err != nil {
+			return err
+		}
+
+		handleErr := handler(ctx, m)
+		if handleErr == nil {
+			return nil
+		}
+		log.Printf("Failed to process message %d of %s/%d, retrying: %v", m.Offset, m.Topic, m.Partition, handleErr)
+
+		// Committing before the message is in a retry topic would lose it
+		for {
+			err := retries.Fail(ctx, m, handleErr)
+			if err == nil {
+				return nil
+			}
+			log.Printf("Failed to hand over message %d of %s/%d: %v", m.Offset, m.Topic, m.Partition, err)
 
-	// Now read the next message, which should be a new one
-	return reader.ReadMessage(ctx)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Second):
+			}
+		}
+	})
 }
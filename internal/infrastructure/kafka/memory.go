@@ -0,0 +1,147 @@
+package kafka
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// MemoryBroker keeps messages in memory, for tests and for running every service in one process
+// without Kafka. Each topic is a single partition that keeps all its messages; the members of a
+// group share its position, so every message goes to one of them. Positions are lost with the
+// broker, and fetched messages that were not committed are not redelivered.
+type MemoryBroker struct {
+	mu     sync.Mutex
+	topics map[string]*memoryTopic
+}
+
+type memoryTopic struct {
+	messages []Message
+	// next is the offset each group fetches next
+	next map[string]int64
+	// published is closed, and replaced, whenever a message is published
+	published chan struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{topics: make(map[string]*memoryTopic)}
+}
+
+var _ Broker = (*MemoryBroker)(nil)
+
+func (b *MemoryBroker) Publisher(topic string) (Publisher, error) {
+	return &memoryPublisher{broker: b, topic: topic}, nil
+}
+
+func (b *MemoryBroker) Subscriber(topic string, groupID string) (Subscriber, error) {
+	return &memorySubscriber{broker: b, topic: topic, groupID: groupID, closed: make(chan struct{})}, nil
+}
+
+// Messages returns the messages published to topic so far.
+func (b *MemoryBroker) Messages(topic string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Message(nil), b.topic(topic).messages...)
+}
+
+// topic returns the state of a topic, creating it on first use. The caller holds mu.
+func (b *MemoryBroker) topic(name string) *memoryTopic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &memoryTopic{next: make(map[string]int64), published: make(chan struct{})}
+		b.topics[name] = t
+	}
+	return t
+}
+
+type memoryPublisher struct {
+	broker *MemoryBroker
+	topic  string
+}
+
+var _ Publisher = (*memoryPublisher)(nil)
+
+func (p *memoryPublisher) Topic() string {
+	return p.topic
+}
+
+func (p *memoryPublisher) Publish(ctx context.Context, messages ...Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.broker.mu.Lock()
+	defer p.broker.mu.Unlock()
+
+	t := p.broker.topic(p.topic)
+	for _, m := range messages {
+		m.Topic = p.topic
+		m.Partition = 0
+		m.Offset = int64(len(t.messages))
+		if m.Time.IsZero() {
+			m.Time = time.Now()
+		}
+		m.Headers = append([]Header(nil), m.Headers...)
+		t.messages = append(t.messages, m)
+	}
+	close(t.published)
+	t.published = make(chan struct{})
+	return nil
+}
+
+func (p *memoryPublisher) Close() error {
+	return nil
+}
+
+type memorySubscriber struct {
+	broker    *MemoryBroker
+	topic     string
+	groupID   string
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+var _ Subscriber = (*memorySubscriber)(nil)
+
+func (s *memorySubscriber) Topic() string {
+	return s.topic
+}
+
+func (s *memorySubscriber) Fetch(ctx context.Context) (Message, error) {
+	for {
+		select {
+		case <-s.closed:
+			return Message{}, io.EOF
+		default:
+		}
+
+		s.broker.mu.Lock()
+		t := s.broker.topic(s.topic)
+		if next := t.next[s.groupID]; next < int64(len(t.messages)) {
+			t.next[s.groupID] = next + 1
+			m := t.messages[next]
+			s.broker.mu.Unlock()
+			return m, nil
+		}
+		published := t.published
+		s.broker.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return Message{}, ctx.Err()
+		case <-s.closed:
+			return Message{}, io.EOF
+		case <-published:
+		}
+	}
+}
+
+func (s *memorySubscriber) Commit(ctx context.Context, messages ...Message) error {
+	return ctx.Err()
+}
+
+func (s *memorySubscriber) Close() error {
+	s.closeOnce.Do(func() { close(s.closed) })
+	return nil
+}
@@ -0,0 +1,125 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestMemoryBrokerGroups(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	broker := NewMemoryBroker()
+	publisher, _ := broker.Publisher("txn")
+	first, _ := broker.Subscriber("txn", "worker")
+	second, _ := broker.Subscriber("txn", "worker")
+	other, _ := broker.Subscriber("txn", "mail")
+
+	if err := publisher.Publish(ctx, Message{Key: []byte("a")}, Message{Key: []byte("b")}); err != nil {
+		t.Fatalf("Failed to publish messages: %v", err)
+	}
+
+	// The members of a group share its messages, while every group gets all of them
+	for i, s := range []Subscriber{first, second, other} {
+		m, err := s.Fetch(ctx)
+		if err != nil {
+			t.Fatalf("Failed to fetch message: %v", err)
+		}
+		if want := []string{"a", "b", "a"}[i]; string(m.Key) != want {
+			t.Errorf("Expected key %s, got %s", want, m.Key)
+		}
+		if m.Topic != "txn" {
+			t.Errorf("Expected topic txn, got %s", m.Topic)
+		}
+	}
+}
+
+func TestMemoryBrokerConsume(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	broker := NewMemoryBroker()
+	publisher, _ := broker.Publisher("txn")
+	subscriber, _ := broker.Subscriber("txn", "worker")
+
+	received := make(chan Message)
+	done := make(chan error)
+	go func() {
+		done <- ConsumeMessages(ctx, subscriber, func(ctx context.Context, m Message) error {
+			received <- m
+			return nil
+		})
+	}()
+
+	// Fetch waits for messages published after it started
+	if err := publisher.Publish(ctx, Message{Key: []byte("a")}); err != nil {
+		t.Fatalf("Failed to publish message: %v", err)
+	}
+	select {
+	case m := <-received:
+		if string(m.Key) != "a" {
+			t.Errorf("Expected key a, got %s", m.Key)
+		}
+	case <-ctx.Done():
+		t.Fatal("Message was not consumed")
+	}
+
+	subscriber.Close()
+	select {
+	case err := <-done:
+		if !errors.Is(err, io.EOF) {
+			t.Errorf("Expected io.EOF once closed, got %v", err)
+		}
+	case <-ctx.Done():
+		t.Fatal("Consumer did not stop when closed")
+	}
+}
+
+// committedSubscriber records the offsets committed through it.
+type committedSubscriber struct {
+	Subscriber
+	committed []int64
+}
+
+func (s *committedSubscriber) Commit(ctx context.Context, messages ...Message) error {
+	for _, m := range messages {
+		s.committed = append(s.committed, m.Offset)
+	}
+	return s.Subscriber.Commit(ctx, messages...)
+}
+
+func TestConsumeMessagesFailure(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	broker := NewMemoryBroker()
+	publisher, _ := broker.Publisher("txn")
+	memory, _ := broker.Subscriber("txn", "mail")
+	subscriber := &committedSubscriber{Subscriber: memory}
+
+	if err := publisher.Publish(ctx, Message{Key: []byte("a")}, Message{Key: []byte("b")}, Message{Key: []byte("c")}); err != nil {
+		t.Fatalf("Failed to publish messages: %v", err)
+	}
+
+	// The failed message is not committed, and neither is anything after it
+	var handled []string
+	err := ConsumeMessages(ctx, subscriber, func(ctx context.Context, m Message) error {
+		handled = append(handled, string(m.Key))
+		if string(m.Key) == "b" {
+			return errTransient
+		}
+		return nil
+	})
+	if !errors.Is(err, errTransient) {
+		t.Fatalf("ConsumeMessages() error = %v, want %v", err, errTransient)
+	}
+	if len(handled) != 2 {
+		t.Errorf("Handled %v, want to stop at b", handled)
+	}
+	if len(subscriber.committed) != 1 || subscriber.committed[0] != 0 {
+		t.Errorf("Committed offsets %v, want only 0", subscriber.committed)
+	}
+}
@@ -0,0 +1,183 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mpc/internal/infrastructure/config"
+	"strconv"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Headers a message carries through the retry topics and into the dead-letter topic.
+const (
+	HeaderOriginalTopic     = "x-original-topic"
+	HeaderOriginalPartition = "x-original-partition"
+	HeaderOriginalOffset    = "x-original-offset"
+	// HeaderAttempt is the number of failed attempts so far.
+	HeaderAttempt = "x-attempt"
+	// HeaderError is the error of the latest attempt.
+	HeaderError    = "x-error"
+	HeaderFailedAt = "x-failed-at"
+	// HeaderRetryAt is the time before which a message in a retry topic must not be processed.
+	HeaderRetryAt = "x-retry-at"
+)
+
+// RetryTopic is the topic messages of topic wait in before their attempt-th retry.
+func RetryTopic(topic string, attempt int) string {
+	return fmt.Sprintf("%s.retry.%d", topic, attempt)
+}
+
+// DLQTopic is the dead-letter topic of topic.
+func DLQTopic(topic string) string {
+	return topic + ".dlq"
+}
+
+// permanentError marks a failure retrying cannot fix.
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as a failure that retrying cannot fix, such as a malformed message. Messages
+// failing with it go straight to the dead-letter topic.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+func isPermanent(err error) bool {
+	var permanent permanentError
+	return errors.As(err, &permanent)
+}
+
+// RetryPipeline routes the messages of a topic that failed to process. The attempt-th failure
+// sends a message to the attempt-th retry topic, where it waits RetryBackoff doubled for every
+// earlier attempt; once MaxRetries retries have failed it goes to the dead-letter topic. Every
+// retry topic waits the same time, so its messages are due in the order they were written.
+type RetryPipeline struct {
+	topic      string
+	cfg        *config.KafkaConfig
+	publishers map[string]Publisher
+}
+
+// NewRetryPipeline creates publishers for the retry and dead-letter topics of topic.
+func NewRetryPipeline(broker Broker, cfg *config.KafkaConfig, topic string) (*RetryPipeline, error) {
+	p := &RetryPipeline{topic: topic, cfg: cfg, publishers: make(map[string]Publisher)}
+	for _, t := range append(p.RetryTopics(), DLQTopic(topic)) {
+		publisher, err := broker.Publisher(t)
+		if err != nil {
+			p.Close()
+			return nil, err
+		}
+		p.publishers[t] = publisher
+	}
+	return p, nil
+}
+
+// RetryTopics returns the retry topics, in the order messages go through them. Consumers of the
+// topic must consume them as well.
+func (p *RetryPipeline) RetryTopics() []string {
+	topics := make([]string, 0, p.cfg.MaxRetries)
+	for attempt := 1; attempt <= p.cfg.MaxRetries; attempt++ {
+		topics = append(topics, RetryTopic(p.topic, attempt))
+	}
+	return topics
+}
+
+// Fail sends a message that failed with err to the next retry topic, or to the dead-letter topic
+// when its retries are used up or the failure is permanent.
+func (p *RetryPipeline) Fail(ctx context.Context, m kafka.Message, err error) error {
+	attempt := headerInt(m, HeaderAttempt) + 1
+	now := time.Now().UTC()
+
+	headers := map[string]string{
+		HeaderAttempt:  strconv.Itoa(attempt),
+		HeaderError:    err.Error(),
+		HeaderFailedAt: now.Format(time.RFC3339),
+	}
+	// The first failure records where the message came from
+	if header(m, HeaderOriginalTopic) == "" {
+		headers[HeaderOriginalTopic] = m.Topic
+		headers[HeaderOriginalPartition] = strconv.Itoa(m.Partition)
+		headers[HeaderOriginalOffset] = strconv.FormatInt(m.Offset, 10)
+	}
+
+	topic := DLQTopic(p.topic)
+	if attempt <= p.cfg.MaxRetries && !isPermanent(err) {
+		topic = RetryTopic(p.topic, attempt)
+		headers[HeaderRetryAt] = now.Add(p.backoff(attempt)).Format(time.RFC3339Nano)
+	}
+
+	if err := p.publishers[topic].Publish(ctx, kafka.Message{
+		Key:     m.Key,
+		Value:   m.Value,
+		Headers: withHeaders(m.Headers, headers),
+	}); err != nil {
+		return fmt.Errorf("failed to publish message to %s: %w", topic, err)
+	}
+	return nil
+}
+
+func (p *RetryPipeline) backoff(attempt int) time.Duration {
+	return p.cfg.RetryBackoff << (attempt - 1)
+}
+
+func (p *RetryPipeline) Close() error {
+	var err error
+	for _, publisher := range p.publishers {
+		if e := publisher.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// waitUntilDue blocks until a message from a retry topic is due. Messages without a retry time are
+// due right away.
+func waitUntilDue(ctx context.Context, m kafka.Message) error {
+	retryAt, err := time.Parse(time.RFC3339Nano, header(m, HeaderRetryAt))
+	if err != nil {
+		return nil
+	}
+
+	timer := time.NewTimer(time.Until(retryAt))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func header(m kafka.Message, key string) string {
+	for _, h := range m.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func headerInt(m kafka.Message, key string) int {
+	n, _ := strconv.Atoi(header(m, key))
+	return n
+}
+
+// withHeaders returns headers with the values of set replacing or adding to them.
+func withHeaders(headers []kafka.Header, set map[string]string) []kafka.Header {
+	result := make([]kafka.Header, 0, len(headers)+len(set))
+	for _, h := range headers {
+		if _, ok := set[h.Key]; !ok {
+			result = append(result, h)
+		}
+	}
+	for key, value := range set {
+		result = append(result, kafka.Header{Key: key, Value: []byte(value)})
+	}
+	return result
+}
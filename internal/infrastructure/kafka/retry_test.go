@@ -0,0 +1,265 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"mpc/internal/infrastructure/config"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+var errTransient = errors.New("node unavailable")
+
+func newTestPipeline(t *testing.T, broker Broker) *RetryPipeline {
+	t.Helper()
+
+	pipeline, err := NewRetryPipeline(broker, &config.KafkaConfig{MaxRetries: 2, RetryBackoff: 50 * time.Millisecond}, "txn")
+	if err != nil {
+		t.Fatalf("NewRetryPipeline() error = %v", err)
+	}
+	t.Cleanup(func() { pipeline.Close() })
+	return pipeline
+}
+
+func TestRetryPipelineFail(t *testing.T) {
+	tests := []struct {
+		name string
+		// attempt is the number of failed attempts the message already went through
+		attempt     int
+		err         error
+		wantTopic   string
+		wantBackoff time.Duration
+	}{
+		{name: "first failure", attempt: 0, err: errTransient, wantTopic: "txn.retry.1", wantBackoff: 50 * time.Millisecond},
+		{name: "backoff doubles", attempt: 1, err: errTransient, wantTopic: "txn.retry.2", wantBackoff: 100 * time.Millisecond},
+		{name: "retries used up", attempt: 2, err: errTransient, wantTopic: "txn.dlq"},
+		{name: "permanent failure", attempt: 0, err: Permanent(errors.New("malformed message")), wantTopic: "txn.dlq"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broker := NewMemoryBroker()
+			pipeline := newTestPipeline(t, broker)
+
+			m := Message{Topic: "txn", Partition: 3, Offset: 42, Key: []byte("key"), Value: []byte("value")}
+			if tt.attempt > 0 {
+				m.Topic = RetryTopic("txn", tt.attempt)
+				m.Headers = []Header{
+					{Key: HeaderAttempt, Value: []byte(strconv.Itoa(tt.attempt))},
+					{Key: HeaderOriginalTopic, Value: []byte("txn")},
+					{Key: HeaderOriginalPartition, Value: []byte("3")},
+					{Key: HeaderOriginalOffset, Value: []byte("42")},
+				}
+			}
+
+			before := time.Now()
+			if err := pipeline.Fail(context.Background(), m, tt.err); err != nil {
+				t.Fatalf("Fail() error = %v", err)
+			}
+
+			for _, topic := range append(pipeline.RetryTopics(), DLQTopic("txn")) {
+				if got := len(broker.Messages(topic)); topic != tt.wantTopic && got != 0 {
+					t.Errorf("Fail() sent %d messages to %s, want none", got, topic)
+				}
+			}
+			messages := broker.Messages(tt.wantTopic)
+			if len(messages) != 1 {
+				t.Fatalf("Fail() sent %d messages to %s, want 1", len(messages), tt.wantTopic)
+			}
+			got := messages[0]
+
+			if string(got.Key) != "key" || string(got.Value) != "value" {
+				t.Errorf("Fail() sent %s=%s, want key=value", got.Key, got.Value)
+			}
+			if attempt := header(got, HeaderAttempt); attempt != strconv.Itoa(tt.attempt+1) {
+				t.Errorf("%s = %s, want %d", HeaderAttempt, attempt, tt.attempt+1)
+			}
+			if header(got, HeaderError) != tt.err.Error() {
+				t.Errorf("%s = %q, want %q", HeaderError, header(got, HeaderError), tt.err.Error())
+			}
+			if header(got, HeaderOriginalTopic) != "txn" || header(got, HeaderOriginalPartition) != "3" || header(got, HeaderOriginalOffset) != "42" {
+				t.Errorf("original position = %s/%s/%s, want txn/3/42", header(got, HeaderOriginalTopic), header(got, HeaderOriginalPartition), header(got, HeaderOriginalOffset))
+			}
+
+			retryAt := header(got, HeaderRetryAt)
+			if tt.wantBackoff == 0 {
+				if retryAt != "" {
+					t.Errorf("%s = %s on a dead letter, want none", HeaderRetryAt, retryAt)
+				}
+				return
+			}
+			due, err := time.Parse(time.RFC3339Nano, retryAt)
+			if err != nil {
+				t.Fatalf("%s = %q: %v", HeaderRetryAt, retryAt, err)
+			}
+			if wait := due.Sub(before); wait < tt.wantBackoff || wait > tt.wantBackoff+time.Second {
+				t.Errorf("%s is %v after the failure, want %v", HeaderRetryAt, wait, tt.wantBackoff)
+			}
+		})
+	}
+}
+
+// handled is a message as the handler saw it, and when.
+type handled struct {
+	m  Message
+	at time.Time
+}
+
+// consumeWithRetries runs ConsumeMessagesWithRetries on txn and its retry topics until the test
+// ends, recording every message handed to handler.
+func consumeWithRetries(t *testing.T, broker *MemoryBroker, handler func(m Message) error) func() []handled {
+	t.Helper()
+
+	pipeline := newTestPipeline(t, broker)
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+
+	var mu sync.Mutex
+	var seen []handled
+	for _, topic := range append([]string{"txn"}, pipeline.RetryTopics()...) {
+		subscriber, err := broker.Subscriber(topic, "worker")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ConsumeMessagesWithRetries(ctx, subscriber, pipeline, func(ctx context.Context, m Message) error {
+				mu.Lock()
+				seen = append(seen, handled{m: m, at: time.Now()})
+				mu.Unlock()
+				return handler(m)
+			})
+		}()
+	}
+
+	return func() []handled {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]handled(nil), seen...)
+	}
+}
+
+// waitFor polls until cond holds or the test times out.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestConsumeMessagesWithRetries(t *testing.T) {
+	t.Run("dead-lettered after the last attempt", func(t *testing.T) {
+		broker := NewMemoryBroker()
+		seen := consumeWithRetries(t, broker, func(m Message) error { return errTransient })
+
+		publisher, _ := broker.Publisher("txn")
+		if err := publisher.Publish(context.Background(), Message{Key: []byte("a")}); err != nil {
+			t.Fatalf("Failed to publish message: %v", err)
+		}
+		waitFor(t, "the dead letter", func() bool { return len(broker.Messages("txn.dlq")) == 1 })
+
+		// The message went through txn, txn.retry.1 and txn.retry.2, each retry once it was due
+		attempts := seen()
+		if len(attempts) != 3 {
+			t.Fatalf("Handler saw %d attempts, want 3", len(attempts))
+		}
+		for i, want := range []string{"txn", "txn.retry.1", "txn.retry.2"} {
+			got := attempts[i]
+			if got.m.Topic != want {
+				t.Errorf("Attempt %d read from %s, want %s", i+1, got.m.Topic, want)
+			}
+			if i == 0 {
+				continue
+			}
+			due, err := time.Parse(time.RFC3339Nano, header(got.m, HeaderRetryAt))
+			if err != nil {
+				t.Fatalf("Attempt %d has no %s: %v", i+1, HeaderRetryAt, err)
+			}
+			if got.at.Before(due) {
+				t.Errorf("Attempt %d handled %v before it was due", i+1, due.Sub(got.at))
+			}
+		}
+		if attempt := header(broker.Messages("txn.dlq")[0], HeaderAttempt); attempt != "3" {
+			t.Errorf("Dead letter %s = %s, want 3", HeaderAttempt, attempt)
+		}
+	})
+
+	t.Run("permanent errors skip the retries", func(t *testing.T) {
+		broker := NewMemoryBroker()
+		seen := consumeWithRetries(t, broker, func(m Message) error { return Permanent(errors.New("malformed message")) })
+
+		publisher, _ := broker.Publisher("txn")
+		if err := publisher.Publish(context.Background(), Message{Key: []byte("a")}); err != nil {
+			t.Fatalf("Failed to publish message: %v", err)
+		}
+		waitFor(t, "the dead letter", func() bool { return len(broker.Messages("txn.dlq")) == 1 })
+
+		if got := len(seen()); got != 1 {
+			t.Errorf("Handler saw %d attempts, want 1", got)
+		}
+		if got := len(broker.Messages("txn.retry.1")); got != 0 {
+			t.Errorf("txn.retry.1 has %d messages, want none", got)
+		}
+	})
+
+	t.Run("a retry that succeeds ends the pipeline", func(t *testing.T) {
+		broker := NewMemoryBroker()
+		seen := consumeWithRetries(t, broker, func(m Message) error {
+			if m.Topic == "txn" {
+				return errTransient
+			}
+			return nil
+		})
+
+		publisher, _ := broker.Publisher("txn")
+		if err := publisher.Publish(context.Background(), Message{Key: []byte("a")}); err != nil {
+			t.Fatalf("Failed to publish message: %v", err)
+		}
+		waitFor(t, "the retry", func() bool { return len(seen()) == 2 })
+
+		// Leave time for a stray hand-over to show up
+		time.Sleep(100 * time.Millisecond)
+		if got := len(broker.Messages("txn.retry.2")) + len(broker.Messages("txn.dlq")); got != 0 {
+			t.Errorf("Succeeded message was handed over %d more times, want none", got)
+		}
+	})
+}
+
+func TestWaitUntilDue(t *testing.T) {
+	due := Message{Headers: []Header{{Key: HeaderRetryAt, Value: []byte(time.Now().Add(100 * time.Millisecond).Format(time.RFC3339Nano))}}}
+
+	start := time.Now()
+	if err := waitUntilDue(context.Background(), due); err != nil {
+		t.Fatalf("waitUntilDue() error = %v", err)
+	}
+	if waited := time.Since(start); waited < 90*time.Millisecond {
+		t.Errorf("waitUntilDue() returned after %v, want about 100ms", waited)
+	}
+
+	// Messages from the original topic have no retry time
+	start = time.Now()
+	if err := waitUntilDue(context.Background(), Message{}); err != nil {
+		t.Fatalf("waitUntilDue() error = %v", err)
+	}
+	if waited := time.Since(start); waited > 50*time.Millisecond {
+		t.Errorf("waitUntilDue() waited %v for a message without %s", waited, HeaderRetryAt)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	late := Message{Headers: []Header{{Key: HeaderRetryAt, Value: []byte(time.Now().Add(time.Hour).Format(time.RFC3339Nano))}}}
+	if err := waitUntilDue(ctx, late); !errors.Is(err, context.Canceled) {
+		t.Errorf("waitUntilDue() error = %v, want context.Canceled", err)
+	}
+}
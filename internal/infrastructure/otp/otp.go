@@ -45,4 +45,3 @@ func (s *otpService) VerifyOTP(ctx context.Context, email, otp string) error {
 	}
 	return s.redisClient.Delete(ctx, fmt.Sprintf("otp:%s", email))
 }
-
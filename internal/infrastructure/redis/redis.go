@@ -13,7 +13,7 @@ type RedisClient struct {
 }
 
 func NewRedisClient(cfg *config.RedisConfig) (*RedisClient, error) {
-	
+
 	client := redis.NewClient(&redis.Options{
 		Addr:     cfg.Address,
 		Username: cfg.Username,
@@ -0,0 +1,222 @@
+// Package safe provides an implementation of the SafeRepository interface
+// for co-signing Safe (Gnosis Safe) multisig transactions.
+package safe
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/repository"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const safeABIJSON = `[
+	{"inputs":[],"name":"getOwners","outputs":[{"name":"","type":"address[]"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"getThreshold","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"nonce","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}],"name":"execTransaction","outputs":[{"name":"success","type":"bool"}],"stateMutability":"payable","type":"function"}
+]`
+
+var (
+	safeABI = mustParseABI(safeABIJSON)
+
+	// EIP-712 type hashes used by Safe >= 1.3.0
+	domainSeparatorTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+	safeTxTypeHash          = crypto.Keccak256Hash([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+
+	addressType, _ = abi.NewType("address", "", nil)
+	uint256Type, _ = abi.NewType("uint256", "", nil)
+	uint8Type, _   = abi.NewType("uint8", "", nil)
+	bytes32Type, _ = abi.NewType("bytes32", "", nil)
+
+	domainSeparatorArgs = abi.Arguments{{Type: bytes32Type}, {Type: uint256Type}, {Type: addressType}}
+	safeTxArgs          = abi.Arguments{
+		{Type: bytes32Type}, // type hash
+		{Type: addressType}, // to
+		{Type: uint256Type}, // value
+		{Type: bytes32Type}, // keccak256(data)
+		{Type: uint8Type},   // operation
+		{Type: uint256Type}, // safeTxGas
+		{Type: uint256Type}, // baseGas
+		{Type: uint256Type}, // gasPrice
+		{Type: addressType}, // gasToken
+		{Type: addressType}, // refundReceiver
+		{Type: uint256Type}, // nonce
+	}
+)
+
+// operationCall is the only Safe operation supported; delegate calls are never proposed.
+const operationCall uint8 = 0
+
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+type Client struct {
+	eth *ethclient.Client
+}
+
+func NewClient(cfg *config.EthereumConfig) (*Client, error) {
+	eth, err := ethclient.Dial(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ethereum client: %w", err)
+	}
+	return &Client{eth: eth}, nil
+}
+
+// Ensure Client implements SafeRepository
+var _ repository.SafeRepository = (*Client)(nil)
+
+// GetSafeInfo reads the owners, threshold and current nonce of a Safe.
+func (c *Client) GetSafeInfo(ctx context.Context, safe common.Address) (domain.SafeInfo, error) {
+	var owners []common.Address
+	if err := c.call(ctx, safe, "getOwners", &owners); err != nil {
+		return domain.SafeInfo{}, err
+	}
+
+	var threshold *big.Int
+	if err := c.call(ctx, safe, "getThreshold", &threshold); err != nil {
+		return domain.SafeInfo{}, err
+	}
+
+	var nonce *big.Int
+	if err := c.call(ctx, safe, "nonce", &nonce); err != nil {
+		return domain.SafeInfo{}, err
+	}
+
+	return domain.SafeInfo{
+		Owners:    owners,
+		Threshold: int(threshold.Int64()),
+		Nonce:     nonce,
+	}, nil
+}
+
+// GetTransactionHash computes the EIP-712 hash owners sign for a Safe transaction.
+func (c *Client) GetTransactionHash(ctx context.Context, tx domain.SafeTransaction) (common.Hash, error) {
+	chainID, err := c.eth.ChainID(ctx)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to get chain ID: %w", err)
+	}
+	return TransactionHash(tx, chainID)
+}
+
+// TransactionHash computes the EIP-712 hash of a Safe transaction on the given chain.
+func TransactionHash(tx domain.SafeTransaction, chainID *big.Int) (common.Hash, error) {
+	domainSeparator, err := domainSeparatorArgs.Pack(domainSeparatorTypeHash, chainID, tx.Safe)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	zero := new(big.Int)
+	structData, err := safeTxArgs.Pack(
+		safeTxTypeHash,
+		tx.To,
+		tx.Value,
+		crypto.Keccak256Hash(tx.Data),
+		operationCall,
+		zero,
+		zero,
+		zero,
+		common.Address{},
+		common.Address{},
+		tx.Nonce,
+	)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	return crypto.Keccak256Hash(
+		[]byte{0x19, 0x01},
+		crypto.Keccak256(domainSeparator),
+		crypto.Keccak256(structData),
+	), nil
+}
+
+// SignTransactionHash signs the Safe transaction hash directly (v = 27 or 28).
+func (c *Client) SignTransactionHash(hash common.Hash, privateKey *ecdsa.PrivateKey) ([]byte, error) {
+	signature, err := crypto.Sign(hash.Bytes(), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign Safe transaction: %w", err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+	return signature, nil
+}
+
+// RecoverSigner returns the owner that produced an ECDSA signature over the Safe transaction hash.
+// Both plain signatures (v = 27/28) and eth_sign signatures (v = 31/32) are accepted.
+func (c *Client) RecoverSigner(hash common.Hash, signature []byte) (common.Address, error) {
+	if len(signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("invalid signature length: %d", len(signature))
+	}
+
+	sig := make([]byte, len(signature))
+	copy(sig, signature)
+
+	digest := hash.Bytes()
+	v := sig[crypto.RecoveryIDOffset]
+	switch {
+	case v == 27 || v == 28:
+		sig[crypto.RecoveryIDOffset] = v - 27
+	case v == 31 || v == 32:
+		sig[crypto.RecoveryIDOffset] = v - 31
+		digest = accounts.TextHash(hash.Bytes())
+	default:
+		return common.Address{}, errors.New("unsupported signature type")
+	}
+
+	publicKey, err := crypto.SigToPub(digest, sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover signer: %w", err)
+	}
+	return crypto.PubkeyToAddress(*publicKey), nil
+}
+
+// EncodeExecTransaction encodes the execTransaction call for tx with the packed owner signatures.
+func (c *Client) EncodeExecTransaction(tx domain.SafeTransaction, signatures []byte) ([]byte, error) {
+	zero := new(big.Int)
+	return safeABI.Pack("execTransaction",
+		tx.To,
+		tx.Value,
+		tx.Data,
+		operationCall,
+		zero,
+		zero,
+		zero,
+		common.Address{},
+		common.Address{},
+		signatures,
+	)
+}
+
+func (c *Client) call(ctx context.Context, safe common.Address, method string, result interface{}) error {
+	data, err := safeABI.Pack(method)
+	if err != nil {
+		return err
+	}
+
+	out, err := c.eth.CallContract(ctx, ethereum.CallMsg{To: &safe, Data: data}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to call %s on Safe: %w", method, err)
+	}
+
+	if err := safeABI.UnpackIntoInterface(result, method, out); err != nil {
+		return fmt.Errorf("failed to decode %s result: %w", method, err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package safe
+
+import (
+	"math/big"
+	"mpc/internal/domain"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// The expected hashes are the EIP-712 typed data hashes of the SafeTx message (Safe >= 1.3.0 domain
+// with chainId and verifyingContract), as computed by go-ethereum's apitypes.TypedDataAndHash.
+func TestTransactionHash(t *testing.T) {
+	tests := []struct {
+		name    string
+		chainID int64
+		tx      domain.SafeTransaction
+		want    string
+	}{
+		{
+			name:    "ether transfer on mainnet",
+			chainID: 1,
+			tx: domain.SafeTransaction{
+				Safe:  common.HexToAddress("0x1c8b9B78e3085866521FE206fa4c1a67F49f153A"),
+				To:    common.HexToAddress("0x6a8A8CE9F0b7E1b7a76fA3E3a6Df7D3C7e3F0b5E"),
+				Value: big.NewInt(1_000_000_000_000_000_000),
+				Nonce: big.NewInt(0),
+			},
+			want: "0xb89a5b31981d1e8b56558eff43c79d89b4ac28a2323fda262c8509e92e20c848",
+		},
+		{
+			name:    "token transfer on sepolia",
+			chainID: 11155111,
+			tx: domain.SafeTransaction{
+				Safe:  common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"),
+				To:    common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"),
+				Value: big.NewInt(0),
+				Data:  hexutil.MustDecode("0xa9059cbb000000000000000000000000d8da6bf26964af9d7eed9e03e53415d37aa960450000000000000000000000000000000000000000000000000000000000000064"),
+				Nonce: big.NewInt(7),
+			},
+			want: "0xbf5b9f65b04f94e16d08ad33eecb6a59f8d0cefdd70c26cdb1043c85ffcfd6de",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TransactionHash(tt.tx, big.NewInt(tt.chainID))
+			if err != nil {
+				t.Fatalf("TransactionHash() error = %v", err)
+			}
+			if got.Hex() != tt.want {
+				t.Errorf("TransactionHash() = %s, want %s", got.Hex(), tt.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/ecdsa"
 	"math/big"
 	"mpc/internal/domain"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -12,7 +13,7 @@ import (
 )
 
 type UserRepository interface {
-	CreateUser(ctx context.Context, params domain.CreateHashedUserParams) (domain.User, error)
+	CreateUser(ctx context.Context, params domain.CreateHashedUserParams, messages ...domain.OutboxMessage) (domain.User, error)
 	GetUser(ctx context.Context, id uuid.UUID) (domain.User, error)
 	GetUserByEmail(ctx context.Context, email string) (domain.User, error)
 	UpdateUser(ctx context.Context, user domain.User) (domain.User, error)
@@ -20,28 +21,168 @@ type UserRepository interface {
 }
 
 type WalletRepository interface {
-	CreateWallet(ctx context.Context, params domain.CreateWalletParams) (domain.Wallet, error)
+	CreateWallet(ctx context.Context, params domain.CreateWalletParams, messages ...domain.OutboxMessage) (domain.Wallet, error)
 	GetWallet(ctx context.Context, id uuid.UUID) (domain.Wallet, error)
 	GetWalletByUserID(ctx context.Context, userID uuid.UUID) (domain.Wallet, error)
 	GetWalletByAddress(ctx context.Context, address string) (domain.Wallet, error)
+	ListWallets(ctx context.Context) ([]domain.Wallet, error)
 	DBTransaction
 }
 
 type TransactionRepository interface {
-	CreateTransaction(ctx context.Context, params domain.CreateTransactionParams) (domain.Transaction, error)
+	// CreateTransaction and CreateInboundTransaction store the transaction together with the outbox
+	// messages announcing it. A deposit that is already recorded is skipped, messages included.
+	CreateTransaction(ctx context.Context, params domain.CreateTransactionParams, messages ...domain.OutboxMessage) (domain.Transaction, error)
 	GetTransaction(ctx context.Context, id uuid.UUID) (domain.Transaction, error)
 	GetTransactionsByWalletID(ctx context.Context, walletID uuid.UUID) ([]domain.Transaction, error)
-	UpdateTransaction(ctx context.Context, transaction domain.Transaction) error
+	// UpdateTransaction saves the transaction together with the outbox messages announcing the
+	// change, in one database transaction. The status is copied to the transactions linked to it.
+	UpdateTransaction(ctx context.Context, transaction domain.Transaction, messages ...domain.OutboxMessage) error
+	CreateInboundTransaction(ctx context.Context, params domain.CreateInboundTransactionParams, messages ...domain.OutboxMessage) error
+	GetSubmittedTransactionsByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.Transaction, error)
+	// GetNextNonce returns the nonce following the submitted transactions of the wallet, including
+	// those the node has not seen yet because they wait for a gas top-up.
+	GetNextNonce(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) (uint64, error)
+	DBTransaction
+}
+
+type ChainRepository interface {
+	ListChains(ctx context.Context) ([]domain.Chain, error)
+	GetChain(ctx context.Context, id uuid.UUID) (domain.Chain, error)
+	GetTokensByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.Token, error)
+	GetChainCursor(ctx context.Context, chainID uuid.UUID) (domain.ChainCursor, error)
+	UpdateChainCursor(ctx context.Context, cursor domain.ChainCursor) error
+	DBTransaction
+}
+
+// OutboxRepository holds the Kafka messages waiting in the outbox.
+type OutboxRepository interface {
+	// ClaimPendingMessages leases up to limit due messages for lease, in the order they were written,
+	// so no other relay claims them while they are published. Only the oldest unsent message of each
+	// key is claimed, so a message waiting for a retry holds back the later messages of its key until
+	// it is sent.
+	ClaimPendingMessages(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error)
+	// MarkMessagesSent marks published messages sent and ends their lease.
+	MarkMessagesSent(ctx context.Context, ids []uuid.UUID) error
+	// MarkMessageFailed records a failed publish and ends the lease; the message is due again at
+	// nextAttemptAt.
+	MarkMessageFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error
+}
+
+// UTXORepository stores the Bitcoin addresses handed out for wallets and the outputs paying to them.
+type UTXORepository interface {
+	CreateBitcoinAddress(ctx context.Context, address domain.BitcoinAddress) error
+	GetUnusedChangeAddress(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) (domain.BitcoinAddress, error)
+	GetNextChangeIndex(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) (uint32, error)
+	ListBitcoinAddressesByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.BitcoinAddress, error)
+	ListBitcoinAddressesByWalletID(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) ([]domain.BitcoinAddress, error)
+	UpsertUTXO(ctx context.Context, utxo domain.UTXO) error
+	ListUnspentUTXOsByWalletID(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) ([]domain.UTXO, error)
+	ListUnspentUTXOsByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.UTXO, error)
+	ReserveUTXOs(ctx context.Context, transactionID uuid.UUID, utxoIDs []uuid.UUID) (bool, error)
+	ReleaseUTXOs(ctx context.Context, transactionID uuid.UUID) error
+	SpendUTXO(ctx context.Context, chainID uuid.UUID, outPoint domain.BitcoinOutPoint, spentTxID string, blockNumber int64) error
+	RewindUTXOs(ctx context.Context, chainID uuid.UUID, blockNumber int64) error
+	DBTransaction
+}
+
+type BalanceRepository interface {
+	UpsertBalance(ctx context.Context, params domain.UpsertBalanceParams) error
+	GetBalancesByWalletID(ctx context.Context, walletID uuid.UUID) ([]domain.Balance, error)
+	ReserveBalance(ctx context.Context, params domain.ReserveBalanceParams) (bool, error)
+	ReleaseReservation(ctx context.Context, transactionID uuid.UUID) error
+	GetReservedAmount(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) (*big.Int, error)
+	DBTransaction
+}
+
+type RelayerRepository interface {
+	CreateRelayerWallet(ctx context.Context, params domain.CreateRelayerWalletParams) (domain.RelayerWallet, error)
+	ListActiveRelayerWallets(ctx context.Context, chainID uuid.UUID) ([]domain.RelayerWallet, error)
+	EnsureSponsorshipBudget(ctx context.Context, userID uuid.UUID, chainID uuid.UUID, budget *big.Int) (domain.SponsorshipBudget, error)
+	ReserveSponsorship(ctx context.Context, userID uuid.UUID, chainID uuid.UUID, amount *big.Int) (domain.SponsorshipBudget, error)
+	ReleaseSponsorship(ctx context.Context, userID uuid.UUID, chainID uuid.UUID, amount *big.Int) (domain.SponsorshipBudget, error)
+	CreateGasSponsorship(ctx context.Context, params domain.CreateGasSponsorshipParams) (domain.GasSponsorship, error)
+	GetGasSponsorshipsByUserID(ctx context.Context, userID uuid.UUID) ([]domain.GasSponsorship, error)
+	GetPendingGasSponsorshipsByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.GasSponsorship, error)
+	ConfirmGasSponsorship(ctx context.Context, id uuid.UUID, fee *big.Int) error
+	FailGasSponsorship(ctx context.Context, sponsorship domain.GasSponsorship, fee *big.Int) error
+	DBTransaction
+}
+
+type UserOperationRepository interface {
+	CreateUserOperation(ctx context.Context, params domain.CreateUserOperationTxnParams) (domain.UserOperationTxn, error)
+	GetUserOperation(ctx context.Context, id uuid.UUID) (domain.UserOperationTxn, error)
+	UpdateUserOperation(ctx context.Context, userOp domain.UserOperationTxn) error
+	DBTransaction
+}
+
+type SafeProposalRepository interface {
+	CreateSafeProposal(ctx context.Context, params domain.CreateSafeProposalParams) (domain.SafeProposal, error)
+	GetSafeProposal(ctx context.Context, id uuid.UUID) (domain.SafeProposal, error)
+	UpdateSafeProposal(ctx context.Context, proposal domain.SafeProposal) error
+	AddSafeSignature(ctx context.Context, proposalID uuid.UUID, owner string, signature []byte) (domain.SafeSignature, error)
+	GetSafeSignatures(ctx context.Context, proposalID uuid.UUID) ([]domain.SafeSignature, error)
 	DBTransaction
 }
 
 type EthereumRepository interface {
-	CreateWallet() (*ecdsa.PrivateKey, common.Address, error)
-	GetBalance(address common.Address) (*big.Int, error)
-	CreateUnsignedTransaction(from common.Address, to common.Address, amount *big.Int) (*types.Transaction, error)
-	SignTransaction(tx *types.Transaction, privateKey *ecdsa.PrivateKey) (*types.Transaction, error)
-	SubmitTransaction(signedTx *types.Transaction) (common.Hash, error)
-	WaitForTxn(hash common.Hash) (*types.Receipt, error)
-	EncryptPrivateKey(data []byte) ([]byte, error)
-	DecryptPrivateKey(ciphertext []byte) ([]byte, error)
+	CreateWallet(ctx context.Context) (*ecdsa.PrivateKey, common.Address, error)
+	GetBalance(ctx context.Context, address common.Address) (*big.Int, error)
+	GetTokenBalance(ctx context.Context, token common.Address, owner common.Address) (*big.Int, error)
+	CreateUnsignedTransaction(ctx context.Context, from common.Address, to common.Address, amount *big.Int) (*types.Transaction, error)
+	CreateUnsignedContractTransaction(ctx context.Context, from common.Address, to common.Address, amount *big.Int, data []byte) (*types.Transaction, error)
+	CreateUnsignedTransactionWithNonce(ctx context.Context, from common.Address, to common.Address, amount *big.Int, data []byte, nonce uint64) (*types.Transaction, error)
+	GetPendingNonce(ctx context.Context, address common.Address) (uint64, error)
+	SignTransaction(ctx context.Context, tx *types.Transaction, privateKey *ecdsa.PrivateKey) (*types.Transaction, error)
+	SigningHash(ctx context.Context, tx *types.Transaction) (common.Hash, error)
+	AddSignature(ctx context.Context, tx *types.Transaction, signature []byte) (*types.Transaction, error)
+	SubmitTransaction(ctx context.Context, signedTx *types.Transaction) (common.Hash, error)
+	WaitForTxn(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+	GetTransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+	GetLatestBlockNumber(ctx context.Context) (uint64, error)
+	GetBlockHash(ctx context.Context, number uint64) (common.Hash, error)
+	FindTransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error)
+	FindTransactionReceipts(ctx context.Context, hashes []common.Hash) (map[common.Hash]*types.Receipt, error)
+	WatchChain(ctx context.Context, tokens []common.Address, pollInterval time.Duration) <-chan domain.ChainEvent
+	GetRevertReason(ctx context.Context, hash common.Hash, blockNumber *big.Int) (string, error)
+	HasTransaction(ctx context.Context, hash common.Hash) (bool, error)
+	GetTransactionState(ctx context.Context, hash common.Hash, from common.Address, nonce uint64) (domain.TxState, error)
+	ScanBlock(ctx context.Context, number uint64, watched map[common.Address]bool, tokens []common.Address) (domain.ScannedBlock, error)
+	EncryptPrivateKey(ctx context.Context, data []byte) ([]byte, error)
+	DecryptPrivateKey(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+type SmartAccountRepository interface {
+	GetAccountAddress(ctx context.Context, owner common.Address) (common.Address, error)
+	IsDeployed(ctx context.Context, account common.Address) (bool, error)
+	BuildUserOperation(ctx context.Context, owner common.Address, to common.Address, value *big.Int, data []byte) (*domain.UserOperation, error)
+	SignUserOperation(ctx context.Context, userOp *domain.UserOperation, privateKey *ecdsa.PrivateKey) (common.Hash, error)
+	SendUserOperation(ctx context.Context, userOp *domain.UserOperation) (common.Hash, error)
+	GetUserOperationReceipt(ctx context.Context, userOpHash common.Hash) (*domain.UserOperationReceipt, error)
+}
+
+type SafeRepository interface {
+	GetSafeInfo(ctx context.Context, safe common.Address) (domain.SafeInfo, error)
+	GetTransactionHash(ctx context.Context, tx domain.SafeTransaction) (common.Hash, error)
+	SignTransactionHash(hash common.Hash, privateKey *ecdsa.PrivateKey) ([]byte, error)
+	RecoverSigner(hash common.Hash, signature []byte) (common.Address, error)
+	EncodeExecTransaction(tx domain.SafeTransaction, signatures []byte) ([]byte, error)
+}
+
+type DisperseRepository interface {
+	ContractAddress() common.Address
+	EncodeDisperseEther(recipients []common.Address, values []*big.Int) ([]byte, error)
+}
+
+type ENSRepository interface {
+	ResolveName(ctx context.Context, name string) (common.Address, error)
+}
+
+// BitcoinBackend is the node-facing side of a Bitcoin chain. Amounts are in satoshis and fee rates in sat/vB.
+type BitcoinBackend interface {
+	GetBlockCount(ctx context.Context) (uint64, error)
+	GetBlockHash(ctx context.Context, number uint64) (string, error)
+	GetBlock(ctx context.Context, hash string) (domain.BitcoinBlock, error)
+	EstimateFeeRate(ctx context.Context, targetBlocks int) (int64, error)
+	Broadcast(ctx context.Context, rawTx []byte) (string, error)
 }
@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"math/big"
+	"mpc/internal/domain"
+	sqlc "mpc/internal/infrastructure/db/sqlc"
+	"mpc/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type balanceRepository struct {
+	repository.BaseRepository
+}
+
+func NewBalanceRepo(dbPool *pgxpool.Pool) repository.BalanceRepository {
+	return &balanceRepository{
+		BaseRepository: repository.NewBaseRepo(dbPool),
+	}
+}
+
+// Ensure BalanceRepository implements BalanceRepository
+var _ repository.BalanceRepository = (*balanceRepository)(nil)
+
+func (r *balanceRepository) UpsertBalance(ctx context.Context, params domain.UpsertBalanceParams) error {
+	return r.WithTx(ctx, func(tx pgx.Tx) error {
+		q := sqlc.New(tx)
+		return q.UpsertBalance(ctx, sqlc.UpsertBalanceParams{
+			WalletID: pgtype.UUID{Bytes: params.WalletID, Valid: true},
+			ChainID:  pgtype.UUID{Bytes: params.ChainID, Valid: true},
+			TokenID:  pgtype.UUID{Bytes: params.TokenID, Valid: params.TokenID != uuid.Nil},
+			Balance:  toNumeric(params.Balance),
+		})
+	})
+}
+
+func (r *balanceRepository) GetBalancesByWalletID(ctx context.Context, walletID uuid.UUID) ([]domain.Balance, error) {
+	q := sqlc.New(r.DB())
+	dbBalances, err := q.GetBalancesByWalletID(ctx, pgtype.UUID{Bytes: walletID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]domain.Balance, 0, len(dbBalances))
+	for _, b := range dbBalances {
+		balances = append(balances, domain.Balance{
+			ID:        b.ID.Bytes,
+			WalletID:  b.WalletID.Bytes,
+			ChainID:   b.ChainID.Bytes,
+			TokenID:   b.TokenID.Bytes,
+			Balance:   fromNumeric(b.Balance),
+			UpdatedAt: b.UpdatedAt.Time,
+		})
+	}
+	return balances, nil
+}
+
+// ReserveBalance records the reservation if the wallet's unreserved balance covers it. The wallet row
+// is locked while the reservations are summed, so concurrent transactions cannot both spend the same funds.
+// It reports whether the reservation was made.
+func (r *balanceRepository) ReserveBalance(ctx context.Context, params domain.ReserveBalanceParams) (bool, error) {
+	reserved := false
+	err := r.WithTx(ctx, func(tx pgx.Tx) error {
+		q := sqlc.New(tx)
+		walletID := pgtype.UUID{Bytes: params.WalletID, Valid: true}
+		chainID := pgtype.UUID{Bytes: params.ChainID, Valid: true}
+
+		if err := q.LockWallet(ctx, walletID); err != nil {
+			return err
+		}
+
+		pending, err := q.GetReservedAmount(ctx, sqlc.GetReservedAmountParams{WalletID: walletID, ChainID: chainID})
+		if err != nil {
+			return err
+		}
+
+		required := new(big.Int).Add(fromNumeric(pending), params.Amount)
+		if required.Cmp(params.Total) > 0 {
+			return nil
+		}
+
+		if err := q.CreateBalanceReservation(ctx, sqlc.CreateBalanceReservationParams{
+			WalletID:      walletID,
+			ChainID:       chainID,
+			TransactionID: pgtype.UUID{Bytes: params.TransactionID, Valid: true},
+			Amount:        toNumeric(params.Amount),
+			ExpiresAt:     pgtype.Timestamptz{Time: params.ExpiresAt, Valid: true},
+		}); err != nil {
+			return err
+		}
+		reserved = true
+		return nil
+	})
+	return reserved, err
+}
+
+func (r *balanceRepository) ReleaseReservation(ctx context.Context, transactionID uuid.UUID) error {
+	q := sqlc.New(r.DB())
+	return q.ReleaseBalanceReservation(ctx, pgtype.UUID{Bytes: transactionID, Valid: true})
+}
+
+func (r *balanceRepository) GetReservedAmount(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) (*big.Int, error) {
+	q := sqlc.New(r.DB())
+	reserved, err := q.GetReservedAmount(ctx, sqlc.GetReservedAmountParams{
+		WalletID: pgtype.UUID{Bytes: walletID, Valid: true},
+		ChainID:  pgtype.UUID{Bytes: chainID, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return fromNumeric(reserved), nil
+}
@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"mpc/internal/domain"
+	sqlc "mpc/internal/infrastructure/db/sqlc"
+	"mpc/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type chainRepository struct {
+	repository.BaseRepository
+}
+
+func NewChainRepo(dbPool *pgxpool.Pool) repository.ChainRepository {
+	return &chainRepository{
+		BaseRepository: repository.NewBaseRepo(dbPool),
+	}
+}
+
+// Ensure ChainRepository implements ChainRepository
+var _ repository.ChainRepository = (*chainRepository)(nil)
+
+func (r *chainRepository) ListChains(ctx context.Context) ([]domain.Chain, error) {
+	q := sqlc.New(r.DB())
+	dbChains, err := q.ListChains(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	chains := make([]domain.Chain, 0, len(dbChains))
+	for _, c := range dbChains {
+		chains = append(chains, toDomainChain(c))
+	}
+	return chains, nil
+}
+
+func (r *chainRepository) GetChain(ctx context.Context, id uuid.UUID) (domain.Chain, error) {
+	q := sqlc.New(r.DB())
+	chain, err := q.GetChain(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		return domain.Chain{}, err
+	}
+	return toDomainChain(chain), nil
+}
+
+func toDomainChain(c sqlc.Chain) domain.Chain {
+	return domain.Chain{
+		ID:                    c.ID.Bytes,
+		Name:                  c.Name,
+		Family:                domain.ChainFamily(c.Family),
+		ChainID:               c.ChainID,
+		RPCURL:                c.RpcUrl,
+		FallbackRPCURLs:       c.FallbackRpcUrls,
+		NativeCurrency:        c.NativeCurrency,
+		ExplorerURL:           c.ExplorerUrl.String,
+		RequiredConfirmations: int(c.RequiredConfirmations),
+		CreatedAt:             c.CreatedAt.Time,
+		UpdatedAt:             c.UpdatedAt.Time,
+	}
+}
+
+func (r *chainRepository) GetTokensByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.Token, error) {
+	q := sqlc.New(r.DB())
+	dbTokens, err := q.ListTokensByChainID(ctx, pgtype.UUID{Bytes: chainID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := make([]domain.Token, 0, len(dbTokens))
+	for _, t := range dbTokens {
+		tokens = append(tokens, domain.Token{
+			ID:              t.ID.Bytes,
+			ChainID:         t.ChainID.Bytes,
+			ContractAddress: t.ContractAddress,
+			Name:            t.Name,
+			Symbol:          t.Symbol,
+			Decimals:        int(t.Decimals),
+			CreatedAt:       t.CreatedAt.Time,
+			UpdatedAt:       t.UpdatedAt.Time,
+		})
+	}
+	return tokens, nil
+}
+
+// GetChainCursor returns pgx.ErrNoRows when the chain has never been scanned.
+func (r *chainRepository) GetChainCursor(ctx context.Context, chainID uuid.UUID) (domain.ChainCursor, error) {
+	q := sqlc.New(r.DB())
+	cursor, err := q.GetChainCursor(ctx, pgtype.UUID{Bytes: chainID, Valid: true})
+	if err != nil {
+		return domain.ChainCursor{}, err
+	}
+	return domain.ChainCursor{
+		ChainID:     cursor.ChainID.Bytes,
+		BlockNumber: cursor.BlockNumber,
+		BlockHash:   cursor.BlockHash,
+		UpdatedAt:   cursor.UpdatedAt.Time,
+	}, nil
+}
+
+func (r *chainRepository) UpdateChainCursor(ctx context.Context, cursor domain.ChainCursor) error {
+	q := sqlc.New(r.DB())
+	return q.UpsertChainCursor(ctx, sqlc.UpsertChainCursorParams{
+		ChainID:     pgtype.UUID{Bytes: cursor.ChainID, Valid: true},
+		BlockNumber: cursor.BlockNumber,
+		BlockHash:   cursor.BlockHash,
+	})
+}
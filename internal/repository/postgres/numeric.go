@@ -0,0 +1,31 @@
+package postgres
+
+import (
+	"math/big"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// toNumeric converts a wei amount into a NUMERIC(78, 0) parameter.
+func toNumeric(v *big.Int) pgtype.Numeric {
+	if v == nil {
+		return pgtype.Numeric{Int: new(big.Int), Valid: true}
+	}
+	return pgtype.Numeric{Int: new(big.Int).Set(v), Valid: true}
+}
+
+// fromNumeric converts a NUMERIC(78, 0) column back into a wei amount.
+// Postgres may return the value with a positive exponent (e.g. 1e18 as 1 * 10^18),
+// so the exponent is folded back into the integer.
+func fromNumeric(n pgtype.Numeric) *big.Int {
+	if !n.Valid || n.Int == nil {
+		return new(big.Int)
+	}
+	v := new(big.Int).Set(n.Int)
+	if n.Exp > 0 {
+		v.Mul(v, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n.Exp)), nil))
+	} else if n.Exp < 0 {
+		v.Quo(v, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(-n.Exp)), nil))
+	}
+	return v
+}
@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"mpc/internal/domain"
+	sqlc "mpc/internal/infrastructure/db/sqlc"
+	"mpc/internal/repository"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type outboxRepository struct {
+	repository.BaseRepository
+}
+
+func NewOutboxRepo(dbPool *pgxpool.Pool) repository.OutboxRepository {
+	return &outboxRepository{
+		BaseRepository: repository.NewBaseRepo(dbPool),
+	}
+}
+
+// Ensure OutboxRepository implements OutboxRepository
+var _ repository.OutboxRepository = (*outboxRepository)(nil)
+
+// ClaimPendingMessages commits the lease before returning, so the messages are published without
+// holding a database transaction or row locks open. The query skips messages with an older unsent
+// message of the same key, even one leased by another relay, so a key is never published out of
+// order and a failure only holds back its own key.
+func (r *outboxRepository) ClaimPendingMessages(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	q := sqlc.New(r.DB())
+	rows, err := q.ClaimOutboxMessages(ctx, sqlc.ClaimOutboxMessagesParams{
+		LockedUntil: pgtype.Timestamptz{Time: time.Now().Add(lease), Valid: true},
+		Limit:       int32(limit),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	// RETURNING does not keep the order of the subquery
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Seq < rows[j].Seq })
+
+	messages := make([]domain.OutboxMessage, 0, len(rows))
+	for _, m := range rows {
+		messages = append(messages, toDomainOutboxMessage(m))
+	}
+	return messages, nil
+}
+
+func (r *outboxRepository) MarkMessagesSent(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.WithTx(ctx, func(tx pgx.Tx) error {
+		q := sqlc.New(tx)
+		for _, id := range ids {
+			if err := q.MarkOutboxMessageSent(ctx, pgtype.UUID{Bytes: id, Valid: true}); err != nil {
+				return fmt.Errorf("failed to mark outbox message %s sent: %w", id, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *outboxRepository) MarkMessageFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	q := sqlc.New(r.DB())
+	if err := q.MarkOutboxMessageFailed(ctx, sqlc.MarkOutboxMessageFailedParams{
+		ID:            pgtype.UUID{Bytes: id, Valid: true},
+		LastError:     pgtype.Text{String: lastError, Valid: true},
+		NextAttemptAt: pgtype.Timestamptz{Time: nextAttemptAt, Valid: true},
+	}); err != nil {
+		return fmt.Errorf("failed to mark outbox message %s failed: %w", id, err)
+	}
+	return nil
+}
+
+// createOutboxMessages writes messages with the queries of the database transaction making the
+// change they announce.
+func createOutboxMessages(ctx context.Context, q *sqlc.Queries, messages []domain.OutboxMessage) error {
+	for _, message := range messages {
+		id := message.ID
+		if id == uuid.Nil {
+			id = uuid.New()
+		}
+		if err := q.CreateOutboxMessage(ctx, sqlc.CreateOutboxMessageParams{
+			ID:      pgtype.UUID{Bytes: id, Valid: true},
+			Topic:   message.Topic,
+			Key:     message.Key,
+			Payload: message.Payload,
+		}); err != nil {
+			return fmt.Errorf("failed to write outbox message: %w", err)
+		}
+	}
+	return nil
+}
+
+func toDomainOutboxMessage(m sqlc.OutboxMessage) domain.OutboxMessage {
+	return domain.OutboxMessage{
+		ID:        m.ID.Bytes,
+		Topic:     m.Topic,
+		Key:       m.Key,
+		Payload:   m.Payload,
+		Attempts:  int(m.Attempts),
+		LastError: m.LastError.String,
+		Seq:       m.Seq,
+		CreatedAt: m.CreatedAt.Time,
+	}
+}
@@ -0,0 +1,217 @@
+package postgres
+
+import (
+	"context"
+	"math/big"
+	"mpc/internal/domain"
+	sqlc "mpc/internal/infrastructure/db/sqlc"
+	"mpc/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type relayerRepository struct {
+	repository.BaseRepository
+}
+
+func NewRelayerRepo(dbPool *pgxpool.Pool) repository.RelayerRepository {
+	return &relayerRepository{
+		BaseRepository: repository.NewBaseRepo(dbPool),
+	}
+}
+
+// Ensure RelayerRepository implements RelayerRepository
+var _ repository.RelayerRepository = (*relayerRepository)(nil)
+
+func (r *relayerRepository) CreateRelayerWallet(ctx context.Context, params domain.CreateRelayerWalletParams) (domain.RelayerWallet, error) {
+	var wallet domain.RelayerWallet
+	err := r.WithTx(ctx, func(tx pgx.Tx) error {
+		q := sqlc.New(tx)
+		createdWallet, err := q.CreateRelayerWallet(ctx, sqlc.CreateRelayerWalletParams{
+			ChainID:             pgtype.UUID{Bytes: params.ChainID, Valid: true},
+			Address:             params.Address,
+			EncryptedPrivateKey: params.EncryptedPrivateKey,
+		})
+		if err != nil {
+			return err
+		}
+		wallet = toDomainRelayerWallet(createdWallet)
+		return nil
+	})
+	return wallet, err
+}
+
+func (r *relayerRepository) ListActiveRelayerWallets(ctx context.Context, chainID uuid.UUID) ([]domain.RelayerWallet, error) {
+	q := sqlc.New(r.DB())
+	dbWallets, err := q.ListActiveRelayerWallets(ctx, pgtype.UUID{Bytes: chainID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	wallets := make([]domain.RelayerWallet, 0, len(dbWallets))
+	for _, w := range dbWallets {
+		wallets = append(wallets, toDomainRelayerWallet(w))
+	}
+	return wallets, nil
+}
+
+func (r *relayerRepository) EnsureSponsorshipBudget(ctx context.Context, userID uuid.UUID, chainID uuid.UUID, budget *big.Int) (domain.SponsorshipBudget, error) {
+	q := sqlc.New(r.DB())
+	dbBudget, err := q.EnsureSponsorshipBudget(ctx, sqlc.EnsureSponsorshipBudgetParams{
+		UserID:    pgtype.UUID{Bytes: userID, Valid: true},
+		ChainID:   pgtype.UUID{Bytes: chainID, Valid: true},
+		BudgetWei: toNumeric(budget),
+	})
+	if err != nil {
+		return domain.SponsorshipBudget{}, err
+	}
+	return toDomainSponsorshipBudget(dbBudget), nil
+}
+
+// ReserveSponsorship atomically adds amount to the spent part of the budget.
+// It returns pgx.ErrNoRows when the reservation would exceed the budget.
+func (r *relayerRepository) ReserveSponsorship(ctx context.Context, userID uuid.UUID, chainID uuid.UUID, amount *big.Int) (domain.SponsorshipBudget, error) {
+	q := sqlc.New(r.DB())
+	dbBudget, err := q.ReserveSponsorship(ctx, sqlc.ReserveSponsorshipParams{
+		Amount:  toNumeric(amount),
+		UserID:  pgtype.UUID{Bytes: userID, Valid: true},
+		ChainID: pgtype.UUID{Bytes: chainID, Valid: true},
+	})
+	if err != nil {
+		return domain.SponsorshipBudget{}, err
+	}
+	return toDomainSponsorshipBudget(dbBudget), nil
+}
+
+func (r *relayerRepository) ReleaseSponsorship(ctx context.Context, userID uuid.UUID, chainID uuid.UUID, amount *big.Int) (domain.SponsorshipBudget, error) {
+	q := sqlc.New(r.DB())
+	dbBudget, err := q.ReleaseSponsorship(ctx, sqlc.ReleaseSponsorshipParams{
+		Amount:  toNumeric(amount),
+		UserID:  pgtype.UUID{Bytes: userID, Valid: true},
+		ChainID: pgtype.UUID{Bytes: chainID, Valid: true},
+	})
+	if err != nil {
+		return domain.SponsorshipBudget{}, err
+	}
+	return toDomainSponsorshipBudget(dbBudget), nil
+}
+
+func (r *relayerRepository) CreateGasSponsorship(ctx context.Context, params domain.CreateGasSponsorshipParams) (domain.GasSponsorship, error) {
+	q := sqlc.New(r.DB())
+	dbSponsorship, err := q.CreateGasSponsorship(ctx, sqlc.CreateGasSponsorshipParams{
+		UserID:          pgtype.UUID{Bytes: params.UserID, Valid: true},
+		ChainID:         pgtype.UUID{Bytes: params.ChainID, Valid: true},
+		RelayerWalletID: pgtype.UUID{Bytes: params.RelayerWalletID, Valid: true},
+		TransactionID:   pgtype.UUID{Bytes: params.TransactionID, Valid: params.TransactionID != uuid.Nil},
+		FundingTxHash:   params.FundingTxHash,
+		AmountWei:       toNumeric(params.AmountWei),
+		FeeWei:          toNumeric(params.FeeWei),
+		Status:          string(params.Status),
+	})
+	if err != nil {
+		return domain.GasSponsorship{}, err
+	}
+	return toDomainGasSponsorship(dbSponsorship), nil
+}
+
+func (r *relayerRepository) GetGasSponsorshipsByUserID(ctx context.Context, userID uuid.UUID) ([]domain.GasSponsorship, error) {
+	q := sqlc.New(r.DB())
+	dbSponsorships, err := q.ListGasSponsorshipsByUserID(ctx, pgtype.UUID{Bytes: userID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	sponsorships := make([]domain.GasSponsorship, 0, len(dbSponsorships))
+	for _, s := range dbSponsorships {
+		sponsorships = append(sponsorships, toDomainGasSponsorship(s))
+	}
+	return sponsorships, nil
+}
+
+func (r *relayerRepository) GetPendingGasSponsorshipsByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.GasSponsorship, error) {
+	q := sqlc.New(r.DB())
+	dbSponsorships, err := q.ListPendingGasSponsorshipsByChainID(ctx, pgtype.UUID{Bytes: chainID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	sponsorships := make([]domain.GasSponsorship, 0, len(dbSponsorships))
+	for _, s := range dbSponsorships {
+		sponsorships = append(sponsorships, toDomainGasSponsorship(s))
+	}
+	return sponsorships, nil
+}
+
+// ConfirmGasSponsorship records that the funding transaction of a sponsorship was mined, and the fee it paid.
+func (r *relayerRepository) ConfirmGasSponsorship(ctx context.Context, id uuid.UUID, fee *big.Int) error {
+	q := sqlc.New(r.DB())
+	return q.UpdateGasSponsorshipStatus(ctx, sqlc.UpdateGasSponsorshipStatusParams{
+		ID:     pgtype.UUID{Bytes: id, Valid: true},
+		Status: string(domain.SponsorshipStatusConfirmed),
+		FeeWei: toNumeric(fee),
+	})
+}
+
+// FailGasSponsorship records that the funding transaction of a sponsorship reverted, and gives its
+// amount back to the user's budget in the same transaction.
+func (r *relayerRepository) FailGasSponsorship(ctx context.Context, sponsorship domain.GasSponsorship, fee *big.Int) error {
+	return r.WithTx(ctx, func(tx pgx.Tx) error {
+		q := sqlc.New(tx)
+		if err := q.UpdateGasSponsorshipStatus(ctx, sqlc.UpdateGasSponsorshipStatusParams{
+			ID:     pgtype.UUID{Bytes: sponsorship.ID, Valid: true},
+			Status: string(domain.SponsorshipStatusFailed),
+			FeeWei: toNumeric(fee),
+		}); err != nil {
+			return err
+		}
+
+		_, err := q.ReleaseSponsorship(ctx, sqlc.ReleaseSponsorshipParams{
+			Amount:  toNumeric(sponsorship.AmountWei),
+			UserID:  pgtype.UUID{Bytes: sponsorship.UserID, Valid: true},
+			ChainID: pgtype.UUID{Bytes: sponsorship.ChainID, Valid: true},
+		})
+		return err
+	})
+}
+
+func toDomainRelayerWallet(w sqlc.RelayerWallet) domain.RelayerWallet {
+	return domain.RelayerWallet{
+		ID:                  w.ID.Bytes,
+		ChainID:             w.ChainID.Bytes,
+		Address:             w.Address,
+		EncryptedPrivateKey: w.EncryptedPrivateKey,
+		Active:              w.Active,
+		CreatedAt:           w.CreatedAt.Time,
+		UpdatedAt:           w.UpdatedAt.Time,
+	}
+}
+
+func toDomainSponsorshipBudget(b sqlc.SponsorshipBudget) domain.SponsorshipBudget {
+	return domain.SponsorshipBudget{
+		ID:        b.ID.Bytes,
+		UserID:    b.UserID.Bytes,
+		ChainID:   b.ChainID.Bytes,
+		BudgetWei: fromNumeric(b.BudgetWei),
+		SpentWei:  fromNumeric(b.SpentWei),
+		CreatedAt: b.CreatedAt.Time,
+		UpdatedAt: b.UpdatedAt.Time,
+	}
+}
+
+func toDomainGasSponsorship(s sqlc.GasSponsorship) domain.GasSponsorship {
+	return domain.GasSponsorship{
+		ID:              s.ID.Bytes,
+		UserID:          s.UserID.Bytes,
+		ChainID:         s.ChainID.Bytes,
+		RelayerWalletID: s.RelayerWalletID.Bytes,
+		TransactionID:   s.TransactionID.Bytes,
+		FundingTxHash:   s.FundingTxHash,
+		AmountWei:       fromNumeric(s.AmountWei),
+		FeeWei:          fromNumeric(s.FeeWei),
+		Status:          domain.SponsorshipStatus(s.Status),
+		CreatedAt:       s.CreatedAt.Time,
+	}
+}
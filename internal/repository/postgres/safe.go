@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"mpc/internal/domain"
+	sqlc "mpc/internal/infrastructure/db/sqlc"
+	"mpc/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type safeProposalRepository struct {
+	repository.BaseRepository
+}
+
+func NewSafeProposalRepo(dbPool *pgxpool.Pool) repository.SafeProposalRepository {
+	return &safeProposalRepository{
+		BaseRepository: repository.NewBaseRepo(dbPool),
+	}
+}
+
+// Ensure SafeProposalRepository implements SafeProposalRepository
+var _ repository.SafeProposalRepository = (*safeProposalRepository)(nil)
+
+func (r *safeProposalRepository) CreateSafeProposal(ctx context.Context, params domain.CreateSafeProposalParams) (domain.SafeProposal, error) {
+	var proposal domain.SafeProposal
+	err := r.WithTx(ctx, func(tx pgx.Tx) error {
+		q := sqlc.New(tx)
+		createdProposal, err := q.CreateSafeProposal(ctx, sqlc.CreateSafeProposalParams{
+			ID:          pgtype.UUID{Bytes: params.ID, Valid: true},
+			WalletID:    pgtype.UUID{Bytes: params.WalletID, Valid: true},
+			ChainID:     pgtype.UUID{Bytes: params.ChainID, Valid: true},
+			SafeAddress: params.SafeAddress,
+			ToAddress:   params.ToAddress,
+			Value:       params.Value,
+			Data:        params.Data,
+			Nonce:       params.Nonce,
+			SafeTxHash:  params.SafeTxHash,
+			Threshold:   int32(params.Threshold),
+			Status:      string(params.Status),
+		})
+		if err != nil {
+			return err
+		}
+		proposal = toDomainSafeProposal(createdProposal)
+		return nil
+	})
+	return proposal, err
+}
+
+func (r *safeProposalRepository) GetSafeProposal(ctx context.Context, id uuid.UUID) (domain.SafeProposal, error) {
+	q := sqlc.New(r.DB())
+	proposal, err := q.GetSafeProposal(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		return domain.SafeProposal{}, err
+	}
+	return toDomainSafeProposal(proposal), nil
+}
+
+func (r *safeProposalRepository) UpdateSafeProposal(ctx context.Context, proposal domain.SafeProposal) error {
+	q := sqlc.New(r.DB())
+	_, err := q.UpdateSafeProposal(ctx, sqlc.UpdateSafeProposalParams{
+		ID:                pgtype.UUID{Bytes: proposal.ID, Valid: true},
+		Status:            string(proposal.Status),
+		ExecTxHash:        pgtype.Text{String: proposal.ExecTxHash, Valid: proposal.ExecTxHash != ""},
+		ExecTransactionID: pgtype.UUID{Bytes: proposal.ExecTransactionID, Valid: proposal.ExecTransactionID != uuid.Nil},
+	})
+	return err
+}
+
+func (r *safeProposalRepository) AddSafeSignature(ctx context.Context, proposalID uuid.UUID, owner string, signature []byte) (domain.SafeSignature, error) {
+	q := sqlc.New(r.DB())
+	sig, err := q.UpsertSafeSignature(ctx, sqlc.UpsertSafeSignatureParams{
+		ProposalID: pgtype.UUID{Bytes: proposalID, Valid: true},
+		Owner:      owner,
+		Signature:  signature,
+	})
+	if err != nil {
+		return domain.SafeSignature{}, err
+	}
+	return toDomainSafeSignature(sig), nil
+}
+
+func (r *safeProposalRepository) GetSafeSignatures(ctx context.Context, proposalID uuid.UUID) ([]domain.SafeSignature, error) {
+	q := sqlc.New(r.DB())
+	dbSignatures, err := q.ListSafeSignatures(ctx, pgtype.UUID{Bytes: proposalID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	signatures := make([]domain.SafeSignature, 0, len(dbSignatures))
+	for _, s := range dbSignatures {
+		signatures = append(signatures, toDomainSafeSignature(s))
+	}
+	return signatures, nil
+}
+
+func toDomainSafeProposal(p sqlc.SafeProposal) domain.SafeProposal {
+	return domain.SafeProposal{
+		ID:                p.ID.Bytes,
+		WalletID:          p.WalletID.Bytes,
+		ChainID:           p.ChainID.Bytes,
+		SafeAddress:       p.SafeAddress,
+		ToAddress:         p.ToAddress,
+		Value:             p.Value,
+		Data:              p.Data,
+		Nonce:             p.Nonce,
+		SafeTxHash:        p.SafeTxHash,
+		Threshold:         int(p.Threshold),
+		Status:            domain.Status(p.Status),
+		ExecTxHash:        p.ExecTxHash.String,
+		ExecTransactionID: p.ExecTransactionID.Bytes,
+		CreatedAt:         p.CreatedAt.Time,
+		UpdatedAt:         p.UpdatedAt.Time,
+	}
+}
+
+func toDomainSafeSignature(s sqlc.SafeSignature) domain.SafeSignature {
+	return domain.SafeSignature{
+		ID:         s.ID.Bytes,
+		ProposalID: s.ProposalID.Bytes,
+		Owner:      s.Owner,
+		Signature:  s.Signature,
+		CreatedAt:  s.CreatedAt.Time,
+	}
+}
@@ -25,7 +25,7 @@ func NewTransactionRepo(dbPool *pgxpool.Pool) repository.TransactionRepository {
 // Ensure TransactionRepository implements TransactionRepository
 var _ repository.TransactionRepository = (*transactionRepository)(nil)
 
-func (r *transactionRepository) CreateTransaction(ctx context.Context, params domain.CreateTransactionParams) (domain.Transaction, error) {
+func (r *transactionRepository) CreateTransaction(ctx context.Context, params domain.CreateTransactionParams, messages ...domain.OutboxMessage) (domain.Transaction, error) {
 
 	var transaction domain.Transaction
 	err := r.WithTx(ctx, func(tx pgx.Tx) error {
@@ -36,11 +36,13 @@ func (r *transactionRepository) CreateTransaction(ctx context.Context, params do
 			ChainID:   pgtype.UUID{Bytes: params.ChainID, Valid: true},
 			ToAddress: params.ToAddress,
 			Amount:    params.Amount,
-			TokenID:   pgtype.UUID{Bytes: params.TokenID, Valid: true},
+			TokenID:   pgtype.UUID{Bytes: params.TokenID, Valid: params.TokenID != uuid.Nil},
 			GasPrice:  pgtype.Text{String: params.GasPrice, Valid: true},
 			GasLimit:  pgtype.Text{String: params.GasLimit, Valid: true},
 			Nonce:     pgtype.Int8{Int64: params.Nonce, Valid: true},
 			Status:    string(params.Status),
+			ToEnsName: pgtype.Text{String: params.ToENSName, Valid: params.ToENSName != ""},
+			ParentID:  pgtype.UUID{Bytes: params.ParentID, Valid: params.ParentID != uuid.Nil},
 		})
 		if err != nil {
 			return err
@@ -50,14 +52,16 @@ func (r *transactionRepository) CreateTransaction(ctx context.Context, params do
 			WalletID:  createdTransaction.WalletID.Bytes,
 			ChainID:   createdTransaction.ChainID.Bytes,
 			ToAddress: createdTransaction.ToAddress,
+			ToENSName: createdTransaction.ToEnsName.String,
 			Amount:    createdTransaction.Amount,
 			TokenID:   createdTransaction.TokenID.Bytes,
 			GasPrice:  createdTransaction.GasPrice.String,
 			GasLimit:  createdTransaction.GasLimit.String,
 			Nonce:     createdTransaction.Nonce.Int64,
 			Status:    domain.Status(createdTransaction.Status),
+			ParentID:  createdTransaction.ParentID.Bytes,
 		}
-		return nil
+		return createOutboxMessages(ctx, q, messages)
 	})
 	return transaction, err
 }
@@ -68,23 +72,20 @@ func (r *transactionRepository) GetTransaction(ctx context.Context, id uuid.UUID
 	if err != nil {
 		return domain.Transaction{}, err
 	}
-	return domain.Transaction{
-		ID:        transaction.ID.Bytes,
-		WalletID:  transaction.WalletID.Bytes,
-		ChainID:   transaction.ChainID.Bytes,
-		ToAddress: transaction.ToAddress,
-		Amount:    transaction.Amount,
-		TokenID:   transaction.TokenID.Bytes,
-		TxHash:    transaction.TxHash.String,
-		GasPrice:  transaction.GasPrice.String,
-		GasLimit:  transaction.GasLimit.String,
-		Nonce:     transaction.Nonce.Int64,
-		Status:    domain.Status(transaction.Status),
-	}, nil
+	return toDomainTransaction(transaction), nil
 }
 
-func (r *transactionRepository) UpdateTransaction(ctx context.Context, transaction domain.Transaction) error {
-	q := sqlc.New(r.DB())
+func (r *transactionRepository) UpdateTransaction(ctx context.Context, transaction domain.Transaction, messages ...domain.OutboxMessage) error {
+	return r.WithTx(ctx, func(tx pgx.Tx) error {
+		q := sqlc.New(tx)
+		if err := updateTransaction(ctx, q, transaction); err != nil {
+			return err
+		}
+		return createOutboxMessages(ctx, q, messages)
+	})
+}
+
+func updateTransaction(ctx context.Context, q *sqlc.Queries, transaction domain.Transaction) error {
 	_, err := q.UpdateTransaction(ctx, sqlc.UpdateTransactionParams{
 		ID:       pgtype.UUID{Bytes: transaction.ID, Valid: true},
 		Status:   string(transaction.Status),
@@ -92,11 +93,78 @@ func (r *transactionRepository) UpdateTransaction(ctx context.Context, transacti
 		GasPrice: pgtype.Text{String: transaction.GasPrice, Valid: transaction.GasPrice != ""},
 		GasLimit: pgtype.Text{String: transaction.GasLimit, Valid: transaction.GasLimit != ""},
 		Nonce:    pgtype.Int8{Int64: transaction.Nonce, Valid: true},
+		// A zero block number means the transaction is not (or no longer) in a block
+		BlockNumber: pgtype.Int8{Int64: transaction.BlockNumber, Valid: transaction.BlockNumber != 0},
+		BlockHash:   pgtype.Text{String: transaction.BlockHash, Valid: transaction.BlockHash != ""},
+		// Receipt fields are only set while the transaction is in a block
+		GasUsed:           pgtype.Int8{Int64: transaction.GasUsed, Valid: transaction.BlockNumber != 0},
+		EffectiveGasPrice: pgtype.Text{String: transaction.EffectiveGasPrice, Valid: transaction.EffectiveGasPrice != ""},
+		Fee:               pgtype.Text{String: transaction.Fee, Valid: transaction.Fee != ""},
+		RevertReason:      pgtype.Text{String: transaction.RevertReason, Valid: transaction.RevertReason != ""},
+		RawTx:             pgtype.Text{String: transaction.RawTx, Valid: transaction.RawTx != ""},
 	})
 	if err != nil {
 		return err
 	}
-	return nil
+	// The recipients of a Disperse batch are paid by this transaction
+	return q.UpdateChildTransactionStatus(ctx, sqlc.UpdateChildTransactionStatusParams{
+		ParentID: pgtype.UUID{Bytes: transaction.ID, Valid: true},
+		Status:   string(transaction.Status),
+	})
+}
+
+func (r *transactionRepository) CreateInboundTransaction(ctx context.Context, params domain.CreateInboundTransactionParams, messages ...domain.OutboxMessage) error {
+	return r.WithTx(ctx, func(tx pgx.Tx) error {
+		q := sqlc.New(tx)
+		created, err := createInboundTransaction(ctx, q, params)
+		if err != nil || created == 0 {
+			return err
+		}
+		return createOutboxMessages(ctx, q, messages)
+	})
+}
+
+func createInboundTransaction(ctx context.Context, q *sqlc.Queries, params domain.CreateInboundTransactionParams) (int64, error) {
+	return q.CreateInboundTransaction(ctx, sqlc.CreateInboundTransactionParams{
+		ID:          pgtype.UUID{Bytes: params.ID, Valid: true},
+		WalletID:    pgtype.UUID{Bytes: params.WalletID, Valid: true},
+		ChainID:     pgtype.UUID{Bytes: params.ChainID, Valid: true},
+		FromAddress: pgtype.Text{String: params.FromAddress, Valid: true},
+		ToAddress:   params.ToAddress,
+		Amount:      params.Amount,
+		TokenID:     pgtype.UUID{Bytes: params.TokenID, Valid: params.TokenID != uuid.Nil},
+		Status:      string(params.Status),
+		TxHash:      pgtype.Text{String: params.TxHash, Valid: true},
+		LogIndex:    pgtype.Int4{Int32: int32(params.LogIndex), Valid: true},
+		BlockNumber: pgtype.Int8{Int64: params.BlockNumber, Valid: params.BlockNumber != 0},
+		BlockHash:   pgtype.Text{String: params.BlockHash, Valid: params.BlockHash != ""},
+	})
+}
+
+func (r *transactionRepository) GetSubmittedTransactionsByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.Transaction, error) {
+	q := sqlc.New(r.DB())
+	dbTransactions, err := q.GetSubmittedTransactionsByChainID(ctx, pgtype.UUID{Bytes: chainID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]domain.Transaction, 0, len(dbTransactions))
+	for _, t := range dbTransactions {
+		transactions = append(transactions, toDomainTransaction(t))
+	}
+	return transactions, nil
+}
+
+func (r *transactionRepository) GetNextNonce(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) (uint64, error) {
+	q := sqlc.New(r.DB())
+	nonce, err := q.GetNextNonce(ctx, sqlc.GetNextNonceParams{
+		WalletID: pgtype.UUID{Bytes: walletID, Valid: true},
+		ChainID:  pgtype.UUID{Bytes: chainID, Valid: true},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return uint64(nonce), nil
 }
 
 func (r *transactionRepository) GetTransactions(ctx context.Context, userID uuid.UUID) ([]domain.Transaction, error) {
@@ -108,3 +176,32 @@ func (r *transactionRepository) GetTransactionsByWalletID(ctx context.Context, w
 	// Implement the database operation here
 	panic("not implemented")
 }
+
+func toDomainTransaction(t sqlc.Transaction) domain.Transaction {
+	return domain.Transaction{
+		ID:                t.ID.Bytes,
+		WalletID:          t.WalletID.Bytes,
+		ChainID:           t.ChainID.Bytes,
+		Direction:         domain.Direction(t.Direction),
+		FromAddress:       t.FromAddress.String,
+		ToAddress:         t.ToAddress,
+		ToENSName:         t.ToEnsName.String,
+		Amount:            t.Amount,
+		TokenID:           t.TokenID.Bytes,
+		TxHash:            t.TxHash.String,
+		GasPrice:          t.GasPrice.String,
+		GasLimit:          t.GasLimit.String,
+		Nonce:             t.Nonce.Int64,
+		Status:            domain.Status(t.Status),
+		BlockNumber:       t.BlockNumber.Int64,
+		BlockHash:         t.BlockHash.String,
+		GasUsed:           t.GasUsed.Int64,
+		EffectiveGasPrice: t.EffectiveGasPrice.String,
+		Fee:               t.Fee.String,
+		RevertReason:      t.RevertReason.String,
+		RawTx:             t.RawTx.String,
+		ParentID:          t.ParentID.Bytes,
+		CreatedAt:         t.CreatedAt.Time,
+		UpdatedAt:         t.UpdatedAt.Time,
+	}
+}
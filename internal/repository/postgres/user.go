@@ -25,11 +25,12 @@ func NewUserRepo(dbPool *pgxpool.Pool) repository.UserRepository {
 // Ensure UserRepository implements UserRepository
 var _ repository.UserRepository = (*userRepository)(nil)
 
-func (r *userRepository) CreateUser(ctx context.Context, params domain.CreateHashedUserParams) (domain.User, error) {
+func (r *userRepository) CreateUser(ctx context.Context, params domain.CreateHashedUserParams, messages ...domain.OutboxMessage) (domain.User, error) {
 	var user domain.User
 	err := r.WithTx(ctx, func(tx pgx.Tx) error {
 		q := sqlc.New(tx)
 		createdUser, err := q.CreateUser(ctx, sqlc.CreateUserParams{
+			ID:           pgtype.UUID{Bytes: params.ID, Valid: true},
 			Email:        params.Email,
 			PasswordHash: params.PasswordHash,
 		})
@@ -43,7 +44,7 @@ func (r *userRepository) CreateUser(ctx context.Context, params domain.CreateHas
 			CreatedAt:    createdUser.CreatedAt.Time,
 			UpdatedAt:    createdUser.UpdatedAt.Time,
 		}
-		return nil
+		return createOutboxMessages(ctx, q, messages)
 	})
 	return user, err
 }
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"mpc/internal/domain"
+	sqlc "mpc/internal/infrastructure/db/sqlc"
+	"mpc/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type userOperationRepository struct {
+	repository.BaseRepository
+}
+
+func NewUserOperationRepo(dbPool *pgxpool.Pool) repository.UserOperationRepository {
+	return &userOperationRepository{
+		BaseRepository: repository.NewBaseRepo(dbPool),
+	}
+}
+
+// Ensure UserOperationRepository implements UserOperationRepository
+var _ repository.UserOperationRepository = (*userOperationRepository)(nil)
+
+func (r *userOperationRepository) CreateUserOperation(ctx context.Context, params domain.CreateUserOperationTxnParams) (domain.UserOperationTxn, error) {
+	var userOp domain.UserOperationTxn
+	err := r.WithTx(ctx, func(tx pgx.Tx) error {
+		q := sqlc.New(tx)
+		createdUserOp, err := q.CreateUserOperation(ctx, sqlc.CreateUserOperationParams{
+			ID:         pgtype.UUID{Bytes: params.ID, Valid: true},
+			WalletID:   pgtype.UUID{Bytes: params.WalletID, Valid: true},
+			ChainID:    pgtype.UUID{Bytes: params.ChainID, Valid: true},
+			Sender:     params.Sender,
+			Nonce:      params.Nonce,
+			ToAddress:  params.ToAddress,
+			Amount:     params.Amount,
+			UserOpHash: params.UserOpHash,
+			Status:     string(params.Status),
+		})
+		if err != nil {
+			return err
+		}
+		userOp = toDomainUserOperation(createdUserOp)
+		return nil
+	})
+	return userOp, err
+}
+
+func (r *userOperationRepository) GetUserOperation(ctx context.Context, id uuid.UUID) (domain.UserOperationTxn, error) {
+	q := sqlc.New(r.DB())
+	userOp, err := q.GetUserOperation(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	if err != nil {
+		return domain.UserOperationTxn{}, err
+	}
+	return toDomainUserOperation(userOp), nil
+}
+
+func (r *userOperationRepository) UpdateUserOperation(ctx context.Context, userOp domain.UserOperationTxn) error {
+	q := sqlc.New(r.DB())
+	_, err := q.UpdateUserOperation(ctx, sqlc.UpdateUserOperationParams{
+		ID:            pgtype.UUID{Bytes: userOp.ID, Valid: true},
+		Status:        string(userOp.Status),
+		TxHash:        pgtype.Text{String: userOp.TxHash, Valid: userOp.TxHash != ""},
+		ActualGasCost: pgtype.Text{String: userOp.ActualGasCost, Valid: userOp.ActualGasCost != ""},
+	})
+	return err
+}
+
+func toDomainUserOperation(u sqlc.UserOperation) domain.UserOperationTxn {
+	return domain.UserOperationTxn{
+		ID:            u.ID.Bytes,
+		WalletID:      u.WalletID.Bytes,
+		ChainID:       u.ChainID.Bytes,
+		Sender:        u.Sender,
+		Nonce:         u.Nonce,
+		ToAddress:     u.ToAddress,
+		Amount:        u.Amount,
+		UserOpHash:    u.UserOpHash,
+		Status:        domain.Status(u.Status),
+		TxHash:        u.TxHash.String,
+		ActualGasCost: u.ActualGasCost.String,
+		CreatedAt:     u.CreatedAt.Time,
+		UpdatedAt:     u.UpdatedAt.Time,
+	}
+}
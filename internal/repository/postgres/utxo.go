@@ -0,0 +1,238 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"mpc/internal/domain"
+	sqlc "mpc/internal/infrastructure/db/sqlc"
+	"mpc/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type utxoRepository struct {
+	repository.BaseRepository
+}
+
+func NewUTXORepo(dbPool *pgxpool.Pool) repository.UTXORepository {
+	return &utxoRepository{
+		BaseRepository: repository.NewBaseRepo(dbPool),
+	}
+}
+
+// Ensure UTXORepository implements UTXORepository
+var _ repository.UTXORepository = (*utxoRepository)(nil)
+
+// errUTXOUnavailable rolls back a reservation when one of its outputs is taken.
+var errUTXOUnavailable = errors.New("utxo is reserved or spent")
+
+func (r *utxoRepository) CreateBitcoinAddress(ctx context.Context, address domain.BitcoinAddress) error {
+	q := sqlc.New(r.DB())
+	return q.CreateBitcoinAddress(ctx, sqlc.CreateBitcoinAddressParams{
+		WalletID:        pgtype.UUID{Bytes: address.WalletID, Valid: true},
+		ChainID:         pgtype.UUID{Bytes: address.ChainID, Valid: true},
+		Address:         address.Address,
+		Change:          address.Change,
+		DerivationIndex: int32(address.DerivationIndex),
+	})
+}
+
+func (r *utxoRepository) GetUnusedChangeAddress(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) (domain.BitcoinAddress, error) {
+	q := sqlc.New(r.DB())
+	address, err := q.GetUnusedChangeAddress(ctx, sqlc.GetUnusedChangeAddressParams{
+		WalletID: pgtype.UUID{Bytes: walletID, Valid: true},
+		ChainID:  pgtype.UUID{Bytes: chainID, Valid: true},
+	})
+	if err != nil {
+		return domain.BitcoinAddress{}, err
+	}
+	return toDomainBitcoinAddress(address), nil
+}
+
+func (r *utxoRepository) GetNextChangeIndex(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) (uint32, error) {
+	q := sqlc.New(r.DB())
+	index, err := q.GetNextChangeIndex(ctx, sqlc.GetNextChangeIndexParams{
+		WalletID: pgtype.UUID{Bytes: walletID, Valid: true},
+		ChainID:  pgtype.UUID{Bytes: chainID, Valid: true},
+	})
+	if err != nil {
+		return 0, err
+	}
+	return uint32(index), nil
+}
+
+func (r *utxoRepository) ListBitcoinAddressesByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.BitcoinAddress, error) {
+	q := sqlc.New(r.DB())
+	dbAddresses, err := q.ListBitcoinAddressesByChainID(ctx, pgtype.UUID{Bytes: chainID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]domain.BitcoinAddress, 0, len(dbAddresses))
+	for _, a := range dbAddresses {
+		addresses = append(addresses, toDomainBitcoinAddress(a))
+	}
+	return addresses, nil
+}
+
+func (r *utxoRepository) ListBitcoinAddressesByWalletID(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) ([]domain.BitcoinAddress, error) {
+	q := sqlc.New(r.DB())
+	dbAddresses, err := q.ListBitcoinAddressesByWalletID(ctx, sqlc.ListBitcoinAddressesByWalletIDParams{
+		WalletID: pgtype.UUID{Bytes: walletID, Valid: true},
+		ChainID:  pgtype.UUID{Bytes: chainID, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make([]domain.BitcoinAddress, 0, len(dbAddresses))
+	for _, a := range dbAddresses {
+		addresses = append(addresses, toDomainBitcoinAddress(a))
+	}
+	return addresses, nil
+}
+
+// UpsertUTXO records an output, or the block of one already recorded, and marks its address used.
+func (r *utxoRepository) UpsertUTXO(ctx context.Context, utxo domain.UTXO) error {
+	return r.WithTx(ctx, func(tx pgx.Tx) error {
+		q := sqlc.New(tx)
+		chainID := pgtype.UUID{Bytes: utxo.ChainID, Valid: true}
+		if err := q.UpsertUTXO(ctx, sqlc.UpsertUTXOParams{
+			WalletID:    pgtype.UUID{Bytes: utxo.WalletID, Valid: true},
+			ChainID:     chainID,
+			TxHash:      utxo.TxID,
+			Vout:        int32(utxo.Vout),
+			Address:     utxo.Address,
+			Amount:      utxo.Amount,
+			PkScript:    utxo.PkScript,
+			BlockNumber: pgtype.Int8{Int64: utxo.BlockNumber, Valid: utxo.BlockNumber != 0},
+			BlockHash:   pgtype.Text{String: utxo.BlockHash, Valid: utxo.BlockHash != ""},
+		}); err != nil {
+			return err
+		}
+		return q.MarkBitcoinAddressUsed(ctx, sqlc.MarkBitcoinAddressUsedParams{ChainID: chainID, Address: utxo.Address})
+	})
+}
+
+func (r *utxoRepository) ListUnspentUTXOsByWalletID(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) ([]domain.UTXO, error) {
+	q := sqlc.New(r.DB())
+	dbUTXOs, err := q.ListUnspentUTXOsByWalletID(ctx, sqlc.ListUnspentUTXOsByWalletIDParams{
+		WalletID: pgtype.UUID{Bytes: walletID, Valid: true},
+		ChainID:  pgtype.UUID{Bytes: chainID, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	utxos := make([]domain.UTXO, 0, len(dbUTXOs))
+	for _, u := range dbUTXOs {
+		utxos = append(utxos, toDomainUTXO(u))
+	}
+	return utxos, nil
+}
+
+func (r *utxoRepository) ListUnspentUTXOsByChainID(ctx context.Context, chainID uuid.UUID) ([]domain.UTXO, error) {
+	q := sqlc.New(r.DB())
+	dbUTXOs, err := q.ListUnspentUTXOsByChainID(ctx, pgtype.UUID{Bytes: chainID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+
+	utxos := make([]domain.UTXO, 0, len(dbUTXOs))
+	for _, u := range dbUTXOs {
+		utxos = append(utxos, toDomainUTXO(u))
+	}
+	return utxos, nil
+}
+
+// ReserveUTXOs reserves every output for the transaction, or none of them when another transaction
+// holds or spent one. It reports whether the outputs were reserved.
+func (r *utxoRepository) ReserveUTXOs(ctx context.Context, transactionID uuid.UUID, utxoIDs []uuid.UUID) (bool, error) {
+	reserved := false
+	err := r.WithTx(ctx, func(tx pgx.Tx) error {
+		q := sqlc.New(tx)
+		for _, id := range utxoIDs {
+			rows, err := q.ReserveUTXO(ctx, sqlc.ReserveUTXOParams{
+				ID:         pgtype.UUID{Bytes: id, Valid: true},
+				ReservedBy: pgtype.UUID{Bytes: transactionID, Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+			if rows == 0 {
+				return errUTXOUnavailable
+			}
+		}
+		reserved = true
+		return nil
+	})
+	if errors.Is(err, errUTXOUnavailable) {
+		return false, nil
+	}
+	return reserved, err
+}
+
+func (r *utxoRepository) ReleaseUTXOs(ctx context.Context, transactionID uuid.UUID) error {
+	q := sqlc.New(r.DB())
+	return q.ReleaseUTXOs(ctx, pgtype.UUID{Bytes: transactionID, Valid: true})
+}
+
+func (r *utxoRepository) SpendUTXO(ctx context.Context, chainID uuid.UUID, outPoint domain.BitcoinOutPoint, spentTxID string, blockNumber int64) error {
+	q := sqlc.New(r.DB())
+	return q.SpendUTXO(ctx, sqlc.SpendUTXOParams{
+		ChainID:          pgtype.UUID{Bytes: chainID, Valid: true},
+		TxHash:           outPoint.TxID,
+		Vout:             int32(outPoint.Vout),
+		SpentTxHash:      pgtype.Text{String: spentTxID, Valid: true},
+		SpentBlockNumber: pgtype.Int8{Int64: blockNumber, Valid: true},
+	})
+}
+
+// RewindUTXOs undoes what blocks after blockNumber did to the UTXO set: outputs they created become
+// unconfirmed and outputs they spent become unspent again.
+func (r *utxoRepository) RewindUTXOs(ctx context.Context, chainID uuid.UUID, blockNumber int64) error {
+	return r.WithTx(ctx, func(tx pgx.Tx) error {
+		q := sqlc.New(tx)
+		chain := pgtype.UUID{Bytes: chainID, Valid: true}
+		if err := q.UnconfirmUTXOs(ctx, sqlc.UnconfirmUTXOsParams{
+			ChainID:     chain,
+			BlockNumber: pgtype.Int8{Int64: blockNumber, Valid: true},
+		}); err != nil {
+			return err
+		}
+		return q.UnspendUTXOs(ctx, sqlc.UnspendUTXOsParams{
+			ChainID:          chain,
+			SpentBlockNumber: pgtype.Int8{Int64: blockNumber, Valid: true},
+		})
+	})
+}
+
+func toDomainBitcoinAddress(a sqlc.BitcoinAddress) domain.BitcoinAddress {
+	return domain.BitcoinAddress{
+		WalletID:        a.WalletID.Bytes,
+		ChainID:         a.ChainID.Bytes,
+		Address:         a.Address,
+		Change:          a.Change,
+		DerivationIndex: uint32(a.DerivationIndex),
+		Used:            a.Used,
+	}
+}
+
+func toDomainUTXO(u sqlc.Utxo) domain.UTXO {
+	return domain.UTXO{
+		ID:          u.ID.Bytes,
+		WalletID:    u.WalletID.Bytes,
+		ChainID:     u.ChainID.Bytes,
+		TxID:        u.TxHash,
+		Vout:        uint32(u.Vout),
+		Amount:      u.Amount,
+		Address:     u.Address,
+		PkScript:    u.PkScript,
+		BlockNumber: u.BlockNumber.Int64,
+		BlockHash:   u.BlockHash.String,
+		ReservedBy:  u.ReservedBy.Bytes,
+	}
+}
@@ -25,11 +25,12 @@ func NewWalletRepo(dbPool *pgxpool.Pool) repository.WalletRepository {
 // Ensure WalletRepository implements WalletRepository
 var _ repository.WalletRepository = (*walletRepository)(nil)
 
-func (r *walletRepository) CreateWallet(ctx context.Context, params domain.CreateWalletParams) (domain.Wallet, error) {
+func (r *walletRepository) CreateWallet(ctx context.Context, params domain.CreateWalletParams, messages ...domain.OutboxMessage) (domain.Wallet, error) {
 	var wallet domain.Wallet
 	err := r.WithTx(ctx, func(tx pgx.Tx) error {
 		q := sqlc.New(tx)
 		createdWallet, err := q.CreateWallet(ctx, sqlc.CreateWalletParams{
+			ID:                  pgtype.UUID{Bytes: params.ID, Valid: true},
 			UserID:              pgtype.UUID{Bytes: params.UserID, Valid: true},
 			Address:             params.Address,
 			EncryptedPrivateKey: params.EncryptedPrivateKey,
@@ -44,7 +45,7 @@ func (r *walletRepository) CreateWallet(ctx context.Context, params domain.Creat
 			Address:             createdWallet.Address,
 			EncryptedPrivateKey: createdWallet.EncryptedPrivateKey,
 		}
-		return nil
+		return createOutboxMessages(ctx, q, messages)
 	})
 	return wallet, err
 }
@@ -92,3 +93,22 @@ func (r *walletRepository) GetWalletByUserID(ctx context.Context, userID uuid.UU
 		EncryptedPrivateKey: wallet.EncryptedPrivateKey,
 	}, nil
 }
+
+func (r *walletRepository) ListWallets(ctx context.Context) ([]domain.Wallet, error) {
+	q := sqlc.New(r.DB())
+	dbWallets, err := q.ListWallets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	wallets := make([]domain.Wallet, 0, len(dbWallets))
+	for _, wallet := range dbWallets {
+		wallets = append(wallets, domain.Wallet{
+			ID:                  wallet.ID.Bytes,
+			UserID:              wallet.UserID.Bytes,
+			Address:             wallet.Address,
+			EncryptedPrivateKey: wallet.EncryptedPrivateKey,
+		})
+	}
+	return wallets, nil
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"mpc/internal/domain"
 	"mpc/internal/infrastructure/auth"
+	"mpc/internal/infrastructure/events"
 	"mpc/internal/repository"
 	"mpc/pkg/utils"
 
@@ -21,13 +22,15 @@ type AuthUseCase interface {
 }
 
 type authUseCase struct {
-	userRepo   repository.UserRepository
-	walletUC   WalletUseCase
-	jwtService auth.JWTService
+	userRepo    repository.UserRepository
+	walletUC    WalletUseCase
+	jwtService  auth.JWTService
+	eventsTopic string
 }
 
-func NewAuthUC(userRepo repository.UserRepository, walletUC WalletUseCase, jwtService auth.JWTService) AuthUseCase {
-	return &authUseCase{userRepo: userRepo, walletUC: walletUC, jwtService: jwtService}
+// NewAuthUC returns the auth usecase. Users who sign up are announced on eventsTopic.
+func NewAuthUC(userRepo repository.UserRepository, walletUC WalletUseCase, jwtService auth.JWTService, eventsTopic string) AuthUseCase {
+	return &authUseCase{userRepo: userRepo, walletUC: walletUC, jwtService: jwtService, eventsTopic: eventsTopic}
 }
 
 var _ AuthUseCase = (*authUseCase)(nil)
@@ -51,11 +54,20 @@ func (uc *authUseCase) Signup(ctx context.Context, params domain.CreateUserParam
 
 		// Create user with hashed password
 		createParams := domain.CreateHashedUserParams{
+			ID:           uuid.New(),
 			Email:        params.Email,
 			PasswordHash: hashedPassword,
 		}
 
-		user, err = uc.userRepo.CreateUser(ctx, createParams)
+		registered, err := events.NewUserMessage(uc.eventsTopic, createParams.ID, domain.EventUserRegistered, domain.UserEvent{
+			UserID: createParams.ID,
+			Email:  createParams.Email,
+		})
+		if err != nil {
+			return err
+		}
+
+		user, err = uc.userRepo.CreateUser(ctx, createParams, registered)
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,428 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/events"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidBatch        = errors.New("invalid batch transfer")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+)
+
+// errBatchHalted is recorded on transfers skipped after an earlier transfer failed,
+// since their nonces could never be mined.
+var errBatchHalted = errors.New("not submitted because an earlier transfer in the batch failed")
+
+type batchRecipient struct {
+	to      common.Address
+	ensName string
+	amount  *big.Int
+	raw     domain.BatchRecipient
+}
+
+// CreateBatchTransaction pays every recipient from one wallet. The total amount plus gas is reserved
+// against the wallet's available balance before anything is sent. In sequential mode each transfer gets the next
+// nonce and its own status; in disperse mode all recipients are paid by one Disperse contract call, stored
+// as a parent transaction the recipients' transactions are linked to.
+func (f *evmFamily) CreateBatchTransaction(ctx context.Context, userID uuid.UUID, wallet domain.Wallet, params domain.CreateBatchTxnRequest) (domain.BatchTxnResponse, error) {
+	recipients, err := f.parseBatchRecipients(ctx, params.Recipients)
+	if err != nil {
+		return domain.BatchTxnResponse{}, err
+	}
+
+	from := common.HexToAddress(wallet.Address)
+
+	// Batches of one sender take their nonces one at a time, until their transactions are stored as submitted
+	unlock := f.lockSender(from)
+	defer unlock()
+
+	if params.Mode == domain.BatchModeDisperse {
+		return f.sendDisperseBatch(ctx, userID, from, params, recipients)
+	}
+	return f.sendSequentialBatch(ctx, userID, from, params, recipients)
+}
+
+func (f *evmFamily) sendSequentialBatch(ctx context.Context, userID uuid.UUID, from common.Address, params domain.CreateBatchTxnRequest, recipients []batchRecipient) (domain.BatchTxnResponse, error) {
+	nonce, err := f.nextNonce(ctx, params.WalletID, params.ChainID, from)
+	if err != nil {
+		return domain.BatchTxnResponse{}, err
+	}
+
+	unsignedTxs := make([]*types.Transaction, len(recipients))
+	for i, recipient := range recipients {
+		unsignedTxs[i], err = f.ethRepo.CreateUnsignedTransactionWithNonce(ctx, from, recipient.to, recipient.amount, nil, nonce+uint64(i))
+		if err != nil {
+			return domain.BatchTxnResponse{}, fmt.Errorf("failed to create unsigned transaction: %w", err)
+		}
+	}
+
+	txnIDs := make([]uuid.UUID, len(recipients))
+	amounts := make([]*big.Int, len(recipients))
+	for i, unsignedTx := range unsignedTxs {
+		txnIDs[i] = uuid.New()
+		amounts[i] = f.reservationAmount(unsignedTx)
+	}
+
+	if err := f.reserveBalances(ctx, params.WalletID, params.ChainID, from, txnIDs, amounts); err != nil {
+		return domain.BatchTxnResponse{}, err
+	}
+
+	privateKey, err := f.walletUC.GetPrivateKey(ctx, userID)
+	if err != nil {
+		for _, txnID := range txnIDs {
+			f.releaseReservation(ctx, txnID)
+		}
+		return domain.BatchTxnResponse{}, fmt.Errorf("failed to get private key: %w", err)
+	}
+
+	// Every transfer is stored before the first one is sent, so the relayer can top the wallet up for
+	// all of them at once
+	items := make([]domain.BatchTxnItem, len(recipients))
+	transactions := make([]domain.Transaction, len(recipients))
+	// stored is the number of leading transfers that were saved; nothing after a gap can be mined
+	stored := len(recipients)
+	for i, recipient := range recipients {
+		unsignedTx := unsignedTxs[i]
+		items[i] = domain.BatchTxnItem{
+			ToAddress: recipient.to.Hex(),
+			ToENSName: recipient.ensName,
+			Amount:    recipient.raw.Amount,
+			Nonce:     int64(unsignedTx.Nonce()),
+			Status:    domain.StatusPending,
+		}
+
+		transactions[i], err = f.createBatchTransaction(ctx, domain.CreateTransactionParams{
+			ID:        txnIDs[i],
+			WalletID:  params.WalletID,
+			ChainID:   params.ChainID,
+			ToAddress: recipient.to.Hex(),
+			ToENSName: recipient.ensName,
+			Amount:    recipient.raw.Amount,
+			TokenID:   params.TokenID,
+			GasPrice:  unsignedTx.GasPrice().String(),
+			GasLimit:  fmt.Sprintf("%d", unsignedTx.Gas()),
+			Nonce:     int64(unsignedTx.Nonce()),
+			Status:    domain.StatusPending,
+		})
+		if err != nil {
+			f.releaseReservation(ctx, txnIDs[i])
+			items[i].Status = domain.StatusFailed
+			items[i].Error = fmt.Sprintf("failed to save transaction to database: %v", err)
+			stored = min(stored, i)
+			continue
+		}
+		items[i].TxnID = transactions[i].ID
+	}
+
+	var sponsorship *domain.GasSponsorship
+	var sponsorErr error
+	if stored > 0 {
+		sponsorship, sponsorErr = f.relayerUC.EnsureGas(ctx, userID, transactions[0], from, batchGasTx(unsignedTxs[:stored]))
+	}
+
+	response := domain.BatchTxnResponse{Mode: domain.BatchModeSequential, Items: make([]domain.BatchTxnItem, 0, len(recipients))}
+	halted := false
+	for i, item := range items {
+		if item.TxnID == uuid.Nil {
+			// Nothing was sent for this nonce, so the following transfers cannot be mined either
+			response.Items = append(response.Items, item)
+			continue
+		}
+
+		if sponsorErr != nil {
+			item.Status = domain.StatusFailed
+			item.Error = fmt.Sprintf("failed to sponsor gas: %v", sponsorErr)
+			f.saveBatchItem(ctx, transactions[i], item, nil)
+			response.Items = append(response.Items, item)
+			continue
+		}
+
+		if halted || i >= stored {
+			item.Status = domain.StatusFailed
+			item.Error = errBatchHalted.Error()
+			f.saveBatchItem(ctx, transactions[i], item, nil)
+			response.Items = append(response.Items, item)
+			continue
+		}
+
+		signedTx, err := f.signAndSubmit(ctx, unsignedTxs[i], privateKey, sponsorship != nil)
+		if err != nil {
+			halted = true
+			item.Status = domain.StatusFailed
+			item.Error = err.Error()
+		} else {
+			item.Status = domain.StatusSubmitted
+			item.TxHash = signedTx.Hash().Hex()
+		}
+
+		f.saveBatchItem(ctx, transactions[i], item, signedTx)
+		response.Items = append(response.Items, item)
+	}
+
+	return response, nil
+}
+
+func (f *evmFamily) sendDisperseBatch(ctx context.Context, userID uuid.UUID, from common.Address, params domain.CreateBatchTxnRequest, recipients []batchRecipient) (domain.BatchTxnResponse, error) {
+	addresses := make([]common.Address, len(recipients))
+	values := make([]*big.Int, len(recipients))
+	total := new(big.Int)
+	for i, recipient := range recipients {
+		addresses[i] = recipient.to
+		values[i] = recipient.amount
+		total.Add(total, recipient.amount)
+	}
+
+	data, err := f.disperseRepo.EncodeDisperseEther(addresses, values)
+	if err != nil {
+		return domain.BatchTxnResponse{}, fmt.Errorf("failed to encode disperse call: %w", err)
+	}
+
+	nonce, err := f.nextNonce(ctx, params.WalletID, params.ChainID, from)
+	if err != nil {
+		return domain.BatchTxnResponse{}, err
+	}
+
+	contract := f.disperseRepo.ContractAddress()
+	unsignedTx, err := f.ethRepo.CreateUnsignedTransactionWithNonce(ctx, from, contract, total, data, nonce)
+	if err != nil {
+		return domain.BatchTxnResponse{}, fmt.Errorf("failed to create unsigned transaction: %w", err)
+	}
+
+	// The contract call is the parent transaction: it alone reserves funds, is followed by the worker
+	// and is announced on the events topic
+	parentID := uuid.New()
+	if err := f.reserveBalance(ctx, params.WalletID, params.ChainID, from, parentID, f.reservationAmount(unsignedTx)); err != nil {
+		return domain.BatchTxnResponse{}, err
+	}
+
+	privateKey, err := f.walletUC.GetPrivateKey(ctx, userID)
+	if err != nil {
+		f.releaseReservation(ctx, parentID)
+		return domain.BatchTxnResponse{}, fmt.Errorf("failed to get private key: %w", err)
+	}
+
+	parent, err := f.createBatchTransaction(ctx, domain.CreateTransactionParams{
+		ID:        parentID,
+		WalletID:  params.WalletID,
+		ChainID:   params.ChainID,
+		ToAddress: contract.Hex(),
+		Amount:    fromWei(total),
+		TokenID:   params.TokenID,
+		GasPrice:  unsignedTx.GasPrice().String(),
+		GasLimit:  fmt.Sprintf("%d", unsignedTx.Gas()),
+		Nonce:     int64(unsignedTx.Nonce()),
+		Status:    domain.StatusPending,
+	})
+	if err != nil {
+		f.releaseReservation(ctx, parentID)
+		return domain.BatchTxnResponse{}, fmt.Errorf("failed to save transaction to database: %w", err)
+	}
+
+	parentItem := domain.BatchTxnItem{TxnID: parent.ID, Nonce: parent.Nonce, Status: domain.StatusFailed}
+	transactions := make([]domain.Transaction, len(recipients))
+	for i, recipient := range recipients {
+		transactions[i], err = f.txnRepo.CreateTransaction(ctx, domain.CreateTransactionParams{
+			ID:        uuid.New(),
+			WalletID:  params.WalletID,
+			ChainID:   params.ChainID,
+			ToAddress: recipient.to.Hex(),
+			ToENSName: recipient.ensName,
+			Amount:    recipient.raw.Amount,
+			TokenID:   params.TokenID,
+			Nonce:     parent.Nonce,
+			Status:    domain.StatusPending,
+			ParentID:  parent.ID,
+		})
+		if err != nil {
+			// The recipients saved so far fail along with the parent
+			parentItem.Error = fmt.Sprintf("failed to save transaction to database: %v", err)
+			f.saveBatchItem(ctx, parent, parentItem, nil)
+			return domain.BatchTxnResponse{}, fmt.Errorf("failed to save transaction to database: %w", err)
+		}
+	}
+
+	var signedTx *types.Transaction
+	sponsorship, err := f.relayerUC.EnsureGas(ctx, userID, parent, from, unsignedTx)
+	if err != nil {
+		parentItem.Error = fmt.Sprintf("failed to sponsor gas: %v", err)
+	} else if signedTx, err = f.signAndSubmit(ctx, unsignedTx, privateKey, sponsorship != nil); err != nil {
+		parentItem.Error = err.Error()
+	} else {
+		parentItem.Status = domain.StatusSubmitted
+		parentItem.TxHash = signedTx.Hash().Hex()
+	}
+	f.saveBatchItem(ctx, parent, parentItem, signedTx)
+
+	response := domain.BatchTxnResponse{Mode: domain.BatchModeDisperse, ParentTxnID: parent.ID, Items: make([]domain.BatchTxnItem, 0, len(recipients))}
+	for i, transaction := range transactions {
+		response.Items = append(response.Items, domain.BatchTxnItem{
+			TxnID:     transaction.ID,
+			ToAddress: transaction.ToAddress,
+			ToENSName: recipients[i].ensName,
+			Amount:    recipients[i].raw.Amount,
+			Nonce:     parent.Nonce,
+			TxHash:    parentItem.TxHash,
+			Status:    parentItem.Status,
+			Error:     parentItem.Error,
+		})
+	}
+
+	return response, nil
+}
+
+// lockSender serializes the batches of one sender within this process, so two batches never pick the
+// same nonces. It returns the function releasing the lock.
+func (f *evmFamily) lockSender(from common.Address) func() {
+	lock, _ := f.senders.LoadOrStore(from, &sync.Mutex{})
+	mu := lock.(*sync.Mutex)
+	mu.Lock()
+	return mu.Unlock
+}
+
+// nextNonce returns the first nonce a batch of the wallet can use. It is the node's pending nonce
+// unless the wallet has submitted transactions the node has not seen yet, held back for a gas top-up.
+func (f *evmFamily) nextNonce(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID, from common.Address) (uint64, error) {
+	pending, err := f.ethRepo.GetPendingNonce(ctx, from)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+
+	stored, err := f.txnRepo.GetNextNonce(ctx, walletID, chainID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get next nonce: %w", err)
+	}
+	return max(pending, stored), nil
+}
+
+// batchGasTx stands for all transfers of a sequential batch when the relayer checks the wallet can
+// pay for gas: their values and gas add up, at the highest gas price among them.
+func batchGasTx(txs []*types.Transaction) *types.Transaction {
+	value := new(big.Int)
+	gasPrice := new(big.Int)
+	var gas uint64
+	for _, tx := range txs {
+		value.Add(value, tx.Value())
+		gas += tx.Gas()
+		if tx.GasPrice().Cmp(gasPrice) > 0 {
+			gasPrice.Set(tx.GasPrice())
+		}
+	}
+	return types.NewTx(&types.LegacyTx{Nonce: txs[0].Nonce(), Value: value, Gas: gas, GasPrice: gasPrice})
+}
+
+func (f *evmFamily) parseBatchRecipients(ctx context.Context, recipients []domain.BatchRecipient) ([]batchRecipient, error) {
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("%w: no recipients", ErrInvalidBatch)
+	}
+	if f.batchCfg.MaxRecipients > 0 && len(recipients) > f.batchCfg.MaxRecipients {
+		return nil, fmt.Errorf("%w: %d recipients exceeds the limit of %d", ErrInvalidBatch, len(recipients), f.batchCfg.MaxRecipients)
+	}
+
+	parsed := make([]batchRecipient, 0, len(recipients))
+	for i, recipient := range recipients {
+		to, ensName, err := resolveRecipient(ctx, f.ensRepo, recipient.ToAddress)
+		if err != nil {
+			return nil, fmt.Errorf("recipient %d: %w", i, err)
+		}
+
+		amount, err := toWei(recipient.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("%w: recipient %d: %v", ErrInvalidBatch, i, err)
+		}
+		if amount.Sign() <= 0 {
+			return nil, fmt.Errorf("%w: recipient %d has non-positive amount", ErrInvalidBatch, i)
+		}
+
+		parsed = append(parsed, batchRecipient{
+			to:      to,
+			ensName: ensName,
+			amount:  amount,
+			raw:     recipient,
+		})
+	}
+	return parsed, nil
+}
+
+// signAndSubmit signs the transaction and submits it. A transaction held back for a gas top-up is
+// only signed: the worker submits it once the top-up is mined.
+func (f *evmFamily) signAndSubmit(ctx context.Context, unsignedTx *types.Transaction, privateKey *ecdsa.PrivateKey, held bool) (*types.Transaction, error) {
+	signedTx, err := f.ethRepo.SignTransaction(ctx, unsignedTx, privateKey)
+	if err != nil {
+		return nil, err
+	}
+	if held {
+		return signedTx, nil
+	}
+	if _, err := f.ethRepo.SubmitTransaction(ctx, signedTx); err != nil {
+		return nil, err
+	}
+	return signedTx, nil
+}
+
+// createBatchTransaction stores one transfer of a batch along with the event announcing it.
+func (f *evmFamily) createBatchTransaction(ctx context.Context, params domain.CreateTransactionParams) (domain.Transaction, error) {
+	created, err := newCreatedMessage(f.eventsTopic, params)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	return f.txnRepo.CreateTransaction(ctx, params, created)
+}
+
+// saveBatchItem stores the outcome of one on-chain transaction of a batch, with the signed transaction
+// when it was submitted.
+// Submitted transfers are announced to the worker through the outbox, and every outcome on the
+// events topic.
+func (f *evmFamily) saveBatchItem(ctx context.Context, transaction domain.Transaction, item domain.BatchTxnItem, signedTx *types.Transaction) {
+	transaction.Status = item.Status
+	transaction.TxHash = item.TxHash
+	if item.Status == domain.StatusFailed {
+		f.releaseReservation(ctx, transaction.ID)
+	}
+	if signedTx != nil {
+		rawTx, err := encodeRawTx(signedTx)
+		if err != nil {
+			log.Printf("Failed to encode signed batch transaction %s: %v", transaction.ID, err)
+		}
+		transaction.RawTx = rawTx
+	}
+	var messages []domain.OutboxMessage
+	if signedTx != nil {
+		// Batch transfers are signed and broadcast in one go, so they are announced together
+		signed := transaction
+		signed.Status = domain.StatusPending
+		message, err := events.NewWalletMessage(f.eventsTopic, transaction.WalletID, domain.EventTransactionSigned, domain.NewTransactionEvent(signed))
+		if err != nil {
+			log.Printf("Failed to build signed event of batch transaction %s: %v", transaction.ID, err)
+		} else {
+			messages = append(messages, message)
+		}
+	}
+	if item.Status == domain.StatusSubmitted {
+		message, err := newTxnMessage(f.txnTopic, transaction)
+		if err != nil {
+			log.Printf("Failed to build message for batch transaction %s: %v", transaction.ID, err)
+		} else {
+			messages = append(messages, message)
+		}
+	}
+	if message, err := events.NewTransactionMessage(f.eventsTopic, transaction); err != nil {
+		log.Printf("Failed to build event of batch transaction %s: %v", transaction.ID, err)
+	} else {
+		messages = append(messages, message)
+	}
+	if err := f.txnRepo.UpdateTransaction(ctx, transaction, messages...); err != nil {
+		log.Printf("Failed to update batch transaction %s: %v", transaction.ID, err)
+	}
+}
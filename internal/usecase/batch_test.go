@@ -0,0 +1,324 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/repository"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+// stubBatchEth builds transactions without a node and records the ones it is asked to submit.
+// Submitting the transaction with nonce failNonce fails.
+type stubBatchEth struct {
+	repository.EthereumRepository
+	pendingNonce uint64
+	failNonce    *uint64
+	submitted    []*types.Transaction
+}
+
+func (s *stubBatchEth) GetPendingNonce(ctx context.Context, address common.Address) (uint64, error) {
+	return s.pendingNonce, nil
+}
+
+func (s *stubBatchEth) GetBalance(ctx context.Context, address common.Address) (*big.Int, error) {
+	return big.NewInt(1e18), nil
+}
+
+func (s *stubBatchEth) CreateUnsignedTransactionWithNonce(ctx context.Context, from common.Address, to common.Address, amount *big.Int, data []byte, nonce uint64) (*types.Transaction, error) {
+	return types.NewTx(&types.LegacyTx{Nonce: nonce, To: &to, Value: amount, Gas: 21_000, GasPrice: big.NewInt(gwei), Data: data}), nil
+}
+
+func (s *stubBatchEth) SignTransaction(ctx context.Context, tx *types.Transaction, privateKey *ecdsa.PrivateKey) (*types.Transaction, error) {
+	return tx, nil
+}
+
+func (s *stubBatchEth) SubmitTransaction(ctx context.Context, signedTx *types.Transaction) (common.Hash, error) {
+	if s.failNonce != nil && signedTx.Nonce() == *s.failNonce {
+		return common.Hash{}, errors.New("nonce too low")
+	}
+	s.submitted = append(s.submitted, signedTx)
+	return signedTx.Hash(), nil
+}
+
+// memBatchTxnRepo keeps the transactions of a batch and the outbox messages written with them.
+// Like the database, saving a transaction copies its status to the transactions linked to it.
+type memBatchTxnRepo struct {
+	repository.TransactionRepository
+	nextNonce    uint64
+	transactions map[uuid.UUID]domain.Transaction
+	messages     map[uuid.UUID][]domain.OutboxMessage
+}
+
+func (r *memBatchTxnRepo) CreateTransaction(ctx context.Context, params domain.CreateTransactionParams, messages ...domain.OutboxMessage) (domain.Transaction, error) {
+	txn := domain.Transaction{ID: params.ID, WalletID: params.WalletID, ChainID: params.ChainID, ToAddress: params.ToAddress, Amount: params.Amount, Nonce: params.Nonce, Status: params.Status, ParentID: params.ParentID}
+	r.transactions[txn.ID] = txn
+	r.messages[txn.ID] = append(r.messages[txn.ID], messages...)
+	return txn, nil
+}
+
+func (r *memBatchTxnRepo) UpdateTransaction(ctx context.Context, txn domain.Transaction, messages ...domain.OutboxMessage) error {
+	r.transactions[txn.ID] = txn
+	r.messages[txn.ID] = append(r.messages[txn.ID], messages...)
+	for id, child := range r.transactions {
+		if child.ParentID == txn.ID {
+			child.Status = txn.Status
+			r.transactions[id] = child
+		}
+	}
+	return nil
+}
+
+func (r *memBatchTxnRepo) GetNextNonce(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) (uint64, error) {
+	return r.nextNonce, nil
+}
+
+// memBatchBalances accepts every reservation and keeps the ones not released yet.
+type memBatchBalances struct {
+	repository.BalanceRepository
+	reservations map[uuid.UUID]*big.Int
+}
+
+func (b *memBatchBalances) ReserveBalance(ctx context.Context, params domain.ReserveBalanceParams) (bool, error) {
+	b.reservations[params.TransactionID] = params.Amount
+	return true, nil
+}
+
+func (b *memBatchBalances) ReleaseReservation(ctx context.Context, transactionID uuid.UUID) error {
+	delete(b.reservations, transactionID)
+	return nil
+}
+
+// stubDisperse encodes the recipients of a Disperse call as their concatenated addresses.
+type stubDisperse struct{}
+
+func (stubDisperse) ContractAddress() common.Address {
+	return common.HexToAddress("0xD152f549545093347A162Dce210e7293f1452150")
+}
+
+func (stubDisperse) EncodeDisperseEther(recipients []common.Address, values []*big.Int) ([]byte, error) {
+	var data []byte
+	for _, recipient := range recipients {
+		data = append(data, recipient.Bytes()...)
+	}
+	return data, nil
+}
+
+// recordingSponsor tops up every wallet with sponsorship, when set, and records what it was asked to pay for.
+type recordingSponsor struct {
+	RelayerUseCase
+	sponsorship *domain.GasSponsorship
+	requests    []*types.Transaction
+}
+
+func (s *recordingSponsor) SponsorsGas() bool {
+	return true
+}
+
+func (s *recordingSponsor) EnsureGas(ctx context.Context, userID uuid.UUID, txn domain.Transaction, from common.Address, tx *types.Transaction) (*domain.GasSponsorship, error) {
+	s.requests = append(s.requests, tx)
+	return s.sponsorship, nil
+}
+
+type batchFixture struct {
+	family   *evmFamily
+	ethRepo  *stubBatchEth
+	txnRepo  *memBatchTxnRepo
+	balances *memBatchBalances
+	sponsor  *recordingSponsor
+	wallet   domain.Wallet
+}
+
+func newBatchFixture(sponsorship *domain.GasSponsorship, failNonce *uint64) *batchFixture {
+	fx := &batchFixture{
+		ethRepo:  &stubBatchEth{pendingNonce: 5, failNonce: failNonce},
+		txnRepo:  &memBatchTxnRepo{nextNonce: 7, transactions: map[uuid.UUID]domain.Transaction{}, messages: map[uuid.UUID][]domain.OutboxMessage{}},
+		balances: &memBatchBalances{reservations: map[uuid.UUID]*big.Int{}},
+		sponsor:  &recordingSponsor{sponsorship: sponsorship},
+		wallet:   domain.Wallet{ID: uuid.New(), UserID: uuid.New(), Address: "0x5FbDB2315678afecb367f032d93F642f64180aa3"},
+	}
+	fx.family = &evmFamily{
+		txnRepo:      fx.txnRepo,
+		ethRepo:      fx.ethRepo,
+		walletUC:     &stubSafeWallets{wallets: []domain.Wallet{fx.wallet}},
+		relayerUC:    fx.sponsor,
+		disperseRepo: stubDisperse{},
+		ensRepo:      stubENS{},
+		balanceRepo:  fx.balances,
+		batchCfg:     &config.BatchConfig{MaxRecipients: 10},
+		balanceCfg:   &config.BalanceConfig{},
+		txnTopic:     "transactions",
+		eventsTopic:  "events",
+	}
+	return fx
+}
+
+func (fx *batchFixture) send(t *testing.T, mode domain.BatchMode) domain.BatchTxnResponse {
+	t.Helper()
+	response, err := fx.family.CreateBatchTransaction(context.Background(), fx.wallet.UserID, fx.wallet, domain.CreateBatchTxnRequest{
+		WalletID: fx.wallet.ID,
+		ChainID:  uuid.New(),
+		Mode:     mode,
+		Recipients: []domain.BatchRecipient{
+			{ToAddress: "0x00000000000000000000000000000000000A11CE", Amount: "0.1"},
+			{ToAddress: "0x0000000000000000000000000000000000000B0B", Amount: "0.2"},
+			{ToAddress: "0x0000000000000000000000000000000000000CA7", Amount: "0.3"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("CreateBatchTransaction() error = %v", err)
+	}
+	return response
+}
+
+func TestSequentialBatch(t *testing.T) {
+	failAt := uint64(8)
+
+	tests := []struct {
+		name        string
+		sponsorship *domain.GasSponsorship
+		failNonce   *uint64
+		wantStatus  []domain.Status
+		// wantSubmitted is the number of transfers sent to the node
+		wantSubmitted int
+	}{
+		{
+			name:          "every transfer submitted",
+			wantStatus:    []domain.Status{domain.StatusSubmitted, domain.StatusSubmitted, domain.StatusSubmitted},
+			wantSubmitted: 3,
+		},
+		{
+			// The third nonce could never be mined once the second was not sent
+			name:          "second transfer fails",
+			failNonce:     &failAt,
+			wantStatus:    []domain.Status{domain.StatusSubmitted, domain.StatusFailed, domain.StatusFailed},
+			wantSubmitted: 1,
+		},
+		{
+			// The worker submits them once the top-up is mined
+			name:        "held back for a top-up",
+			sponsorship: &domain.GasSponsorship{ID: uuid.New(), Status: domain.SponsorshipStatusPending},
+			wantStatus:  []domain.Status{domain.StatusSubmitted, domain.StatusSubmitted, domain.StatusSubmitted},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fx := newBatchFixture(tt.sponsorship, tt.failNonce)
+
+			response := fx.send(t, domain.BatchModeSequential)
+
+			if len(response.Items) != len(tt.wantStatus) {
+				t.Fatalf("Got %d items, want %d", len(response.Items), len(tt.wantStatus))
+			}
+			for i, item := range response.Items {
+				// Nonces continue after the transactions still held for a top-up, not the node's pending nonce
+				if item.Nonce != int64(7+i) {
+					t.Errorf("Item %d has nonce %d, want %d", i, item.Nonce, 7+i)
+				}
+				if item.Status != tt.wantStatus[i] {
+					t.Errorf("Item %d is %s (%s), want %s", i, item.Status, item.Error, tt.wantStatus[i])
+				}
+				if got := fx.txnRepo.transactions[item.TxnID].Status; got != tt.wantStatus[i] {
+					t.Errorf("Transaction %d is stored as %s, want %s", i, got, tt.wantStatus[i])
+				}
+				// Failed transfers give their funds back
+				_, reserved := fx.balances.reservations[item.TxnID]
+				if reserved != (tt.wantStatus[i] == domain.StatusSubmitted) {
+					t.Errorf("Transaction %d reserved = %v while %s", i, reserved, tt.wantStatus[i])
+				}
+			}
+			if len(fx.ethRepo.submitted) != tt.wantSubmitted {
+				t.Errorf("Submitted %d transfers, want %d", len(fx.ethRepo.submitted), tt.wantSubmitted)
+			}
+
+			// The relayer is asked once, for the value and gas of the whole batch
+			if len(fx.sponsor.requests) != 1 {
+				t.Fatalf("EnsureGas() called %d times, want once", len(fx.sponsor.requests))
+			}
+			if got, want := fx.sponsor.requests[0].Value(), big.NewInt(6e17); got.Cmp(want) != 0 {
+				t.Errorf("Gas requested for a value of %s, want %s", got, want)
+			}
+			if got := fx.sponsor.requests[0].Gas(); got != 3*21_000 {
+				t.Errorf("Gas requested for %d gas, want %d", got, 3*21_000)
+			}
+		})
+	}
+}
+
+func TestDisperseBatch(t *testing.T) {
+	failAt := uint64(7)
+
+	tests := []struct {
+		name          string
+		sponsorship   *domain.GasSponsorship
+		failNonce     *uint64
+		wantStatus    domain.Status
+		wantSubmitted bool
+	}{
+		{
+			name:          "contract call submitted",
+			wantStatus:    domain.StatusSubmitted,
+			wantSubmitted: true,
+		},
+		{
+			name:       "contract call fails",
+			failNonce:  &failAt,
+			wantStatus: domain.StatusFailed,
+		},
+		{
+			name:        "held back for a top-up",
+			sponsorship: &domain.GasSponsorship{ID: uuid.New(), Status: domain.SponsorshipStatusPending},
+			wantStatus:  domain.StatusSubmitted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fx := newBatchFixture(tt.sponsorship, tt.failNonce)
+
+			response := fx.send(t, domain.BatchModeDisperse)
+
+			parent, ok := fx.txnRepo.transactions[response.ParentTxnID]
+			if !ok {
+				t.Fatalf("Parent transaction %s not stored", response.ParentTxnID)
+			}
+			if parent.Status != tt.wantStatus || parent.Nonce != 7 || parent.Amount != "0.6" {
+				t.Errorf("Parent is %s with nonce %d for %s, want %s with nonce 7 for 0.6", parent.Status, parent.Nonce, parent.Amount, tt.wantStatus)
+			}
+			if submitted := len(fx.ethRepo.submitted) == 1; submitted != tt.wantSubmitted {
+				t.Errorf("Submitted = %v, want %v", submitted, tt.wantSubmitted)
+			}
+			if len(fx.sponsor.requests) != 1 {
+				t.Errorf("EnsureGas() called %d times, want once", len(fx.sponsor.requests))
+			}
+
+			// Only the parent reserves funds and is announced; its fee and events are counted once
+			_, reserved := fx.balances.reservations[parent.ID]
+			if reserved != (tt.wantStatus == domain.StatusSubmitted) || len(fx.balances.reservations) > 1 {
+				t.Errorf("Reservations = %v, want only the parent's while it is %s", fx.balances.reservations, tt.wantStatus)
+			}
+
+			if len(response.Items) != 3 {
+				t.Fatalf("Got %d items, want 3", len(response.Items))
+			}
+			for i, item := range response.Items {
+				child := fx.txnRepo.transactions[item.TxnID]
+				if child.ParentID != parent.ID {
+					t.Errorf("Recipient %d is linked to %s, want the parent %s", i, child.ParentID, parent.ID)
+				}
+				if child.Status != tt.wantStatus || item.Status != tt.wantStatus {
+					t.Errorf("Recipient %d is stored as %s and reported as %s, want %s", i, child.Status, item.Status, tt.wantStatus)
+				}
+				if messages := fx.txnRepo.messages[child.ID]; len(messages) != 0 {
+					t.Errorf("Recipient %d was announced with %d messages, want none", i, len(messages))
+				}
+			}
+		})
+	}
+}
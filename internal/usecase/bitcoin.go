@@ -0,0 +1,585 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/bitcoin"
+	"mpc/internal/repository"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/btcsuite/btcd/btcutil"
+	"github.com/btcsuite/btcd/btcutil/psbt"
+	"github.com/btcsuite/btcd/chaincfg"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var ErrUnsupportedChain = errors.New("operation is not supported on this chain")
+
+// feeTargets are the confirmation targets, in blocks, of each fee priority.
+var feeTargets = map[domain.FeePriority]int{
+	domain.FeePriorityLow:    144,
+	domain.FeePriorityMedium: 6,
+	domain.FeePriorityHigh:   2,
+}
+
+// minFeeRate is the minimum relay fee rate of Bitcoin Core, in sat/vB.
+const minFeeRate = 1
+
+type BitcoinUseCase interface {
+	GetAddresses(ctx context.Context, userID uuid.UUID, walletID uuid.UUID, chainID uuid.UUID) (domain.BitcoinAddresses, error)
+	GetUTXOs(ctx context.Context, userID uuid.UUID, walletID uuid.UUID, chainID uuid.UUID) (domain.BitcoinUTXOsResponse, error)
+	EstimateFeeRates(ctx context.Context, chainID uuid.UUID) (domain.BitcoinFeeRates, error)
+	ChainFamily
+}
+
+type bitcoinUseCase struct {
+	chainRepo  repository.ChainRepository
+	utxoRepo   repository.UTXORepository
+	walletUC   WalletUseCase
+	newBackend func(chain domain.Chain) (repository.BitcoinBackend, error)
+}
+
+func NewBitcoinUC(chainRepo repository.ChainRepository, utxoRepo repository.UTXORepository, walletUC WalletUseCase, newBackend func(chain domain.Chain) (repository.BitcoinBackend, error)) BitcoinUseCase {
+	return &bitcoinUseCase{chainRepo: chainRepo, utxoRepo: utxoRepo, walletUC: walletUC, newBackend: newBackend}
+}
+
+var _ BitcoinUseCase = (*bitcoinUseCase)(nil)
+
+// GetAddresses returns the P2WPKH and P2TR addresses a wallet's key controls on a Bitcoin chain and
+// starts tracking the outputs paying to them.
+func (uc *bitcoinUseCase) GetAddresses(ctx context.Context, userID uuid.UUID, walletID uuid.UUID, chainID uuid.UUID) (domain.BitcoinAddresses, error) {
+	if _, err := getOwnedWallet(ctx, uc.walletUC, userID, walletID); err != nil {
+		return domain.BitcoinAddresses{}, err
+	}
+
+	_, params, err := uc.getChain(ctx, chainID)
+	if err != nil {
+		return domain.BitcoinAddresses{}, err
+	}
+
+	key, err := uc.walletKey(ctx, userID)
+	if err != nil {
+		return domain.BitcoinAddresses{}, err
+	}
+	return uc.registerAddresses(ctx, walletID, chainID, key, params)
+}
+
+// GetUTXOs returns the wallet's unspent outputs on a Bitcoin chain with their totals.
+func (uc *bitcoinUseCase) GetUTXOs(ctx context.Context, userID uuid.UUID, walletID uuid.UUID, chainID uuid.UUID) (domain.BitcoinUTXOsResponse, error) {
+	if _, err := getOwnedWallet(ctx, uc.walletUC, userID, walletID); err != nil {
+		return domain.BitcoinUTXOsResponse{}, err
+	}
+	if _, _, err := uc.getChain(ctx, chainID); err != nil {
+		return domain.BitcoinUTXOsResponse{}, err
+	}
+
+	utxos, err := uc.utxoRepo.ListUnspentUTXOsByWalletID(ctx, walletID, chainID)
+	if err != nil {
+		return domain.BitcoinUTXOsResponse{}, fmt.Errorf("failed to list UTXOs: %w", err)
+	}
+
+	response := domain.BitcoinUTXOsResponse{WalletID: walletID, ChainID: chainID, UTXOs: utxos}
+	for _, utxo := range utxos {
+		switch {
+		case utxo.ReservedBy != uuid.Nil:
+			response.Reserved += utxo.Amount
+		case utxo.BlockNumber == 0:
+			response.Unconfirmed += utxo.Amount
+		default:
+			response.Confirmed += utxo.Amount
+		}
+	}
+	return response, nil
+}
+
+// EstimateFeeRates returns the node's fee rate estimates for each priority.
+func (uc *bitcoinUseCase) EstimateFeeRates(ctx context.Context, chainID uuid.UUID) (domain.BitcoinFeeRates, error) {
+	chain, _, err := uc.getChain(ctx, chainID)
+	if err != nil {
+		return domain.BitcoinFeeRates{}, err
+	}
+
+	backend, err := uc.newBackend(chain)
+	if err != nil {
+		return domain.BitcoinFeeRates{}, fmt.Errorf("failed to connect to %s: %w", chain.Name, err)
+	}
+
+	rates := domain.BitcoinFeeRates{ChainID: chainID}
+	for priority, rate := range map[domain.FeePriority]*int64{
+		domain.FeePriorityLow:    &rates.Low,
+		domain.FeePriorityMedium: &rates.Medium,
+		domain.FeePriorityHigh:   &rates.High,
+	} {
+		if *rate, err = estimateFeeRate(ctx, backend, priority); err != nil {
+			return domain.BitcoinFeeRates{}, err
+		}
+	}
+	return rates, nil
+}
+
+func (uc *bitcoinUseCase) Family() domain.ChainFamily {
+	return domain.ChainFamilyBitcoin
+}
+
+// BuildTransaction selects UTXOs for the payment and builds an unsigned PSBT with change going to a
+// fresh change address. Every input of the PSBT names the key that spends it.
+func (uc *bitcoinUseCase) BuildTransaction(ctx context.Context, userID uuid.UUID, chain domain.Chain, wallet domain.Wallet, params domain.CreateTxnRequest) (domain.UnsignedTransaction, error) {
+	network, err := bitcoin.NetworkParams(chain.ChainID)
+	if err != nil {
+		return domain.UnsignedTransaction{}, err
+	}
+
+	recipient, err := bitcoin.DecodeAddress(params.ToAddress, network)
+	if err != nil {
+		return domain.UnsignedTransaction{}, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+	paymentScript, err := txscript.PayToAddrScript(recipient)
+	if err != nil {
+		return domain.UnsignedTransaction{}, fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+	}
+
+	amount, err := toSatoshis(params.Amount)
+	if err != nil {
+		return domain.UnsignedTransaction{}, err
+	}
+
+	feeRate := params.FeeRate
+	if feeRate <= 0 {
+		backend, err := uc.newBackend(chain)
+		if err != nil {
+			return domain.UnsignedTransaction{}, fmt.Errorf("failed to connect to %s: %w", chain.Name, err)
+		}
+		if feeRate, err = estimateFeeRate(ctx, backend, params.FeePriority); err != nil {
+			return domain.UnsignedTransaction{}, err
+		}
+	}
+
+	key, err := uc.walletKey(ctx, userID)
+	if err != nil {
+		return domain.UnsignedTransaction{}, err
+	}
+	if _, err := uc.registerAddresses(ctx, wallet.ID, chain.ID, key, network); err != nil {
+		return domain.UnsignedTransaction{}, err
+	}
+
+	utxos, err := uc.spendableUTXOs(ctx, wallet.ID, chain.ID)
+	if err != nil {
+		return domain.UnsignedTransaction{}, err
+	}
+
+	// Change of the same type as the payment does not stand out from it
+	_, taproot := recipient.(*btcutil.AddressTaproot)
+	change, err := uc.changeAddress(ctx, wallet.ID, chain.ID, key, network, taproot && params.CoinSelection == domain.CoinSelectionPrivacy)
+	if err != nil {
+		return domain.UnsignedTransaction{}, err
+	}
+	changeScript, err := txscript.PayToAddrScript(change)
+	if err != nil {
+		return domain.UnsignedTransaction{}, err
+	}
+
+	selection, err := bitcoin.SelectCoins(params.CoinSelection, utxos, amount, paymentScript, changeScript, feeRate)
+	if err != nil {
+		if errors.Is(err, bitcoin.ErrInsufficientFunds) {
+			return domain.UnsignedTransaction{}, fmt.Errorf("%w: %v", ErrInsufficientBalance, err)
+		}
+		return domain.UnsignedTransaction{}, err
+	}
+
+	addresses, err := uc.utxoRepo.ListBitcoinAddressesByWalletID(ctx, wallet.ID, chain.ID)
+	if err != nil {
+		return domain.UnsignedTransaction{}, fmt.Errorf("failed to list addresses: %w", err)
+	}
+	keys, err := keyRing(key, addresses, network)
+	if err != nil {
+		return domain.UnsignedTransaction{}, err
+	}
+
+	packet, err := bitcoin.BuildPSBT(selection, bitcoin.Payment{Address: recipient, Amount: amount}, change, keys)
+	if err != nil {
+		return domain.UnsignedTransaction{}, err
+	}
+	var payload bytes.Buffer
+	if err := packet.Serialize(&payload); err != nil {
+		return domain.UnsignedTransaction{}, fmt.Errorf("failed to encode PSBT: %w", err)
+	}
+
+	return domain.UnsignedTransaction{Payload: payload.Bytes(), ToAddress: recipient.EncodeAddress()}, nil
+}
+
+// ReserveFunds reserves the UTXOs the PSBT spends.
+func (uc *bitcoinUseCase) ReserveFunds(ctx context.Context, txn domain.Transaction, unsigned domain.UnsignedTransaction) error {
+	packet, err := decodePSBT(unsigned.Payload)
+	if err != nil {
+		return err
+	}
+
+	utxos, err := uc.utxoRepo.ListUnspentUTXOsByWalletID(ctx, txn.WalletID, txn.ChainID)
+	if err != nil {
+		return fmt.Errorf("failed to list UTXOs: %w", err)
+	}
+	ids := make(map[domain.BitcoinOutPoint]uuid.UUID, len(utxos))
+	for _, utxo := range utxos {
+		ids[domain.BitcoinOutPoint{TxID: utxo.TxID, Vout: utxo.Vout}] = utxo.ID
+	}
+
+	utxoIDs := make([]uuid.UUID, 0, len(packet.UnsignedTx.TxIn))
+	for _, txIn := range packet.UnsignedTx.TxIn {
+		outPoint := domain.BitcoinOutPoint{TxID: txIn.PreviousOutPoint.Hash.String(), Vout: txIn.PreviousOutPoint.Index}
+		id, ok := ids[outPoint]
+		if !ok {
+			return fmt.Errorf("failed to reserve UTXOs: %s:%d is spent", outPoint.TxID, outPoint.Vout)
+		}
+		utxoIDs = append(utxoIDs, id)
+	}
+
+	reserved, err := uc.utxoRepo.ReserveUTXOs(ctx, txn.ID, utxoIDs)
+	if err == nil && !reserved {
+		err = errors.New("another transaction is spending the selected outputs")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to reserve UTXOs: %w", err)
+	}
+	return nil
+}
+
+func (uc *bitcoinUseCase) ReleaseFunds(ctx context.Context, txn domain.Transaction) {
+	if err := uc.utxoRepo.ReleaseUTXOs(ctx, txn.ID); err != nil {
+		log.Printf("Failed to release UTXOs of transaction %s: %v", txn.ID, err)
+	}
+}
+
+// SigningPayloads returns the signature hash of every input. Inputs paying to change addresses are
+// signed by the change keys derived from the wallet key.
+func (uc *bitcoinUseCase) SigningPayloads(ctx context.Context, userID uuid.UUID, txn domain.Transaction, unsigned domain.UnsignedTransaction) ([]domain.SigningPayload, error) {
+	packet, err := decodePSBT(unsigned.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := uc.walletKey(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return bitcoin.SigningPayloads(packet, bitcoin.ChangeChainCode(key.PubKey()))
+}
+
+func (uc *bitcoinUseCase) AssembleTransaction(ctx context.Context, unsigned domain.UnsignedTransaction, signatures [][]byte) (domain.SignedTransaction, error) {
+	packet, err := decodePSBT(unsigned.Payload)
+	if err != nil {
+		return domain.SignedTransaction{}, err
+	}
+
+	if err := bitcoin.AddSignatures(packet, signatures); err != nil {
+		return domain.SignedTransaction{}, err
+	}
+	tx, rawTx, err := bitcoin.ExtractTransaction(packet)
+	if err != nil {
+		return domain.SignedTransaction{}, err
+	}
+	return domain.SignedTransaction{Payload: rawTx, TxHash: tx.TxHash().String()}, nil
+}
+
+// Broadcast sends the transaction to the chain's node and records its fee, the value of the UTXOs
+// reserved for it minus the value of its outputs.
+func (uc *bitcoinUseCase) Broadcast(ctx context.Context, userID uuid.UUID, txn domain.Transaction, signed domain.SignedTransaction) (domain.Transaction, error) {
+	chain, _, err := uc.getChain(ctx, txn.ChainID)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(signed.Payload)); err != nil {
+		return domain.Transaction{}, fmt.Errorf("invalid transaction: %w", err)
+	}
+
+	utxos, err := uc.utxoRepo.ListUnspentUTXOsByWalletID(ctx, txn.WalletID, txn.ChainID)
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("failed to list UTXOs: %w", err)
+	}
+	var fee int64
+	for _, utxo := range utxos {
+		if utxo.ReservedBy == txn.ID {
+			fee += utxo.Amount
+		}
+	}
+	for _, output := range tx.TxOut {
+		fee -= output.Value
+	}
+
+	backend, err := uc.newBackend(chain)
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("failed to connect to %s: %w", chain.Name, err)
+	}
+	txHash, err := backend.Broadcast(ctx, signed.Payload)
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+
+	txn.TxHash = txHash
+	txn.Fee = strconv.FormatInt(fee, 10)
+	txn.RawTx = hex.EncodeToString(signed.Payload)
+	return txn, nil
+}
+
+// TrackTransaction adds the change output of the broadcast transaction to the UTXO set as
+// unconfirmed, so it can fund the next transaction before this one is mined. The block scanner
+// confirms it along with the transaction.
+func (uc *bitcoinUseCase) TrackTransaction(ctx context.Context, txn domain.Transaction) error {
+	_, network, err := uc.getChain(ctx, txn.ChainID)
+	if err != nil {
+		return err
+	}
+
+	rawTx, err := hex.DecodeString(txn.RawTx)
+	if err != nil {
+		return fmt.Errorf("invalid signed transaction: %w", err)
+	}
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+		return fmt.Errorf("invalid signed transaction: %w", err)
+	}
+
+	addresses, err := uc.utxoRepo.ListBitcoinAddressesByWalletID(ctx, txn.WalletID, txn.ChainID)
+	if err != nil {
+		return fmt.Errorf("failed to list addresses: %w", err)
+	}
+	changeAddresses, err := scriptAddresses(addresses, network, true)
+	if err != nil {
+		return fmt.Errorf("failed to decode change addresses: %w", err)
+	}
+
+	for vout, output := range tx.TxOut {
+		address, ok := changeAddresses[string(output.PkScript)]
+		if !ok {
+			continue
+		}
+		if err := uc.utxoRepo.UpsertUTXO(ctx, domain.UTXO{
+			WalletID: txn.WalletID,
+			ChainID:  txn.ChainID,
+			TxID:     txn.TxHash,
+			Vout:     uint32(vout),
+			Amount:   output.Value,
+			Address:  address,
+			PkScript: output.PkScript,
+		}); err != nil {
+			return fmt.Errorf("failed to record change: %w", err)
+		}
+	}
+	return nil
+}
+
+func decodePSBT(payload []byte) (*psbt.Packet, error) {
+	packet, err := psbt.NewFromRawBytes(bytes.NewReader(payload), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode PSBT: %w", err)
+	}
+	return packet, nil
+}
+
+// getChain loads a chain and its network parameters, refusing chains of other families.
+func (uc *bitcoinUseCase) getChain(ctx context.Context, chainID uuid.UUID) (domain.Chain, *chaincfg.Params, error) {
+	chain, err := uc.chainRepo.GetChain(ctx, chainID)
+	if err != nil {
+		return domain.Chain{}, nil, fmt.Errorf("failed to get chain: %w", err)
+	}
+	if chain.Family != domain.ChainFamilyBitcoin {
+		return domain.Chain{}, nil, fmt.Errorf("%w: %s is not a Bitcoin chain", ErrUnsupportedChain, chain.Name)
+	}
+
+	params, err := bitcoin.NetworkParams(chain.ChainID)
+	if err != nil {
+		return domain.Chain{}, nil, err
+	}
+	return chain, params, nil
+}
+
+func (uc *bitcoinUseCase) walletKey(ctx context.Context, userID uuid.UUID) (*btcec.PrivateKey, error) {
+	privateKey, err := uc.walletUC.GetPrivateKey(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get private key: %w", err)
+	}
+	return bitcoin.PrivateKey(privateKey), nil
+}
+
+// registerAddresses records the receive addresses of the wallet key so the block scanner tracks them.
+func (uc *bitcoinUseCase) registerAddresses(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID, key *btcec.PrivateKey, params *chaincfg.Params) (domain.BitcoinAddresses, error) {
+	p2wpkh, p2tr, err := bitcoin.Addresses(key.PubKey(), params)
+	if err != nil {
+		return domain.BitcoinAddresses{}, fmt.Errorf("failed to derive addresses: %w", err)
+	}
+
+	addresses := domain.BitcoinAddresses{WalletID: walletID, ChainID: chainID, P2WPKH: p2wpkh.EncodeAddress(), P2TR: p2tr.EncodeAddress()}
+	for _, address := range []string{addresses.P2WPKH, addresses.P2TR} {
+		if err := uc.utxoRepo.CreateBitcoinAddress(ctx, domain.BitcoinAddress{WalletID: walletID, ChainID: chainID, Address: address}); err != nil {
+			return domain.BitcoinAddresses{}, fmt.Errorf("failed to save address: %w", err)
+		}
+	}
+	return addresses, nil
+}
+
+// changeAddress returns an unused change address, deriving and recording the next one when every
+// change address has received funds. Change goes to P2WPKH unless taproot is set.
+func (uc *bitcoinUseCase) changeAddress(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID, key *btcec.PrivateKey, params *chaincfg.Params, taproot bool) (btcutil.Address, error) {
+	unused, err := uc.utxoRepo.GetUnusedChangeAddress(ctx, walletID, chainID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get change address: %w", err)
+	}
+	if err == nil {
+		change, err := deriveChangeAddress(key, params, unused.DerivationIndex, taproot)
+		if err != nil {
+			return nil, err
+		}
+		if change.EncodeAddress() == unused.Address {
+			return change, nil
+		}
+	}
+
+	// The unused address is of the other type, or there is none
+	index, err := uc.utxoRepo.GetNextChangeIndex(ctx, walletID, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get next change index: %w", err)
+	}
+	change, err := deriveChangeAddress(key, params, index, taproot)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.utxoRepo.CreateBitcoinAddress(ctx, domain.BitcoinAddress{
+		WalletID:        walletID,
+		ChainID:         chainID,
+		Address:         change.EncodeAddress(),
+		Change:          true,
+		DerivationIndex: index,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to save change address: %w", err)
+	}
+	return change, nil
+}
+
+func deriveChangeAddress(key *btcec.PrivateKey, params *chaincfg.Params, index uint32, taproot bool) (btcutil.Address, error) {
+	changeKey, err := bitcoin.ChangeKey(key, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive change key: %w", err)
+	}
+	p2wpkh, p2tr, err := bitcoin.Addresses(changeKey.PubKey(), params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive change address: %w", err)
+	}
+	if taproot {
+		return p2tr, nil
+	}
+	return p2wpkh, nil
+}
+
+// spendableUTXOs returns the wallet's unreserved outputs that are confirmed or are change of its own
+// transactions, which cannot be double spent by anyone else.
+func (uc *bitcoinUseCase) spendableUTXOs(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID) ([]domain.UTXO, error) {
+	utxos, err := uc.utxoRepo.ListUnspentUTXOsByWalletID(ctx, walletID, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list UTXOs: %w", err)
+	}
+	addresses, err := uc.utxoRepo.ListBitcoinAddressesByWalletID(ctx, walletID, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list addresses: %w", err)
+	}
+
+	change := make(map[string]bool, len(addresses))
+	for _, address := range addresses {
+		change[address.Address] = address.Change
+	}
+
+	spendable := make([]domain.UTXO, 0, len(utxos))
+	for _, utxo := range utxos {
+		if utxo.ReservedBy == uuid.Nil && (utxo.BlockNumber != 0 || change[utxo.Address]) {
+			spendable = append(spendable, utxo)
+		}
+	}
+	return spendable, nil
+}
+
+// keyRing collects the public keys of the wallet key and of the wallet's change addresses.
+func keyRing(key *btcec.PrivateKey, addresses []domain.BitcoinAddress, params *chaincfg.Params) (bitcoin.KeyRing, error) {
+	keys := bitcoin.KeyRing{}
+	if err := keys.Add(key.PubKey(), nil, params); err != nil {
+		return nil, err
+	}
+
+	derived := make(map[uint32]bool)
+	for _, address := range addresses {
+		if !address.Change || derived[address.DerivationIndex] {
+			continue
+		}
+		changeKey, err := bitcoin.ChangeKey(key, address.DerivationIndex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive change key %d: %w", address.DerivationIndex, err)
+		}
+		if err := keys.Add(changeKey.PubKey(), bitcoin.ChangePath(address.DerivationIndex), params); err != nil {
+			return nil, err
+		}
+		derived[address.DerivationIndex] = true
+	}
+	return keys, nil
+}
+
+// scriptAddresses maps the output scripts of the addresses to their encoded form, keeping only
+// change addresses when change is set.
+func scriptAddresses(addresses []domain.BitcoinAddress, params *chaincfg.Params, change bool) (map[string]string, error) {
+	scripts := make(map[string]string, len(addresses))
+	for _, address := range addresses {
+		if change && !address.Change {
+			continue
+		}
+		decoded, err := btcutil.DecodeAddress(address.Address, params)
+		if err != nil {
+			return scripts, err
+		}
+		script, err := txscript.PayToAddrScript(decoded)
+		if err != nil {
+			return scripts, err
+		}
+		scripts[string(script)] = address.Address
+	}
+	return scripts, nil
+}
+
+// estimateFeeRate asks the node for the fee rate of the priority, medium by default.
+func estimateFeeRate(ctx context.Context, backend repository.BitcoinBackend, priority domain.FeePriority) (int64, error) {
+	target, ok := feeTargets[priority]
+	if !ok && priority != "" {
+		return 0, fmt.Errorf("unknown fee priority %q", priority)
+	}
+	if !ok {
+		target = feeTargets[domain.FeePriorityMedium]
+	}
+
+	rate, err := backend.EstimateFeeRate(ctx, target)
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate fee rate: %w", err)
+	}
+	if rate < minFeeRate {
+		rate = minFeeRate
+	}
+	return rate, nil
+}
+
+// toSatoshis converts an amount in BTC to satoshis.
+func toSatoshis(amount string) (int64, error) {
+	btc, err := strconv.ParseFloat(amount, 64)
+	if err != nil || btc <= 0 {
+		return 0, fmt.Errorf("invalid amount: %s", amount)
+	}
+	satoshis, err := btcutil.NewAmount(btc)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount: %s", amount)
+	}
+	return int64(satoshis), nil
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"mpc/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// ChainFamily builds, signs and broadcasts the transactions of one family of chains. A transaction
+// moves between the steps as an opaque payload, so txnUseCase never sees family-specific types:
+// the family builds it, names the digests the wallet key must sign, assembles the signatures into
+// the signed transaction, broadcasts it and sets up whatever follows it until it is final.
+type ChainFamily interface {
+	// Family is the chain family implemented.
+	Family() domain.ChainFamily
+	// BuildTransaction creates the unsigned transaction of a transfer from the wallet.
+	BuildTransaction(ctx context.Context, userID uuid.UUID, chain domain.Chain, wallet domain.Wallet, params domain.CreateTxnRequest) (domain.UnsignedTransaction, error)
+	// ReserveFunds holds back what the stored transaction spends, so no other transaction spends it too.
+	ReserveFunds(ctx context.Context, txn domain.Transaction, unsigned domain.UnsignedTransaction) error
+	// ReleaseFunds gives back what was held for a transaction that will not reach the chain.
+	ReleaseFunds(ctx context.Context, txn domain.Transaction)
+	// SigningPayloads returns the digests the wallet must sign to authorize the transaction.
+	SigningPayloads(ctx context.Context, userID uuid.UUID, txn domain.Transaction, unsigned domain.UnsignedTransaction) ([]domain.SigningPayload, error)
+	// AssembleTransaction adds one signature per signing payload, in the same order.
+	AssembleTransaction(ctx context.Context, unsigned domain.UnsignedTransaction, signatures [][]byte) (domain.SignedTransaction, error)
+	// Broadcast submits the signed transaction and returns the transaction with its hash and
+	// whatever else the family records about it.
+	Broadcast(ctx context.Context, userID uuid.UUID, txn domain.Transaction, signed domain.SignedTransaction) (domain.Transaction, error)
+	// TrackTransaction prepares for following the broadcast transaction until its block is final.
+	TrackTransaction(ctx context.Context, txn domain.Transaction) error
+}
+
+// BatchChainFamily is a chain family that can pay many recipients at once.
+type BatchChainFamily interface {
+	ChainFamily
+	CreateBatchTransaction(ctx context.Context, userID uuid.UUID, wallet domain.Wallet, params domain.CreateBatchTxnRequest) (domain.BatchTxnResponse, error)
+}
+
+// ChainFamilies holds the implementation of every supported chain family.
+type ChainFamilies struct {
+	families map[domain.ChainFamily]ChainFamily
+}
+
+// NewChainFamilies registers each family under the family it implements.
+func NewChainFamilies(families ...ChainFamily) *ChainFamilies {
+	registry := &ChainFamilies{families: make(map[domain.ChainFamily]ChainFamily, len(families))}
+	for _, family := range families {
+		registry.Register(family)
+	}
+	return registry
+}
+
+// Register adds a family, replacing an earlier implementation of the same family.
+func (r *ChainFamilies) Register(family ChainFamily) {
+	r.families[family.Family()] = family
+}
+
+// Get returns the implementation of a family.
+func (r *ChainFamilies) Get(family domain.ChainFamily) (ChainFamily, error) {
+	implementation, ok := r.families[family]
+	if !ok {
+		return nil, fmt.Errorf("%w: no implementation of the %s chain family", ErrUnsupportedChain, family)
+	}
+	return implementation, nil
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"mpc/internal/domain"
+	"mpc/internal/repository"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// stubFamily implements a chain family without doing anything on chain.
+type stubFamily struct {
+	ChainFamily
+	family domain.ChainFamily
+}
+
+func (f *stubFamily) Family() domain.ChainFamily {
+	return f.family
+}
+
+// stubBatchFamily is a chain family that counts the batches it was given.
+type stubBatchFamily struct {
+	stubFamily
+	batches int
+}
+
+func (f *stubBatchFamily) CreateBatchTransaction(ctx context.Context, userID uuid.UUID, wallet domain.Wallet, params domain.CreateBatchTxnRequest) (domain.BatchTxnResponse, error) {
+	f.batches++
+	return domain.BatchTxnResponse{}, nil
+}
+
+// stubFamilyChains holds chains by ID.
+type stubFamilyChains struct {
+	repository.ChainRepository
+	chains map[uuid.UUID]domain.Chain
+}
+
+func (r *stubFamilyChains) GetChain(ctx context.Context, id uuid.UUID) (domain.Chain, error) {
+	return r.chains[id], nil
+}
+
+func TestChainFamilies(t *testing.T) {
+	evm := &stubBatchFamily{stubFamily: stubFamily{family: domain.ChainFamilyEVM}}
+	bitcoin := &stubFamily{family: domain.ChainFamilyBitcoin}
+	chains := map[uuid.UUID]domain.Chain{}
+	for _, family := range []domain.ChainFamily{domain.ChainFamilyEVM, domain.ChainFamilyBitcoin, domain.ChainFamilySolana} {
+		id := uuid.New()
+		chains[id] = domain.Chain{ID: id, Name: string(family), Family: family}
+	}
+	chainOf := func(family domain.ChainFamily) uuid.UUID {
+		for id, chain := range chains {
+			if chain.Family == family {
+				return id
+			}
+		}
+		return uuid.Nil
+	}
+
+	// A family registered later replaces the one it was built with
+	families := NewChainFamilies(&stubFamily{family: domain.ChainFamilyEVM}, bitcoin)
+	families.Register(evm)
+	uc := &txnUseCase{chainRepo: &stubFamilyChains{chains: chains}, families: families}
+
+	tests := []struct {
+		name      string
+		chain     domain.ChainFamily
+		requested domain.ChainFamily
+		want      ChainFamily
+		wantErr   error
+	}{
+		{name: "EVM chain", chain: domain.ChainFamilyEVM, want: evm},
+		{name: "Bitcoin chain", chain: domain.ChainFamilyBitcoin, want: bitcoin},
+		{name: "requested family of the chain", chain: domain.ChainFamilyBitcoin, requested: domain.ChainFamilyBitcoin, want: bitcoin},
+		{name: "requested family of another chain", chain: domain.ChainFamilyBitcoin, requested: domain.ChainFamilyEVM, wantErr: ErrUnsupportedChain},
+		{name: "family without an implementation", chain: domain.ChainFamilySolana, wantErr: ErrUnsupportedChain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain, family, err := uc.chainFamily(context.Background(), chainOf(tt.chain), tt.requested)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("chainFamily() error = %v, want %v", err, tt.wantErr)
+			}
+			if family != tt.want {
+				t.Errorf("chainFamily() = %v, want %v", family, tt.want)
+			}
+			if tt.wantErr == nil && chain.Family != tt.chain {
+				t.Errorf("chainFamily() chain is a %s chain, want %s", chain.Family, tt.chain)
+			}
+		})
+	}
+}
+
+func TestCreateBatchTransactionFamily(t *testing.T) {
+	tests := []struct {
+		name        string
+		family      domain.ChainFamily
+		wantErr     error
+		wantBatches int
+	}{
+		{name: "family with batch transfers", family: domain.ChainFamilyEVM, wantBatches: 1},
+		{name: "family without batch transfers", family: domain.ChainFamilyBitcoin, wantErr: ErrUnsupportedChain},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wallet := domain.Wallet{ID: uuid.New(), UserID: uuid.New()}
+			chain := domain.Chain{ID: uuid.New(), Name: "Test", Family: tt.family}
+			evm := &stubBatchFamily{stubFamily: stubFamily{family: domain.ChainFamilyEVM}}
+			uc := &txnUseCase{
+				chainRepo: &stubFamilyChains{chains: map[uuid.UUID]domain.Chain{chain.ID: chain}},
+				walletUC:  &stubSafeWallets{wallets: []domain.Wallet{wallet}},
+				families:  NewChainFamilies(evm, &stubFamily{family: domain.ChainFamilyBitcoin}),
+			}
+
+			_, err := uc.CreateBatchTransaction(context.Background(), wallet.UserID, domain.CreateBatchTxnRequest{WalletID: wallet.ID, ChainID: chain.ID})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateBatchTransaction() error = %v, want %v", err, tt.wantErr)
+			}
+			if evm.batches != tt.wantBatches {
+				t.Errorf("EVM family got %d batches, want %d", evm.batches, tt.wantBatches)
+			}
+		})
+	}
+}
@@ -0,0 +1,273 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/infrastructure/ens"
+	"mpc/internal/repository"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+var ErrInvalidAddress = errors.New("invalid recipient address")
+
+// evmFamily is the ChainFamily of Ethereum and other EVM chains. Payloads are RLP encoded
+// transactions; funds are reserved against the wallet's balance, and wallets that cannot pay for gas
+// are topped up by the relayer right before their transaction is broadcast.
+type evmFamily struct {
+	txnRepo      repository.TransactionRepository
+	ethRepo      repository.EthereumRepository
+	walletUC     WalletUseCase
+	relayerUC    RelayerUseCase
+	disperseRepo repository.DisperseRepository
+	ensRepo      repository.ENSRepository
+	balanceRepo  repository.BalanceRepository
+	batchCfg     *config.BatchConfig
+	balanceCfg   *config.BalanceConfig
+	// txnTopic is the Kafka topic submitted batch transfers are announced on, through the outbox.
+	txnTopic string
+	// eventsTopic is the public Kafka topic every state change of a transfer is announced on.
+	eventsTopic string
+	// senders holds a mutex per sending address, taken while a batch picks and uses its nonces.
+	senders sync.Map
+}
+
+func NewEVMFamily(txnRepo repository.TransactionRepository, ethRepo repository.EthereumRepository, walletUC WalletUseCase, relayerUC RelayerUseCase, disperseRepo repository.DisperseRepository, ensRepo repository.ENSRepository, balanceRepo repository.BalanceRepository, batchCfg *config.BatchConfig, balanceCfg *config.BalanceConfig, txnTopic string, eventsTopic string) BatchChainFamily {
+	return &evmFamily{txnRepo: txnRepo, ethRepo: ethRepo, walletUC: walletUC, relayerUC: relayerUC, disperseRepo: disperseRepo, ensRepo: ensRepo, balanceRepo: balanceRepo, batchCfg: batchCfg, balanceCfg: balanceCfg, txnTopic: txnTopic, eventsTopic: eventsTopic}
+}
+
+var _ BatchChainFamily = (*evmFamily)(nil)
+
+func (f *evmFamily) Family() domain.ChainFamily {
+	return domain.ChainFamilyEVM
+}
+
+func (f *evmFamily) BuildTransaction(ctx context.Context, userID uuid.UUID, chain domain.Chain, wallet domain.Wallet, params domain.CreateTxnRequest) (domain.UnsignedTransaction, error) {
+	toAddress, ensName, err := resolveRecipient(ctx, f.ensRepo, params.ToAddress)
+	if err != nil {
+		return domain.UnsignedTransaction{}, err
+	}
+
+	amountInWei, err := toWei(params.Amount)
+	if err != nil {
+		return domain.UnsignedTransaction{}, err
+	}
+
+	unsignedTx, err := f.ethRepo.CreateUnsignedTransaction(ctx, common.HexToAddress(wallet.Address), toAddress, amountInWei)
+	if err != nil {
+		return domain.UnsignedTransaction{}, fmt.Errorf("failed to create unsigned transaction: %w", err)
+	}
+
+	// Print useful information about the unsigned transaction
+	fmt.Printf("CreateTransaction: unsignedTx details:\n")
+	fmt.Printf("  To: %s\n", unsignedTx.To().Hex())
+	fmt.Printf("  Value: %s\n", unsignedTx.Value().String())
+	fmt.Printf("  Gas: %d\n", unsignedTx.Gas())
+	fmt.Printf("  GasPrice: %s\n", unsignedTx.GasPrice().String())
+	fmt.Printf("  Nonce: %d\n", unsignedTx.Nonce())
+
+	payload, err := unsignedTx.MarshalBinary()
+	if err != nil {
+		return domain.UnsignedTransaction{}, fmt.Errorf("failed to serialize unsigned transaction: %w", err)
+	}
+
+	return domain.UnsignedTransaction{Payload: payload, ToAddress: toAddress.Hex(), ToENSName: ensName}, nil
+}
+
+// ReserveFunds holds back the amount and maximum fee of the transaction.
+func (f *evmFamily) ReserveFunds(ctx context.Context, txn domain.Transaction, unsigned domain.UnsignedTransaction) error {
+	unsignedTx, err := decodeTransaction(unsigned.Payload)
+	if err != nil {
+		return err
+	}
+
+	wallet, err := f.walletUC.GetWallet(ctx, txn.WalletID)
+	if err != nil {
+		return fmt.Errorf("failed to get wallet: %w", err)
+	}
+	return f.reserveBalance(ctx, txn.WalletID, txn.ChainID, common.HexToAddress(wallet.Address), txn.ID, f.reservationAmount(unsignedTx))
+}
+
+func (f *evmFamily) ReleaseFunds(ctx context.Context, txn domain.Transaction) {
+	f.releaseReservation(ctx, txn.ID)
+}
+
+// SigningPayloads returns the EIP-155 signing hash of the transaction.
+func (f *evmFamily) SigningPayloads(ctx context.Context, userID uuid.UUID, txn domain.Transaction, unsigned domain.UnsignedTransaction) ([]domain.SigningPayload, error) {
+	unsignedTx, err := decodeTransaction(unsigned.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := f.ethRepo.SigningHash(ctx, unsignedTx)
+	if err != nil {
+		return nil, err
+	}
+	return []domain.SigningPayload{{Digest: hash.Bytes(), Scheme: domain.SignatureSchemeECDSA}}, nil
+}
+
+func (f *evmFamily) AssembleTransaction(ctx context.Context, unsigned domain.UnsignedTransaction, signatures [][]byte) (domain.SignedTransaction, error) {
+	if len(signatures) != 1 {
+		return domain.SignedTransaction{}, fmt.Errorf("expected one signature, got %d", len(signatures))
+	}
+
+	unsignedTx, err := decodeTransaction(unsigned.Payload)
+	if err != nil {
+		return domain.SignedTransaction{}, err
+	}
+
+	signedTx, err := f.ethRepo.AddSignature(ctx, unsignedTx, signatures[0])
+	if err != nil {
+		return domain.SignedTransaction{}, err
+	}
+
+	payload, err := signedTx.MarshalBinary()
+	if err != nil {
+		return domain.SignedTransaction{}, fmt.Errorf("failed to serialize signed transaction: %w", err)
+	}
+	return domain.SignedTransaction{Payload: payload, TxHash: signedTx.Hash().Hex()}, nil
+}
+
+// Broadcast tops the wallet up from the relayer pool if it cannot pay for gas and submits the
+// transaction, recording its nonce, gas and raw form for rebroadcasts. A transaction that needed a
+// top-up is not submitted here: the block scanner submits it from its raw form once the funding
+// transaction is mined.
+func (f *evmFamily) Broadcast(ctx context.Context, userID uuid.UUID, txn domain.Transaction, signed domain.SignedTransaction) (domain.Transaction, error) {
+	signedTx, err := decodeTransaction(signed.Payload)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+
+	wallet, err := f.walletUC.GetWallet(ctx, txn.WalletID)
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	sponsorship, err := f.relayerUC.EnsureGas(ctx, userID, txn, common.HexToAddress(wallet.Address), signedTx)
+	if err != nil {
+		return domain.Transaction{}, fmt.Errorf("failed to sponsor gas: %w", err)
+	}
+
+	txHash := signedTx.Hash()
+	if sponsorship == nil {
+		if txHash, err = f.ethRepo.SubmitTransaction(ctx, signedTx); err != nil {
+			return domain.Transaction{}, err
+		}
+	}
+
+	txn.TxHash = txHash.Hex()
+	// Record the nonce and gas of the signed transaction; the draft row does not have them
+	txn.Nonce = int64(signedTx.Nonce())
+	txn.GasPrice = signedTx.GasPrice().String()
+	txn.GasLimit = fmt.Sprintf("%d", signedTx.Gas())
+	txn.RawTx = hexutil.Encode(signed.Payload)
+	return txn, nil
+}
+
+// TrackTransaction has nothing to prepare: the receipt worker and the block scanner find EVM
+// transactions by their hash.
+func (f *evmFamily) TrackTransaction(ctx context.Context, txn domain.Transaction) error {
+	return nil
+}
+
+func decodeTransaction(payload []byte) (*types.Transaction, error) {
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(payload); err != nil {
+		return nil, fmt.Errorf("failed to deserialize transaction: %w", err)
+	}
+	return &tx, nil
+}
+
+// encodeRawTx returns the hex encoded signed transaction, as accepted by eth_sendRawTransaction.
+func encodeRawTx(signedTx *types.Transaction) (string, error) {
+	raw, err := signedTx.MarshalBinary()
+	if err != nil {
+		return "", err
+	}
+	return hexutil.Encode(raw), nil
+}
+
+// toWei converts an amount in ether to wei, truncating any fractional wei.
+func toWei(amount string) (*big.Int, error) {
+	// Convert amount string to big.Float first, then to big.Int
+	amountFloat, _, err := new(big.Float).Parse(amount, 10)
+	if err != nil {
+		return nil, fmt.Errorf("invalid amount: %s", amount)
+	}
+
+	// Multiply by 1e18 to convert to Wei
+	weiFloat := new(big.Float).Mul(amountFloat, big.NewFloat(1e18))
+
+	// Convert to big.Int, truncating any fractional part
+	amountInWei, _ := weiFloat.Int(nil)
+	return amountInWei, nil
+}
+
+// fromWei renders an amount in wei as ether, the unit amounts are stored in.
+func fromWei(amount *big.Int) string {
+	ether := new(big.Rat).SetFrac(amount, big.NewInt(1e18)).FloatString(18)
+	ether = strings.TrimRight(ether, "0")
+	return strings.TrimSuffix(ether, ".")
+}
+
+// resolveRecipient turns a recipient into an address. Hex addresses must pass the EIP-55 checksum
+// when they use mixed case; anything else containing a dot is resolved as an ENS name.
+// It returns the ENS name that was resolved, if any.
+func resolveRecipient(ctx context.Context, ensRepo repository.ENSRepository, recipient string) (common.Address, string, error) {
+	recipient = strings.TrimSpace(recipient)
+
+	if strings.HasPrefix(recipient, "0x") || strings.HasPrefix(recipient, "0X") {
+		address, err := validateHexAddress(recipient)
+		return address, "", err
+	}
+
+	if !isENSName(recipient) {
+		return common.Address{}, "", fmt.Errorf("%w: %q", ErrInvalidAddress, recipient)
+	}
+
+	name := strings.ToLower(recipient)
+	address, err := ensRepo.ResolveName(ctx, name)
+	if err != nil {
+		if errors.Is(err, ens.ErrNameNotFound) {
+			return common.Address{}, "", fmt.Errorf("%w: %v", ErrInvalidAddress, err)
+		}
+		return common.Address{}, "", fmt.Errorf("failed to resolve ENS name %s: %w", name, err)
+	}
+	return address, name, nil
+}
+
+// validateHexAddress parses a hex address, enforcing the EIP-55 checksum unless the address is all one case.
+func validateHexAddress(input string) (common.Address, error) {
+	if !common.IsHexAddress(input) {
+		return common.Address{}, fmt.Errorf("%w: %q", ErrInvalidAddress, input)
+	}
+
+	address := common.HexToAddress(input)
+	digits := input[2:]
+	if digits != strings.ToLower(digits) && digits != strings.ToUpper(digits) && address.Hex()[2:] != digits {
+		return common.Address{}, fmt.Errorf("%w: bad EIP-55 checksum for %q", ErrInvalidAddress, input)
+	}
+	return address, nil
+}
+
+func isENSName(name string) bool {
+	labels := strings.Split(name, ".")
+	if len(labels) < 2 {
+		return false
+	}
+	for _, label := range labels {
+		if label == "" || strings.ContainsAny(label, " \t\n/:@") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/ens"
+	"mpc/internal/repository"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+// stubENS resolves the names it holds; any other name is not found, except down.eth whose lookup fails.
+type stubENS map[string]common.Address
+
+func (s stubENS) ResolveName(ctx context.Context, name string) (common.Address, error) {
+	if name == "down.eth" {
+		return common.Address{}, errors.New("connection refused")
+	}
+	address, ok := s[name]
+	if !ok {
+		return common.Address{}, fmt.Errorf("%w: %s has no resolver", ens.ErrNameNotFound, name)
+	}
+	return address, nil
+}
+
+// eip55Address is the first example of EIP-55.
+const eip55Address = "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed"
+
+func TestValidateHexAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "valid checksum", input: eip55Address},
+		{name: "all lowercase", input: "0x5aaeb6053f3e94c9b9a09f33669435e7ef1beaed"},
+		{name: "all uppercase", input: "0x5AAEB6053F3E94C9B9A09F33669435E7EF1BEAED"},
+		{name: "bad checksum", input: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", wantErr: true},
+		{name: "too short", input: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", wantErr: true},
+		{name: "not hex", input: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAzz", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateHexAddress(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAddress) {
+					t.Fatalf("validateHexAddress(%q) error = %v, want ErrInvalidAddress", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("validateHexAddress(%q) error = %v", tt.input, err)
+			}
+			if got.Hex() != eip55Address {
+				t.Errorf("validateHexAddress(%q) = %s, want %s", tt.input, got.Hex(), eip55Address)
+			}
+		})
+	}
+}
+
+func TestIsENSName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "vitalik.eth", want: true},
+		{name: "pay.alice.eth", want: true},
+		{name: "eth", want: false},
+		{name: "alice..eth", want: false},
+		{name: "alice.eth.", want: false},
+		{name: "al ice.eth", want: false},
+		{name: "user@mail.com", want: false},
+		{name: "https://alice.eth", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isENSName(tt.name); got != tt.want {
+				t.Errorf("isENSName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRecipient(t *testing.T) {
+	alice := common.HexToAddress("0x00000000000000000000000000000000000A11CE")
+	ensRepo := stubENS{"alice.eth": alice}
+
+	tests := []struct {
+		name      string
+		recipient string
+		want      common.Address
+		wantName  string
+		wantErr   bool
+		// wantInvalid means the error is ErrInvalidAddress, which the handler reports as a bad request
+		wantInvalid bool
+	}{
+		{name: "hex address", recipient: eip55Address, want: common.HexToAddress(eip55Address)},
+		{name: "bad checksum", recipient: "0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", wantErr: true, wantInvalid: true},
+		{name: "ENS name", recipient: "alice.eth", want: alice, wantName: "alice.eth"},
+		{name: "ENS name is normalized", recipient: " Alice.ETH ", want: alice, wantName: "alice.eth"},
+		{name: "unresolvable name", recipient: "bob.eth", wantErr: true, wantInvalid: true},
+		{name: "neither address nor name", recipient: "alice", wantErr: true, wantInvalid: true},
+		{name: "resolver unavailable", recipient: "down.eth", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotName, err := resolveRecipient(context.Background(), ensRepo, tt.recipient)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("resolveRecipient(%q) succeeded, want an error", tt.recipient)
+				}
+				if errors.Is(err, ErrInvalidAddress) != tt.wantInvalid {
+					t.Fatalf("resolveRecipient(%q) error = %v, want ErrInvalidAddress = %v", tt.recipient, err, tt.wantInvalid)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolveRecipient(%q) error = %v", tt.recipient, err)
+			}
+			if got != tt.want || gotName != tt.wantName {
+				t.Errorf("resolveRecipient(%q) = %s, %q, want %s, %q", tt.recipient, got.Hex(), gotName, tt.want.Hex(), tt.wantName)
+			}
+		})
+	}
+}
+
+// stubSubmitEth records the transactions it is asked to submit.
+type stubSubmitEth struct {
+	repository.EthereumRepository
+	submitted []*types.Transaction
+}
+
+func (s *stubSubmitEth) SubmitTransaction(ctx context.Context, signedTx *types.Transaction) (common.Hash, error) {
+	s.submitted = append(s.submitted, signedTx)
+	return signedTx.Hash(), nil
+}
+
+// stubSponsor tops up every wallet with sponsorship, when set.
+type stubSponsor struct {
+	RelayerUseCase
+	sponsorship *domain.GasSponsorship
+}
+
+func (s *stubSponsor) EnsureGas(ctx context.Context, userID uuid.UUID, txn domain.Transaction, from common.Address, tx *types.Transaction) (*domain.GasSponsorship, error) {
+	return s.sponsorship, nil
+}
+
+func TestBroadcastSponsored(t *testing.T) {
+	to := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+	signedTx := types.NewTx(&types.LegacyTx{Nonce: 7, To: &to, Value: big.NewInt(1), Gas: 21_000, GasPrice: big.NewInt(10)})
+	payload, err := signedTx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		sponsorship *domain.GasSponsorship
+		// wantSubmitted tells whether the transaction goes to the node right away
+		wantSubmitted bool
+	}{
+		{
+			name:          "wallet pays its own gas",
+			wantSubmitted: true,
+		},
+		{
+			// The worker submits it once the top-up is mined
+			name:        "held back for its top-up",
+			sponsorship: &domain.GasSponsorship{ID: uuid.New(), Status: domain.SponsorshipStatusPending},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wallet := domain.Wallet{ID: uuid.New(), Address: "0x5FbDB2315678afecb367f032d93F642f64180aa3"}
+			ethRepo := &stubSubmitEth{}
+			f := &evmFamily{ethRepo: ethRepo, walletUC: &stubSafeWallets{wallets: []domain.Wallet{wallet}}, relayerUC: &stubSponsor{sponsorship: tt.sponsorship}}
+
+			txn, err := f.Broadcast(context.Background(), uuid.New(), domain.Transaction{ID: uuid.New(), WalletID: wallet.ID}, domain.SignedTransaction{Payload: payload})
+			if err != nil {
+				t.Fatalf("Broadcast() error = %v", err)
+			}
+			if submitted := len(ethRepo.submitted) == 1; submitted != tt.wantSubmitted {
+				t.Errorf("Submitted = %v, want %v", submitted, tt.wantSubmitted)
+			}
+			if txn.TxHash != signedTx.Hash().Hex() || txn.RawTx != hexutil.Encode(payload) || txn.Nonce != 7 {
+				t.Errorf("Broadcast() = hash %s, nonce %d, raw %s; want the signed transaction recorded for the worker", txn.TxHash, txn.Nonce, txn.RawTx)
+			}
+		})
+	}
+}
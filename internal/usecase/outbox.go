@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/infrastructure/events"
+	"mpc/internal/infrastructure/kafka"
+	"mpc/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxRelayUseCase publishes the messages of the outbox to Kafka.
+type OutboxRelayUseCase interface {
+	// Run relays messages every poll interval until the context is cancelled.
+	Run(ctx context.Context)
+	// RelayPending publishes the messages that are due and returns how many were sent.
+	RelayPending(ctx context.Context) (int, error)
+}
+
+type outboxRelayUseCase struct {
+	outboxRepo repository.OutboxRepository
+	cfg        *config.OutboxConfig
+	publishers map[string]kafka.Publisher
+}
+
+// NewOutboxRelayUC returns a relay publishing each message with the publisher of its topic.
+func NewOutboxRelayUC(outboxRepo repository.OutboxRepository, cfg *config.OutboxConfig, publishers ...kafka.Publisher) OutboxRelayUseCase {
+	byTopic := make(map[string]kafka.Publisher, len(publishers))
+	for _, publisher := range publishers {
+		byTopic[publisher.Topic()] = publisher
+	}
+	return &outboxRelayUseCase{outboxRepo: outboxRepo, cfg: cfg, publishers: byTopic}
+}
+
+var _ OutboxRelayUseCase = (*outboxRelayUseCase)(nil)
+
+func (uc *outboxRelayUseCase) Run(ctx context.Context) {
+	ticker := time.NewTicker(uc.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep going while messages are sent, so a backlog drains without waiting for the ticker. A
+		// batch holds one message per key, so the later messages of a key go out in the next rounds.
+		for {
+			sent, err := uc.RelayPending(ctx)
+			if err != nil {
+				log.Printf("Failed to relay outbox messages: %v", err)
+			}
+			if err != nil || sent == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayPending claims the messages that are due and publishes them in one batch per topic, in the
+// order they were written. No database transaction is open while Kafka is written to; messages the
+// relay does not get to mark are claimed again once their lease runs out, so a message can be
+// published twice but never lost.
+func (uc *outboxRelayUseCase) RelayPending(ctx context.Context) (int, error) {
+	messages, err := uc.outboxRepo.ClaimPendingMessages(ctx, uc.cfg.BatchSize, uc.cfg.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	var topics []string
+	batches := make(map[string][]domain.OutboxMessage)
+	for _, message := range messages {
+		if _, ok := batches[message.Topic]; !ok {
+			topics = append(topics, message.Topic)
+		}
+		batches[message.Topic] = append(batches[message.Topic], message)
+	}
+
+	var sent []uuid.UUID
+	var failed []domain.OutboxMessage
+	var publishErrs []error
+	for _, topic := range topics {
+		batch := batches[topic]
+		for i, err := range uc.publish(ctx, topic, batch) {
+			if err != nil {
+				failed = append(failed, batch[i])
+				publishErrs = append(publishErrs, err)
+				continue
+			}
+			sent = append(sent, batch[i].ID)
+		}
+	}
+
+	if err := uc.outboxRepo.MarkMessagesSent(ctx, sent); err != nil {
+		return 0, err
+	}
+	for i, message := range failed {
+		log.Printf("Failed to relay outbox message %s to %s: %v", message.ID, message.Topic, publishErrs[i])
+		if err := uc.outboxRepo.MarkMessageFailed(ctx, message.ID, publishErrs[i].Error(), time.Now().Add(uc.backoff(message.Attempts+1))); err != nil {
+			return len(sent), err
+		}
+	}
+	return len(sent), nil
+}
+
+// publish writes the messages of one topic in a single batch and returns the error of each message.
+func (uc *outboxRelayUseCase) publish(ctx context.Context, topic string, messages []domain.OutboxMessage) []error {
+	errs := make([]error, len(messages))
+	fail := func(err error) []error {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	publisher, ok := uc.publishers[topic]
+	if !ok {
+		return fail(fmt.Errorf("no publisher for topic %q", topic))
+	}
+
+	batch := make([]kafka.Message, 0, len(messages))
+	for _, message := range messages {
+		batch = append(batch, kafka.Message{Key: message.Key, Value: message.Payload})
+	}
+
+	err := publisher.Publish(ctx, batch...)
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) && len(writeErrs) == len(messages) {
+		for i, err := range writeErrs {
+			if err != nil {
+				errs[i] = fmt.Errorf("failed to publish message to Kafka: %w", err)
+			}
+		}
+		return errs
+	}
+	if err != nil {
+		return fail(fmt.Errorf("failed to publish messages to Kafka: %w", err))
+	}
+	return errs
+}
+
+// backoff doubles the wait before the next attempt with every failed one, up to MaxRetryBackoff.
+func (uc *outboxRelayUseCase) backoff(attempts int) time.Duration {
+	wait := uc.cfg.RetryBackoff
+	for i := 1; i < attempts && wait < uc.cfg.MaxRetryBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, uc.cfg.MaxRetryBackoff)
+}
+
+// newCreatedMessage returns the outbox message announcing a transaction created with params.
+func newCreatedMessage(eventsTopic string, params domain.CreateTransactionParams) (domain.OutboxMessage, error) {
+	return events.NewTransactionMessage(eventsTopic, domain.Transaction{
+		ID:          params.ID,
+		WalletID:    params.WalletID,
+		ChainID:     params.ChainID,
+		Direction:   domain.DirectionOutbound,
+		FromAddress: params.FromAddress,
+		ToAddress:   params.ToAddress,
+		ToENSName:   params.ToENSName,
+		Amount:      params.Amount,
+		TokenID:     params.TokenID,
+		Nonce:       params.Nonce,
+		Status:      params.Status,
+	})
+}
+
+// newTxnMessage returns the outbox message that hands a submitted transaction to the worker.
+func newTxnMessage(topic string, txn domain.Transaction) (domain.OutboxMessage, error) {
+	payload, err := events.Encode(domain.EventTransactionSubmitted, domain.NewTransactionEvent(txn))
+	if err != nil {
+		return domain.OutboxMessage{}, fmt.Errorf("failed to encode transaction message: %w", err)
+	}
+
+	return domain.OutboxMessage{
+		ID:      uuid.New(),
+		Topic:   topic,
+		Key:     []byte(txn.ID.String()),
+		Payload: payload,
+	}, nil
+}
@@ -0,0 +1,245 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/infrastructure/kafka"
+	"mpc/internal/repository"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memOutboxMessage is a message of memOutbox with the state the relay keeps about it.
+type memOutboxMessage struct {
+	domain.OutboxMessage
+	sent          bool
+	nextAttemptAt time.Time
+	lockedUntil   time.Time
+}
+
+// memOutbox claims messages the way the outbox query does: due, not leased, in the order they were
+// written, and only the oldest unsent message of each key.
+type memOutbox struct {
+	repository.OutboxRepository
+	messages []*memOutboxMessage
+}
+
+func (o *memOutbox) add(topic string, key string) uuid.UUID {
+	id := uuid.New()
+	o.messages = append(o.messages, &memOutboxMessage{OutboxMessage: domain.OutboxMessage{
+		ID:      id,
+		Topic:   topic,
+		Key:     []byte(key),
+		Payload: []byte(key),
+		Seq:     int64(len(o.messages) + 1),
+	}})
+	return id
+}
+
+func (o *memOutbox) get(id uuid.UUID) *memOutboxMessage {
+	for _, m := range o.messages {
+		if m.ID == id {
+			return m
+		}
+	}
+	return nil
+}
+
+func (o *memOutbox) ClaimPendingMessages(ctx context.Context, limit int, lease time.Duration) ([]domain.OutboxMessage, error) {
+	now := time.Now()
+	blocked := make(map[string]bool)
+	var claimed []domain.OutboxMessage
+	for _, m := range o.messages {
+		if m.sent {
+			continue
+		}
+		key := m.Topic + "/" + string(m.Key)
+		if blocked[key] {
+			continue
+		}
+		blocked[key] = true
+		if m.nextAttemptAt.After(now) || m.lockedUntil.After(now) || len(claimed) == limit {
+			continue
+		}
+		m.lockedUntil = now.Add(lease)
+		claimed = append(claimed, m.OutboxMessage)
+	}
+	return claimed, nil
+}
+
+func (o *memOutbox) MarkMessagesSent(ctx context.Context, ids []uuid.UUID) error {
+	for _, id := range ids {
+		m := o.get(id)
+		m.sent = true
+		m.Attempts++
+		m.LastError = ""
+		m.lockedUntil = time.Time{}
+	}
+	return nil
+}
+
+func (o *memOutbox) MarkMessageFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	m := o.get(id)
+	m.Attempts++
+	m.LastError = lastError
+	m.nextAttemptAt = nextAttemptAt
+	m.lockedUntil = time.Time{}
+	return nil
+}
+
+// flakyPublisher records the batches it writes and fails the messages of the keys in failing, the
+// way a Kafka writer reports a partly written batch.
+type flakyPublisher struct {
+	topic   string
+	failing map[string]bool
+	batches [][]string
+}
+
+func (p *flakyPublisher) Topic() string {
+	return p.topic
+}
+
+func (p *flakyPublisher) Publish(ctx context.Context, messages ...kafka.Message) error {
+	var written []string
+	errs := make(kafka.WriteErrors, len(messages))
+	failed := false
+	for i, m := range messages {
+		if p.failing[string(m.Key)] {
+			errs[i] = errors.New("leader not available")
+			failed = true
+			continue
+		}
+		written = append(written, string(m.Key))
+	}
+	p.batches = append(p.batches, written)
+	if failed {
+		return errs
+	}
+	return nil
+}
+
+func (p *flakyPublisher) Close() error {
+	return nil
+}
+
+func TestRelayPendingKeyOrder(t *testing.T) {
+	ctx := context.Background()
+	outbox := &memOutbox{}
+	a1 := outbox.add("events", "a")
+	a2 := outbox.add("events", "a")
+	outbox.add("events", "b")
+	outbox.add("transactions", "a")
+
+	events := &flakyPublisher{topic: "events", failing: map[string]bool{"a": true}}
+	transactions := &flakyPublisher{topic: "transactions"}
+	cfg := &config.OutboxConfig{BatchSize: 10, Lease: time.Minute, RetryBackoff: time.Minute, MaxRetryBackoff: time.Hour}
+	uc := NewOutboxRelayUC(outbox, cfg, events, transactions)
+
+	relay := func(wantSent int) {
+		t.Helper()
+		sent, err := uc.RelayPending(ctx)
+		if err != nil {
+			t.Fatalf("RelayPending() error = %v", err)
+		}
+		if sent != wantSent {
+			t.Fatalf("RelayPending() = %d, want %d", sent, wantSent)
+		}
+	}
+
+	// The first message of key a fails; b and the key a of the other topic go out regardless, each
+	// topic in a single batch
+	before := time.Now()
+	relay(2)
+	if got := len(events.batches) + len(transactions.batches); got != 2 {
+		t.Fatalf("Published %d batches, want one per topic", got)
+	}
+
+	failed := outbox.get(a1)
+	if failed.Attempts != 1 || failed.LastError == "" || !failed.lockedUntil.IsZero() {
+		t.Fatalf("Failed message has %d attempts, error %q and lease until %v, want 1 attempt with its error and no lease", failed.Attempts, failed.LastError, failed.lockedUntil)
+	}
+	if wait := failed.nextAttemptAt.Sub(before); wait < time.Minute || wait > time.Minute+time.Second {
+		t.Fatalf("Next attempt in %v, want the first backoff of %v", wait, time.Minute)
+	}
+
+	// Waiting for its retry, the failed message holds back the later one of its key
+	relay(0)
+
+	// It fails again once due, and waits twice as long
+	failed.nextAttemptAt = time.Time{}
+	before = time.Now()
+	relay(0)
+	if wait := failed.nextAttemptAt.Sub(before); failed.Attempts != 2 || wait < 2*time.Minute || wait > 2*time.Minute+time.Second {
+		t.Fatalf("After %d attempts the next one is in %v, want 2 attempts and a backoff of %v", failed.Attempts, wait, 2*time.Minute)
+	}
+
+	// Once Kafka is back, the key is published in order
+	events.failing = nil
+	failed.nextAttemptAt = time.Time{}
+	relay(1)
+	relay(1)
+	relay(0)
+
+	var published []string
+	for _, batch := range events.batches {
+		published = append(published, batch...)
+	}
+	if want := []string{"b", "a", "a"}; fmt.Sprint(published) != fmt.Sprint(want) {
+		t.Fatalf("Published %v on events, want %v", published, want)
+	}
+	if !outbox.get(a1).sent || !outbox.get(a2).sent || outbox.get(a1).LastError != "" {
+		t.Errorf("Messages of key a are not both sent")
+	}
+	if outbox.get(a1).Attempts != 3 || outbox.get(a2).Attempts != 1 {
+		t.Errorf("Attempts are %d and %d, want 3 and 1", outbox.get(a1).Attempts, outbox.get(a2).Attempts)
+	}
+}
+
+func TestRelayPendingLease(t *testing.T) {
+	ctx := context.Background()
+	outbox := &memOutbox{}
+	id := outbox.add("events", "a")
+
+	// A relay that stopped after claiming the message still holds its lease
+	if _, err := outbox.ClaimPendingMessages(ctx, 10, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	events := &flakyPublisher{topic: "events"}
+	uc := NewOutboxRelayUC(outbox, &config.OutboxConfig{BatchSize: 10, Lease: time.Minute}, events)
+	if sent, err := uc.RelayPending(ctx); err != nil || sent != 0 {
+		t.Fatalf("RelayPending() = %d, %v while the message is leased, want 0", sent, err)
+	}
+
+	// Once the lease runs out the message is claimed again
+	outbox.get(id).lockedUntil = time.Now().Add(-time.Second)
+	if sent, err := uc.RelayPending(ctx); err != nil || sent != 1 {
+		t.Fatalf("RelayPending() = %d, %v after the lease ran out, want 1", sent, err)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	uc := &outboxRelayUseCase{cfg: &config.OutboxConfig{RetryBackoff: time.Second, MaxRetryBackoff: 5 * time.Minute}}
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 3, want: 4 * time.Second},
+		{attempts: 9, want: 256 * time.Second},
+		{attempts: 10, want: 5 * time.Minute},
+		{attempts: 100, want: 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := uc.backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/repository"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrSponsorshipBudgetExceeded = errors.New("gas sponsorship budget exceeded")
+	ErrNoRelayerAvailable        = errors.New("no relayer wallet with enough funds is available")
+)
+
+type RelayerUseCase interface {
+	CreateRelayerWallet(ctx context.Context, chainID uuid.UUID) (domain.RelayerWallet, error)
+	EnsureGas(ctx context.Context, userID uuid.UUID, txn domain.Transaction, from common.Address, tx *types.Transaction) (*domain.GasSponsorship, error)
+	GetSponsorships(ctx context.Context, userID uuid.UUID) ([]domain.GasSponsorship, error)
+	SponsorsGas() bool
+}
+
+type relayerUseCase struct {
+	relayerRepo repository.RelayerRepository
+	chainRepo   repository.ChainRepository
+	// ethRepo generates and encrypts relayer keys; everything sent on a chain goes through the
+	// client of that chain, see client.
+	ethRepo       repository.EthereumRepository
+	newClient     func(chain domain.Chain) (repository.EthereumRepository, error)
+	policy        domain.FundingPolicy
+	defaultBudget *big.Int
+	maxTopUp      *big.Int
+	bufferPercent int64
+
+	// locks serializes nonce allocation per relayer address
+	locks sync.Map
+	// clients holds the client of every chain a top-up was sent on, by chain ID
+	clients sync.Map
+}
+
+func NewRelayerUC(relayerRepo repository.RelayerRepository, chainRepo repository.ChainRepository, ethRepo repository.EthereumRepository, newClient func(chain domain.Chain) (repository.EthereumRepository, error), cfg *config.RelayerConfig) (RelayerUseCase, error) {
+	defaultBudget, ok := new(big.Int).SetString(cfg.DefaultBudgetWei, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid relayer default budget: %s", cfg.DefaultBudgetWei)
+	}
+
+	maxTopUp, ok := new(big.Int).SetString(cfg.MaxTopUpWei, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid relayer max top-up: %s", cfg.MaxTopUpWei)
+	}
+
+	policy := domain.FundingPolicy(cfg.FundingPolicy)
+	switch policy {
+	case domain.FundingPolicyNone, domain.FundingPolicyTopUp:
+	case domain.FundingPolicyRelay:
+		// Relaying needs a forwarder contract that every token and recipient would have to trust
+		return nil, fmt.Errorf("relayer funding policy %s is not supported, use %s or %s", policy, domain.FundingPolicyTopUp, domain.FundingPolicyNone)
+	default:
+		return nil, fmt.Errorf("unknown relayer funding policy: %s", cfg.FundingPolicy)
+	}
+
+	return &relayerUseCase{
+		relayerRepo:   relayerRepo,
+		chainRepo:     chainRepo,
+		ethRepo:       ethRepo,
+		newClient:     newClient,
+		policy:        policy,
+		defaultBudget: defaultBudget,
+		maxTopUp:      maxTopUp,
+		bufferPercent: int64(cfg.TopUpBufferPercent),
+	}, nil
+}
+
+var _ RelayerUseCase = (*relayerUseCase)(nil)
+
+// CreateRelayerWallet generates a new hot wallet for the given chain and adds it to the pool.
+// The wallet has to be funded before it can sponsor any gas.
+func (uc *relayerUseCase) CreateRelayerWallet(ctx context.Context, chainID uuid.UUID) (domain.RelayerWallet, error) {
+	privateKey, address, err := uc.ethRepo.CreateWallet(ctx)
+	if err != nil {
+		return domain.RelayerWallet{}, err
+	}
+
+	encryptedPrivateKey, err := uc.ethRepo.EncryptPrivateKey(ctx, crypto.FromECDSA(privateKey))
+	if err != nil {
+		return domain.RelayerWallet{}, err
+	}
+
+	return uc.relayerRepo.CreateRelayerWallet(ctx, domain.CreateRelayerWalletParams{
+		ChainID:             chainID,
+		Address:             address.Hex(),
+		EncryptedPrivateKey: encryptedPrivateKey,
+	})
+}
+
+// SponsorsGas reports whether wallets that cannot pay for gas are topped up by a relayer.
+func (uc *relayerUseCase) SponsorsGas() bool {
+	return uc.policy == domain.FundingPolicyTopUp
+}
+
+// EnsureGas makes sure the sender of tx can pay for its gas.
+// When the wallet holds enough for the value but not for the fee, the shortfall (plus a buffer)
+// is sent from a relayer wallet and charged against the user's sponsorship budget. The top-up is
+// not waited for: it is recorded as a pending sponsorship, and tx must be held back until the
+// worker sees the funding transaction mined. It returns nil when no sponsorship was needed.
+func (uc *relayerUseCase) EnsureGas(ctx context.Context, userID uuid.UUID, txn domain.Transaction, from common.Address, tx *types.Transaction) (*domain.GasSponsorship, error) {
+	if uc.policy != domain.FundingPolicyTopUp {
+		return nil, nil
+	}
+
+	client, err := uc.client(ctx, txn.ChainID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance, err := client.GetBalance(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet balance: %w", err)
+	}
+
+	// Only gas is sponsored, never the transferred value itself
+	if balance.Cmp(tx.Value()) < 0 {
+		return nil, nil
+	}
+
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), tx.GasPrice())
+	cost.Add(cost, tx.Value())
+	if balance.Cmp(cost) >= 0 {
+		return nil, nil
+	}
+
+	shortfall := new(big.Int).Sub(cost, balance)
+	topUp := new(big.Int).Mul(shortfall, big.NewInt(100+uc.bufferPercent))
+	topUp.Div(topUp, big.NewInt(100))
+	if topUp.Cmp(uc.maxTopUp) > 0 {
+		return nil, fmt.Errorf("required top-up %s exceeds the limit of %s wei", topUp, uc.maxTopUp)
+	}
+
+	if _, err := uc.relayerRepo.EnsureSponsorshipBudget(ctx, userID, txn.ChainID, uc.defaultBudget); err != nil {
+		return nil, fmt.Errorf("failed to load sponsorship budget: %w", err)
+	}
+
+	if _, err := uc.relayerRepo.ReserveSponsorship(ctx, userID, txn.ChainID, topUp); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSponsorshipBudgetExceeded
+		}
+		return nil, fmt.Errorf("failed to reserve sponsorship budget: %w", err)
+	}
+
+	relayer, hash, err := uc.topUp(ctx, client, txn.ChainID, from, topUp)
+	if err != nil {
+		// Nothing was broadcast, so nothing was spent
+		if _, relErr := uc.relayerRepo.ReleaseSponsorship(ctx, userID, txn.ChainID, topUp); relErr != nil {
+			log.Printf("Failed to release sponsorship budget for user %s: %v", userID, relErr)
+		}
+		return nil, err
+	}
+
+	// The top-up is on its way to the wallet, so the budget stays charged from here on. The worker
+	// records its fee once it is mined, or gives the amount back if it reverts.
+	sponsorship, err := uc.relayerRepo.CreateGasSponsorship(ctx, domain.CreateGasSponsorshipParams{
+		UserID:          userID,
+		ChainID:         txn.ChainID,
+		RelayerWalletID: relayer.ID,
+		TransactionID:   txn.ID,
+		FundingTxHash:   hash.Hex(),
+		AmountWei:       topUp,
+		FeeWei:          new(big.Int),
+		Status:          domain.SponsorshipStatusPending,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to record sponsorship of funding transaction %s: %w", hash.Hex(), err)
+	}
+	return &sponsorship, nil
+}
+
+func (uc *relayerUseCase) GetSponsorships(ctx context.Context, userID uuid.UUID) ([]domain.GasSponsorship, error) {
+	return uc.relayerRepo.GetGasSponsorshipsByUserID(ctx, userID)
+}
+
+// topUp sends amount from the first relayer wallet of the chain that can afford it. It returns
+// the relayer and the hash of the funding transaction, without waiting for it to be mined.
+func (uc *relayerUseCase) topUp(ctx context.Context, client repository.EthereumRepository, chainID uuid.UUID, to common.Address, amount *big.Int) (domain.RelayerWallet, common.Hash, error) {
+	relayers, err := uc.relayerRepo.ListActiveRelayerWallets(ctx, chainID)
+	if err != nil {
+		return domain.RelayerWallet{}, common.Hash{}, fmt.Errorf("failed to list relayer wallets: %w", err)
+	}
+
+	for _, relayer := range relayers {
+		hash, ok, err := uc.sendFromRelayer(ctx, client, relayer, to, amount)
+		if err != nil {
+			log.Printf("Relayer %s failed to send top-up: %v", relayer.Address, err)
+			continue
+		}
+		if ok {
+			return relayer, hash, nil
+		}
+	}
+
+	return domain.RelayerWallet{}, common.Hash{}, ErrNoRelayerAvailable
+}
+
+// client returns the client of the chain, connecting to it on first use.
+func (uc *relayerUseCase) client(ctx context.Context, chainID uuid.UUID) (repository.EthereumRepository, error) {
+	if client, ok := uc.clients.Load(chainID); ok {
+		return client.(repository.EthereumRepository), nil
+	}
+
+	chain, err := uc.chainRepo.GetChain(ctx, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain: %w", err)
+	}
+
+	client, err := uc.newClient(chain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", chain.Name, err)
+	}
+	// Another request may have connected in the meantime; its client is kept
+	stored, loaded := uc.clients.LoadOrStore(chainID, client)
+	if closer, ok := client.(interface{ Close() }); ok && loaded {
+		closer.Close()
+	}
+	return stored.(repository.EthereumRepository), nil
+}
+
+// sendFromRelayer signs and submits a transfer from the relayer wallet.
+// It reports false when the relayer cannot cover the amount and its own fee.
+func (uc *relayerUseCase) sendFromRelayer(ctx context.Context, client repository.EthereumRepository, relayer domain.RelayerWallet, to common.Address, amount *big.Int) (common.Hash, bool, error) {
+	lock, _ := uc.locks.LoadOrStore(relayer.Address, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	from := common.HexToAddress(relayer.Address)
+	balance, err := client.GetBalance(ctx, from)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+
+	unsignedTx, err := client.CreateUnsignedTransaction(ctx, from, to, amount)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+
+	required := new(big.Int).Mul(new(big.Int).SetUint64(unsignedTx.Gas()), unsignedTx.GasPrice())
+	required.Add(required, amount)
+	if balance.Cmp(required) < 0 {
+		return common.Hash{}, false, nil
+	}
+
+	privateKey, err := uc.privateKey(ctx, relayer)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+
+	signedTx, err := client.SignTransaction(ctx, unsignedTx, privateKey)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+
+	hash, err := client.SubmitTransaction(ctx, signedTx)
+	if err != nil {
+		return common.Hash{}, false, err
+	}
+
+	return hash, true, nil
+}
+
+func (uc *relayerUseCase) privateKey(ctx context.Context, relayer domain.RelayerWallet) (*ecdsa.PrivateKey, error) {
+	privateKeyBytes, err := uc.ethRepo.DecryptPrivateKey(ctx, relayer.EncryptedPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.ToECDSA(privateKeyBytes)
+}
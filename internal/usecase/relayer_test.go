@@ -0,0 +1,339 @@
+package usecase
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/config"
+	"mpc/internal/repository"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const gwei = 1_000_000_000
+
+// stubRelayerEth is a node holding balances and charging a fixed gas price. Transfers it is asked to
+// submit are recorded; it cannot wait for receipts, since top-ups are not waited for.
+type stubRelayerEth struct {
+	repository.EthereumRepository
+	balances map[common.Address]*big.Int
+	gasPrice *big.Int
+	key      []byte
+	sent     []*types.Transaction
+}
+
+func (s *stubRelayerEth) GetBalance(ctx context.Context, address common.Address) (*big.Int, error) {
+	if balance, ok := s.balances[address]; ok {
+		return balance, nil
+	}
+	return new(big.Int), nil
+}
+
+func (s *stubRelayerEth) CreateUnsignedTransaction(ctx context.Context, from common.Address, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return types.NewTx(&types.LegacyTx{To: &to, Value: amount, Gas: 21_000, GasPrice: s.gasPrice}), nil
+}
+
+func (s *stubRelayerEth) DecryptPrivateKey(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	return s.key, nil
+}
+
+func (s *stubRelayerEth) SignTransaction(ctx context.Context, tx *types.Transaction, privateKey *ecdsa.PrivateKey) (*types.Transaction, error) {
+	return tx, nil
+}
+
+func (s *stubRelayerEth) SubmitTransaction(ctx context.Context, signedTx *types.Transaction) (common.Hash, error) {
+	s.sent = append(s.sent, signedTx)
+	return signedTx.Hash(), nil
+}
+
+// stubRelayerChains knows every chain it is asked about.
+type stubRelayerChains struct {
+	repository.ChainRepository
+}
+
+func (r *stubRelayerChains) GetChain(ctx context.Context, id uuid.UUID) (domain.Chain, error) {
+	return domain.Chain{ID: id, Name: id.String()}, nil
+}
+
+// chainClients hands out the same node for every chain and records the chains connected to.
+type chainClients struct {
+	eth       repository.EthereumRepository
+	connected []uuid.UUID
+}
+
+func (c *chainClients) newClient(chain domain.Chain) (repository.EthereumRepository, error) {
+	c.connected = append(c.connected, chain.ID)
+	return c.eth, nil
+}
+
+// stubRelayerRepo keeps a single sponsorship budget and records the sponsorships created from it.
+type stubRelayerRepo struct {
+	repository.RelayerRepository
+	relayers     []domain.RelayerWallet
+	remaining    *big.Int
+	sponsorships []domain.CreateGasSponsorshipParams
+	createErr    error
+}
+
+func (r *stubRelayerRepo) ListActiveRelayerWallets(ctx context.Context, chainID uuid.UUID) ([]domain.RelayerWallet, error) {
+	return r.relayers, nil
+}
+
+func (r *stubRelayerRepo) EnsureSponsorshipBudget(ctx context.Context, userID uuid.UUID, chainID uuid.UUID, budget *big.Int) (domain.SponsorshipBudget, error) {
+	return domain.SponsorshipBudget{UserID: userID, ChainID: chainID, BudgetWei: budget}, nil
+}
+
+func (r *stubRelayerRepo) ReserveSponsorship(ctx context.Context, userID uuid.UUID, chainID uuid.UUID, amount *big.Int) (domain.SponsorshipBudget, error) {
+	if r.remaining.Cmp(amount) < 0 {
+		return domain.SponsorshipBudget{}, pgx.ErrNoRows
+	}
+	r.remaining.Sub(r.remaining, amount)
+	return domain.SponsorshipBudget{UserID: userID, ChainID: chainID}, nil
+}
+
+func (r *stubRelayerRepo) ReleaseSponsorship(ctx context.Context, userID uuid.UUID, chainID uuid.UUID, amount *big.Int) (domain.SponsorshipBudget, error) {
+	r.remaining.Add(r.remaining, amount)
+	return domain.SponsorshipBudget{UserID: userID, ChainID: chainID}, nil
+}
+
+func (r *stubRelayerRepo) CreateGasSponsorship(ctx context.Context, params domain.CreateGasSponsorshipParams) (domain.GasSponsorship, error) {
+	if r.createErr != nil {
+		return domain.GasSponsorship{}, r.createErr
+	}
+	r.sponsorships = append(r.sponsorships, params)
+	return domain.GasSponsorship{
+		ID:              uuid.New(),
+		UserID:          params.UserID,
+		ChainID:         params.ChainID,
+		RelayerWalletID: params.RelayerWalletID,
+		TransactionID:   params.TransactionID,
+		FundingTxHash:   params.FundingTxHash,
+		AmountWei:       params.AmountWei,
+		FeeWei:          params.FeeWei,
+		Status:          params.Status,
+	}, nil
+}
+
+func TestEnsureGas(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wallet := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	relayer := domain.RelayerWallet{ID: uuid.New(), Address: "0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512", Active: true}
+
+	// The user's transaction costs 21,000 gas at 10 gwei on top of its value
+	value := big.NewInt(1_000 * gwei)
+	fee := big.NewInt(21_000 * 10 * gwei)
+	cost := new(big.Int).Add(value, fee)
+
+	tests := []struct {
+		name           string
+		policy         domain.FundingPolicy
+		balance        *big.Int
+		relayerBalance *big.Int
+		budget         *big.Int
+		createErr      error
+		wantTopUp      *big.Int
+		wantErr        error
+	}{
+		{
+			name:    "already funded",
+			policy:  domain.FundingPolicyTopUp,
+			balance: cost,
+		},
+		{
+			name:    "value not covered",
+			policy:  domain.FundingPolicyTopUp,
+			balance: new(big.Int).Sub(value, big.NewInt(1)),
+		},
+		{
+			name:    "sponsorship disabled",
+			policy:  domain.FundingPolicyNone,
+			balance: value,
+		},
+		{
+			// The whole fee is missing; 20% is added on top of it
+			name:      "shortfall with buffer",
+			policy:    domain.FundingPolicyTopUp,
+			balance:   value,
+			wantTopUp: big.NewInt(21_000 * 12 * gwei),
+		},
+		{
+			name:      "partial shortfall",
+			policy:    domain.FundingPolicyTopUp,
+			balance:   new(big.Int).Sub(cost, big.NewInt(10_000*gwei)),
+			wantTopUp: big.NewInt(12_000 * gwei),
+		},
+		{
+			name:    "budget exhausted",
+			policy:  domain.FundingPolicyTopUp,
+			balance: value,
+			budget:  big.NewInt(21_000*12*gwei - 1),
+			wantErr: ErrSponsorshipBudgetExceeded,
+		},
+		{
+			name:           "no relayer can afford the top-up",
+			policy:         domain.FundingPolicyTopUp,
+			balance:        value,
+			relayerBalance: big.NewInt(21_000 * 12 * gwei),
+			wantErr:        ErrNoRelayerAvailable,
+		},
+		{
+			// The top-up was broadcast, so its amount stays charged to the budget
+			name:      "recording fails after the broadcast",
+			policy:    domain.FundingPolicyTopUp,
+			balance:   value,
+			createErr: errors.New("connection reset"),
+			wantTopUp: big.NewInt(21_000 * 12 * gwei),
+			wantErr:   errors.New("connection reset"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.relayerBalance == nil {
+				tt.relayerBalance = big.NewInt(1e18)
+			}
+			if tt.budget == nil {
+				tt.budget = big.NewInt(1e16)
+			}
+
+			ethRepo := &stubRelayerEth{
+				balances: map[common.Address]*big.Int{wallet: tt.balance, common.HexToAddress(relayer.Address): tt.relayerBalance},
+				gasPrice: big.NewInt(10 * gwei),
+				key:      crypto.FromECDSA(key),
+			}
+			relayerRepo := &stubRelayerRepo{relayers: []domain.RelayerWallet{relayer}, remaining: new(big.Int).Set(tt.budget), createErr: tt.createErr}
+			clients := &chainClients{eth: ethRepo}
+			uc, err := NewRelayerUC(relayerRepo, &stubRelayerChains{}, ethRepo, clients.newClient, &config.RelayerConfig{
+				FundingPolicy:      string(tt.policy),
+				DefaultBudgetWei:   tt.budget.String(),
+				MaxTopUpWei:        "5000000000000000",
+				TopUpBufferPercent: 20,
+			})
+			if err != nil {
+				t.Fatalf("NewRelayerUC() error = %v", err)
+			}
+
+			to := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+			tx := types.NewTx(&types.LegacyTx{To: &to, Value: value, Gas: 21_000, GasPrice: big.NewInt(10 * gwei)})
+			txn := domain.Transaction{ID: uuid.New(), ChainID: uuid.New()}
+
+			sponsorship, err := uc.EnsureGas(context.Background(), uuid.New(), txn, wallet, tx)
+			if tt.wantErr != nil {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr.Error()) {
+					t.Fatalf("EnsureGas() error = %v, want %v", err, tt.wantErr)
+				}
+				if len(relayerRepo.sponsorships) != 0 {
+					t.Errorf("EnsureGas() recorded %d sponsorships on failure", len(relayerRepo.sponsorships))
+				}
+				charged := new(big.Int)
+				if tt.wantTopUp != nil {
+					charged = tt.wantTopUp
+				}
+				if spent := new(big.Int).Sub(tt.budget, relayerRepo.remaining); spent.Cmp(charged) != 0 {
+					t.Errorf("Budget charged %s after a failed top-up, want %s", spent, charged)
+				}
+				if len(ethRepo.sent) != 0 && tt.wantTopUp == nil {
+					t.Errorf("Relayer sent %d transfers, want none", len(ethRepo.sent))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("EnsureGas() error = %v", err)
+			}
+
+			if tt.wantTopUp == nil {
+				if sponsorship != nil || len(ethRepo.sent) != 0 {
+					t.Fatalf("EnsureGas() = %+v and sent %d transfers, want no sponsorship", sponsorship, len(ethRepo.sent))
+				}
+				return
+			}
+
+			if sponsorship == nil {
+				t.Fatal("EnsureGas() = nil, want a sponsorship")
+			}
+			if sponsorship.AmountWei.Cmp(tt.wantTopUp) != 0 {
+				t.Errorf("Top-up = %s, want %s", sponsorship.AmountWei, tt.wantTopUp)
+			}
+			if len(ethRepo.sent) != 1 || ethRepo.sent[0].Value().Cmp(tt.wantTopUp) != 0 || *ethRepo.sent[0].To() != wallet {
+				t.Errorf("Relayer sent %d transfers, want one of %s to the wallet", len(ethRepo.sent), tt.wantTopUp)
+			}
+			if spent := new(big.Int).Sub(tt.budget, relayerRepo.remaining); spent.Cmp(tt.wantTopUp) != 0 {
+				t.Errorf("Budget charged %s, want %s", spent, tt.wantTopUp)
+			}
+			// The funding transaction is not waited for; the worker records its fee once it is mined
+			if sponsorship.Status != domain.SponsorshipStatusPending || sponsorship.FeeWei.Sign() != 0 {
+				t.Errorf("Sponsorship is %s with a fee of %s, want pending without a fee", sponsorship.Status, sponsorship.FeeWei)
+			}
+			if sponsorship.FundingTxHash != ethRepo.sent[0].Hash().Hex() || sponsorship.TransactionID != txn.ID {
+				t.Errorf("Sponsorship funds %s with %s, want %s with %s", sponsorship.TransactionID, sponsorship.FundingTxHash, txn.ID, ethRepo.sent[0].Hash().Hex())
+			}
+		})
+	}
+}
+
+func TestNewRelayerUCFundingPolicy(t *testing.T) {
+	cfg := config.RelayerConfig{DefaultBudgetWei: "0", MaxTopUpWei: "0"}
+
+	for _, policy := range []string{"relay", "bogus"} {
+		cfg.FundingPolicy = policy
+		if _, err := NewRelayerUC(nil, nil, nil, nil, &cfg); err == nil {
+			t.Errorf("NewRelayerUC() with funding policy %s succeeded, want an error", policy)
+		}
+	}
+}
+
+func TestEnsureGasChainClient(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wallet := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	relayer := domain.RelayerWallet{ID: uuid.New(), Address: "0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512", Active: true}
+	value := big.NewInt(1_000 * gwei)
+
+	ethRepo := &stubRelayerEth{
+		balances: map[common.Address]*big.Int{wallet: value, common.HexToAddress(relayer.Address): big.NewInt(1e18)},
+		gasPrice: big.NewInt(10 * gwei),
+		key:      crypto.FromECDSA(key),
+	}
+	relayerRepo := &stubRelayerRepo{relayers: []domain.RelayerWallet{relayer}, remaining: big.NewInt(1e17)}
+	clients := &chainClients{eth: ethRepo}
+	uc, err := NewRelayerUC(relayerRepo, &stubRelayerChains{}, ethRepo, clients.newClient, &config.RelayerConfig{
+		FundingPolicy:      string(domain.FundingPolicyTopUp),
+		DefaultBudgetWei:   "100000000000000000",
+		MaxTopUpWei:        "5000000000000000",
+		TopUpBufferPercent: 20,
+	})
+	if err != nil {
+		t.Fatalf("NewRelayerUC() error = %v", err)
+	}
+
+	// Every top-up goes out on the chain of its transaction, over one client per chain
+	to := common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045")
+	tx := types.NewTx(&types.LegacyTx{To: &to, Value: value, Gas: 21_000, GasPrice: big.NewInt(10 * gwei)})
+	mainnet, base := uuid.New(), uuid.New()
+	for _, chainID := range []uuid.UUID{mainnet, base, mainnet} {
+		if _, err := uc.EnsureGas(context.Background(), uuid.New(), domain.Transaction{ID: uuid.New(), ChainID: chainID}, wallet, tx); err != nil {
+			t.Fatalf("EnsureGas() error = %v", err)
+		}
+	}
+	if want := []uuid.UUID{mainnet, base}; fmt.Sprint(clients.connected) != fmt.Sprint(want) {
+		t.Errorf("Connected to chains %v, want %v", clients.connected, want)
+	}
+	for i, sponsorship := range relayerRepo.sponsorships {
+		if want := []uuid.UUID{mainnet, base, mainnet}[i]; sponsorship.ChainID != want {
+			t.Errorf("Sponsorship %d is on chain %s, want %s", i, sponsorship.ChainID, want)
+		}
+	}
+}
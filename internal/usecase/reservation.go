@@ -0,0 +1,86 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"mpc/internal/domain"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+)
+
+// reserveBalance holds back amount, the value plus maximum fee of a transaction, from the wallet's
+// on-chain balance minus what is already reserved for its other in-flight transactions.
+func (f *evmFamily) reserveBalance(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID, from common.Address, txnID uuid.UUID, amount *big.Int) error {
+	return f.reserveBalances(ctx, walletID, chainID, from, []uuid.UUID{txnID}, []*big.Int{amount})
+}
+
+// reserveBalances reserves funds for several transactions of one wallet, all or nothing.
+func (f *evmFamily) reserveBalances(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID, from common.Address, txnIDs []uuid.UUID, amounts []*big.Int) error {
+	total, err := f.ethRepo.GetBalance(ctx, from)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(f.balanceCfg.ReservationTTL)
+	for i, txnID := range txnIDs {
+		reserved, err := f.balanceRepo.ReserveBalance(ctx, domain.ReserveBalanceParams{
+			WalletID:      walletID,
+			ChainID:       chainID,
+			TransactionID: txnID,
+			Amount:        amounts[i],
+			Total:         total,
+			ExpiresAt:     expiresAt,
+		})
+		if err != nil || !reserved {
+			for _, reservedID := range txnIDs[:i] {
+				f.releaseReservation(ctx, reservedID)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to reserve balance: %w", err)
+		}
+		if !reserved {
+			return f.insufficientBalance(ctx, walletID, chainID, total, amounts)
+		}
+	}
+	return nil
+}
+
+func (f *evmFamily) insufficientBalance(ctx context.Context, walletID uuid.UUID, chainID uuid.UUID, total *big.Int, amounts []*big.Int) error {
+	required := new(big.Int)
+	for _, amount := range amounts {
+		required.Add(required, amount)
+	}
+
+	pending, err := f.balanceRepo.GetReservedAmount(ctx, walletID, chainID)
+	if err != nil {
+		return fmt.Errorf("%w: need %s wei", ErrInsufficientBalance, required)
+	}
+
+	available := new(big.Int).Sub(total, pending)
+	if available.Sign() < 0 {
+		available.SetInt64(0)
+	}
+	return fmt.Errorf("%w: total %s wei, pending %s wei, available %s wei, need %s wei", ErrInsufficientBalance, total, pending, available, required)
+}
+
+// reservationAmount is the value plus maximum fee of tx. When a relayer tops up wallets that cannot
+// pay for gas, only the value has to be covered by the wallet itself.
+func (f *evmFamily) reservationAmount(tx *types.Transaction) *big.Int {
+	if f.relayerUC.SponsorsGas() {
+		return new(big.Int).Set(tx.Value())
+	}
+	return tx.Cost()
+}
+
+// releaseReservation gives back the funds reserved for a transaction that failed or never made it on chain.
+func (f *evmFamily) releaseReservation(ctx context.Context, txnID uuid.UUID) {
+	if err := f.balanceRepo.ReleaseReservation(ctx, txnID); err != nil {
+		log.Printf("Failed to release balance reservation of transaction %s: %v", txnID, err)
+	}
+}
@@ -0,0 +1,565 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/events"
+	"mpc/internal/repository"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrNotSafeOwner        = errors.New("signer is not an owner of the Safe")
+	ErrProposalNotPending  = errors.New("safe proposal is not pending")
+	ErrThresholdNotReached = errors.New("safe proposal has not reached its signature threshold")
+)
+
+type SafeUseCase interface {
+	ProposeTransaction(ctx context.Context, userID uuid.UUID, params domain.CreateSafeProposalRequest) (domain.SafeProposalResponse, error)
+	GetProposal(ctx context.Context, userID uuid.UUID, proposalID uuid.UUID) (domain.SafeProposalResponse, error)
+	AddSignature(ctx context.Context, userID uuid.UUID, proposalID uuid.UUID, signature string) (domain.SafeProposalResponse, error)
+	ExecuteProposal(ctx context.Context, userID uuid.UUID, proposalID uuid.UUID, signatures []string) (domain.SafeProposalResponse, error)
+}
+
+type safeUseCase struct {
+	proposalRepo repository.SafeProposalRepository
+	safeRepo     repository.SafeRepository
+	ethRepo      repository.EthereumRepository
+	txnRepo      repository.TransactionRepository
+	ensRepo      repository.ENSRepository
+	walletUC     WalletUseCase
+	// evmFamily reserves the funds of exec transactions and broadcasts them, topping the proposing
+	// wallet up from the relayer pool like any other transfer.
+	evmFamily ChainFamily
+	// txnTopic and eventsTopic are where the exec transaction is announced, as in txnUseCase.
+	txnTopic    string
+	eventsTopic string
+}
+
+func NewSafeUC(proposalRepo repository.SafeProposalRepository, safeRepo repository.SafeRepository, ethRepo repository.EthereumRepository, txnRepo repository.TransactionRepository, ensRepo repository.ENSRepository, walletUC WalletUseCase, evmFamily ChainFamily, txnTopic string, eventsTopic string) SafeUseCase {
+	return &safeUseCase{proposalRepo: proposalRepo, safeRepo: safeRepo, ethRepo: ethRepo, txnRepo: txnRepo, ensRepo: ensRepo, walletUC: walletUC, evmFamily: evmFamily, txnTopic: txnTopic, eventsTopic: eventsTopic}
+}
+
+var _ SafeUseCase = (*safeUseCase)(nil)
+
+// ProposeTransaction creates a Safe transaction proposal at the Safe's current nonce and signs it
+// with the proposing wallet. The recipient is a hex address or an ENS name, as for transfers. The
+// proposal is executed right away when the Safe needs a single signature.
+func (uc *safeUseCase) ProposeTransaction(ctx context.Context, userID uuid.UUID, params domain.CreateSafeProposalRequest) (domain.SafeProposalResponse, error) {
+	wallet, err := getOwnedWallet(ctx, uc.walletUC, userID, params.WalletID)
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	safeAddress := common.HexToAddress(params.SafeAddress)
+	info, err := uc.safeRepo.GetSafeInfo(ctx, safeAddress)
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	owner := common.HexToAddress(wallet.Address)
+	if !isSafeOwner(info, owner) {
+		return domain.SafeProposalResponse{}, ErrNotSafeOwner
+	}
+
+	to, _, err := resolveRecipient(ctx, uc.ensRepo, params.ToAddress)
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	value, err := toWei(params.Amount)
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	var data []byte
+	if params.Data != "" {
+		data, err = hexutil.Decode(params.Data)
+		if err != nil {
+			return domain.SafeProposalResponse{}, fmt.Errorf("invalid call data: %w", err)
+		}
+	}
+
+	safeTx := domain.SafeTransaction{
+		Safe:  safeAddress,
+		To:    to,
+		Value: value,
+		Data:  data,
+		Nonce: info.Nonce,
+	}
+
+	safeTxHash, err := uc.safeRepo.GetTransactionHash(ctx, safeTx)
+	if err != nil {
+		return domain.SafeProposalResponse{}, fmt.Errorf("failed to compute Safe transaction hash: %w", err)
+	}
+
+	privateKey, err := uc.walletUC.GetPrivateKey(ctx, userID)
+	if err != nil {
+		return domain.SafeProposalResponse{}, fmt.Errorf("failed to get private key: %w", err)
+	}
+
+	signature, err := uc.safeRepo.SignTransactionHash(safeTxHash, privateKey)
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	proposal, err := uc.proposalRepo.CreateSafeProposal(ctx, domain.CreateSafeProposalParams{
+		ID:          uuid.New(),
+		WalletID:    wallet.ID,
+		ChainID:     params.ChainID,
+		SafeAddress: safeAddress.Hex(),
+		ToAddress:   safeTx.To.Hex(),
+		Value:       value.String(),
+		Data:        data,
+		Nonce:       info.Nonce.String(),
+		SafeTxHash:  safeTxHash.Hex(),
+		Threshold:   info.Threshold,
+		Status:      domain.StatusPending,
+	})
+	if err != nil {
+		return domain.SafeProposalResponse{}, fmt.Errorf("failed to save Safe proposal to database: %w", err)
+	}
+
+	if _, err := uc.proposalRepo.AddSafeSignature(ctx, proposal.ID, owner.Hex(), signature); err != nil {
+		return domain.SafeProposalResponse{}, fmt.Errorf("failed to save Safe signature to database: %w", err)
+	}
+
+	return uc.executeIfReady(ctx, proposal)
+}
+
+// GetProposal returns a proposal together with the signatures collected so far. Only the user of the
+// proposing wallet and users whose wallet owns the Safe can see it; for anyone else it returns
+// ErrWalletNotFound, as for proposals that do not exist.
+func (uc *safeUseCase) GetProposal(ctx context.Context, userID uuid.UUID, proposalID uuid.UUID) (domain.SafeProposalResponse, error) {
+	proposal, err := uc.getProposal(ctx, proposalID)
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	if err := uc.checkProposalAccess(ctx, userID, proposal); err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	proposal, err = uc.syncExecStatus(ctx, proposal)
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+	return uc.toResponse(ctx, proposal)
+}
+
+// getProposal loads a proposal. It returns ErrWalletNotFound when there is none, like for proposals
+// the user may not see.
+func (uc *safeUseCase) getProposal(ctx context.Context, proposalID uuid.UUID) (domain.SafeProposal, error) {
+	proposal, err := uc.proposalRepo.GetSafeProposal(ctx, proposalID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.SafeProposal{}, ErrWalletNotFound
+	}
+	if err != nil {
+		return domain.SafeProposal{}, fmt.Errorf("failed to get Safe proposal: %w", err)
+	}
+	return proposal, nil
+}
+
+// checkProposalAccess makes sure the user proposed the proposal or owns its Safe with their wallet.
+func (uc *safeUseCase) checkProposalAccess(ctx context.Context, userID uuid.UUID, proposal domain.SafeProposal) error {
+	_, err := getOwnedWallet(ctx, uc.walletUC, userID, proposal.WalletID)
+	if !errors.Is(err, ErrWalletNotFound) {
+		return err
+	}
+
+	wallet, err := uc.walletUC.GetWalletByUserID(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrWalletNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	info, err := uc.safeRepo.GetSafeInfo(ctx, common.HexToAddress(proposal.SafeAddress))
+	if err != nil {
+		return err
+	}
+	if !isSafeOwner(info, common.HexToAddress(wallet.Address)) {
+		return ErrWalletNotFound
+	}
+	return nil
+}
+
+// AddSignature records a co-signer's signature over the proposal's Safe transaction hash.
+// The signature may come from any Safe owner, not only wallets managed by this service.
+// Once enough owners have signed, the proposal is executed by the proposing wallet.
+func (uc *safeUseCase) AddSignature(ctx context.Context, userID uuid.UUID, proposalID uuid.UUID, signature string) (domain.SafeProposalResponse, error) {
+	proposal, err := uc.getProposal(ctx, proposalID)
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+	if proposal.Status != domain.StatusPending {
+		return domain.SafeProposalResponse{}, ErrProposalNotPending
+	}
+
+	info, err := uc.safeRepo.GetSafeInfo(ctx, common.HexToAddress(proposal.SafeAddress))
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	if err := uc.addSignature(ctx, proposal, info, signature); err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	return uc.executeIfReady(ctx, proposal)
+}
+
+// ExecuteProposal submits execTransaction for a proposal that has reached its threshold.
+// Signatures of owners outside this service can be passed along; they are checked and stored
+// like the ones added with AddSignature. The transaction is sent and paid for by the proposing wallet.
+func (uc *safeUseCase) ExecuteProposal(ctx context.Context, userID uuid.UUID, proposalID uuid.UUID, signatures []string) (domain.SafeProposalResponse, error) {
+	proposal, err := uc.getProposal(ctx, proposalID)
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+	if proposal.Status != domain.StatusPending {
+		return domain.SafeProposalResponse{}, ErrProposalNotPending
+	}
+
+	if _, err := getOwnedWallet(ctx, uc.walletUC, userID, proposal.WalletID); err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	if len(signatures) > 0 {
+		info, err := uc.safeRepo.GetSafeInfo(ctx, common.HexToAddress(proposal.SafeAddress))
+		if err != nil {
+			return domain.SafeProposalResponse{}, err
+		}
+		for _, signature := range signatures {
+			if err := uc.addSignature(ctx, proposal, info, signature); err != nil {
+				return domain.SafeProposalResponse{}, err
+			}
+		}
+	}
+
+	return uc.execute(ctx, proposal)
+}
+
+// addSignature checks that signature was made by an owner of the Safe over the proposal's Safe
+// transaction hash and stores it.
+func (uc *safeUseCase) addSignature(ctx context.Context, proposal domain.SafeProposal, info domain.SafeInfo, signature string) error {
+	sig, err := hexutil.Decode(signature)
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	signer, err := uc.safeRepo.RecoverSigner(common.HexToHash(proposal.SafeTxHash), sig)
+	if err != nil {
+		return err
+	}
+	if !isSafeOwner(info, signer) {
+		return ErrNotSafeOwner
+	}
+
+	if _, err := uc.proposalRepo.AddSafeSignature(ctx, proposal.ID, signer.Hex(), sig); err != nil {
+		return fmt.Errorf("failed to save Safe signature to database: %w", err)
+	}
+	return nil
+}
+
+// execute sends execTransaction from the proposing wallet, whoever triggered the execution. The
+// transaction goes through the EVM family like a transfer: its fee is reserved against the wallet's
+// balance and the relayer tops the wallet up when it cannot pay for gas. It is stored as a submitted
+// transaction of the wallet, so the receipt worker and the block scanner track it; the proposal
+// follows its status.
+func (uc *safeUseCase) execute(ctx context.Context, proposal domain.SafeProposal) (domain.SafeProposalResponse, error) {
+	wallet, err := uc.walletUC.GetWallet(ctx, proposal.WalletID)
+	if err != nil {
+		return domain.SafeProposalResponse{}, fmt.Errorf("failed to get wallet: %w", err)
+	}
+
+	info, err := uc.safeRepo.GetSafeInfo(ctx, common.HexToAddress(proposal.SafeAddress))
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	// Another transaction used this nonce, so the collected signatures can never be executed
+	if info.Nonce.String() != proposal.Nonce {
+		return uc.updateProposalStatus(ctx, proposal, domain.StatusFailed)
+	}
+
+	signatures, err := uc.proposalRepo.GetSafeSignatures(ctx, proposal.ID)
+	if err != nil {
+		return domain.SafeProposalResponse{}, fmt.Errorf("failed to get Safe signatures: %w", err)
+	}
+
+	packed, err := packSafeSignatures(info, signatures)
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	safeTx, err := toSafeTransaction(proposal)
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	data, err := uc.safeRepo.EncodeExecTransaction(safeTx, packed)
+	if err != nil {
+		return domain.SafeProposalResponse{}, fmt.Errorf("failed to encode execTransaction: %w", err)
+	}
+
+	unsignedTx, err := uc.ethRepo.CreateUnsignedContractTransaction(ctx, common.HexToAddress(wallet.Address), safeTx.Safe, new(big.Int), data)
+	if err != nil {
+		return domain.SafeProposalResponse{}, fmt.Errorf("failed to create execTransaction: %w", err)
+	}
+
+	payload, err := unsignedTx.MarshalBinary()
+	if err != nil {
+		return domain.SafeProposalResponse{}, fmt.Errorf("failed to serialize execTransaction: %w", err)
+	}
+	unsigned := domain.UnsignedTransaction{Payload: payload, ToAddress: safeTx.Safe.Hex()}
+
+	createParams := domain.CreateTransactionParams{
+		ID:          uuid.New(),
+		WalletID:    wallet.ID,
+		ChainID:     proposal.ChainID,
+		FromAddress: wallet.Address,
+		ToAddress:   safeTx.Safe.Hex(),
+		Amount:      "0",
+		GasPrice:    unsignedTx.GasPrice().String(),
+		GasLimit:    fmt.Sprintf("%d", unsignedTx.Gas()),
+		Nonce:       int64(unsignedTx.Nonce()),
+		Status:      domain.StatusPending,
+	}
+	created, err := newCreatedMessage(uc.eventsTopic, createParams)
+	if err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+	transaction, err := uc.txnRepo.CreateTransaction(ctx, createParams, created)
+	if err != nil {
+		return domain.SafeProposalResponse{}, fmt.Errorf("failed to save transaction to database: %w", err)
+	}
+
+	// The fee is held back like that of any other transaction of the wallet. Without the funds the
+	// proposal stays pending, so it can be executed once the wallet is funded.
+	if err := uc.evmFamily.ReserveFunds(ctx, transaction, unsigned); err != nil {
+		return domain.SafeProposalResponse{}, uc.failTransaction(ctx, transaction, err)
+	}
+
+	privateKey, err := uc.walletUC.GetPrivateKey(ctx, wallet.UserID)
+	if err != nil {
+		return domain.SafeProposalResponse{}, uc.failTransaction(ctx, transaction, fmt.Errorf("failed to get private key: %w", err))
+	}
+
+	signedTx, err := uc.ethRepo.SignTransaction(ctx, unsignedTx, privateKey)
+	if err != nil {
+		return domain.SafeProposalResponse{}, uc.failTransaction(ctx, transaction, err)
+	}
+
+	signedPayload, err := signedTx.MarshalBinary()
+	if err != nil {
+		return domain.SafeProposalResponse{}, uc.failTransaction(ctx, transaction, fmt.Errorf("failed to serialize signed execTransaction: %w", err))
+	}
+
+	// Broadcast tops the wallet up from the relayer pool when it cannot pay for gas
+	submitted, err := uc.evmFamily.Broadcast(ctx, wallet.UserID, transaction, domain.SignedTransaction{Payload: signedPayload, TxHash: signedTx.Hash().Hex()})
+	if err != nil {
+		log.Printf("Failed to submit execTransaction for Safe proposal %s: %v", proposal.ID, err)
+		if err := uc.failTransaction(ctx, transaction, nil); err != nil {
+			return domain.SafeProposalResponse{}, err
+		}
+		proposal.ExecTransactionID = transaction.ID
+		return uc.updateProposalStatus(ctx, proposal, domain.StatusFailed)
+	}
+
+	submitted.Status = domain.StatusSubmitted
+	// The worker learns about the transaction from a message committed with its new status
+	if err := uc.saveTransaction(ctx, submitted, uc.txnTopic); err != nil {
+		return domain.SafeProposalResponse{}, err
+	}
+
+	proposal.ExecTransactionID = submitted.ID
+	proposal.ExecTxHash = submitted.TxHash
+	return uc.updateProposalStatus(ctx, proposal, domain.StatusSubmitted)
+}
+
+// failTransaction marks an exec transaction that will not reach the chain as failed and gives back
+// what was reserved for it. It returns err, the reason the transaction failed.
+func (uc *safeUseCase) failTransaction(ctx context.Context, transaction domain.Transaction, err error) error {
+	uc.evmFamily.ReleaseFunds(ctx, transaction)
+	transaction.Status = domain.StatusFailed
+	if saveErr := uc.saveTransaction(ctx, transaction); saveErr != nil {
+		if err == nil {
+			return saveErr
+		}
+		return fmt.Errorf("%w (original error: %v)", saveErr, err)
+	}
+	return err
+}
+
+// saveTransaction stores the exec transaction with its status event, and with a message on each
+// of topics.
+func (uc *safeUseCase) saveTransaction(ctx context.Context, transaction domain.Transaction, topics ...string) error {
+	messages := make([]domain.OutboxMessage, 0, len(topics)+1)
+	for _, topic := range topics {
+		message, err := newTxnMessage(topic, transaction)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+	}
+	event, err := events.NewTransactionMessage(uc.eventsTopic, transaction)
+	if err != nil {
+		return err
+	}
+	messages = append(messages, event)
+
+	if err := uc.txnRepo.UpdateTransaction(ctx, transaction, messages...); err != nil {
+		return fmt.Errorf("failed to update transaction in database: %w", err)
+	}
+	return nil
+}
+
+func (uc *safeUseCase) executeIfReady(ctx context.Context, proposal domain.SafeProposal) (domain.SafeProposalResponse, error) {
+	signatures, err := uc.proposalRepo.GetSafeSignatures(ctx, proposal.ID)
+	if err != nil {
+		return domain.SafeProposalResponse{}, fmt.Errorf("failed to get Safe signatures: %w", err)
+	}
+
+	if len(signatures) < proposal.Threshold {
+		return uc.toResponse(ctx, proposal)
+	}
+
+	response, err := uc.execute(ctx, proposal)
+	if err != nil {
+		// The signatures are stored, so execution can be retried from the execute endpoint
+		log.Printf("Failed to execute Safe proposal %s: %v", proposal.ID, err)
+		return uc.toResponse(ctx, proposal)
+	}
+	return response, nil
+}
+
+// syncExecStatus moves a submitted proposal to the final status of its exec transaction, once the
+// worker has settled it.
+func (uc *safeUseCase) syncExecStatus(ctx context.Context, proposal domain.SafeProposal) (domain.SafeProposal, error) {
+	if proposal.Status != domain.StatusSubmitted || proposal.ExecTransactionID == uuid.Nil {
+		return proposal, nil
+	}
+
+	transaction, err := uc.txnRepo.GetTransaction(ctx, proposal.ExecTransactionID)
+	if err != nil {
+		return domain.SafeProposal{}, fmt.Errorf("failed to get exec transaction: %w", err)
+	}
+	if transaction.Status == domain.StatusPending || transaction.Status == domain.StatusSubmitted {
+		return proposal, nil
+	}
+
+	proposal.Status = transaction.Status
+	if err := uc.proposalRepo.UpdateSafeProposal(ctx, proposal); err != nil {
+		return domain.SafeProposal{}, fmt.Errorf("failed to update Safe proposal: %w", err)
+	}
+	return proposal, nil
+}
+
+func (uc *safeUseCase) updateProposalStatus(ctx context.Context, proposal domain.SafeProposal, status domain.Status) (domain.SafeProposalResponse, error) {
+	proposal.Status = status
+	if err := uc.proposalRepo.UpdateSafeProposal(ctx, proposal); err != nil {
+		return domain.SafeProposalResponse{}, fmt.Errorf("failed to update Safe proposal: %w", err)
+	}
+	return uc.toResponse(ctx, proposal)
+}
+
+func (uc *safeUseCase) toResponse(ctx context.Context, proposal domain.SafeProposal) (domain.SafeProposalResponse, error) {
+	signatures, err := uc.proposalRepo.GetSafeSignatures(ctx, proposal.ID)
+	if err != nil {
+		return domain.SafeProposalResponse{}, fmt.Errorf("failed to get Safe signatures: %w", err)
+	}
+
+	signatureResponses := make([]domain.SafeSignatureResponse, 0, len(signatures))
+	for _, signature := range signatures {
+		signatureResponses = append(signatureResponses, domain.SafeSignatureResponse{
+			Owner:     signature.Owner,
+			Signature: hexutil.Encode(signature.Signature),
+		})
+	}
+
+	var execTransactionID *uuid.UUID
+	if proposal.ExecTransactionID != uuid.Nil {
+		execTransactionID = &proposal.ExecTransactionID
+	}
+
+	return domain.SafeProposalResponse{
+		ID:                proposal.ID,
+		WalletID:          proposal.WalletID,
+		ChainID:           proposal.ChainID,
+		SafeAddress:       proposal.SafeAddress,
+		ToAddress:         proposal.ToAddress,
+		Value:             proposal.Value,
+		Data:              hexutil.Encode(proposal.Data),
+		Nonce:             proposal.Nonce,
+		SafeTxHash:        proposal.SafeTxHash,
+		Threshold:         proposal.Threshold,
+		Status:            proposal.Status,
+		ExecTxHash:        proposal.ExecTxHash,
+		ExecTransactionID: execTransactionID,
+		Signatures:        signatureResponses,
+	}, nil
+}
+
+func toSafeTransaction(proposal domain.SafeProposal) (domain.SafeTransaction, error) {
+	value, ok := new(big.Int).SetString(proposal.Value, 10)
+	if !ok {
+		return domain.SafeTransaction{}, fmt.Errorf("invalid proposal value: %s", proposal.Value)
+	}
+	nonce, ok := new(big.Int).SetString(proposal.Nonce, 10)
+	if !ok {
+		return domain.SafeTransaction{}, fmt.Errorf("invalid proposal nonce: %s", proposal.Nonce)
+	}
+
+	return domain.SafeTransaction{
+		Safe:  common.HexToAddress(proposal.SafeAddress),
+		To:    common.HexToAddress(proposal.ToAddress),
+		Value: value,
+		Data:  proposal.Data,
+		Nonce: nonce,
+	}, nil
+}
+
+// packSafeSignatures concatenates signatures from current owners in ascending owner order,
+// which is what the Safe contract requires when checking them.
+func packSafeSignatures(info domain.SafeInfo, signatures []domain.SafeSignature) ([]byte, error) {
+	valid := make([]domain.SafeSignature, 0, len(signatures))
+	for _, signature := range signatures {
+		if isSafeOwner(info, common.HexToAddress(signature.Owner)) {
+			valid = append(valid, signature)
+		}
+	}
+
+	if len(valid) < info.Threshold {
+		return nil, fmt.Errorf("%w: %d of %d", ErrThresholdNotReached, len(valid), info.Threshold)
+	}
+
+	sort.Slice(valid, func(i, j int) bool {
+		return bytes.Compare(common.HexToAddress(valid[i].Owner).Bytes(), common.HexToAddress(valid[j].Owner).Bytes()) < 0
+	})
+
+	var packed []byte
+	for _, signature := range valid[:info.Threshold] {
+		packed = append(packed, signature.Signature...)
+	}
+	return packed, nil
+}
+
+func isSafeOwner(info domain.SafeInfo, address common.Address) bool {
+	for _, owner := range info.Owners {
+		if owner == address {
+			return true
+		}
+	}
+	return false
+}
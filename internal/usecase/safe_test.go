@@ -0,0 +1,378 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"mpc/internal/domain"
+	"mpc/internal/repository"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// stubSafeProposalRepo keeps proposals and their signatures in memory.
+type stubSafeProposalRepo struct {
+	repository.SafeProposalRepository
+	proposals  map[uuid.UUID]domain.SafeProposal
+	signatures map[uuid.UUID][]domain.SafeSignature
+}
+
+func (r *stubSafeProposalRepo) CreateSafeProposal(ctx context.Context, params domain.CreateSafeProposalParams) (domain.SafeProposal, error) {
+	proposal := domain.SafeProposal{ID: params.ID, WalletID: params.WalletID, SafeAddress: params.SafeAddress, ToAddress: params.ToAddress, Status: params.Status}
+	r.proposals[proposal.ID] = proposal
+	return proposal, nil
+}
+
+func (r *stubSafeProposalRepo) GetSafeProposal(ctx context.Context, id uuid.UUID) (domain.SafeProposal, error) {
+	proposal, ok := r.proposals[id]
+	if !ok {
+		return domain.SafeProposal{}, pgx.ErrNoRows
+	}
+	return proposal, nil
+}
+
+func (r *stubSafeProposalRepo) UpdateSafeProposal(ctx context.Context, proposal domain.SafeProposal) error {
+	r.proposals[proposal.ID] = proposal
+	return nil
+}
+
+func (r *stubSafeProposalRepo) GetSafeSignatures(ctx context.Context, proposalID uuid.UUID) ([]domain.SafeSignature, error) {
+	return r.signatures[proposalID], nil
+}
+
+// stubSafe is a Safe whose owners and nonce are fixed.
+type stubSafe struct {
+	repository.SafeRepository
+	info domain.SafeInfo
+}
+
+func (s *stubSafe) GetSafeInfo(ctx context.Context, safe common.Address) (domain.SafeInfo, error) {
+	return s.info, nil
+}
+
+func (s *stubSafe) EncodeExecTransaction(tx domain.SafeTransaction, signatures []byte) ([]byte, error) {
+	return signatures, nil
+}
+
+// stubSafeEth builds and signs exec transactions without a node.
+type stubSafeEth struct {
+	repository.EthereumRepository
+}
+
+func (s *stubSafeEth) CreateUnsignedContractTransaction(ctx context.Context, from common.Address, to common.Address, amount *big.Int, data []byte) (*types.Transaction, error) {
+	return types.NewTx(&types.LegacyTx{Nonce: 7, To: &to, Value: amount, Gas: 100_000, GasPrice: big.NewInt(gwei), Data: data}), nil
+}
+
+func (s *stubSafeEth) SignTransaction(ctx context.Context, tx *types.Transaction, privateKey *ecdsa.PrivateKey) (*types.Transaction, error) {
+	return tx, nil
+}
+
+// stubSafeTxnRepo keeps the exec transactions in memory.
+type stubSafeTxnRepo struct {
+	repository.TransactionRepository
+	transactions map[uuid.UUID]domain.Transaction
+}
+
+func (r *stubSafeTxnRepo) CreateTransaction(ctx context.Context, params domain.CreateTransactionParams, messages ...domain.OutboxMessage) (domain.Transaction, error) {
+	txn := domain.Transaction{ID: params.ID, WalletID: params.WalletID, ChainID: params.ChainID, FromAddress: params.FromAddress, ToAddress: params.ToAddress, Amount: params.Amount, Status: params.Status}
+	r.transactions[txn.ID] = txn
+	return txn, nil
+}
+
+func (r *stubSafeTxnRepo) UpdateTransaction(ctx context.Context, txn domain.Transaction, messages ...domain.OutboxMessage) error {
+	r.transactions[txn.ID] = txn
+	return nil
+}
+
+// stubSafeWallets holds the wallets of users, one per user.
+type stubSafeWallets struct {
+	WalletUseCase
+	wallets []domain.Wallet
+}
+
+func (w *stubSafeWallets) GetWallet(ctx context.Context, id uuid.UUID) (domain.Wallet, error) {
+	for _, wallet := range w.wallets {
+		if wallet.ID == id {
+			return wallet, nil
+		}
+	}
+	return domain.Wallet{}, pgx.ErrNoRows
+}
+
+func (w *stubSafeWallets) GetWalletByUserID(ctx context.Context, userID uuid.UUID) (domain.Wallet, error) {
+	for _, wallet := range w.wallets {
+		if wallet.UserID == userID {
+			return wallet, nil
+		}
+	}
+	return domain.Wallet{}, pgx.ErrNoRows
+}
+
+func (w *stubSafeWallets) GetPrivateKey(ctx context.Context, userID uuid.UUID) (*ecdsa.PrivateKey, error) {
+	return nil, nil
+}
+
+// stubEVMFamily records the reservations and broadcasts of exec transactions.
+type stubEVMFamily struct {
+	ChainFamily
+	reserveErr   error
+	broadcastErr error
+	reserved     []uuid.UUID
+	released     []uuid.UUID
+	broadcast    []uuid.UUID
+}
+
+func (f *stubEVMFamily) ReserveFunds(ctx context.Context, txn domain.Transaction, unsigned domain.UnsignedTransaction) error {
+	if f.reserveErr != nil {
+		return f.reserveErr
+	}
+	f.reserved = append(f.reserved, txn.ID)
+	return nil
+}
+
+func (f *stubEVMFamily) ReleaseFunds(ctx context.Context, txn domain.Transaction) {
+	f.released = append(f.released, txn.ID)
+}
+
+func (f *stubEVMFamily) Broadcast(ctx context.Context, userID uuid.UUID, txn domain.Transaction, signed domain.SignedTransaction) (domain.Transaction, error) {
+	if f.broadcastErr != nil {
+		return domain.Transaction{}, f.broadcastErr
+	}
+	f.broadcast = append(f.broadcast, txn.ID)
+	txn.TxHash = signed.TxHash
+	return txn, nil
+}
+
+// safeFixture is a Safe owned by the wallets of the proposer and a co-owner, with a pending
+// proposal of the proposer that has reached its threshold of one signature.
+type safeFixture struct {
+	proposer, coOwner, outsider domain.Wallet
+	proposal                    domain.SafeProposal
+	proposalRepo                *stubSafeProposalRepo
+	txnRepo                     *stubSafeTxnRepo
+	family                      *stubEVMFamily
+	uc                          SafeUseCase
+}
+
+func newSafeFixture() *safeFixture {
+	f := &safeFixture{
+		proposer: domain.Wallet{ID: uuid.New(), UserID: uuid.New(), Address: "0x1000000000000000000000000000000000000001"},
+		coOwner:  domain.Wallet{ID: uuid.New(), UserID: uuid.New(), Address: "0x7000000000000000000000000000000000000007"},
+		outsider: domain.Wallet{ID: uuid.New(), UserID: uuid.New(), Address: "0xf00000000000000000000000000000000000000f"},
+		family:   &stubEVMFamily{},
+		txnRepo:  &stubSafeTxnRepo{transactions: map[uuid.UUID]domain.Transaction{}},
+	}
+	f.proposal = domain.SafeProposal{
+		ID:          uuid.New(),
+		WalletID:    f.proposer.ID,
+		ChainID:     uuid.New(),
+		SafeAddress: "0x00000000000000000000000000000000000005af",
+		ToAddress:   "0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045",
+		Value:       "0",
+		Nonce:       "3",
+		Threshold:   1,
+		Status:      domain.StatusPending,
+	}
+	f.proposalRepo = &stubSafeProposalRepo{
+		proposals:  map[uuid.UUID]domain.SafeProposal{f.proposal.ID: f.proposal},
+		signatures: map[uuid.UUID][]domain.SafeSignature{f.proposal.ID: {{Owner: f.proposer.Address, Signature: []byte{1}}}},
+	}
+	safe := &stubSafe{info: domain.SafeInfo{
+		Owners:    []common.Address{common.HexToAddress(f.proposer.Address), common.HexToAddress(f.coOwner.Address)},
+		Threshold: 1,
+		Nonce:     big.NewInt(3),
+	}}
+	wallets := &stubSafeWallets{wallets: []domain.Wallet{f.proposer, f.coOwner, f.outsider}}
+	f.uc = NewSafeUC(f.proposalRepo, safe, &stubSafeEth{}, f.txnRepo, stubENS{}, wallets, f.family, "transactions", "events")
+	return f
+}
+
+func TestGetProposalAccess(t *testing.T) {
+	f := newSafeFixture()
+
+	tests := []struct {
+		name       string
+		userID     uuid.UUID
+		proposalID uuid.UUID
+		wantErr    error
+	}{
+		{name: "proposer", userID: f.proposer.UserID, proposalID: f.proposal.ID},
+		{name: "co-owner of the Safe", userID: f.coOwner.UserID, proposalID: f.proposal.ID},
+		{name: "wallet that does not own the Safe", userID: f.outsider.UserID, proposalID: f.proposal.ID, wantErr: ErrWalletNotFound},
+		{name: "user without a wallet", userID: uuid.New(), proposalID: f.proposal.ID, wantErr: ErrWalletNotFound},
+		{name: "unknown proposal", userID: f.proposer.UserID, proposalID: uuid.New(), wantErr: ErrWalletNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := f.uc.GetProposal(context.Background(), tt.userID, tt.proposalID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("GetProposal() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("GetProposal() error = %v", err)
+			}
+			if got.ID != tt.proposalID {
+				t.Errorf("GetProposal() = %s, want %s", got.ID, tt.proposalID)
+			}
+		})
+	}
+}
+
+func TestProposeTransactionRecipient(t *testing.T) {
+	for _, recipient := range []string{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", "0x1234", "bob.eth"} {
+		t.Run(recipient, func(t *testing.T) {
+			f := newSafeFixture()
+			_, err := f.uc.ProposeTransaction(context.Background(), f.proposer.UserID, domain.CreateSafeProposalRequest{
+				WalletID:    f.proposer.ID,
+				SafeAddress: f.proposal.SafeAddress,
+				ToAddress:   recipient,
+				Amount:      "1",
+			})
+			if !errors.Is(err, ErrInvalidAddress) {
+				t.Fatalf("ProposeTransaction() error = %v, want %v", err, ErrInvalidAddress)
+			}
+			if len(f.proposalRepo.proposals) != 1 {
+				t.Errorf("ProposeTransaction() stored a proposal for an invalid recipient")
+			}
+		})
+	}
+}
+
+func TestExecuteProposal(t *testing.T) {
+	tests := []struct {
+		name         string
+		reserveErr   error
+		broadcastErr error
+		wantErr      error
+		wantProposal domain.Status
+		wantTxn      domain.Status
+	}{
+		{
+			name:         "reserved and broadcast",
+			wantProposal: domain.StatusSubmitted,
+			wantTxn:      domain.StatusSubmitted,
+		},
+		{
+			// Nothing reached the chain, so the proposal can be executed once the wallet is funded
+			name:         "fee not covered",
+			reserveErr:   fmt.Errorf("%w: need 100000 gwei", ErrInsufficientBalance),
+			wantErr:      ErrInsufficientBalance,
+			wantProposal: domain.StatusPending,
+			wantTxn:      domain.StatusFailed,
+		},
+		{
+			name:         "broadcast fails",
+			broadcastErr: ErrSponsorshipBudgetExceeded,
+			wantProposal: domain.StatusFailed,
+			wantTxn:      domain.StatusFailed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newSafeFixture()
+			f.family.reserveErr = tt.reserveErr
+			f.family.broadcastErr = tt.broadcastErr
+
+			_, err := f.uc.ExecuteProposal(context.Background(), f.proposer.UserID, f.proposal.ID, nil)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ExecuteProposal() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatalf("ExecuteProposal() error = %v", err)
+			}
+
+			if got := f.proposalRepo.proposals[f.proposal.ID].Status; got != tt.wantProposal {
+				t.Errorf("Proposal is %s, want %s", got, tt.wantProposal)
+			}
+			if len(f.txnRepo.transactions) != 1 {
+				t.Fatalf("Stored %d exec transactions, want 1", len(f.txnRepo.transactions))
+			}
+			for id, txn := range f.txnRepo.transactions {
+				if txn.Status != tt.wantTxn {
+					t.Errorf("Exec transaction is %s, want %s", txn.Status, tt.wantTxn)
+				}
+				if tt.wantTxn == domain.StatusSubmitted {
+					if len(f.family.reserved) != 1 || len(f.family.broadcast) != 1 || len(f.family.released) != 0 {
+						t.Errorf("Reserved %v, broadcast %v and released %v, want %s reserved and broadcast", f.family.reserved, f.family.broadcast, f.family.released, id)
+					}
+				} else if len(f.family.released) != 1 || f.family.released[0] != id {
+					t.Errorf("Released %v, want %s", f.family.released, id)
+				}
+			}
+		})
+	}
+}
+
+func TestPackSafeSignatures(t *testing.T) {
+	low := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	mid := common.HexToAddress("0x7000000000000000000000000000000000000007")
+	high := common.HexToAddress("0xf00000000000000000000000000000000000000f")
+	outsider := common.HexToAddress("0x0000000000000000000000000000000000000abc")
+
+	// Each signature is a single byte naming its owner, so the packed order is easy to read
+	sig := func(owner common.Address, b byte) domain.SafeSignature {
+		return domain.SafeSignature{Owner: owner.Hex(), Signature: []byte{b}}
+	}
+
+	tests := []struct {
+		name       string
+		threshold  int
+		signatures []domain.SafeSignature
+		want       []byte
+		wantErr    error
+	}{
+		{
+			name:       "sorted by ascending owner",
+			threshold:  3,
+			signatures: []domain.SafeSignature{sig(high, 'h'), sig(low, 'l'), sig(mid, 'm')},
+			want:       []byte("lmh"),
+		},
+		{
+			name:       "only the threshold is packed",
+			threshold:  2,
+			signatures: []domain.SafeSignature{sig(high, 'h'), sig(mid, 'm'), sig(low, 'l')},
+			want:       []byte("lm"),
+		},
+		{
+			name:       "signatures of removed owners are skipped",
+			threshold:  2,
+			signatures: []domain.SafeSignature{sig(outsider, 'o'), sig(high, 'h'), sig(mid, 'm')},
+			want:       []byte("mh"),
+		},
+		{
+			name:       "threshold not reached",
+			threshold:  2,
+			signatures: []domain.SafeSignature{sig(outsider, 'o'), sig(high, 'h')},
+			wantErr:    ErrThresholdNotReached,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := domain.SafeInfo{Owners: []common.Address{high, low, mid}, Threshold: tt.threshold}
+			got, err := packSafeSignatures(info, tt.signatures)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("packSafeSignatures() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("packSafeSignatures() error = %v", err)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("packSafeSignatures() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
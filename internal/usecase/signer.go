@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"crypto/ecdsa"
+	"fmt"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/bitcoin"
+	"mpc/internal/infrastructure/eddsa"
+
+	"github.com/btcsuite/btcd/btcec/v2/schnorr"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// signPayloads signs each payload with the wallet key, or with the child of it the payload names.
+func signPayloads(key *ecdsa.PrivateKey, payloads []domain.SigningPayload) ([][]byte, error) {
+	signatures := make([][]byte, 0, len(payloads))
+	for i, payload := range payloads {
+		signature, err := signPayload(key, payload)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign payload %d: %w", i, err)
+		}
+		signatures = append(signatures, signature)
+	}
+	return signatures, nil
+}
+
+func signPayload(key *ecdsa.PrivateKey, payload domain.SigningPayload) ([]byte, error) {
+	if payload.Scheme == domain.SignatureSchemeEd25519 {
+		return eddsa.NewLocalSigner(eddsa.WalletKey(key)).Sign(payload.Digest)
+	}
+
+	signingKey := bitcoin.PrivateKey(key)
+	if len(payload.Path) > 0 {
+		child, err := bitcoin.DeriveKey(signingKey, payload.ChainCode, payload.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to derive key: %w", err)
+		}
+		signingKey = child
+	}
+
+	switch payload.Scheme {
+	case domain.SignatureSchemeECDSA:
+		return crypto.Sign(payload.Digest, signingKey.ToECDSA())
+	case domain.SignatureSchemeSchnorr:
+		if payload.TaprootTweak {
+			signingKey = txscript.TweakTaprootPrivKey(*signingKey, nil)
+		}
+		signature, err := schnorr.Sign(signingKey, payload.Digest)
+		if err != nil {
+			return nil, err
+		}
+		return signature.Serialize(), nil
+	default:
+		return nil, fmt.Errorf("unsupported signature scheme %q", payload.Scheme)
+	}
+}
@@ -0,0 +1,142 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mpc/internal/domain"
+	"mpc/internal/infrastructure/events"
+	"mpc/internal/infrastructure/kafka"
+	"mpc/internal/repository"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/google/uuid"
+)
+
+type SmartAccountUseCase interface {
+	GetSmartAccount(ctx context.Context, userID uuid.UUID, walletID uuid.UUID) (domain.SmartAccountResponse, error)
+	SendUserOperation(ctx context.Context, userID uuid.UUID, params domain.CreateUserOpRequest) (domain.UserOperationTxn, error)
+}
+
+type smartAccountUseCase struct {
+	userOpRepo       repository.UserOperationRepository
+	smartAccountRepo repository.SmartAccountRepository
+	walletUC         WalletUseCase
+	userOpPublisher  kafka.Publisher
+}
+
+func NewSmartAccountUC(userOpRepo repository.UserOperationRepository, smartAccountRepo repository.SmartAccountRepository, walletUC WalletUseCase, userOpPublisher kafka.Publisher) SmartAccountUseCase {
+	return &smartAccountUseCase{userOpRepo: userOpRepo, smartAccountRepo: smartAccountRepo, walletUC: walletUC, userOpPublisher: userOpPublisher}
+}
+
+var _ SmartAccountUseCase = (*smartAccountUseCase)(nil)
+
+// GetSmartAccount returns the smart account controlled by the wallet key.
+func (uc *smartAccountUseCase) GetSmartAccount(ctx context.Context, userID uuid.UUID, walletID uuid.UUID) (domain.SmartAccountResponse, error) {
+	wallet, err := getOwnedWallet(ctx, uc.walletUC, userID, walletID)
+	if err != nil {
+		return domain.SmartAccountResponse{}, err
+	}
+
+	owner := common.HexToAddress(wallet.Address)
+	account, err := uc.smartAccountRepo.GetAccountAddress(ctx, owner)
+	if err != nil {
+		return domain.SmartAccountResponse{}, err
+	}
+
+	deployed, err := uc.smartAccountRepo.IsDeployed(ctx, account)
+	if err != nil {
+		return domain.SmartAccountResponse{}, err
+	}
+
+	return domain.SmartAccountResponse{
+		WalletID: wallet.ID,
+		Owner:    owner.Hex(),
+		Address:  account.Hex(),
+		Deployed: deployed,
+	}, nil
+}
+
+// SendUserOperation builds a user operation for the wallet's smart account, signs it with the
+// wallet key, hands it to the bundler and queues it for receipt tracking.
+func (uc *smartAccountUseCase) SendUserOperation(ctx context.Context, userID uuid.UUID, params domain.CreateUserOpRequest) (domain.UserOperationTxn, error) {
+	wallet, err := getOwnedWallet(ctx, uc.walletUC, userID, params.WalletID)
+	if err != nil {
+		return domain.UserOperationTxn{}, err
+	}
+
+	amountInWei, err := toWei(params.Amount)
+	if err != nil {
+		return domain.UserOperationTxn{}, err
+	}
+
+	var data []byte
+	if params.Data != "" {
+		data, err = hexutil.Decode(params.Data)
+		if err != nil {
+			return domain.UserOperationTxn{}, fmt.Errorf("invalid call data: %w", err)
+		}
+	}
+
+	owner := common.HexToAddress(wallet.Address)
+	to := common.HexToAddress(params.ToAddress)
+
+	userOp, err := uc.smartAccountRepo.BuildUserOperation(ctx, owner, to, amountInWei, data)
+	if err != nil {
+		return domain.UserOperationTxn{}, fmt.Errorf("failed to build user operation: %w", err)
+	}
+
+	privateKey, err := uc.walletUC.GetPrivateKey(ctx, userID)
+	if err != nil {
+		return domain.UserOperationTxn{}, fmt.Errorf("failed to get private key: %w", err)
+	}
+
+	if _, err := uc.smartAccountRepo.SignUserOperation(ctx, userOp, privateKey); err != nil {
+		return domain.UserOperationTxn{}, err
+	}
+
+	userOpHash, err := uc.smartAccountRepo.SendUserOperation(ctx, userOp)
+	if err != nil {
+		return domain.UserOperationTxn{}, err
+	}
+
+	userOpTxn, err := uc.userOpRepo.CreateUserOperation(ctx, domain.CreateUserOperationTxnParams{
+		ID:         uuid.New(),
+		WalletID:   wallet.ID,
+		ChainID:    params.ChainID,
+		Sender:     userOp.Sender.Hex(),
+		Nonce:      userOp.Nonce.String(),
+		ToAddress:  to.Hex(),
+		Amount:     params.Amount,
+		UserOpHash: userOpHash.Hex(),
+		Status:     domain.StatusSubmitted,
+	})
+	if err != nil {
+		return domain.UserOperationTxn{}, fmt.Errorf("failed to save user operation to database: %w", err)
+	}
+
+	uc.publishMessage(ctx, userOpTxn)
+
+	return userOpTxn, nil
+}
+
+func (uc *smartAccountUseCase) publishMessage(ctx context.Context, userOp domain.UserOperationTxn) {
+	message := domain.UserOpMessage{
+		ChainID:    userOp.ChainID,
+		UserOpHash: userOp.UserOpHash,
+	}
+
+	messageJSON, err := events.Encode(domain.EventUserOperationSubmitted, message)
+	if err != nil {
+		log.Printf("Failed to encode user operation message: %v", err)
+		return
+	}
+
+	if err := uc.userOpPublisher.Publish(ctx, kafka.Message{
+		Key:   []byte(userOp.ID.String()),
+		Value: messageJSON,
+	}); err != nil {
+		log.Printf("Failed to publish message to Kafka: %v", err)
+	}
+}
@@ -7,116 +7,123 @@ import (
 	"crypto/cipher"
 	"crypto/rand"
 	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"log"
-	"math/big"
 	"mpc/internal/domain"
+	"mpc/internal/infrastructure/events"
 	"mpc/internal/infrastructure/redis"
 	"mpc/internal/repository"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/google/uuid"
-	"github.com/segmentio/kafka-go"
 )
 
 type TxnUseCase interface {
 	CreateTransaction(ctx context.Context, userID uuid.UUID, params domain.CreateTxnRequest) (uuid.UUID, error)
 	SubmitTransaction(ctx context.Context, userId uuid.UUID, txnId uuid.UUID) (domain.Transaction, error)
+	CreateBatchTransaction(ctx context.Context, userID uuid.UUID, params domain.CreateBatchTxnRequest) (domain.BatchTxnResponse, error)
 	GetTransactions(ctx context.Context, walletID uuid.UUID) ([]domain.Transaction, error)
 }
 
+// txnUseCase runs the transaction lifecycle shared by every chain: build, reserve funds, sign,
+// broadcast and track. What each step means on a chain is up to its ChainFamily.
 type txnUseCase struct {
-	txnRepo       repository.TransactionRepository
-	ethRepo       repository.EthereumRepository
-	walletUC      WalletUseCase
-	redisClient   redis.RedisClient
-	kafkaProducer *kafka.Writer
+	txnRepo     repository.TransactionRepository
+	chainRepo   repository.ChainRepository
+	walletUC    WalletUseCase
+	families    *ChainFamilies
+	redisClient redis.RedisClient
+	// txnTopic is the Kafka topic submitted transactions are announced on, through the outbox.
+	txnTopic string
+	// eventsTopic is the public Kafka topic every state change of a transaction is announced on.
+	eventsTopic string
 }
 
-func NewTxnUC(txnRepo repository.TransactionRepository, ethRepo repository.EthereumRepository, walletUC WalletUseCase, redisClient redis.RedisClient, kafkaProducer *kafka.Writer) TxnUseCase {
-	return &txnUseCase{txnRepo: txnRepo, ethRepo: ethRepo, walletUC: walletUC, redisClient: redisClient, kafkaProducer: kafkaProducer}
+func NewTxnUC(txnRepo repository.TransactionRepository, chainRepo repository.ChainRepository, walletUC WalletUseCase, families *ChainFamilies, redisClient redis.RedisClient, txnTopic string, eventsTopic string) TxnUseCase {
+	return &txnUseCase{txnRepo: txnRepo, chainRepo: chainRepo, walletUC: walletUC, families: families, redisClient: redisClient, txnTopic: txnTopic, eventsTopic: eventsTopic}
 }
 
 var _ TxnUseCase = (*txnUseCase)(nil)
 
-// CreateTransaction creates a new transaction and stores it in the database.
+// CreateTransaction builds an unsigned transaction, stores it in the database and reserves the funds
+// it spends. The encrypted payload waits in Redis until the transaction is submitted.
 func (uc *txnUseCase) CreateTransaction(ctx context.Context, userID uuid.UUID, params domain.CreateTxnRequest) (uuid.UUID, error) {
-	wallet, err := uc.walletUC.GetWallet(ctx, params.WalletID)
+	wallet, err := getOwnedWallet(ctx, uc.walletUC, userID, params.WalletID)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to get wallet: %w", err)
+		return uuid.Nil, err
 	}
 
-	fromAddress := common.HexToAddress(wallet.Address)
-	toAddress := common.HexToAddress(params.ToAddress)
-
-	// Convert amount string to big.Float first, then to big.Int
-	amountFloat, _, err := new(big.Float).Parse(params.Amount, 10)
+	chain, family, err := uc.chainFamily(ctx, params.ChainID, params.ChainFamily)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("invalid amount: %s", params.Amount)
+		return uuid.Nil, err
 	}
 
-	// Multiply by 1e18 to convert to Wei
-	weiFloat := new(big.Float).Mul(amountFloat, big.NewFloat(1e18))
+	unsigned, err := family.BuildTransaction(ctx, userID, chain, wallet, params)
+	if err != nil {
+		return uuid.Nil, err
+	}
 
-	// Convert to big.Int, truncating any fractional part
-	amountInWei, _ := weiFloat.Int(nil)
+	createParams := domain.CreateTransactionParams{
+		ID:        uuid.New(),
+		WalletID:  params.WalletID,
+		ChainID:   params.ChainID,
+		Amount:    params.Amount,
+		ToAddress: unsigned.ToAddress,
+		ToENSName: unsigned.ToENSName,
+		TokenID:   params.TokenID,
+		Status:    domain.StatusPending,
+	}
+	created, err := newCreatedMessage(uc.eventsTopic, createParams)
+	if err != nil {
+		return uuid.Nil, err
+	}
 
-	unsignedTx, err := uc.ethRepo.CreateUnsignedTransaction(fromAddress, toAddress, amountInWei)
+	transaction, err := uc.txnRepo.CreateTransaction(ctx, createParams, created)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to create unsigned transaction: %w", err)
+		return uuid.Nil, fmt.Errorf("failed to save transaction to database: %w", err)
 	}
 
-	// Print useful information about the unsigned transaction
-	fmt.Printf("CreateTransaction: unsignedTx details:\n")
-	fmt.Printf("  To: %s\n", unsignedTx.To().Hex())
-	fmt.Printf("  Value: %s\n", unsignedTx.Value().String())
-	fmt.Printf("  Gas: %d\n", unsignedTx.Gas())
-	fmt.Printf("  GasPrice: %s\n", unsignedTx.GasPrice().String())
-	fmt.Printf("  Nonce: %d\n", unsignedTx.Nonce())
+	// Hold back the funds so other transactions cannot spend them
+	if err := family.ReserveFunds(ctx, transaction, unsigned); err != nil {
+		_, err = uc.updateTransactionStatus(ctx, transaction.ID, domain.StatusFailed, err)
+		return uuid.Nil, err
+	}
 
-	// Serialize the unsigned transaction
-	unsignedTxData, err := unsignedTx.MarshalBinary()
+	// Encrypt the unsigned transaction data
+	encryptedData, err := uc.encryptData(unsigned.Payload)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to serialize unsigned transaction: %w", err)
+		_, err = uc.updateTransactionStatus(ctx, transaction.ID, domain.StatusFailed, fmt.Errorf("failed to encrypt transaction data: %w", err))
+		return uuid.Nil, err
 	}
 
-	// Encrypt the unsigned transaction data
-	encryptedData, err := uc.encryptData(unsignedTxData)
+	err = uc.redisClient.Set(ctx, fmt.Sprintf("transaction:%s", transaction.ID), encryptedData, 24*time.Hour)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to encrypt transaction data: %w", err)
+		_, err = uc.updateTransactionStatus(ctx, transaction.ID, domain.StatusFailed, fmt.Errorf("failed to save encrypted transaction to Redis: %w", err))
+		return uuid.Nil, err
 	}
 
-	txID := uuid.New()
-	err = uc.redisClient.Set(ctx, fmt.Sprintf("transaction:%s", txID), encryptedData, 24*time.Hour)
+	return transaction.ID, nil
+}
+
+// SubmitTransaction signs the payloads of a created transaction with the wallet key and broadcasts
+// it to its chain.
+func (uc *txnUseCase) SubmitTransaction(ctx context.Context, userId uuid.UUID, txnId uuid.UUID) (domain.Transaction, error) {
+	transaction, err := uc.txnRepo.GetTransaction(ctx, txnId)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to save encrypted transaction to Redis: %w", err)
+		return domain.Transaction{}, fmt.Errorf("failed to get transaction from database: %w", err)
 	}
 
-	transaction := domain.CreateTransactionParams{
-		ID:        txID,
-		WalletID:  params.WalletID,
-		ChainID:   params.ChainID,
-		Amount:    params.Amount,
-		ToAddress: params.ToAddress,
-		TokenID:   params.TokenID,
-		Status:    domain.StatusPending,
+	if _, err := getOwnedWallet(ctx, uc.walletUC, userId, transaction.WalletID); err != nil {
+		return domain.Transaction{}, err
 	}
 
-	_, err = uc.txnRepo.CreateTransaction(ctx, transaction)
+	_, family, err := uc.chainFamily(ctx, transaction.ChainID, "")
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("failed to save transaction to database: %w", err)
+		return domain.Transaction{}, err
 	}
 
-	return txID, nil
-}
-
-// SubmitTransaction signs and submits a transaction to the Ethereum network.
-func (uc *txnUseCase) SubmitTransaction(ctx context.Context, userId uuid.UUID, txnId uuid.UUID) (domain.Transaction, error) {
 	// Retrieve the encrypted transaction from Redis
 	encryptedData, err := uc.redisClient.Get(ctx, fmt.Sprintf("transaction:%s", txnId))
 	if err != nil {
@@ -124,15 +131,15 @@ func (uc *txnUseCase) SubmitTransaction(ctx context.Context, userId uuid.UUID, t
 	}
 
 	// Decrypt the transaction data
-	unsignedTxData, err := uc.decryptData(encryptedData)
+	payload, err := uc.decryptData(encryptedData)
 	if err != nil {
 		return domain.Transaction{}, fmt.Errorf("failed to decrypt transaction data: %w", err)
 	}
+	unsigned := domain.UnsignedTransaction{Payload: payload, ToAddress: transaction.ToAddress, ToENSName: transaction.ToENSName}
 
-	var unsignedTx types.Transaction
-	err = unsignedTx.UnmarshalBinary(unsignedTxData)
+	payloads, err := family.SigningPayloads(ctx, userId, transaction, unsigned)
 	if err != nil {
-		return domain.Transaction{}, fmt.Errorf("failed to deserialize unsigned transaction: %w", err)
+		return uc.updateTransactionStatus(ctx, txnId, domain.StatusFailed, err)
 	}
 
 	// Get private key from user
@@ -141,25 +148,41 @@ func (uc *txnUseCase) SubmitTransaction(ctx context.Context, userId uuid.UUID, t
 		return domain.Transaction{}, fmt.Errorf("failed to get private key: %w", err)
 	}
 
-	signedTx, err := uc.ethRepo.SignTransaction(&unsignedTx, privateKey)
+	signatures, err := signPayloads(privateKey, payloads)
 	if err != nil {
 		return uc.updateTransactionStatus(ctx, txnId, domain.StatusFailed, err)
 	}
 
-	txHash, err := uc.ethRepo.SubmitTransaction(signedTx)
+	signed, err := family.AssembleTransaction(ctx, unsigned, signatures)
 	if err != nil {
 		return uc.updateTransactionStatus(ctx, txnId, domain.StatusFailed, err)
 	}
 
-	transaction, err := uc.txnRepo.GetTransaction(ctx, txnId)
+	transaction.TxHash = signed.TxHash
+	signedMessage, err := events.NewWalletMessage(uc.eventsTopic, transaction.WalletID, domain.EventTransactionSigned, domain.NewTransactionEvent(transaction))
 	if err != nil {
-		return domain.Transaction{}, fmt.Errorf("failed to get transaction from database: %w", err)
+		return domain.Transaction{}, err
+	}
+	if err := uc.txnRepo.UpdateTransaction(ctx, transaction, signedMessage); err != nil {
+		return domain.Transaction{}, fmt.Errorf("failed to update transaction in database: %w", err)
 	}
 
-	transaction.Status = domain.StatusSubmitted
-	transaction.TxHash = txHash.Hex()
+	transaction, err = family.Broadcast(ctx, userId, transaction, signed)
+	if err != nil {
+		return uc.updateTransactionStatus(ctx, txnId, domain.StatusFailed, err)
+	}
 
-	if err := uc.txnRepo.UpdateTransaction(ctx, transaction); err != nil {
+	transaction.Status = domain.StatusSubmitted
+	// The worker learns about the transaction from a message committed with its new status
+	message, err := newTxnMessage(uc.txnTopic, transaction)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	submitted, err := events.NewTransactionMessage(uc.eventsTopic, transaction)
+	if err != nil {
+		return domain.Transaction{}, err
+	}
+	if err := uc.txnRepo.UpdateTransaction(ctx, transaction, message, submitted); err != nil {
 		return domain.Transaction{}, fmt.Errorf("failed to update transaction in database: %w", err)
 	}
 
@@ -167,16 +190,56 @@ func (uc *txnUseCase) SubmitTransaction(ctx context.Context, userId uuid.UUID, t
 		log.Printf("Failed to delete submitted transaction from Redis: %v", err)
 	}
 
-	uc.publishMessage(ctx, txnId, transaction.ChainID, txHash)
+	if err := family.TrackTransaction(ctx, transaction); err != nil {
+		log.Printf("Failed to track transaction %s: %v", txnId, err)
+	}
 
 	return transaction, nil
 }
 
+// CreateBatchTransaction pays every recipient of the batch from one wallet, on chains whose family
+// supports batch transfers.
+func (uc *txnUseCase) CreateBatchTransaction(ctx context.Context, userID uuid.UUID, params domain.CreateBatchTxnRequest) (domain.BatchTxnResponse, error) {
+	wallet, err := getOwnedWallet(ctx, uc.walletUC, userID, params.WalletID)
+	if err != nil {
+		return domain.BatchTxnResponse{}, err
+	}
+
+	chain, family, err := uc.chainFamily(ctx, params.ChainID, "")
+	if err != nil {
+		return domain.BatchTxnResponse{}, err
+	}
+	batchFamily, ok := family.(BatchChainFamily)
+	if !ok {
+		return domain.BatchTxnResponse{}, fmt.Errorf("%w: %s chains do not support batch transfers", ErrUnsupportedChain, chain.Family)
+	}
+
+	return batchFamily.CreateBatchTransaction(ctx, userID, wallet, params)
+}
+
 // GetTransactions retrieves all transactions for a given user ID.
 func (uc *txnUseCase) GetTransactions(ctx context.Context, walletID uuid.UUID) ([]domain.Transaction, error) {
 	return uc.txnRepo.GetTransactionsByWalletID(ctx, walletID)
 }
 
+// chainFamily returns the chain and the implementation of its family, making sure the family is the
+// requested one when there is one.
+func (uc *txnUseCase) chainFamily(ctx context.Context, chainID uuid.UUID, requested domain.ChainFamily) (domain.Chain, ChainFamily, error) {
+	chain, err := uc.chainRepo.GetChain(ctx, chainID)
+	if err != nil {
+		return domain.Chain{}, nil, fmt.Errorf("failed to get chain: %w", err)
+	}
+	if requested != "" && requested != chain.Family {
+		return domain.Chain{}, nil, fmt.Errorf("%w: %s is a %s chain, not %s", ErrUnsupportedChain, chain.Name, chain.Family, requested)
+	}
+
+	family, err := uc.families.Get(chain.Family)
+	if err != nil {
+		return domain.Chain{}, nil, err
+	}
+	return chain, family, nil
+}
+
 func (uc *txnUseCase) updateTransactionStatus(ctx context.Context, id uuid.UUID, status domain.Status, err error) (domain.Transaction, error) {
 	transaction, dbErr := uc.txnRepo.GetTransaction(ctx, id)
 	if dbErr != nil {
@@ -184,8 +247,17 @@ func (uc *txnUseCase) updateTransactionStatus(ctx context.Context, id uuid.UUID,
 	}
 
 	transaction.Status = status
+	if status == domain.StatusFailed {
+		if _, family, familyErr := uc.chainFamily(ctx, transaction.ChainID, ""); familyErr == nil {
+			family.ReleaseFunds(ctx, transaction)
+		}
+	}
 
-	if dbErr := uc.txnRepo.UpdateTransaction(ctx, transaction); dbErr != nil {
+	message, dbErr := events.NewTransactionMessage(uc.eventsTopic, transaction)
+	if dbErr != nil {
+		return domain.Transaction{}, fmt.Errorf("%w (original error: %v)", dbErr, err)
+	}
+	if dbErr := uc.txnRepo.UpdateTransaction(ctx, transaction, message); dbErr != nil {
 		return domain.Transaction{}, fmt.Errorf("failed to update transaction status: %w (original error: %v)", dbErr, err)
 	}
 
@@ -240,25 +312,3 @@ func (uc *txnUseCase) decryptData(encryptedData string) ([]byte, error) {
 	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
 	return gcm.Open(nil, nonce, ciphertext, nil)
 }
-
-func (uc *txnUseCase) publishMessage(ctx context.Context, txnId uuid.UUID, chainID uuid.UUID, txHash common.Hash) {
-	// Prepare the message
-	message := domain.TxnMessage{
-		ChainID: chainID,
-		TxHash:  txHash.Hex(),
-	}
-
-	// Serialize the message to JSON
-	messageJSON, err := json.Marshal(message)
-	if err != nil {
-		log.Printf("Failed to marshal transaction message: %v", err)
-	}
-
-	// Publish message to Kafka
-	if err := uc.kafkaProducer.WriteMessages(ctx, kafka.Message{
-		Key:   []byte(txnId.String()),
-		Value: messageJSON,
-	}); err != nil {
-		log.Printf("Failed to publish message to Kafka: %v", err)
-	}
-}
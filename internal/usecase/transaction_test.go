@@ -2,8 +2,12 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"mpc/internal/domain"
 	"mpc/internal/infrastructure/config"
+	"mpc/internal/infrastructure/events"
 	"mpc/internal/infrastructure/kafka"
+	"mpc/internal/repository"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -12,27 +16,142 @@ import (
 
 var mockConfig = config.Config{
 	Kafka: config.KafkaConfig{
-		Brokers: []string{"localhost:29092"},
-		Topic:   "mpc",
+		Topic: "mpc",
 	},
 }
 
 func TestPublishMessage(t *testing.T) {
 
-	// Create a real Kafka writer
-	writer, err := kafka.NewKafkaProducer(&mockConfig)
+	// Publish to an in-memory broker, so the test does not need Kafka
+	broker := kafka.NewMemoryBroker()
+	publisher, err := broker.Publisher(mockConfig.Kafka.Topic)
 	if err != nil {
-		t.Fatalf("Failed to create Kafka writer: %v", err)
+		t.Fatalf("Failed to create publisher: %v", err)
 	}
-	defer writer.Close()
+	defer publisher.Close()
 
-	uc := &txnUseCase{kafkaProducer: writer}
+	uc := NewOutboxRelayUC(nil, &config.OutboxConfig{}, publisher).(*outboxRelayUseCase)
 
 	ctx := context.Background()
-	txnID := uuid.MustParse("48f8fc39-e2c5-467a-8429-14d68a1a4e37")
-	chainID := uuid.MustParse("1ec0a60a-08fe-4fb2-a6b2-ca2f506f8275")
-	txHash := common.HexToHash("0x1dd41fd648b9feb3b4ee6a8e7ed0e964d01d20188b9873650fcdded8b8e6a566")
+	txn := domain.Transaction{
+		ID:          uuid.MustParse("48f8fc39-e2c5-467a-8429-14d68a1a4e37"),
+		WalletID:    uuid.MustParse("9a3c2a51-5d0e-4a43-9d43-35f4c2b0f1c7"),
+		ChainID:     uuid.MustParse("1ec0a60a-08fe-4fb2-a6b2-ca2f506f8275"),
+		FromAddress: "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+		ToAddress:   "0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512",
+		Amount:      "1000000000000000",
+		TokenID:     uuid.MustParse("0b5e1c8e-7e54-4b8b-8f1e-4c8f3b0d2a61"),
+		Status:      domain.StatusSubmitted,
+		TxHash:      common.HexToHash("0x1dd41fd648b9feb3b4ee6a8e7ed0e964d01d20188b9873650fcdded8b8e6a566").Hex(),
+	}
+
+	message, err := newTxnMessage(mockConfig.Kafka.Topic, txn)
+	if err != nil {
+		t.Fatalf("Failed to build message: %v", err)
+	}
 
 	// Call the method you want to test
-	uc.publishMessage(ctx, txnID, chainID, txHash)
+	if errs := uc.publish(ctx, message.Topic, []domain.OutboxMessage{message}); errs[0] != nil {
+		t.Fatalf("Failed to publish message: %v", errs[0])
+	}
+
+	published := broker.Messages(mockConfig.Kafka.Topic)
+	if len(published) != 1 {
+		t.Fatalf("Expected 1 published message, got %d", len(published))
+	}
+	if string(published[0].Key) != txn.ID.String() {
+		t.Errorf("Expected key %s, got %s", txn.ID, published[0].Key)
+	}
+
+	var event domain.TransactionEvent
+	if _, err := events.DecodeAs(published[0].Value, domain.EventTransactionSubmitted, &event); err != nil {
+		t.Fatalf("Failed to decode published message: %v", err)
+	}
+	if event.TxHash != txn.TxHash {
+		t.Errorf("Expected tx hash %s, got %s", txn.TxHash, event.TxHash)
+	}
+}
+
+// outboxTxnRepo holds transactions and records the outbox messages written with each update.
+type outboxTxnRepo struct {
+	repository.TransactionRepository
+	transactions map[uuid.UUID]domain.Transaction
+	updates      [][]domain.OutboxMessage
+}
+
+func (r *outboxTxnRepo) GetTransaction(ctx context.Context, id uuid.UUID) (domain.Transaction, error) {
+	return r.transactions[id], nil
+}
+
+func (r *outboxTxnRepo) UpdateTransaction(ctx context.Context, txn domain.Transaction, messages ...domain.OutboxMessage) error {
+	r.transactions[txn.ID] = txn
+	r.updates = append(r.updates, messages)
+	return nil
+}
+
+func TestUpdateTransactionStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		family domain.ChainFamily
+		// wantReleased tells whether the family gave back the funds held for the transaction
+		wantReleased bool
+	}{
+		{name: "failed", family: domain.ChainFamilyEVM, wantReleased: true},
+		{
+			// The failure is recorded and announced even when nothing can be released
+			name:   "failed on a chain without a family",
+			family: domain.ChainFamilySolana,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := domain.Chain{ID: uuid.New(), Name: "Test", Family: tt.family}
+			txn := domain.Transaction{
+				ID:          uuid.New(),
+				WalletID:    uuid.New(),
+				ChainID:     chain.ID,
+				Direction:   domain.DirectionOutbound,
+				FromAddress: "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+				ToAddress:   "0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512",
+				Amount:      "0.001",
+				Status:      domain.StatusPending,
+			}
+			txnRepo := &outboxTxnRepo{transactions: map[uuid.UUID]domain.Transaction{txn.ID: txn}}
+			family := &stubEVMFamily{}
+			uc := &txnUseCase{
+				txnRepo:     txnRepo,
+				chainRepo:   &stubFamilyChains{chains: map[uuid.UUID]domain.Chain{chain.ID: chain}},
+				families:    NewChainFamilies(&stubFamily{ChainFamily: family, family: domain.ChainFamilyEVM}),
+				eventsTopic: "events",
+			}
+
+			cause := errors.New("insufficient funds")
+			if _, err := uc.updateTransactionStatus(context.Background(), txn.ID, domain.StatusFailed, cause); !errors.Is(err, cause) {
+				t.Fatalf("updateTransactionStatus() error = %v, want %v", err, cause)
+			}
+
+			// The status and its event are written together, in a single update
+			if got := txnRepo.transactions[txn.ID].Status; got != domain.StatusFailed {
+				t.Errorf("Status = %s, want %s", got, domain.StatusFailed)
+			}
+			if len(txnRepo.updates) != 1 || len(txnRepo.updates[0]) != 1 {
+				t.Fatalf("Updates wrote %v, want one update with one message", txnRepo.updates)
+			}
+			message := txnRepo.updates[0][0]
+			if message.Topic != "events" || string(message.Key) != txn.WalletID.String() {
+				t.Errorf("Message on %s keyed %s, want the events topic keyed by wallet %s", message.Topic, message.Key, txn.WalletID)
+			}
+			var event domain.TransactionEvent
+			if _, err := events.DecodeAs(message.Payload, domain.EventTransactionFailed, &event); err != nil {
+				t.Fatalf("Failed to decode message: %v", err)
+			}
+			if event.TransactionID != txn.ID {
+				t.Errorf("Event is about transaction %s, want %s", event.TransactionID, txn.ID)
+			}
+
+			if released := len(family.released) == 1 && family.released[0] == txn.ID; released != tt.wantReleased {
+				t.Errorf("Released %v, want the funds of %s released: %t", family.released, txn.ID, tt.wantReleased)
+			}
+		})
+	}
 }
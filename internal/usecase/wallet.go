@@ -3,32 +3,48 @@ package usecase
 import (
 	"context"
 	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
 	"mpc/internal/domain"
+	"mpc/internal/infrastructure/eddsa"
+	"mpc/internal/infrastructure/events"
 	"mpc/internal/repository"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
+// ErrWalletNotFound is returned for wallets that do not exist and for wallets of other users, so
+// callers cannot tell the two apart.
+var ErrWalletNotFound = errors.New("wallet not found")
+
 type WalletUseCase interface {
 	CreateWallet(ctx context.Context, userID uuid.UUID) (domain.Wallet, error)
 	GetWallet(ctx context.Context, id uuid.UUID) (domain.Wallet, error)
+	GetWalletByUserID(ctx context.Context, userID uuid.UUID) (domain.Wallet, error)
 	GetPrivateKey(ctx context.Context, userID uuid.UUID) (*ecdsa.PrivateKey, error)
+	GetBalances(ctx context.Context, userID uuid.UUID, walletID uuid.UUID) (domain.WalletBalancesResponse, error)
+	GetAddress(ctx context.Context, userID uuid.UUID, walletID uuid.UUID, family domain.ChainFamily) (domain.WalletAddress, error)
 }
 
 type walletUseCase struct {
-	walletRepo repository.WalletRepository
-	ethRepo    repository.EthereumRepository
+	walletRepo  repository.WalletRepository
+	ethRepo     repository.EthereumRepository
+	balanceRepo repository.BalanceRepository
+	eventsTopic string
 }
 
-func NewWalletUC(walletRepo repository.WalletRepository, ethRepo repository.EthereumRepository) WalletUseCase {
-	return &walletUseCase{walletRepo: walletRepo, ethRepo: ethRepo}
+// NewWalletUC returns the wallet usecase. Created wallets are announced on eventsTopic.
+func NewWalletUC(walletRepo repository.WalletRepository, ethRepo repository.EthereumRepository, balanceRepo repository.BalanceRepository, eventsTopic string) WalletUseCase {
+	return &walletUseCase{walletRepo: walletRepo, ethRepo: ethRepo, balanceRepo: balanceRepo, eventsTopic: eventsTopic}
 }
 
 var _ WalletUseCase = (*walletUseCase)(nil)
 
 func (uc *walletUseCase) CreateWallet(ctx context.Context, userID uuid.UUID) (domain.Wallet, error) {
-	privateKey, address, err := uc.ethRepo.CreateWallet()
+	privateKey, address, err := uc.ethRepo.CreateWallet(ctx)
 	if err != nil {
 		return domain.Wallet{}, err
 	}
@@ -37,33 +53,132 @@ func (uc *walletUseCase) CreateWallet(ctx context.Context, userID uuid.UUID) (do
 	privateKeyBytes := crypto.FromECDSA(privateKey)
 
 	// Encrypt the private key
-	encryptedPrivateKey, err := uc.ethRepo.EncryptPrivateKey(privateKeyBytes)
+	encryptedPrivateKey, err := uc.ethRepo.EncryptPrivateKey(ctx, privateKeyBytes)
 	if err != nil {
 		return domain.Wallet{}, err
 	}
 
 	wallet := domain.CreateWalletParams{
+		ID:                  uuid.New(),
 		UserID:              userID,
 		Address:             address.Hex(),
 		EncryptedPrivateKey: encryptedPrivateKey,
 	}
 
-	return uc.walletRepo.CreateWallet(ctx, wallet)
+	created, err := events.NewWalletMessage(uc.eventsTopic, wallet.ID, domain.EventWalletCreated, domain.WalletEvent{
+		WalletID: wallet.ID,
+		UserID:   wallet.UserID,
+		Address:  wallet.Address,
+	})
+	if err != nil {
+		return domain.Wallet{}, err
+	}
+
+	return uc.walletRepo.CreateWallet(ctx, wallet, created)
 }
 
 func (uc *walletUseCase) GetWallet(ctx context.Context, id uuid.UUID) (domain.Wallet, error) {
 	return uc.walletRepo.GetWallet(ctx, id)
 }
 
+func (uc *walletUseCase) GetWalletByUserID(ctx context.Context, userID uuid.UUID) (domain.Wallet, error) {
+	return uc.walletRepo.GetWalletByUserID(ctx, userID)
+}
+
 func (uc *walletUseCase) GetPrivateKey(ctx context.Context, userID uuid.UUID) (*ecdsa.PrivateKey, error) {
 	wallet, err := uc.walletRepo.GetWalletByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
 
-	privateKeyBytes, err := uc.ethRepo.DecryptPrivateKey(wallet.EncryptedPrivateKey)
+	privateKeyBytes, err := uc.ethRepo.DecryptPrivateKey(ctx, wallet.EncryptedPrivateKey)
 	if err != nil {
 		return nil, err
 	}
 	return crypto.ToECDSA(privateKeyBytes)
 }
+
+// GetBalances returns the stored balances of the wallet. They are refreshed by the worker when a
+// transaction is mined and when a transaction or deposit becomes final, so each one carries the time it was last read from the chain.
+// The native balance is split into what is reserved for in-flight transactions and what is available.
+func (uc *walletUseCase) GetBalances(ctx context.Context, userID uuid.UUID, walletID uuid.UUID) (domain.WalletBalancesResponse, error) {
+	wallet, err := getOwnedWallet(ctx, uc, userID, walletID)
+	if err != nil {
+		return domain.WalletBalancesResponse{}, err
+	}
+
+	balances, err := uc.balanceRepo.GetBalancesByWalletID(ctx, wallet.ID)
+	if err != nil {
+		return domain.WalletBalancesResponse{}, fmt.Errorf("failed to get balances: %w", err)
+	}
+
+	response := domain.WalletBalancesResponse{WalletID: wallet.ID, Balances: make([]domain.WalletBalance, 0, len(balances))}
+	for _, balance := range balances {
+		pending := new(big.Int)
+		if balance.TokenID == uuid.Nil {
+			pending, err = uc.balanceRepo.GetReservedAmount(ctx, wallet.ID, balance.ChainID)
+			if err != nil {
+				return domain.WalletBalancesResponse{}, fmt.Errorf("failed to get reserved balance: %w", err)
+			}
+		}
+
+		available := new(big.Int).Sub(balance.Balance, pending)
+		if available.Sign() < 0 {
+			available.SetInt64(0)
+		}
+
+		response.Balances = append(response.Balances, domain.WalletBalance{
+			ChainID:   balance.ChainID,
+			TokenID:   balance.TokenID,
+			Balance:   balance.Balance.String(),
+			Pending:   pending.String(),
+			Available: available.String(),
+			UpdatedAt: balance.UpdatedAt,
+		})
+	}
+	return response, nil
+}
+
+// GetAddress returns the address of the wallet on chains of a family. EVM chains share the wallet's
+// address; Ed25519 chains use the address of the Ed25519 key derived from the wallet key. Bitcoin
+// wallets have several addresses, listed by the Bitcoin usecase.
+func (uc *walletUseCase) GetAddress(ctx context.Context, userID uuid.UUID, walletID uuid.UUID, family domain.ChainFamily) (domain.WalletAddress, error) {
+	wallet, err := getOwnedWallet(ctx, uc, userID, walletID)
+	if err != nil {
+		return domain.WalletAddress{}, err
+	}
+
+	response := domain.WalletAddress{WalletID: wallet.ID, Family: family}
+	switch {
+	case family == domain.ChainFamilyEVM:
+		response.Address = wallet.Address
+	case eddsa.IsEd25519Family(family):
+		privateKey, err := uc.GetPrivateKey(ctx, userID)
+		if err != nil {
+			return domain.WalletAddress{}, fmt.Errorf("failed to get private key: %w", err)
+		}
+		signer := eddsa.NewLocalSigner(eddsa.WalletKey(privateKey))
+		if response.Address, err = eddsa.Address(family, signer.PublicKey()); err != nil {
+			return domain.WalletAddress{}, err
+		}
+	default:
+		return domain.WalletAddress{}, fmt.Errorf("%w: no single address on %s chains", ErrUnsupportedChain, family)
+	}
+	return response, nil
+}
+
+// getOwnedWallet loads a wallet and makes sure it belongs to the user. It returns ErrWalletNotFound
+// otherwise.
+func getOwnedWallet(ctx context.Context, walletUC WalletUseCase, userID uuid.UUID, walletID uuid.UUID) (domain.Wallet, error) {
+	wallet, err := walletUC.GetWallet(ctx, walletID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return domain.Wallet{}, ErrWalletNotFound
+	}
+	if err != nil {
+		return domain.Wallet{}, fmt.Errorf("failed to get wallet: %w", err)
+	}
+	if wallet.UserID != userID {
+		return domain.Wallet{}, ErrWalletNotFound
+	}
+	return wallet, nil
+}